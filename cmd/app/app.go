@@ -3,66 +3,184 @@ package app
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
-	"firebase.google.com/go/auth"
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	_ "github.com/lib/pq"
+	"github.com/sarvochcha01/enlace-backend/internal/auth"
+	"github.com/sarvochcha01/enlace-backend/internal/config"
+	"github.com/sarvochcha01/enlace-backend/internal/database"
+	"github.com/sarvochcha01/enlace-backend/internal/logging"
+	"github.com/sarvochcha01/enlace-backend/internal/metrics"
+	"github.com/sarvochcha01/enlace-backend/internal/middlewares"
+	"github.com/sarvochcha01/enlace-backend/internal/repositories"
 	"github.com/sarvochcha01/enlace-backend/internal/routes"
+	"github.com/sarvochcha01/enlace-backend/internal/websockets"
 )
 
 type App struct {
-	router     chi.Router
-	db         *sql.DB
-	authClient *auth.Client
+	config   *config.Config
+	logger   *slog.Logger
+	metrics  *metrics.Metrics
+	router   chi.Router
+	db       *sql.DB
+	verifier auth.TokenVerifier
+	wsHub    *websockets.WebSocketHub
 }
 
 func (a *App) Initialise() {
 
-	InitFirebase()
 	var err error
-	a.authClient, err = FirebaseApp.Auth(context.Background())
+	a.config, err = loadConfig()
 	if err != nil {
-		log.Fatal("Failed to initialize Firebase Auth: ", err)
+		log.Fatal(err)
 	}
 
-	connStr := os.Getenv("DATABASE_URL")
-	if connStr == "" {
-		connStr = "user=postgres password=123456 dbname=enlace sslmode=disable host=localhost port=5431"
+	a.logger = logging.New(os.Stdout, a.config.Logging)
+	slog.SetDefault(a.logger)
+
+	a.verifier, err = newTokenVerifier(a.config.Auth)
+	if err != nil {
+		a.fatal("failed to initialize token verifier", err)
 	}
 
-	a.db, err = sql.Open("postgres", connStr)
+	a.db, err = openDB(a.config.Database)
+	if err != nil {
+		a.fatal("failed to connect to db", err)
+	}
 
+	migrator, err := database.NewMigrator(a.db)
 	if err != nil {
-		log.Fatal("Failed to open db: ", err)
-		return
+		a.fatal("failed to load migrations", err)
 	}
 
-	if err := a.db.Ping(); err != nil {
-		log.Fatal("Error connecting to db: ", err)
-		return
+	if a.config.Features.AutoMigrate {
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			a.fatal("failed to apply migrations", err)
+		}
+		for _, m := range applied {
+			a.logger.Info("applied migration", "version", m.Version, "name", m.Name)
+		}
 	}
 
+	if err := migrator.CheckUpToDate(context.Background()); err != nil {
+		if errors.Is(err, database.ErrSchemaOutOfDate) {
+			a.fatal("schema out of date, run `migrate up` before starting the server", err)
+		}
+		a.fatal("failed to check schema version", err)
+	}
+
+	a.metrics = metrics.New()
+	a.metrics.RegisterDBStats(a.db)
+
 	a.router = chi.NewRouter()
 
+	// First so that the request ID, access log and metrics cover CORS rejections and timeouts too
+	a.router.Use(middlewares.RequestLogger(a.logger))
+	a.router.Use(middlewares.Metrics(a.metrics))
+
 	a.router.Use(cors.New(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:5173", "https://enlace-frontend.vercel.app"}, // Frontend URL
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},                     // Allowed HTTP methods
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},                               // Allowed headers
-		ExposedHeaders:   []string{"Content-Length"},
+		AllowedOrigins:   a.config.CORS.AllowedOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}, // Allowed HTTP methods
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "If-Match", middlewares.RequestIDHeader},
+		ExposedHeaders:   []string{"Content-Length", "ETag", middlewares.RequestIDHeader},
 		AllowCredentials: true,
 		MaxAge:           300, // Cache preflight for 5 minutes
 	}).Handler)
 
-	routes.SetupRoutes(a.router, a.db, a.authClient)
+	// Deadline for every request context so slow queries are cancelled instead of piling up
+	a.router.Use(middleware.Timeout(a.config.Server.RequestTimeout))
+
+	a.wsHub = websockets.NewWebSocketHub(a.verifier, a.config.WebSocket.AllowedOrigins, a.metrics)
+	a.metrics.RegisterWebSocketClients(a.wsHub.ConnectedClients)
+	go a.wsHub.Run()
+
+	routes.SetupRoutes(a.router, repositories.New(a.db), a.verifier, a.wsHub, a.config, a.metrics)
 }
 
+// Run serves until SIGINT or SIGTERM, then drains HTTP requests, closes WebSockets and finally the database
 func (a *App) Run() {
-	log.Println("Server running on port 3000")
-	if err := http.ListenAndServe(":3000", a.router); err != nil {
-		log.Fatal("Server failed to start:", err)
+	server := &http.Server{
+		Addr:              a.config.Server.Address,
+		Handler:           a.router,
+		ReadTimeout:       a.config.Server.ReadTimeout,
+		ReadHeaderTimeout: a.config.Server.ReadHeaderTimeout,
+		WriteTimeout:      a.config.Server.WriteTimeout,
+		IdleTimeout:       a.config.Server.IdleTimeout,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		a.logger.Info("server listening", "address", a.config.Server.Address)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	select {
+	case err := <-serverErr:
+		a.fatal("server failed to start", err)
+	case <-ctx.Done():
+	}
+
+	a.logger.Info("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.config.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		a.logger.Warn("http server did not drain cleanly", "error", err)
 	}
+
+	// Hijacked WebSocket connections are not covered by server.Shutdown
+	if err := a.wsHub.Shutdown(shutdownCtx); err != nil {
+		a.logger.Warn("websocket hub did not drain cleanly", "error", err)
+	}
+
+	if err := a.db.Close(); err != nil {
+		a.logger.Error("failed to close db", "error", err)
+	}
+
+	a.logger.Info("server stopped")
+}
+
+// fatal logs at error level and exits; the config has to be loaded first
+func (a *App) fatal(msg string, err error) {
+	a.logger.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// loadConfig reads the optional file named by CONFIG_FILE, overlaid with environment variables
+func loadConfig() (*config.Config, error) {
+	return config.Load(os.Getenv("CONFIG_FILE"))
+}
+
+func openDB(cfg config.DatabaseConfig) (*sql.DB, error) {
+	db, err := sql.Open("postgres", cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
 }
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/sarvochcha01/enlace-backend/internal/database"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status"
+
+// Migrate runs the `migrate` subcommand against the configured database and exits on failure
+func (a *App) Migrate(args []string) {
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := openDB(cfg.Database)
+	if err != nil {
+		log.Fatal("Error connecting to db: ", err)
+	}
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Fatal("Failed to load migrations: ", err)
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			log.Printf("Applied %04d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(applied) == 0 {
+			log.Println("Schema already up to date")
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				log.Fatal("steps must be a positive integer")
+			}
+		}
+
+		rolledBack, err := migrator.Down(ctx, steps)
+		for _, m := range rolledBack {
+			log.Printf("Rolled back %04d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(rolledBack) == 0 {
+			log.Println("Nothing to roll back")
+		}
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(os.Stdout, "%04d_%-40s %s\n", s.Version, s.Name, appliedAt)
+		}
+
+	default:
+		log.Fatal(migrateUsage)
+	}
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sarvochcha01/enlace-backend/internal/auth"
+	"github.com/sarvochcha01/enlace-backend/internal/config"
+)
+
+// newTokenVerifier builds the verifier selected by auth.mode. Firebase is the default, the jwt
+// mode verifies locally signed tokens so the server can run without Google credentials.
+func newTokenVerifier(cfg config.AuthConfig) (auth.TokenVerifier, error) {
+	switch cfg.Mode {
+	case config.AuthModeFirebase:
+		firebaseApp, err := InitFirebase(cfg.FirebaseCredentialsPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Firebase: %w", err)
+		}
+
+		client, err := firebaseApp.Auth(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Firebase Auth: %w", err)
+		}
+
+		return auth.NewFirebaseVerifier(client), nil
+
+	case config.AuthModeJWT:
+		options := auth.JWTOptions{
+			Algorithm: cfg.JWT.Algorithm,
+			Secret:    []byte(cfg.JWT.Secret),
+			Issuer:    cfg.JWT.Issuer,
+			Audience:  cfg.JWT.Audience,
+		}
+
+		if options.Algorithm == auth.AlgorithmRS256 {
+			publicKey, err := auth.LoadRSAPublicKey(cfg.JWT.PublicKeyPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load JWT public key: %w", err)
+			}
+			options.PublicKey = publicKey
+		}
+
+		return auth.NewJWTVerifier(options)
+
+	default:
+		return nil, fmt.Errorf("unknown auth mode %q", cfg.Mode)
+	}
+}
@@ -4,12 +4,15 @@ go 1.23.5
 
 require (
 	firebase.google.com/go v3.13.0+incompatible
+	github.com/BurntSushi/toml v1.4.0
 	github.com/go-chi/chi/v5 v5.2.1
 	github.com/go-chi/cors v1.2.1
+	github.com/golang-jwt/jwt/v5 v5.2.2
 	github.com/google/uuid v1.6.0
 	github.com/gorilla/websocket v1.5.3
 	github.com/lib/pq v1.10.9
 	google.golang.org/api v0.221.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,104 @@
+package apperrors
+
+import "errors"
+
+type Kind string
+
+const (
+	KindValidation   Kind = "validation"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindUnavailable  Kind = "unavailable"
+	KindInternal     Kind = "internal"
+
+	// KindPreconditionFailed is a conditional write, e.g. with If-Match, to a resource that has changed since
+	KindPreconditionFailed Kind = "precondition_failed"
+)
+
+// Sentinels for errors.Is checks, e.g. errors.Is(err, apperrors.ErrNotFound) matches any not found error
+var (
+	ErrValidation   = &Error{Kind: KindValidation}
+	ErrUnauthorized = &Error{Kind: KindUnauthorized}
+	ErrForbidden    = &Error{Kind: KindForbidden}
+	ErrNotFound     = &Error{Kind: KindNotFound}
+	ErrConflict     = &Error{Kind: KindConflict}
+	ErrUnavailable  = &Error{Kind: KindUnavailable}
+
+	ErrPreconditionFailed = &Error{Kind: KindPreconditionFailed}
+)
+
+// Error is a domain error that the HTTP layer can map to a status code. Message is safe to show to clients.
+type Error struct {
+	Kind    Kind
+	Message string
+	Details map[string]string
+	// Current is the resource as it is now, sent back with a failed precondition so the client can
+	// merge its change and retry without reading it again
+	Current any
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches the bare sentinels by kind so callers don't need to care about the message
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return t.Message == "" && t.Details == nil && t.Current == nil && t.Err == nil && t.Kind == e.Kind
+}
+
+// Wrap keeps the underlying cause for logs and errors.Is without exposing it to clients
+func (e *Error) Wrap(err error) *Error {
+	e.Err = err
+	return e
+}
+
+func Validation(message string, details map[string]string) *Error {
+	return &Error{Kind: KindValidation, Message: message, Details: details}
+}
+
+func Unauthorized(message string) *Error {
+	return &Error{Kind: KindUnauthorized, Message: message}
+}
+
+func Forbidden(message string) *Error {
+	return &Error{Kind: KindForbidden, Message: message}
+}
+
+func NotFound(message string) *Error {
+	return &Error{Kind: KindNotFound, Message: message}
+}
+
+func Conflict(message string) *Error {
+	return &Error{Kind: KindConflict, Message: message}
+}
+
+func PreconditionFailed(message string) *Error {
+	return &Error{Kind: KindPreconditionFailed, Message: message}
+}
+
+func Unavailable(message string) *Error {
+	return &Error{Kind: KindUnavailable, Message: message}
+}
+
+// KindOf reports the kind of the first *Error in err's chain, or KindInternal if there is none
+func KindOf(err error) Kind {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Kind
+	}
+	return KindInternal
+}
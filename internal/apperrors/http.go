@@ -0,0 +1,62 @@
+package apperrors
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Response is the JSON envelope every error is rendered as
+type Response struct {
+	Code    Kind              `json:"code"`
+	Message string            `json:"message"`
+	Details map[string]string `json:"details,omitempty"`
+	Current any               `json:"current,omitempty"`
+}
+
+func StatusCode(kind Kind) int {
+	switch kind {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	case KindUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ResponseOf is the envelope err is rendered as. Errors without a domain kind are reported as
+// internal errors using fallbackMessage so that driver and SQL details never leak to clients.
+func ResponseOf(err error, fallbackMessage string) Response {
+	response := Response{Code: KindInternal, Message: fallbackMessage}
+
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		response = Response{Code: appErr.Kind, Message: appErr.Message, Details: appErr.Details, Current: appErr.Current}
+	}
+
+	if response.Message == "" {
+		response.Message = http.StatusText(StatusCode(response.Code))
+	}
+
+	return response
+}
+
+// Write renders err as a JSON error envelope, see ResponseOf
+func Write(w http.ResponseWriter, err error, fallbackMessage string) {
+	response := ResponseOf(err, fallbackMessage)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(StatusCode(response.Code))
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	firebaseauth "firebase.google.com/go/auth"
+)
+
+type firebaseVerifier struct {
+	client *firebaseauth.Client
+}
+
+func NewFirebaseVerifier(client *firebaseauth.Client) TokenVerifier {
+	return &firebaseVerifier{client: client}
+}
+
+func (v *firebaseVerifier) VerifyToken(ctx context.Context, idToken string) (*Token, error) {
+	token, err := v.client.VerifyIDToken(ctx, idToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	return &Token{UID: token.UID, Claims: token.Claims}, nil
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+// JWTOptions configures a verifier for locally signed tokens. Secret is used for HS256 and
+// PublicKey for RS256. Issuer and Audience are only checked when set.
+type JWTOptions struct {
+	Algorithm string
+	Secret    []byte
+	PublicKey *rsa.PublicKey
+	Issuer    string
+	Audience  string
+}
+
+type jwtVerifier struct {
+	options JWTOptions
+	parser  *jwt.Parser
+}
+
+func NewJWTVerifier(options JWTOptions) (TokenVerifier, error) {
+	switch options.Algorithm {
+	case AlgorithmHS256:
+		if len(options.Secret) == 0 {
+			return nil, errors.New("HS256 requires a secret")
+		}
+	case AlgorithmRS256:
+		if options.PublicKey == nil {
+			return nil, errors.New("RS256 requires a public key")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", options.Algorithm)
+	}
+
+	parserOptions := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{options.Algorithm}),
+		jwt.WithExpirationRequired(),
+	}
+	if options.Issuer != "" {
+		parserOptions = append(parserOptions, jwt.WithIssuer(options.Issuer))
+	}
+	if options.Audience != "" {
+		parserOptions = append(parserOptions, jwt.WithAudience(options.Audience))
+	}
+
+	return &jwtVerifier{options: options, parser: jwt.NewParser(parserOptions...)}, nil
+}
+
+// LoadRSAPublicKey reads a PEM encoded RSA public key for RS256 verification
+func LoadRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return jwt.ParseRSAPublicKeyFromPEM(pem)
+}
+
+func (v *jwtVerifier) VerifyToken(ctx context.Context, idToken string) (*Token, error) {
+	claims := jwt.MapClaims{}
+
+	_, err := v.parser.ParseWithClaims(idToken, claims, func(t *jwt.Token) (interface{}, error) {
+		if v.options.Algorithm == AlgorithmHS256 {
+			return v.options.Secret, nil
+		}
+		return v.options.PublicKey, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+
+	return &Token{UID: subject, Claims: claims}, nil
+}
@@ -0,0 +1,113 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sarvochcha01/enlace-backend/internal/auth"
+)
+
+func TestJWTVerifier(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	secret := []byte("test-secret")
+
+	hs256 := auth.JWTOptions{Algorithm: auth.AlgorithmHS256, Secret: secret}
+	rs256 := auth.JWTOptions{Algorithm: auth.AlgorithmRS256, PublicKey: &key.PublicKey}
+	strict := auth.JWTOptions{Algorithm: auth.AlgorithmHS256, Secret: secret, Issuer: "enlace", Audience: "enlace-api"}
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{"sub": "alice-uid", "iss": "enlace", "aud": "enlace-api", "exp": time.Now().Add(time.Hour).Unix()}
+	}
+	with := func(name string, value any) jwt.MapClaims {
+		claims := valid()
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name    string
+		options auth.JWTOptions
+		method  jwt.SigningMethod
+		claims  jwt.MapClaims
+		wantUID string // empty when the token must be refused
+	}{
+		{name: "valid HS256", options: hs256, method: jwt.SigningMethodHS256, claims: valid(), wantUID: "alice-uid"},
+		{name: "valid RS256", options: rs256, method: jwt.SigningMethodRS256, claims: valid(), wantUID: "alice-uid"},
+		{name: "issuer and audience match", options: strict, method: jwt.SigningMethodHS256, claims: valid(), wantUID: "alice-uid"},
+		{name: "HS256 token against an RS256 key", options: rs256, method: jwt.SigningMethodHS256, claims: valid()},
+		{name: "RS256 token against an HS256 secret", options: hs256, method: jwt.SigningMethodRS256, claims: valid()},
+		{name: "unsigned token", options: hs256, method: jwt.SigningMethodNone, claims: valid()},
+		{name: "expired", options: hs256, method: jwt.SigningMethodHS256, claims: with("exp", time.Now().Add(-time.Minute).Unix())},
+		{name: "no expiry", options: hs256, method: jwt.SigningMethodHS256, claims: with("exp", nil)},
+		{name: "wrong issuer", options: strict, method: jwt.SigningMethodHS256, claims: with("iss", "someone-else")},
+		{name: "wrong audience", options: strict, method: jwt.SigningMethodHS256, claims: with("aud", "another-api")},
+		{name: "no subject", options: hs256, method: jwt.SigningMethodHS256, claims: with("sub", nil)},
+		{name: "empty subject", options: hs256, method: jwt.SigningMethodHS256, claims: with("sub", "")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var signingKey any
+			switch tt.method {
+			case jwt.SigningMethodHS256:
+				signingKey = secret
+			case jwt.SigningMethodRS256:
+				signingKey = key
+			default:
+				signingKey = jwt.UnsafeAllowNoneSignatureType
+			}
+			token, err := jwt.NewWithClaims(tt.method, tt.claims).SignedString(signingKey)
+			if err != nil {
+				t.Fatalf("sign token: %v", err)
+			}
+
+			verifier, err := auth.NewJWTVerifier(tt.options)
+			if err != nil {
+				t.Fatalf("create verifier: %v", err)
+			}
+
+			verified, err := verifier.VerifyToken(context.Background(), token)
+			if tt.wantUID == "" {
+				if !errors.Is(err, auth.ErrInvalidToken) {
+					t.Fatalf("expected the token to be refused, got %+v, %v", verified, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if verified.UID != tt.wantUID {
+				t.Fatalf("expected uid %s, got %s", tt.wantUID, verified.UID)
+			}
+		})
+	}
+}
+
+func TestNewJWTVerifierErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		options auth.JWTOptions
+	}{
+		{"HS256 without a secret", auth.JWTOptions{Algorithm: auth.AlgorithmHS256}},
+		{"RS256 without a public key", auth.JWTOptions{Algorithm: auth.AlgorithmRS256}},
+		{"unsupported algorithm", auth.JWTOptions{Algorithm: "none", Secret: []byte("test-secret")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := auth.NewJWTVerifier(tt.options); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
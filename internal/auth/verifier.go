@@ -0,0 +1,19 @@
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrInvalidToken = errors.New("invalid authentication token")
+
+// Token is the verified identity of a caller, independent of the identity provider that issued it
+type Token struct {
+	UID    string
+	Claims map[string]interface{}
+}
+
+// TokenVerifier checks a bearer token and returns the identity it carries
+type TokenVerifier interface {
+	VerifyToken(ctx context.Context, idToken string) (*Token, error)
+}
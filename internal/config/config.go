@@ -0,0 +1,370 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/sarvochcha01/enlace-backend/internal/auth"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	AuthModeFirebase = "firebase"
+	AuthModeJWT      = "jwt"
+
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+type Config struct {
+	Server    ServerConfig    `yaml:"server" toml:"server"`
+	Database  DatabaseConfig  `yaml:"database" toml:"database"`
+	CORS      CORSConfig      `yaml:"cors" toml:"cors"`
+	Auth      AuthConfig      `yaml:"auth" toml:"auth"`
+	WebSocket WebSocketConfig `yaml:"websocket" toml:"websocket"`
+	Features  FeatureConfig   `yaml:"features" toml:"features"`
+	Logging   LoggingConfig   `yaml:"logging" toml:"logging"`
+}
+
+type ServerConfig struct {
+	Address           string        `yaml:"address" toml:"address"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" toml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" toml:"read_header_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	// RequestTimeout is the deadline put on every request context, cancelling its queries when hit
+	RequestTimeout time.Duration `yaml:"request_timeout" toml:"request_timeout"`
+	// ShutdownTimeout bounds how long in-flight requests and sockets get to drain on SIGTERM
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+}
+
+type DatabaseConfig struct {
+	URL             string        `yaml:"url" toml:"url"`
+	MaxOpenConns    int           `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+}
+
+type CORSConfig struct {
+	AllowedOrigins []string `yaml:"allowed_origins" toml:"allowed_origins"`
+}
+
+type AuthConfig struct {
+	Mode                    string    `yaml:"mode" toml:"mode"`
+	FirebaseCredentialsPath string    `yaml:"firebase_credentials_path" toml:"firebase_credentials_path"`
+	JWT                     JWTConfig `yaml:"jwt" toml:"jwt"`
+}
+
+type JWTConfig struct {
+	Algorithm     string `yaml:"algorithm" toml:"algorithm"`
+	Secret        string `yaml:"secret" toml:"secret"`
+	PublicKeyPath string `yaml:"public_key_path" toml:"public_key_path"`
+	Issuer        string `yaml:"issuer" toml:"issuer"`
+	Audience      string `yaml:"audience" toml:"audience"`
+}
+
+type WebSocketConfig struct {
+	AllowedOrigins []string `yaml:"allowed_origins" toml:"allowed_origins"`
+}
+
+type FeatureConfig struct {
+	// AutoMigrate applies pending migrations on startup instead of refusing to start
+	AutoMigrate bool `yaml:"auto_migrate" toml:"auto_migrate"`
+	// WebSockets serves the live notification socket
+	WebSockets bool `yaml:"websockets" toml:"websockets"`
+	// Metrics serves Prometheus metrics at /metrics
+	Metrics bool `yaml:"metrics" toml:"metrics"`
+}
+
+type LoggingConfig struct {
+	// Level is one of debug, info, warn or error
+	Level  string `yaml:"level" toml:"level"`
+	Format string `yaml:"format" toml:"format"`
+}
+
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Address:           ":3000",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			RequestTimeout:    10 * time.Second,
+			ShutdownTimeout:   20 * time.Second,
+		},
+		Database: DatabaseConfig{
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"http://localhost:5173", "https://enlace-frontend.vercel.app"},
+		},
+		Auth: AuthConfig{
+			Mode:                    AuthModeFirebase,
+			FirebaseCredentialsPath: "config/service-account-key.json",
+			JWT: JWTConfig{
+				Algorithm: auth.AlgorithmHS256,
+			},
+		},
+		WebSocket: WebSocketConfig{
+			AllowedOrigins: []string{"http://localhost:5173", "https://enlace-frontend.vercel.app"},
+		},
+		Features: FeatureConfig{
+			AutoMigrate: false,
+			WebSockets:  true,
+			Metrics:     true,
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: LogFormatJSON,
+		},
+	}
+}
+
+// Load builds the configuration from defaults, then the optional file at path (YAML or TOML,
+// picked by extension), then environment variables, and validates the result.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(contents, c)
+	case ".toml":
+		err = toml.Unmarshal(contents, c)
+	default:
+		return fmt.Errorf("unsupported config file type %q (use .yaml, .yml or .toml)", filepath.Ext(path))
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	var errs []error
+
+	setString := func(name string, target *string) {
+		if value, ok := os.LookupEnv(name); ok {
+			*target = value
+		}
+	}
+	setList := func(name string, target *[]string) {
+		if value, ok := os.LookupEnv(name); ok {
+			*target = splitList(value)
+		}
+	}
+	setInt := func(name string, target *int) {
+		if value, ok := os.LookupEnv(name); ok {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be an integer, got %q", name, value))
+				return
+			}
+			*target = parsed
+		}
+	}
+	setBool := func(name string, target *bool) {
+		if value, ok := os.LookupEnv(name); ok {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be a boolean, got %q", name, value))
+				return
+			}
+			*target = parsed
+		}
+	}
+	setDuration := func(name string, target *time.Duration) {
+		if value, ok := os.LookupEnv(name); ok {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be a duration such as 30m, got %q", name, value))
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	setString("SERVER_ADDRESS", &c.Server.Address)
+	// PORT is what most hosting platforms inject
+	if port, ok := os.LookupEnv("PORT"); ok {
+		c.Server.Address = ":" + port
+	}
+	setDuration("SERVER_READ_TIMEOUT", &c.Server.ReadTimeout)
+	setDuration("SERVER_READ_HEADER_TIMEOUT", &c.Server.ReadHeaderTimeout)
+	setDuration("SERVER_WRITE_TIMEOUT", &c.Server.WriteTimeout)
+	setDuration("SERVER_IDLE_TIMEOUT", &c.Server.IdleTimeout)
+	setDuration("SERVER_REQUEST_TIMEOUT", &c.Server.RequestTimeout)
+	setDuration("SERVER_SHUTDOWN_TIMEOUT", &c.Server.ShutdownTimeout)
+
+	setString("DATABASE_URL", &c.Database.URL)
+	setInt("DB_MAX_OPEN_CONNS", &c.Database.MaxOpenConns)
+	setInt("DB_MAX_IDLE_CONNS", &c.Database.MaxIdleConns)
+	setDuration("DB_CONN_MAX_LIFETIME", &c.Database.ConnMaxLifetime)
+
+	setList("CORS_ALLOWED_ORIGINS", &c.CORS.AllowedOrigins)
+
+	setString("AUTH_MODE", &c.Auth.Mode)
+	setString("FIREBASE_CREDENTIALS_PATH", &c.Auth.FirebaseCredentialsPath)
+	setString("AUTH_JWT_ALGORITHM", &c.Auth.JWT.Algorithm)
+	setString("AUTH_JWT_SECRET", &c.Auth.JWT.Secret)
+	setString("AUTH_JWT_PUBLIC_KEY_PATH", &c.Auth.JWT.PublicKeyPath)
+	setString("AUTH_JWT_ISSUER", &c.Auth.JWT.Issuer)
+	setString("AUTH_JWT_AUDIENCE", &c.Auth.JWT.Audience)
+
+	setList("WS_ALLOWED_ORIGINS", &c.WebSocket.AllowedOrigins)
+
+	setBool("FEATURE_AUTO_MIGRATE", &c.Features.AutoMigrate)
+	setBool("FEATURE_WEBSOCKETS", &c.Features.WebSockets)
+	setBool("FEATURE_METRICS", &c.Features.Metrics)
+
+	setString("LOG_LEVEL", &c.Logging.Level)
+	setString("LOG_FORMAT", &c.Logging.Format)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid environment: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// Validate reports every problem with the configuration at once
+func (c *Config) Validate() error {
+	var problems []string
+
+	if c.Server.Address == "" {
+		problems = append(problems, "server.address is required (SERVER_ADDRESS or PORT)")
+	}
+	timeouts := []struct {
+		field string
+		value time.Duration
+	}{
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.read_header_timeout", c.Server.ReadHeaderTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.request_timeout", c.Server.RequestTimeout},
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value <= 0 {
+			problems = append(problems, timeout.field+" must be positive")
+		}
+	}
+
+	if c.Database.URL == "" {
+		problems = append(problems, "database.url is required (DATABASE_URL)")
+	}
+	if c.Database.MaxOpenConns < 0 {
+		problems = append(problems, "database.max_open_conns must not be negative")
+	}
+	if c.Database.MaxIdleConns < 0 {
+		problems = append(problems, "database.max_idle_conns must not be negative")
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		problems = append(problems, "database.max_idle_conns must not exceed database.max_open_conns")
+	}
+	if c.Database.ConnMaxLifetime < 0 {
+		problems = append(problems, "database.conn_max_lifetime must not be negative")
+	}
+
+	problems = append(problems, validateOrigins("cors.allowed_origins", c.CORS.AllowedOrigins)...)
+	problems = append(problems, validateOrigins("websocket.allowed_origins", c.WebSocket.AllowedOrigins)...)
+
+	switch c.Auth.Mode {
+	case AuthModeFirebase:
+		if c.Auth.FirebaseCredentialsPath == "" {
+			problems = append(problems, "auth.firebase_credentials_path is required in firebase mode (FIREBASE_CREDENTIALS_PATH)")
+		}
+	case AuthModeJWT:
+		switch c.Auth.JWT.Algorithm {
+		case auth.AlgorithmHS256:
+			if c.Auth.JWT.Secret == "" {
+				problems = append(problems, "auth.jwt.secret is required for HS256 (AUTH_JWT_SECRET)")
+			}
+		case auth.AlgorithmRS256:
+			if c.Auth.JWT.PublicKeyPath == "" {
+				problems = append(problems, "auth.jwt.public_key_path is required for RS256 (AUTH_JWT_PUBLIC_KEY_PATH)")
+			}
+		default:
+			problems = append(problems, fmt.Sprintf("auth.jwt.algorithm must be %s or %s, got %q", auth.AlgorithmHS256, auth.AlgorithmRS256, c.Auth.JWT.Algorithm))
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("auth.mode must be %q or %q, got %q", AuthModeFirebase, AuthModeJWT, c.Auth.Mode))
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Logging.Level)); err != nil {
+		problems = append(problems, fmt.Sprintf("logging.level must be debug, info, warn or error, got %q", c.Logging.Level))
+	}
+	if c.Logging.Format != LogFormatJSON && c.Logging.Format != LogFormatText {
+		problems = append(problems, fmt.Sprintf("logging.format must be %q or %q, got %q", LogFormatJSON, LogFormatText, c.Logging.Format))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+
+	return nil
+}
+
+func validateOrigins(field string, origins []string) []string {
+	var problems []string
+
+	for _, origin := range origins {
+		if origin == "*" {
+			continue
+		}
+
+		parsed, err := url.Parse(origin)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" || (parsed.Path != "" && parsed.Path != "/") {
+			problems = append(problems, fmt.Sprintf("%s contains %q, expected an origin like https://example.com or *", field, origin))
+		}
+	}
+
+	return problems
+}
+
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,198 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sarvochcha01/enlace-backend/internal/config"
+)
+
+// clearEnv unsets every variable Load reads for the rest of the test, so the environment the
+// tests run in doesn't leak into them
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{
+		"SERVER_ADDRESS", "PORT", "SERVER_READ_TIMEOUT", "SERVER_READ_HEADER_TIMEOUT", "SERVER_WRITE_TIMEOUT",
+		"SERVER_IDLE_TIMEOUT", "SERVER_REQUEST_TIMEOUT", "SERVER_SHUTDOWN_TIMEOUT",
+		"DATABASE_URL", "DB_MAX_OPEN_CONNS", "DB_MAX_IDLE_CONNS", "DB_CONN_MAX_LIFETIME",
+		"CORS_ALLOWED_ORIGINS", "WS_ALLOWED_ORIGINS",
+		"AUTH_MODE", "FIREBASE_CREDENTIALS_PATH", "AUTH_JWT_ALGORITHM", "AUTH_JWT_SECRET",
+		"AUTH_JWT_PUBLIC_KEY_PATH", "AUTH_JWT_ISSUER", "AUTH_JWT_AUDIENCE",
+		"FEATURE_AUTO_MIGRATE", "FEATURE_WEBSOCKETS", "FEATURE_METRICS",
+		"LOG_LEVEL", "LOG_FORMAT",
+	} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+}
+
+func writeFile(t *testing.T, name string, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+	}{
+		{"config.yaml", `
+server:
+  address: ":8080"
+  write_timeout: 1m
+database:
+  url: postgres://localhost/enlace
+auth:
+  mode: jwt
+  jwt:
+    secret: file-secret
+`},
+		{"config.toml", `
+[server]
+address = ":8080"
+write_timeout = "1m"
+
+[database]
+url = "postgres://localhost/enlace"
+
+[auth]
+mode = "jwt"
+
+[auth.jwt]
+secret = "file-secret"
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+
+			cfg, err := config.Load(writeFile(t, tt.name, tt.contents))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if cfg.Server.Address != ":8080" || cfg.Server.WriteTimeout != time.Minute || cfg.Database.URL != "postgres://localhost/enlace" ||
+				cfg.Auth.Mode != config.AuthModeJWT || cfg.Auth.JWT.Secret != "file-secret" {
+				t.Fatalf("expected the file's settings, got %+v", cfg)
+			}
+
+			// Everything the file leaves out keeps its default
+			defaults := config.Default()
+			if cfg.Server.ReadTimeout != defaults.Server.ReadTimeout || cfg.Database.MaxOpenConns != defaults.Database.MaxOpenConns ||
+				cfg.Auth.JWT.Algorithm != defaults.Auth.JWT.Algorithm || cfg.Logging != defaults.Logging || cfg.Features != defaults.Features {
+				t.Fatalf("expected defaults for the remaining settings, got %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestLoadEnvOverridesFile(t *testing.T) {
+	clearEnv(t)
+	path := writeFile(t, "config.yaml", `
+server:
+  address: ":8080"
+database:
+  url: postgres://file/enlace
+  max_open_conns: 10
+features:
+  metrics: true
+`)
+	t.Setenv("PORT", "9000")
+	t.Setenv("DATABASE_URL", "postgres://env/enlace")
+	t.Setenv("DB_MAX_OPEN_CONNS", "50")
+	t.Setenv("FEATURE_METRICS", "false")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://a.example.com, https://b.example.com")
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Server.Address != ":9000" || cfg.Database.URL != "postgres://env/enlace" || cfg.Database.MaxOpenConns != 50 || cfg.Features.Metrics {
+		t.Fatalf("expected the environment to override the file, got %+v", cfg)
+	}
+	if len(cfg.CORS.AllowedOrigins) != 2 || cfg.CORS.AllowedOrigins[1] != "https://b.example.com" {
+		t.Fatalf("expected two allowed origins, got %q", cfg.CORS.AllowedOrigins)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string // a file name and its contents, or none
+		body  string
+		env   map[string]string
+		wants []string // parts of the error message
+	}{
+		{
+			name:  "unknown file extension",
+			file:  "config.json",
+			body:  `{"database": {"url": "postgres://localhost/enlace"}}`,
+			wants: []string{`unsupported config file type ".json"`},
+		},
+		{
+			name:  "unparsable file",
+			file:  "config.yaml",
+			body:  "server: [",
+			wants: []string{"failed to parse config file"},
+		},
+		{
+			name:  "invalid environment values are reported together",
+			env:   map[string]string{"DATABASE_URL": "postgres://localhost/enlace", "DB_MAX_OPEN_CONNS": "many", "FEATURE_METRICS": "maybe"},
+			wants: []string{"DB_MAX_OPEN_CONNS must be an integer", "FEATURE_METRICS must be a boolean"},
+		},
+		{
+			name: "every invalid setting is reported together",
+			env: map[string]string{
+				"AUTH_MODE":            "oauth",
+				"LOG_LEVEL":            "loud",
+				"DB_MAX_OPEN_CONNS":    "2",
+				"DB_MAX_IDLE_CONNS":    "5",
+				"SERVER_READ_TIMEOUT":  "0s",
+				"CORS_ALLOWED_ORIGINS": "example.com",
+			},
+			wants: []string{
+				"database.url is required",
+				`auth.mode must be "firebase" or "jwt", got "oauth"`,
+				`logging.level must be debug, info, warn or error, got "loud"`,
+				"database.max_idle_conns must not exceed database.max_open_conns",
+				"server.read_timeout must be positive",
+				`cors.allowed_origins contains "example.com"`,
+			},
+		},
+		{
+			name:  "jwt mode needs its key",
+			env:   map[string]string{"DATABASE_URL": "postgres://localhost/enlace", "AUTH_MODE": "jwt", "AUTH_JWT_ALGORITHM": "RS256"},
+			wants: []string{"auth.jwt.public_key_path is required for RS256"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			var path string
+			if tt.file != "" {
+				path = writeFile(t, tt.file, tt.body)
+			}
+
+			cfg, err := config.Load(path)
+			if err == nil {
+				t.Fatalf("expected an error, got %+v", cfg)
+			}
+			for _, want := range tt.wants {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected the error to mention %q, got:\n%v", want, err)
+				}
+			}
+		})
+	}
+}
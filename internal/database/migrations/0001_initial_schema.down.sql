@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS comments;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS invitations;
+DROP TABLE IF EXISTS tasks;
+DROP TABLE IF EXISTS project_members;
+DROP TABLE IF EXISTS projects;
+DROP TABLE IF EXISTS users;
+
+DROP FUNCTION IF EXISTS assign_task_number();
+DROP FUNCTION IF EXISTS set_updated_at();
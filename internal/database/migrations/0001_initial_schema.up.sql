@@ -0,0 +1,125 @@
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+CREATE OR REPLACE FUNCTION set_updated_at() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TABLE users (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    firebase_uid TEXT NOT NULL UNIQUE,
+    name         TEXT NOT NULL,
+    email        TEXT NOT NULL UNIQUE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE projects (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    key         VARCHAR(10) NOT NULL UNIQUE,
+    created_by  UUID NOT NULL REFERENCES users(id),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER projects_set_updated_at
+    BEFORE UPDATE ON projects
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE project_members (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    role       TEXT NOT NULL DEFAULT 'viewer' CHECK (role IN ('owner', 'editor', 'viewer')),
+    status     TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'inactive')),
+    joined_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, project_id)
+);
+
+CREATE INDEX project_members_project_id_idx ON project_members (project_id);
+
+CREATE TABLE tasks (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id  UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    task_number INTEGER NOT NULL,
+    title       TEXT NOT NULL,
+    description TEXT,
+    status      TEXT NOT NULL DEFAULT 'todo' CHECK (status IN ('todo', 'in-progress', 'completed')),
+    priority    TEXT NOT NULL DEFAULT 'medium' CHECK (priority IN ('low', 'medium', 'high', 'critical')),
+    due_date    TIMESTAMPTZ,
+    created_by  UUID NOT NULL REFERENCES project_members(id),
+    updated_by  UUID NOT NULL REFERENCES project_members(id),
+    assigned_to UUID REFERENCES project_members(id),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (project_id, task_number)
+);
+
+CREATE INDEX tasks_assigned_to_idx ON tasks (assigned_to);
+
+-- Task numbers are allocated per project. Locking the parent project row
+-- serialises concurrent inserts so two tasks never get the same number.
+CREATE OR REPLACE FUNCTION assign_task_number() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.task_number IS NULL THEN
+        PERFORM 1 FROM projects WHERE id = NEW.project_id FOR UPDATE;
+        SELECT COALESCE(MAX(task_number), 0) + 1
+        INTO NEW.task_number
+        FROM tasks
+        WHERE project_id = NEW.project_id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tasks_assign_task_number
+    BEFORE INSERT ON tasks
+    FOR EACH ROW EXECUTE FUNCTION assign_task_number();
+
+CREATE TRIGGER tasks_set_updated_at
+    BEFORE UPDATE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE invitations (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    invited_by      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    invited_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    project_id      UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    status          TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined')),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX invitations_invited_user_id_idx ON invitations (invited_user_id);
+CREATE INDEX invitations_project_id_idx ON invitations (project_id);
+
+CREATE TABLE notifications (
+    id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id            UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type               TEXT NOT NULL,
+    content            TEXT NOT NULL,
+    related_project_id UUID REFERENCES projects(id) ON DELETE CASCADE,
+    related_task_id    UUID REFERENCES tasks(id) ON DELETE CASCADE,
+    status             TEXT NOT NULL DEFAULT 'unread' CHECK (status IN ('unread', 'read')),
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX notifications_user_id_created_at_idx ON notifications (user_id, created_at DESC);
+
+CREATE TABLE comments (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    task_id    UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    created_by UUID NOT NULL REFERENCES project_members(id),
+    comment    TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX comments_task_id_idx ON comments (task_id);
+
+CREATE TRIGGER comments_set_updated_at
+    BEFORE UPDATE ON comments
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
@@ -0,0 +1,11 @@
+CREATE TABLE task_activity (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id    UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    actor_id   UUID NOT NULL REFERENCES project_members(id),
+    field      TEXT NOT NULL CHECK (field IN ('title', 'description', 'status', 'priority', 'dueDate', 'assignedTo')),
+    old_value  TEXT,
+    new_value  TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX task_activity_task_id_created_at_idx ON task_activity (task_id, created_at);
@@ -0,0 +1,9 @@
+DELETE FROM task_activity WHERE field = 'parentId';
+
+ALTER TABLE task_activity
+    DROP CONSTRAINT task_activity_field_check,
+    ADD CONSTRAINT task_activity_field_check CHECK (field IN ('title', 'description', 'status', 'priority', 'dueDate', 'assignedTo'));
+
+DROP INDEX IF EXISTS tasks_parent_id_idx;
+
+ALTER TABLE tasks DROP COLUMN parent_id;
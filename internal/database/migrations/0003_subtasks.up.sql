@@ -0,0 +1,10 @@
+-- Deleting a parent promotes its subtasks to top level tasks rather than deleting them
+ALTER TABLE tasks
+    ADD COLUMN parent_id UUID REFERENCES tasks(id) ON DELETE SET NULL,
+    ADD CONSTRAINT tasks_parent_id_check CHECK (parent_id <> id);
+
+CREATE INDEX tasks_parent_id_idx ON tasks (parent_id);
+
+ALTER TABLE task_activity
+    DROP CONSTRAINT task_activity_field_check,
+    ADD CONSTRAINT task_activity_field_check CHECK (field IN ('title', 'description', 'status', 'priority', 'dueDate', 'assignedTo', 'parentId'));
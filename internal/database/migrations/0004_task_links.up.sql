@@ -0,0 +1,14 @@
+-- Links are stored in one direction only: "blocked-by" is kept as the inverse "blocks" and
+-- "relates-to", which reads the same both ways, is stored once per pair
+CREATE TABLE task_links (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    source_task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    target_task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    type           TEXT NOT NULL CHECK (type IN ('blocks', 'relates-to', 'duplicates')),
+    created_by     UUID NOT NULL REFERENCES project_members(id),
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (source_task_id <> target_task_id),
+    UNIQUE (source_task_id, target_task_id, type)
+);
+
+CREATE INDEX task_links_target_task_id_idx ON task_links (target_task_id);
@@ -0,0 +1,23 @@
+CREATE TABLE labels (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name       TEXT NOT NULL,
+    color      TEXT NOT NULL CHECK (color ~ '^#[0-9a-f]{6}$'),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX labels_project_id_name_idx ON labels (project_id, LOWER(name));
+
+CREATE TRIGGER labels_set_updated_at
+    BEFORE UPDATE ON labels
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE task_labels (
+    task_id    UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    label_id   UUID NOT NULL REFERENCES labels(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (task_id, label_id)
+);
+
+CREATE INDEX task_labels_label_id_idx ON task_labels (label_id);
@@ -0,0 +1,14 @@
+ALTER TABLE tasks DROP CONSTRAINT tasks_status_fkey;
+
+-- Tasks in custom statuses go back to the fixed status of their category
+UPDATE tasks t
+SET status = CASE s.category WHEN 'todo' THEN 'todo' WHEN 'active' THEN 'in-progress' ELSE 'completed' END
+FROM task_statuses s
+WHERE s.project_id = t.project_id AND s.key = t.status;
+
+ALTER TABLE tasks
+    ALTER COLUMN status SET DEFAULT 'todo',
+    ADD CONSTRAINT tasks_status_check CHECK (status IN ('todo', 'in-progress', 'completed'));
+
+DROP TABLE IF EXISTS task_status_transitions;
+DROP TABLE IF EXISTS task_statuses;
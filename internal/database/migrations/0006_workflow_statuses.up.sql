@@ -0,0 +1,49 @@
+-- Each project defines its own ordered task statuses. Stats and the dashboard go by a status's
+-- category rather than its key.
+CREATE TABLE task_statuses (
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    key        TEXT NOT NULL CHECK (key ~ '^[a-z0-9]+(-[a-z0-9]+)*$'),
+    name       TEXT NOT NULL,
+    category   TEXT NOT NULL CHECK (category IN ('todo', 'active', 'done')),
+    position   INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (project_id, key)
+);
+
+CREATE TRIGGER task_statuses_set_updated_at
+    BEFORE UPDATE ON task_statuses
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- A task can move from from_status to to_status
+CREATE TABLE task_status_transitions (
+    project_id  UUID NOT NULL,
+    from_status TEXT NOT NULL,
+    to_status   TEXT NOT NULL,
+    PRIMARY KEY (project_id, from_status, to_status),
+    FOREIGN KEY (project_id, from_status) REFERENCES task_statuses (project_id, key) ON DELETE CASCADE,
+    FOREIGN KEY (project_id, to_status) REFERENCES task_statuses (project_id, key) ON DELETE CASCADE,
+    CHECK (from_status <> to_status)
+);
+
+-- Existing projects keep the statuses every task had so far, with every transition allowed
+INSERT INTO task_statuses (project_id, key, name, category, position)
+SELECT p.id, s.key, s.name, s.category, s.position
+FROM projects p
+CROSS JOIN (VALUES
+    ('todo', 'To do', 'todo', 0),
+    ('in-progress', 'In progress', 'active', 1),
+    ('completed', 'Completed', 'done', 2)
+) AS s (key, name, category, position);
+
+INSERT INTO task_status_transitions (project_id, from_status, to_status)
+SELECT a.project_id, a.key, b.key
+FROM task_statuses a
+INNER JOIN task_statuses b ON a.project_id = b.project_id AND a.key <> b.key;
+
+-- Deferred so that deleting a project, which cascades to both tables, is only checked once it is done
+ALTER TABLE tasks
+    DROP CONSTRAINT tasks_status_check,
+    ALTER COLUMN status DROP DEFAULT,
+    ADD CONSTRAINT tasks_status_fkey FOREIGN KEY (project_id, status)
+        REFERENCES task_statuses (project_id, key) DEFERRABLE INITIALLY DEFERRED;
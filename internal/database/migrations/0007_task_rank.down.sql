@@ -0,0 +1,11 @@
+DROP TRIGGER tasks_set_updated_at ON tasks;
+
+CREATE TRIGGER tasks_set_updated_at
+    BEFORE UPDATE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+DROP FUNCTION IF EXISTS set_task_updated_at();
+
+DROP INDEX IF EXISTS tasks_project_id_status_rank_idx;
+
+ALTER TABLE tasks DROP COLUMN rank;
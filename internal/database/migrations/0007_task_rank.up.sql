@@ -0,0 +1,32 @@
+-- rank orders the tasks of a status column on the board. Ranks are spaced 65536 apart so a moved
+-- task usually fits between its neighbours, ties go by task number.
+ALTER TABLE tasks ADD COLUMN rank BIGINT;
+
+-- Reordering a board can renumber a whole column, which isn't an edit of those tasks
+CREATE OR REPLACE FUNCTION set_task_updated_at() RETURNS TRIGGER AS $$
+BEGIN
+    IF to_jsonb(NEW) - 'rank' IS DISTINCT FROM to_jsonb(OLD) - 'rank' THEN
+        NEW.updated_at = NOW();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER tasks_set_updated_at ON tasks;
+
+CREATE TRIGGER tasks_set_updated_at
+    BEFORE UPDATE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION set_task_updated_at();
+
+-- Existing columns keep the task number order
+UPDATE tasks t
+SET rank = ranked.rank
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY project_id, status ORDER BY task_number) * 65536 AS rank
+    FROM tasks
+) ranked
+WHERE ranked.id = t.id;
+
+ALTER TABLE tasks ALTER COLUMN rank SET NOT NULL;
+
+CREATE INDEX tasks_project_id_status_rank_idx ON tasks (project_id, status, rank);
@@ -0,0 +1,24 @@
+-- A series of recurring tasks. Each occurrence is an ordinary task pointing at its series, and
+-- completing the current one creates the next.
+CREATE TABLE task_recurrences (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id      UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    rule            TEXT NOT NULL,
+    -- Tasks the series has created so far, for the rule's COUNT
+    occurrences     INT NOT NULL DEFAULT 1 CHECK (occurrences > 0),
+    -- NULL once the series has ended
+    current_task_id UUID REFERENCES tasks(id) ON DELETE SET NULL,
+    created_by      UUID REFERENCES project_members(id) ON DELETE SET NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX task_recurrences_current_task_id_idx ON task_recurrences (current_task_id);
+
+CREATE TRIGGER task_recurrences_set_updated_at
+    BEFORE UPDATE ON task_recurrences
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+ALTER TABLE tasks ADD COLUMN recurrence_id UUID REFERENCES task_recurrences(id) ON DELETE SET NULL;
+
+CREATE INDEX tasks_recurrence_id_idx ON tasks (recurrence_id);
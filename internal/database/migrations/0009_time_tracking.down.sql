@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS worklogs;
+
+DELETE FROM task_activity WHERE field IN ('originalEstimate', 'remainingEstimate');
+
+ALTER TABLE task_activity
+    DROP CONSTRAINT task_activity_field_check,
+    ADD CONSTRAINT task_activity_field_check CHECK (field IN ('title', 'description', 'status', 'priority', 'dueDate', 'assignedTo', 'parentId'));
+
+ALTER TABLE tasks
+    DROP COLUMN original_estimate,
+    DROP COLUMN remaining_estimate;
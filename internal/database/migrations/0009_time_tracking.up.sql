@@ -0,0 +1,26 @@
+-- Estimates and logged work are in minutes
+ALTER TABLE tasks
+    ADD COLUMN original_estimate INT CHECK (original_estimate >= 0),
+    ADD COLUMN remaining_estimate INT CHECK (remaining_estimate >= 0);
+
+ALTER TABLE task_activity
+    DROP CONSTRAINT task_activity_field_check,
+    ADD CONSTRAINT task_activity_field_check CHECK (field IN ('title', 'description', 'status', 'priority', 'dueDate', 'assignedTo', 'parentId', 'originalEstimate', 'remainingEstimate'));
+
+CREATE TABLE worklogs (
+    id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id           UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    project_member_id UUID NOT NULL REFERENCES project_members(id),
+    duration          INT NOT NULL CHECK (duration > 0),
+    work_date         DATE NOT NULL,
+    note              TEXT,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX worklogs_task_id_work_date_idx ON worklogs (task_id, work_date);
+CREATE INDEX worklogs_project_member_id_work_date_idx ON worklogs (project_member_id, work_date);
+
+CREATE TRIGGER worklogs_set_updated_at
+    BEFORE UPDATE ON worklogs
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
@@ -0,0 +1,12 @@
+DELETE FROM task_activity WHERE field IN ('storyPoints', 'sprintId');
+
+ALTER TABLE task_activity
+    DROP CONSTRAINT task_activity_field_check,
+    ADD CONSTRAINT task_activity_field_check CHECK (field IN ('title', 'description', 'status', 'priority', 'dueDate', 'assignedTo', 'parentId', 'originalEstimate', 'remainingEstimate'));
+
+ALTER TABLE tasks
+    DROP COLUMN story_points,
+    DROP COLUMN sprint_id,
+    DROP COLUMN completed_at;
+
+DROP TABLE IF EXISTS sprints;
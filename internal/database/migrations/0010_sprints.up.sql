@@ -0,0 +1,50 @@
+-- A time boxed iteration of a project. Sprints are planned, then one at a time active, then closed.
+CREATE TABLE sprints (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id          UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name                TEXT NOT NULL,
+    goal                TEXT,
+    start_date          DATE NOT NULL,
+    end_date            DATE NOT NULL,
+    state               TEXT NOT NULL DEFAULT 'planned' CHECK (state IN ('planned', 'active', 'closed')),
+    -- Story points in the sprint when it started, and done and carried over when it closed
+    committed_points    INT,
+    completed_points    INT,
+    carried_over_points INT,
+    started_at          TIMESTAMPTZ,
+    closed_at           TIMESTAMPTZ,
+    created_by          UUID REFERENCES project_members(id) ON DELETE SET NULL,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX sprints_project_id_start_date_idx ON sprints (project_id, start_date);
+CREATE UNIQUE INDEX sprints_one_active_per_project_idx ON sprints (project_id) WHERE state = 'active';
+
+CREATE TRIGGER sprints_set_updated_at
+    BEFORE UPDATE ON sprints
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- completed_at is when the task last moved into a done status, NULL while it isn't done
+ALTER TABLE tasks
+    ADD COLUMN story_points INT CHECK (story_points >= 0),
+    ADD COLUMN sprint_id UUID REFERENCES sprints(id) ON DELETE SET NULL,
+    ADD COLUMN completed_at TIMESTAMPTZ;
+
+CREATE INDEX tasks_sprint_id_idx ON tasks (sprint_id);
+
+-- Tasks that are already done were completed at their last update at the latest. The trigger
+-- would move updated_at to now, so it is off while they are filled in.
+ALTER TABLE tasks DISABLE TRIGGER tasks_set_updated_at;
+
+UPDATE tasks t
+SET completed_at = t.updated_at
+FROM task_statuses ts
+WHERE ts.project_id = t.project_id AND ts.key = t.status AND ts.category = 'done';
+
+ALTER TABLE tasks ENABLE TRIGGER tasks_set_updated_at;
+
+ALTER TABLE task_activity
+    DROP CONSTRAINT task_activity_field_check,
+    ADD CONSTRAINT task_activity_field_check CHECK (field IN ('title', 'description', 'status', 'priority', 'dueDate', 'assignedTo', 'parentId', 'originalEstimate', 'remainingEstimate', 'storyPoints', 'sprintId'));
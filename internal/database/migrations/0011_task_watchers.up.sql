@@ -0,0 +1,15 @@
+-- Members who hear about a task's status, assignee and due date changes and its new comments
+CREATE TABLE task_watchers (
+    task_id           UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    project_member_id UUID NOT NULL REFERENCES project_members(id) ON DELETE CASCADE,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (task_id, project_member_id)
+);
+
+CREATE INDEX task_watchers_project_member_id_idx ON task_watchers (project_member_id);
+
+-- Creators and assignees watch their tasks, existing ones included
+INSERT INTO task_watchers (task_id, project_member_id)
+SELECT id, created_by FROM tasks
+UNION
+SELECT id, assigned_to FROM tasks WHERE assigned_to IS NOT NULL;
@@ -2,12 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 
-	"firebase.google.com/go/auth"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/auth"
 	"github.com/sarvochcha01/enlace-backend/internal/middlewares"
 	"github.com/sarvochcha01/enlace-backend/internal/models"
 	"github.com/sarvochcha01/enlace-backend/internal/services"
@@ -27,40 +26,38 @@ func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 
 	projectID := chi.URLParam(r, "projectID")
 
-	CreateCommentDTO.ProjectID, err = uuid.Parse(projectID)
+	parsedProjectID, err := uuid.Parse(projectID)
 	if err != nil {
-		log.Println("Invalid project ID (must be a valid UUID): ", err)
-		http.Error(w, "Invalid project ID (must be a valid UUID)", http.StatusBadRequest)
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
 		return
 	}
 
 	taskID := chi.URLParam(r, "taskID")
 
-	CreateCommentDTO.TaskID, err = uuid.Parse(taskID)
+	parsedTaskID, err := uuid.Parse(taskID)
 	if err != nil {
-		log.Println("Invalid project ID (must be a valid UUID): ", err)
-		http.Error(w, "Invalid project ID (must be a valid UUID)", http.StatusBadRequest)
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
 		return
 	}
 
 	if err = json.NewDecoder(r.Body).Decode(&CreateCommentDTO); err != nil {
-		log.Println("Invalid request body: ", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		badRequest(w, r, "Invalid request body")
 		return
 	}
+	// The task is the one in the URL, whatever the body says
+	CreateCommentDTO.ProjectID = parsedProjectID
+	CreateCommentDTO.TaskID = parsedTaskID
 
 	var user *auth.Token
 	user, err = middlewares.GetFirebaseUser(r)
 
 	if err != nil {
-		log.Println("Unauthorized: ", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
-	if err = h.commentService.CreateComment(&CreateCommentDTO, user.UID); err != nil {
-		log.Println("Failed to create comment: ", err)
-		http.Error(w, "Failed to create comment", http.StatusInternalServerError)
+	if err = h.commentService.CreateComment(r.Context(), &CreateCommentDTO, user.UID); err != nil {
+		writeError(w, r, err, "Failed to create comment")
 		return
 	}
 
@@ -70,27 +67,36 @@ func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *CommentHandler) GetComment(w http.ResponseWriter, r *http.Request) {
+	parsedProjectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project id")
+		return
+	}
+
+	parsedTaskID, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if err != nil {
+		badRequest(w, r, "Invalid task id")
+		return
+	}
 
 	commentID := chi.URLParam(r, "commentID")
 
 	parsedCommentID, err := uuid.Parse(commentID)
 
 	if err != nil {
-		log.Println("Invalid project ID (must be a valid UUID): ", err)
-		http.Error(w, "Invalid project ID (must be a valid UUID)", http.StatusBadRequest)
+		badRequest(w, r, "Invalid comment ID (must be a valid UUID)")
 		return
 	}
 
-	_, err = middlewares.GetFirebaseUser(r)
+	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized: ", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
-	comment, err := h.commentService.GetComment(parsedCommentID)
+	comment, err := h.commentService.GetComment(r.Context(), parsedProjectID, parsedTaskID, parsedCommentID, user.UID)
 	if err != nil {
-		http.Error(w, "Comment not found", http.StatusNotFound)
+		writeError(w, r, err, "Comment not found")
 		return
 	}
 
@@ -107,31 +113,31 @@ func (h *CommentHandler) EditComment(w http.ResponseWriter, r *http.Request) {
 	projectID := chi.URLParam(r, "projectID")
 	UpdateCommentDTO.ProjectID, err = uuid.Parse(projectID)
 	if err != nil {
-		http.Error(w, "Invalid project ID", http.StatusBadRequest)
+		badRequest(w, r, "Invalid project ID")
 		return
 	}
 
 	commentID := chi.URLParam(r, "commentID")
 	UpdateCommentDTO.CommentID, err = uuid.Parse(commentID)
 	if err != nil {
-		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		badRequest(w, r, "Invalid comment ID")
 		return
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&UpdateCommentDTO); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		badRequest(w, r, "Invalid request body")
 		return
 	}
 
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
-	err = h.commentService.UpdateComment(&UpdateCommentDTO, user.UID)
+	err = h.commentService.UpdateComment(r.Context(), &UpdateCommentDTO, user.UID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusForbidden)
+		writeError(w, r, err, "Failed to update comment")
 		return
 	}
 
@@ -146,27 +152,26 @@ func (h *CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 	projectID := chi.URLParam(r, "projectID")
 	deleteCommentDTO.ProjectID, err = uuid.Parse(projectID)
 	if err != nil {
-		log.Println("Invalid project ID (must be a valid UUID): ", err)
-		http.Error(w, "Invalid project ID (must be a valid UUID)", http.StatusBadRequest)
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
 		return
 	}
 
 	commentID := chi.URLParam(r, "commentID")
 	deleteCommentDTO.CommentID, err = uuid.Parse(commentID)
 	if err != nil {
-		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		badRequest(w, r, "Invalid comment ID")
 		return
 	}
 
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
-	err = h.commentService.DeleteComment(&deleteCommentDTO, user.UID)
+	err = h.commentService.DeleteComment(r.Context(), &deleteCommentDTO, user.UID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusForbidden)
+		writeError(w, r, err, "Failed to delete comment")
 		return
 	}
 
@@ -179,31 +184,27 @@ func (h *CommentHandler) GetAllCommentsForTask(w http.ResponseWriter, r *http.Re
 
 	parsedProjectID, err := uuid.Parse(projectID)
 	if err != nil {
-		log.Println("Invlaid project id: ", err)
-		http.Error(w, "Invalid project id", http.StatusBadRequest)
+		badRequest(w, r, "Invalid project id")
 		return
 	}
 
 	taskID := chi.URLParam(r, "taskID")
 	parsedTaskID, err := uuid.Parse(taskID)
 	if err != nil {
-		log.Println("Invlaid task id: ", err)
-		http.Error(w, "Invalid task id", http.StatusBadRequest)
+		badRequest(w, r, "Invalid task id")
 		return
 	}
 
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized: ", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
 	var commentResponseDTO []models.CommentResponseDTO
 
-	if commentResponseDTO, err = h.commentService.GetAllCommentsForTask(parsedTaskID, parsedProjectID, user.UID); err != nil {
-		log.Println("Failed to get Comments: ", err)
-		http.Error(w, "Failed to get Comments", http.StatusInternalServerError)
+	if commentResponseDTO, err = h.commentService.GetAllCommentsForTask(r.Context(), parsedTaskID, parsedProjectID, user.UID); err != nil {
+		writeError(w, r, err, "Failed to get Comments")
 		return
 	}
 
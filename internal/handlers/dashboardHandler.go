@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
 
@@ -21,119 +20,104 @@ func NewDashboardHandler(dashboardService services.DashboardService) *DashboardH
 func (h *DashboardHandler) GetRecentlyAssignedTasks(w http.ResponseWriter, r *http.Request) {
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized:", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
 	limitStr := r.URL.Query().Get("limit")
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit <= 0 {
-		log.Println("Invalid limit parameter:", err)
-		http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+		badRequest(w, r, "Invalid limit parameter")
 		return
 	}
 
-	tasks, err := h.dashboardService.GetRecentlyAssignedTasks(user.UID, limit)
+	tasks, err := h.dashboardService.GetRecentlyAssignedTasks(r.Context(), user.UID, limit)
 	if err != nil {
-		log.Println("Failed to get recently assigned tasks:", err)
-		http.Error(w, "Failed to get recently assigned tasks", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to get recently assigned tasks")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(tasks); err != nil {
-		log.Println("Failed to encode tasks:", err)
-		http.Error(w, "Failed to encode tasks", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to encode tasks")
 	}
 }
 
 func (h *DashboardHandler) GetInProgressTasks(w http.ResponseWriter, r *http.Request) {
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized:", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
 	limitStr := r.URL.Query().Get("limit")
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit <= 0 {
-		log.Println("Invalid limit parameter:", err)
-		http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+		badRequest(w, r, "Invalid limit parameter")
 		return
 	}
 
-	tasks, err := h.dashboardService.GetInProgressTasks(user.UID, limit)
+	tasks, err := h.dashboardService.GetInProgressTasks(r.Context(), user.UID, limit)
 	if err != nil {
-		log.Println("Failed to get in-progress tasks:", err)
-		http.Error(w, "Failed to get in-progress tasks", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to get in-progress tasks")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(tasks); err != nil {
-		log.Println("Failed to encode tasks:", err)
-		http.Error(w, "Failed to encode tasks", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to encode tasks")
 	}
 }
 
 func (h *DashboardHandler) GetApproachingDeadlineTasks(w http.ResponseWriter, r *http.Request) {
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized:", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
 	limitStr := r.URL.Query().Get("limit")
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit <= 0 {
-		log.Println("Invalid limit parameter:", err)
-		http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+		badRequest(w, r, "Invalid limit parameter")
 		return
 	}
 
-	tasks, err := h.dashboardService.GetApproachingDeadlineTasks(user.UID, limit)
+	tasks, err := h.dashboardService.GetApproachingDeadlineTasks(r.Context(), user.UID, limit)
 	if err != nil {
-		log.Println("Failed to get approaching deadline tasks:", err)
-		http.Error(w, "Failed to get approaching deadline tasks", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to get approaching deadline tasks")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(tasks); err != nil {
-		log.Println("Failed to encode tasks:", err)
-		http.Error(w, "Failed to encode tasks", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to encode tasks")
 	}
 }
 
 func (h *DashboardHandler) Search(w http.ResponseWriter, r *http.Request) {
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized:", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
+	// labels is a comma separated list of label names, a task matches if it has any of them
 	query := r.URL.Query().Get("query")
-	if query == "" {
-		log.Println("Query parameter is required")
-		http.Error(w, "Query parameter is required", http.StatusBadRequest)
+	labels := listParam(r.URL.Query(), "labels")
+	if query == "" && len(labels) == 0 {
+		badRequest(w, r, "Query or labels parameter is required")
 		return
 	}
 
-	result, err := h.dashboardService.Search(user.UID, query)
+	result, err := h.dashboardService.Search(r.Context(), user.UID, query, labels)
 	if err != nil {
-		log.Println("Failed to search:", err)
-		http.Error(w, "Failed to search", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to search")
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(result); err != nil {
-		log.Println("Failed to encode search result:", err)
-		http.Error(w, "Failed to encode search result", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to encode search result")
 	}
-	log.Println("Search completed successfully")
 
 }
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/logging"
+	"github.com/sarvochcha01/enlace-backend/internal/metrics"
+)
+
+// writeError renders a service error with the status of its domain kind. Errors without one are
+// reported as internal errors with message, which is also what clients see. Internal errors are
+// logged at error level, everything else is an expected client error and logged at info.
+func writeError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	kind := apperrors.KindOf(err)
+
+	level := slog.LevelInfo
+	if kind == apperrors.KindInternal {
+		level = slog.LevelError
+	}
+	logging.FromContext(r.Context()).Log(r.Context(), level, message, "error", err)
+	metrics.FromContext(r.Context()).RecordError(string(kind))
+
+	apperrors.Write(w, err, message)
+}
+
+func badRequest(w http.ResponseWriter, r *http.Request, message string) {
+	logging.FromContext(r.Context()).Debug("bad request", "reason", message)
+	metrics.FromContext(r.Context()).RecordError(string(apperrors.KindValidation))
+	apperrors.Write(w, apperrors.Validation(message, nil), message)
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, message string) {
+	logging.FromContext(r.Context()).Debug("unauthorized request", "reason", message)
+	metrics.FromContext(r.Context()).RecordError(string(apperrors.KindUnauthorized))
+	apperrors.Write(w, apperrors.Unauthorized(message), message)
+}
@@ -1,10 +1,92 @@
 package handlers
 
-// type HealthHandler struct {
-// }
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
 
-// func NewHealthHandler() *HealthHandler {
-// 	return &HealthHandler{}
-// }
+	"github.com/sarvochcha01/enlace-backend/internal/logging"
+)
 
-// func (h )
+// Probes run every few seconds, so a dependency that takes longer than this counts as down
+const readinessTimeout = 2 * time.Second
+
+const (
+	healthStatusOK          = "ok"
+	healthStatusUnavailable = "unavailable"
+)
+
+// HealthCheck is one dependency that has to be working before the server takes traffic
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type HealthHandler struct {
+	checks []HealthCheck
+}
+
+func NewHealthHandler(checks ...HealthCheck) *HealthHandler {
+	return &HealthHandler{checks: checks}
+}
+
+type dependencyStatus struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type readinessResponse struct {
+	Status       string                      `json:"status"`
+	Dependencies map[string]dependencyStatus `json:"dependencies"`
+}
+
+// Liveness only reports that the process is serving requests, it never looks at dependencies so
+// that a database outage doesn't get every instance restarted
+func (h *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": healthStatusOK})
+}
+
+// Readiness runs every check concurrently and answers 503 if any of them fails
+func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	response := readinessResponse{Status: healthStatusOK, Dependencies: make(map[string]dependencyStatus, len(h.checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			start := time.Now()
+			err := check.Check(ctx)
+			status := dependencyStatus{Status: healthStatusOK, LatencyMs: float64(time.Since(start).Microseconds()) / 1000}
+			if err != nil {
+				status.Status = healthStatusUnavailable
+				status.Error = err.Error()
+				logging.FromContext(r.Context()).Warn("readiness check failed", "dependency", check.Name, "error", err)
+			}
+
+			mu.Lock()
+			response.Dependencies[check.Name] = status
+			if err != nil {
+				response.Status = healthStatusUnavailable
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if response.Status != healthStatusOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(response)
+}
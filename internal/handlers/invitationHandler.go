@@ -2,8 +2,6 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
-	"log"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -25,21 +23,18 @@ func (h *InvitationHandler) CreateInvitation(w http.ResponseWriter, r *http.Requ
 
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized:", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
 	var createInvitationDTO models.CreateInvitationDTO
 	if err = json.NewDecoder(r.Body).Decode(&createInvitationDTO); err != nil {
-		log.Println("Invalid request body")
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		badRequest(w, r, "Invalid request body")
 		return
 	}
 
-	if err = h.invitationService.CreateInvitation(user.UID, &createInvitationDTO); err != nil {
-		log.Println("Failed to create invitation:", err)
-		http.Error(w, "Failed to create invitation", http.StatusInternalServerError)
+	if err = h.invitationService.CreateInvitation(r.Context(), user.UID, &createInvitationDTO); err != nil {
+		writeError(w, r, err, "Failed to create invitation")
 		return
 	}
 
@@ -51,15 +46,13 @@ func (h *InvitationHandler) CreateInvitation(w http.ResponseWriter, r *http.Requ
 func (h *InvitationHandler) GetInvitations(w http.ResponseWriter, r *http.Request) {
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized:", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
-	invitations, err := h.invitationService.GetInvitations(user.UID)
+	invitations, err := h.invitationService.GetInvitations(r.Context(), user.UID)
 	if err != nil {
-		log.Println("Failed to get invitations:", err)
-		http.Error(w, "Failed to get invitations", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to get invitations")
 		return
 	}
 
@@ -72,33 +65,27 @@ func (h *InvitationHandler) EditInvitation(w http.ResponseWriter, r *http.Reques
 	invitationID := chi.URLParam(r, "invitationID")
 	parsedInvitationID, err := uuid.Parse(invitationID)
 	if err != nil {
-		log.Println("Invalid invitation id:", err)
-		http.Error(w, "Invalid invitation id", http.StatusBadRequest)
+		badRequest(w, r, "Invalid invitation id")
 		return
 	}
 
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized:", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
 	var EditInvitationRequest models.EditInvitationDTO
 
 	if err = json.NewDecoder(r.Body).Decode(&EditInvitationRequest); err != nil {
-		log.Println("Invalid request body")
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		badRequest(w, r, "Invalid request body")
 		return
 	}
 
 	EditInvitationRequest.InvitationID = parsedInvitationID
 
-	fmt.Println(EditInvitationRequest)
-
-	if err = h.invitationService.EditInvitation(user.UID, EditInvitationRequest); err != nil {
-		log.Println("Failed to edit invitation:", err)
-		http.Error(w, "Failed to edit invitation", http.StatusInternalServerError)
+	if err = h.invitationService.EditInvitation(r.Context(), user.UID, EditInvitationRequest); err != nil {
+		writeError(w, r, err, "Failed to edit invitation")
 		return
 	}
 
@@ -112,19 +99,17 @@ func (h *InvitationHandler) HasInvitation(w http.ResponseWriter, r *http.Request
 	projectIDStr := chi.URLParam(r, "projectID")
 	projectID, err := uuid.Parse(projectIDStr)
 	if err != nil {
-		log.Println("Invalid project id:", err)
-		http.Error(w, "Invalid project id", http.StatusBadRequest)
+		badRequest(w, r, "Invalid project id")
 		return
 	}
 
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized:", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
-	hasInvitation := h.invitationService.HasInvitationFirebaseUID(user.UID, projectID)
+	hasInvitation := h.invitationService.HasInvitationFirebaseUID(r.Context(), user.UID, projectID)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/middlewares"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/services"
+)
+
+type LabelHandler struct {
+	labelService services.LabelService
+}
+
+func NewLabelHandler(labelService services.LabelService) *LabelHandler {
+	return &LabelHandler{labelService: labelService}
+}
+
+func (h *LabelHandler) CreateLabel(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	var labelDTO models.CreateLabelDTO
+	if err := json.NewDecoder(r.Body).Decode(&labelDTO); err != nil {
+		badRequest(w, r, "Invalid request body")
+		return
+	}
+	labelDTO.ProjectID = projectID
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	label, err := h.labelService.CreateLabel(r.Context(), user.UID, &labelDTO)
+	if err != nil {
+		writeError(w, r, err, "Failed to create label")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(label)
+}
+
+func (h *LabelHandler) GetLabels(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	labels, err := h.labelService.GetLabels(r.Context(), user.UID, projectID)
+	if err != nil {
+		writeError(w, r, err, "Failed to get labels")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(labels)
+}
+
+func (h *LabelHandler) UpdateLabel(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	labelID, err := uuid.Parse(chi.URLParam(r, "labelID"))
+	if err != nil {
+		badRequest(w, r, "Invalid label ID (must be a valid UUID)")
+		return
+	}
+
+	var labelDTO models.UpdateLabelDTO
+	if err := json.NewDecoder(r.Body).Decode(&labelDTO); err != nil {
+		badRequest(w, r, "Invalid request body")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	label, err := h.labelService.UpdateLabel(r.Context(), user.UID, projectID, labelID, &labelDTO)
+	if err != nil {
+		writeError(w, r, err, "Failed to update label")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(label)
+}
+
+func (h *LabelHandler) DeleteLabel(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	labelID, err := uuid.Parse(chi.URLParam(r, "labelID"))
+	if err != nil {
+		badRequest(w, r, "Invalid label ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	if err := h.labelService.DeleteLabel(r.Context(), user.UID, projectID, labelID); err != nil {
+		writeError(w, r, err, "Failed to delete label")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Label deleted successfully"))
+}
+
+// AddTaskLabel attaches the label to the task and responds with the task. Attaching twice is a no-op.
+func (h *LabelHandler) AddTaskLabel(w http.ResponseWriter, r *http.Request) {
+	h.changeTaskLabel(w, r, h.labelService.AddTaskLabel, "Failed to add label to task")
+}
+
+// RemoveTaskLabel detaches the label from the task and responds with the task
+func (h *LabelHandler) RemoveTaskLabel(w http.ResponseWriter, r *http.Request) {
+	h.changeTaskLabel(w, r, h.labelService.RemoveTaskLabel, "Failed to remove label from task")
+}
+
+type taskLabelChange func(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID, labelID uuid.UUID) (*models.TaskResponseDTO, error)
+
+func (h *LabelHandler) changeTaskLabel(w http.ResponseWriter, r *http.Request, change taskLabelChange, fallback string) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if err != nil {
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
+		return
+	}
+
+	labelID, err := uuid.Parse(chi.URLParam(r, "labelID"))
+	if err != nil {
+		badRequest(w, r, "Invalid label ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	task, err := change(r.Context(), user.UID, projectID, taskID, labelID)
+	if err != nil {
+		writeError(w, r, err, fallback)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(task)
+}
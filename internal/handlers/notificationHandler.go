@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -25,15 +24,13 @@ func (h *NotificationHandler) GetAllNotificationsForUser(w http.ResponseWriter,
 
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized:", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
-	notifications, err := h.notificationService.GetAllNotificationsForUser(user.UID)
+	notifications, err := h.notificationService.GetAllNotificationsForUser(r.Context(), user.UID)
 	if err != nil {
-		log.Println("Failed to get notificaitons:", err)
-		http.Error(w, "Failed to get notificaitons", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to get notifications")
 		return
 	}
 
@@ -46,22 +43,19 @@ func (h *NotificationHandler) MarkNotificationAsRead(w http.ResponseWriter, r *h
 	notificationIDStr := chi.URLParam(r, "notificationID")
 	notificationID, err := uuid.Parse(notificationIDStr)
 	if err != nil {
-		log.Println("Invalid notification ID:", err)
-		http.Error(w, "Invalid notification ID", http.StatusBadRequest)
+		badRequest(w, r, "Invalid notification ID")
 		return
 	}
 
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized:", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
-	err = h.notificationService.MarkNotificationAsRead(user.UID, notificationID)
+	err = h.notificationService.MarkNotificationAsRead(r.Context(), user.UID, notificationID)
 	if err != nil {
-		log.Println("Failed to get notificaitons:", err)
-		http.Error(w, "Failed to get notificaitons", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to get notifications")
 		return
 	}
 
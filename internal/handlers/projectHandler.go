@@ -2,12 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 
-	"firebase.google.com/go/auth"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/auth"
 	"github.com/sarvochcha01/enlace-backend/internal/middlewares"
 	"github.com/sarvochcha01/enlace-backend/internal/models"
 	"github.com/sarvochcha01/enlace-backend/internal/services"
@@ -25,21 +24,18 @@ func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
 	var projectDTO models.CreateProjectDTO
 
 	if err := json.NewDecoder(r.Body).Decode(&projectDTO); err != nil {
-		log.Println("Invalid request body: ", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		badRequest(w, r, "Invalid request body")
 		return
 	}
 
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized: ", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
-	if err := h.projectService.CreateProject(&projectDTO, user.UID); err != nil {
-		log.Println("Failed to create project: ", err)
-		http.Error(w, "Failed to create project", http.StatusInternalServerError)
+	if err := h.projectService.CreateProject(r.Context(), &projectDTO, user.UID); err != nil {
+		writeError(w, r, err, "Failed to create project")
 		return
 	}
 
@@ -51,8 +47,7 @@ func (h *ProjectHandler) GetProjectByID(w http.ResponseWriter, r *http.Request)
 
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized: ", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
@@ -61,18 +56,18 @@ func (h *ProjectHandler) GetProjectByID(w http.ResponseWriter, r *http.Request)
 	parsedProjectID, err := uuid.Parse(projectID)
 
 	if err != nil {
-		log.Println("Invalid project ID (must be a valid UUID): ", err)
-		http.Error(w, "Invalid project ID (must be a valid UUID)", http.StatusBadRequest)
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
 		return
 	}
 
-	project, err := h.projectService.GetProjectByID(parsedProjectID, user.UID)
+	project, err := h.projectService.GetProjectByID(r.Context(), parsedProjectID, user.UID)
 
 	if err != nil {
-		http.Error(w, "Project not found", http.StatusNotFound)
+		writeError(w, r, err, "Project not found")
 		return
 	}
 
+	w.Header().Set("ETag", project.ETag())
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(project)
 
@@ -81,8 +76,7 @@ func (h *ProjectHandler) GetProjectByID(w http.ResponseWriter, r *http.Request)
 func (h *ProjectHandler) GetProjectName(w http.ResponseWriter, r *http.Request) {
 	_, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized: ", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
@@ -91,19 +85,17 @@ func (h *ProjectHandler) GetProjectName(w http.ResponseWriter, r *http.Request)
 	parsedProjectID, err := uuid.Parse(projectID)
 
 	if err != nil {
-		log.Println("Invalid project ID (must be a valid UUID): ", err)
-		http.Error(w, "Invalid project ID (must be a valid UUID)", http.StatusBadRequest)
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
 		return
 	}
 
 	var projectNameResponse struct {
 		Name string `json:"projectName"`
 	}
-	projectNameResponse.Name, err = h.projectService.GetProjectName(parsedProjectID)
+	projectNameResponse.Name, err = h.projectService.GetProjectName(r.Context(), parsedProjectID)
 
 	if err != nil {
-		log.Println("Failed to get project name: ", err)
-		http.Error(w, "Failed to get project", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to get project")
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -116,58 +108,56 @@ func (h *ProjectHandler) GetAllProjectsForUser(w http.ResponseWriter, r *http.Re
 	user, err = middlewares.GetFirebaseUser(r)
 
 	if err != nil {
-		log.Println("Unauthorized: ", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
 	var projectResponseDTO []models.ProjectResponseDTO
 
-	if projectResponseDTO, err = h.projectService.GetAllProjectsForUser(user.UID); err != nil {
-		log.Println("Failed to get Projects: ", err)
-		http.Error(w, "Failed to get Projects", http.StatusInternalServerError)
+	if projectResponseDTO, err = h.projectService.GetAllProjectsForUser(r.Context(), user.UID); err != nil {
+		writeError(w, r, err, "Failed to get Projects")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(projectResponseDTO); err != nil {
-		log.Println("Failed to encode response: ", err)
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to encode response")
 	}
 }
 
+// UpdateProject edits the project. With an If-Match of the ETag from GetProjectByID it only does so
+// if nobody changed the project in between, answering 412 with the current project otherwise.
 func (h *ProjectHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
 
 	projectID := chi.URLParam(r, "projectID")
 
 	parsedProjectID, err := uuid.Parse(projectID)
 	if err != nil {
-		log.Println("Invalid project ID:", err)
-		http.Error(w, "Invalid projedt ID", http.StatusBadRequest)
+		badRequest(w, r, "Invalid projedt ID")
 		return
 	}
 
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized:", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
 	var updateProjectDTO models.EditProjectDTO
 	if err = json.NewDecoder(r.Body).Decode(&updateProjectDTO); err != nil {
-		log.Println("Invalid request body: ", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		badRequest(w, r, "Invalid request body")
 		return
 	}
+	updateProjectDTO.IfMatch = models.ParseIfMatch(r.Header.Get("If-Match"))
 
-	if err = h.projectService.EditProject(user.UID, parsedProjectID, &updateProjectDTO); err != nil {
-		log.Println("Failed to create project: ", err)
-		http.Error(w, "Failed to create project", http.StatusInternalServerError)
+	project, err := h.projectService.EditProject(r.Context(), user.UID, parsedProjectID, &updateProjectDTO)
+	if err != nil {
+		writeError(w, r, err, "Failed to create project")
 		return
 	}
 
+	w.Header().Set("ETag", project.ETag())
 	w.WriteHeader(http.StatusCreated)
 	w.Write([]byte("Project updated successfully"))
 
@@ -177,22 +167,19 @@ func (h *ProjectHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
 	projectID := chi.URLParam(r, "projectID")
 	parsedProjectID, err := uuid.Parse(projectID)
 	if err != nil {
-		log.Println("Invalid project id:", err)
-		http.Error(w, "Invalid project id", http.StatusBadRequest)
+		badRequest(w, r, "Invalid project id")
 		return
 	}
 
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized: ", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
-	err = h.projectService.DeleteProject(user.UID, parsedProjectID)
+	err = h.projectService.DeleteProject(r.Context(), user.UID, parsedProjectID)
 	if err != nil {
-		log.Println("Failed to delete project:", err)
-		http.Error(w, "failed to delete project", http.StatusInternalServerError)
+		writeError(w, r, err, "failed to delete project")
 		return
 	}
 
@@ -205,22 +192,19 @@ func (h *ProjectHandler) JoinProject(w http.ResponseWriter, r *http.Request) {
 	projectID := chi.URLParam(r, "projectID")
 	parsedProjectID, err := uuid.Parse(projectID)
 	if err != nil {
-		log.Println("Invalid project id:", err)
-		http.Error(w, "Invalid project id", http.StatusBadRequest)
+		badRequest(w, r, "Invalid project id")
 		return
 	}
 
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized: ", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
-	err = h.projectService.JoinProject(parsedProjectID, user.UID)
+	err = h.projectService.JoinProject(r.Context(), parsedProjectID, user.UID)
 	if err != nil {
-		log.Println("Failed to join project:", err)
-		http.Error(w, "Failed to join project", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to join project")
 		return
 	}
 
@@ -235,22 +219,19 @@ func (h *ProjectHandler) LeaveProject(w http.ResponseWriter, r *http.Request) {
 
 	parsedProjectID, err := uuid.Parse(projectID)
 	if err != nil {
-		log.Println("Invalid project ID:", err)
-		http.Error(w, "Invalid project ID", http.StatusBadRequest)
+		badRequest(w, r, "Invalid project ID")
 		return
 	}
 
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized:", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
-	err = h.projectService.LeaveProject(parsedProjectID, user.UID)
+	err = h.projectService.LeaveProject(r.Context(), parsedProjectID, user.UID)
 	if err != nil {
-		log.Println("Failed to leave project: ", err)
-		http.Error(w, "Failed to leave project", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to leave project")
 		return
 	}
 
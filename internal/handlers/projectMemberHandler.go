@@ -2,12 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 
-	"firebase.google.com/go/auth"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/auth"
 	"github.com/sarvochcha01/enlace-backend/internal/middlewares"
 	"github.com/sarvochcha01/enlace-backend/internal/models"
 	"github.com/sarvochcha01/enlace-backend/internal/services"
@@ -31,8 +30,7 @@ func (h *ProjectMemberHandler) CreateProjectMember(w http.ResponseWriter, r *htt
 	parsedProjectID, err := uuid.Parse(projectID)
 
 	if err != nil {
-		log.Println("Invalid project ID (must be a valid UUID): ", err)
-		http.Error(w, "Invalid project ID (must be a valid UUID)", http.StatusBadRequest)
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
 		return
 	}
 	createProjectMemberDTO.ProjectID = parsedProjectID
@@ -42,14 +40,12 @@ func (h *ProjectMemberHandler) CreateProjectMember(w http.ResponseWriter, r *htt
 	user, err = middlewares.GetFirebaseUser(r)
 
 	if err != nil {
-		log.Println("Unauthorized: ", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
-	if err = h.projectMemberService.CreateProjectMember(&createProjectMemberDTO, user.UID); err != nil {
-		log.Println("Failed to join project: ", err)
-		http.Error(w, "Failed to join project", http.StatusUnauthorized)
+	if err = h.projectMemberService.CreateProjectMember(r.Context(), &createProjectMemberDTO, user.UID); err != nil {
+		writeError(w, r, err, "Failed to join project")
 		return
 	}
 
@@ -63,8 +59,7 @@ func (h *ProjectMemberHandler) GetProjectMemberID(w http.ResponseWriter, r *http
 	parsedProjectID, err := uuid.Parse(projectID)
 
 	if err != nil {
-		log.Println("Invalid project ID (must be a valid UUID): ", err)
-		http.Error(w, "Invalid project ID (must be a valid UUID)", http.StatusBadRequest)
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
 		return
 	}
 
@@ -72,8 +67,7 @@ func (h *ProjectMemberHandler) GetProjectMemberID(w http.ResponseWriter, r *http
 
 	user, err = middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized: ", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
@@ -81,9 +75,8 @@ func (h *ProjectMemberHandler) GetProjectMemberID(w http.ResponseWriter, r *http
 		ID uuid.UUID `json:"id"`
 	}
 
-	if projectMemberResponse.ID, err = h.projectMemberService.GetProjectMemberIDByFirebaseUID(user.UID, parsedProjectID); err != nil {
-		log.Println("Failed to get project member: ", err)
-		http.Error(w, "Failed to get project member", http.StatusUnauthorized)
+	if projectMemberResponse.ID, err = h.projectMemberService.GetProjectMemberIDByFirebaseUID(r.Context(), user.UID, parsedProjectID); err != nil {
+		writeError(w, r, err, "Failed to get project member")
 		return
 	}
 
@@ -96,39 +89,34 @@ func (h *ProjectMemberHandler) UpdateProjectMember(w http.ResponseWriter, r *htt
 
 	parsedProjectID, err := uuid.Parse(projectID)
 	if err != nil {
-		log.Println("Invalid project id:", err)
-		http.Error(w, "invalid project id", http.StatusBadRequest)
+		badRequest(w, r, "invalid project id")
 		return
 	}
 
 	projectMemberID := chi.URLParam(r, "projectMemberID")
 	parsedProjectMemberID, err := uuid.Parse(projectMemberID)
 	if err != nil {
-		log.Println("Invalid member id:", err)
-		http.Error(w, "invalid member id", http.StatusBadRequest)
+		badRequest(w, r, "invalid member id")
 		return
 	}
 
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized:", err)
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "unauthorized")
 		return
 	}
 
 	var updateProjectMemberDTO models.UpdateProjectMemberDTO
 	if err = json.NewDecoder(r.Body).Decode(&updateProjectMemberDTO); err != nil {
-		log.Println("Invalid request body: ", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		badRequest(w, r, "Invalid request body")
 		return
 	}
 
 	updateProjectMemberDTO.ID = parsedProjectMemberID
 	updateProjectMemberDTO.ProjectID = parsedProjectID
 
-	if err = h.projectMemberService.UpdateProjectMemberRole(user.UID, &updateProjectMemberDTO); err != nil {
-		log.Println("Failed to update project member role:", err)
-		http.Error(w, "failed to update project member role:", http.StatusInternalServerError)
+	if err = h.projectMemberService.UpdateProjectMemberRole(r.Context(), user.UID, &updateProjectMemberDTO); err != nil {
+		writeError(w, r, err, "Failed to update project member role")
 		return
 	}
 
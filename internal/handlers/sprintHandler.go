@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/middlewares"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/services"
+)
+
+type SprintHandler struct {
+	sprintService services.SprintService
+}
+
+func NewSprintHandler(sprintService services.SprintService) *SprintHandler {
+	return &SprintHandler{sprintService: sprintService}
+}
+
+// CreateSprint plans a sprint, e.g. {"name": "Sprint 1", "goal": "...", "startDate": "2026-10-19", "endDate": "2026-11-01"}
+func (h *SprintHandler) CreateSprint(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	var sprintDTO models.CreateSprintDTO
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&sprintDTO); err != nil {
+		writeError(w, r, apperrors.Validation("Invalid request body", map[string]string{"body": err.Error()}), "Invalid request body")
+		return
+	}
+	sprintDTO.ProjectID = projectID
+
+	sprint, err := h.sprintService.CreateSprint(r.Context(), user.UID, &sprintDTO)
+	if err != nil {
+		writeError(w, r, err, "Failed to create sprint")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sprint)
+}
+
+func (h *SprintHandler) GetSprints(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	sprints, err := h.sprintService.GetSprints(r.Context(), user.UID, projectID)
+	if err != nil {
+		writeError(w, r, err, "Failed to get sprints")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sprints)
+}
+
+func (h *SprintHandler) GetSprint(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	sprintID, err := uuid.Parse(chi.URLParam(r, "sprintID"))
+	if err != nil {
+		badRequest(w, r, "Invalid sprint ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	sprint, err := h.sprintService.GetSprint(r.Context(), user.UID, projectID, sprintID)
+	if err != nil {
+		writeError(w, r, err, "Failed to get sprint")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sprint)
+}
+
+func (h *SprintHandler) UpdateSprint(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	sprintID, err := uuid.Parse(chi.URLParam(r, "sprintID"))
+	if err != nil {
+		badRequest(w, r, "Invalid sprint ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	var sprintDTO models.UpdateSprintDTO
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&sprintDTO); err != nil {
+		writeError(w, r, apperrors.Validation("Invalid request body", map[string]string{"body": err.Error()}), "Invalid request body")
+		return
+	}
+
+	sprint, err := h.sprintService.UpdateSprint(r.Context(), user.UID, projectID, sprintID, &sprintDTO)
+	if err != nil {
+		writeError(w, r, err, "Failed to update sprint")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sprint)
+}
+
+func (h *SprintHandler) DeleteSprint(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	sprintID, err := uuid.Parse(chi.URLParam(r, "sprintID"))
+	if err != nil {
+		badRequest(w, r, "Invalid sprint ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	if err := h.sprintService.DeleteSprint(r.Context(), user.UID, projectID, sprintID); err != nil {
+		writeError(w, r, err, "Failed to delete sprint")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Sprint deleted successfully"))
+}
+
+func (h *SprintHandler) StartSprint(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	sprintID, err := uuid.Parse(chi.URLParam(r, "sprintID"))
+	if err != nil {
+		badRequest(w, r, "Invalid sprint ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	sprint, err := h.sprintService.StartSprint(r.Context(), user.UID, projectID, sprintID)
+	if err != nil {
+		writeError(w, r, err, "Failed to start sprint")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sprint)
+}
+
+// CloseSprint closes the active sprint, carrying its unfinished tasks over to {"carryOverTo": "<sprint ID>"}
+// or, without a body or with null, back to the backlog
+func (h *SprintHandler) CloseSprint(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	sprintID, err := uuid.Parse(chi.URLParam(r, "sprintID"))
+	if err != nil {
+		badRequest(w, r, "Invalid sprint ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	var closeDTO models.CloseSprintDTO
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&closeDTO); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, r, apperrors.Validation("Invalid request body", map[string]string{"body": err.Error()}), "Invalid request body")
+		return
+	}
+
+	closed, err := h.sprintService.CloseSprint(r.Context(), user.UID, projectID, sprintID, &closeDTO)
+	if err != nil {
+		writeError(w, r, err, "Failed to close sprint")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(closed)
+}
+
+func (h *SprintHandler) GetBurndown(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	sprintID, err := uuid.Parse(chi.URLParam(r, "sprintID"))
+	if err != nil {
+		badRequest(w, r, "Invalid sprint ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	burndown, err := h.sprintService.GetBurndown(r.Context(), user.UID, projectID, sprintID)
+	if err != nil {
+		writeError(w, r, err, "Failed to get burndown")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(burndown)
+}
+
+func (h *SprintHandler) GetVelocity(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	velocity, err := h.sprintService.GetVelocity(r.Context(), user.UID, projectID)
+	if err != nil {
+		writeError(w, r, err, "Failed to get velocity")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(velocity)
+}
@@ -2,14 +2,20 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
-	"firebase.google.com/go/auth"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/auth"
 	"github.com/sarvochcha01/enlace-backend/internal/middlewares"
 	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/repositories"
 	"github.com/sarvochcha01/enlace-backend/internal/services"
 )
 
@@ -29,27 +35,23 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 
 	taskDTO.ProjectID, err = uuid.Parse(projectID)
 	if err != nil {
-		log.Println("Invalid project ID (must be a valid UUID): ", err)
-		http.Error(w, "Invalid project ID (must be a valid UUID)", http.StatusBadRequest)
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
 		return
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&taskDTO); err != nil {
-		log.Println("Invalid request body: ", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		badRequest(w, r, "Invalid request body")
 		return
 	}
 
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized: ", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
-	if _, err = h.taskService.CreateTask(&taskDTO, user.UID); err != nil {
-		log.Println("Failed to create task: ", err)
-		http.Error(w, "Failed to create task", http.StatusInternalServerError)
+	if _, err = h.taskService.CreateTask(r.Context(), &taskDTO, user.UID); err != nil {
+		writeError(w, r, err, "Failed to create task")
 		return
 	}
 
@@ -63,45 +65,205 @@ func (h *TaskHandler) GetTaskByID(w http.ResponseWriter, r *http.Request) {
 	projectID := chi.URLParam(r, "projectID")
 	parsedProjectID, err := uuid.Parse(projectID)
 	if err != nil {
-		log.Println("Invalid project ID (must be a valid UUID): ", err)
-		http.Error(w, "Invalid project ID (must be a valid UUID)", http.StatusBadRequest)
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
 		return
 	}
 
 	taskID := chi.URLParam(r, "taskID")
 	parsedTaskID, err := uuid.Parse(taskID)
 	if err != nil {
-		log.Println("Invalid task ID (must be a valid UUID): ", err)
-		http.Error(w, "Invalid task ID (must be a valid UUID)", http.StatusBadRequest)
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
 		return
 	}
 
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized:", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
-	task, err := h.taskService.GetTaskByID(user.UID, parsedProjectID, parsedTaskID)
+	task, err := h.taskService.GetTaskByID(r.Context(), user.UID, parsedProjectID, parsedTaskID)
 
 	if err != nil {
-		http.Error(w, "Task not found", http.StatusNotFound)
+		writeError(w, r, err, "Task not found")
 		return
 	}
 
+	w.Header().Set("ETag", models.ETag(task.UpdatedAt))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(task)
 }
 
+// GetTaskByKey looks a task up by its key, e.g. /tasks/by-key/APO-42
+func (h *TaskHandler) GetTaskByKey(w http.ResponseWriter, r *http.Request) {
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	task, err := h.taskService.GetTaskByKey(r.Context(), user.UID, chi.URLParam(r, "taskKey"))
+	if err != nil {
+		writeError(w, r, err, "Task not found")
+		return
+	}
+
+	w.Header().Set("ETag", models.ETag(task.UpdatedAt))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(task)
+}
+
+func (h *TaskHandler) GetTaskActivity(w http.ResponseWriter, r *http.Request) {
+	parsedProjectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	parsedTaskID, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if err != nil {
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	timeline, err := h.taskService.GetTaskActivity(r.Context(), user.UID, parsedProjectID, parsedTaskID)
+	if err != nil {
+		writeError(w, r, err, "Failed to get task activity")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(timeline)
+}
+
+// ListTasks serves one page of a project's tasks. Query parameters, all optional:
+//
+//	status, priority  comma separated values, e.g. status=todo,in-progress
+//	assignee          comma separated project member IDs, "me" and "unassigned"
+//	dueAfter          due on or after, RFC 3339 or YYYY-MM-DD (midnight UTC)
+//	dueBefore         due strictly before, same formats
+//	q                 text in the title or description
+//	label             comma separated label IDs, tasks with any of them
+//	sort              comma separated fields, "-" for descending, e.g. sort=-priority,dueDate
+//	limit             page size, 1 to 200
+//	cursor            nextCursor of the previous page
+func (h *TaskHandler) ListTasks(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	query, err := parseTaskListQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, r, err, "Invalid task list query")
+		return
+	}
+
+	page, err := h.taskService.ListTasks(r.Context(), user.UID, projectID, query)
+	if err != nil {
+		writeError(w, r, err, "Failed to list tasks")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// ListSubtasks serves one page of a task's direct subtasks and takes the same query parameters as ListTasks
+func (h *TaskHandler) ListSubtasks(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if err != nil {
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	query, err := parseTaskListQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, r, err, "Invalid task list query")
+		return
+	}
+
+	page, err := h.taskService.ListSubtasks(r.Context(), user.UID, projectID, taskID, query)
+	if err != nil {
+		writeError(w, r, err, "Failed to list subtasks")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// CreateSubtask creates a task under the task in the URL and responds with the new subtask
+func (h *TaskHandler) CreateSubtask(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if err != nil {
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
+		return
+	}
+
+	var taskDTO models.CreateTaskDTO
+	if err := json.NewDecoder(r.Body).Decode(&taskDTO); err != nil {
+		badRequest(w, r, "Invalid request body")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	subtask, err := h.taskService.CreateSubtask(r.Context(), user.UID, projectID, taskID, &taskDTO)
+	if err != nil {
+		writeError(w, r, err, "Failed to create subtask")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(subtask)
+}
+
+// EditTask replaces the task's fields. Like PatchTask it honours If-Match, answering 412 with the
+// current task when the ETag given is out of date.
 func (h *TaskHandler) EditTask(w http.ResponseWriter, r *http.Request) {
 	taskID := chi.URLParam(r, "taskID")
 
 	parsedTaskID, err := uuid.Parse(taskID)
 
 	if err != nil {
-		log.Println("Invalid task ID (must be a valid UUID): ", err)
-		http.Error(w, "Invalid task ID (must be a valid UUID)", http.StatusBadRequest)
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
 		return
 	}
 
@@ -110,8 +272,7 @@ func (h *TaskHandler) EditTask(w http.ResponseWriter, r *http.Request) {
 	parsedProjectID, err := uuid.Parse(projectID)
 
 	if err != nil {
-		log.Println("Invalid task ID (must be a valid UUID): ", err)
-		http.Error(w, "Invalid task ID (must be a valid UUID)", http.StatusBadRequest)
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
 		return
 	}
 
@@ -119,29 +280,339 @@ func (h *TaskHandler) EditTask(w http.ResponseWriter, r *http.Request) {
 	user, err = middlewares.GetFirebaseUser(r)
 
 	if err != nil {
-		log.Println("Unauthorized: ", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
 	var updateTaskDTO models.UpdateTaskDTO
 
 	if err := json.NewDecoder(r.Body).Decode(&updateTaskDTO); err != nil {
-		log.Println("Invalid request body: ", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		badRequest(w, r, "Invalid request body")
+		return
+	}
+
+	if updateTaskDTO.OverrideBlockers, err = parseOverrideBlockers(r); err != nil {
+		writeError(w, r, err, "Invalid overrideBlockers parameter")
 		return
 	}
+	updateTaskDTO.IfMatch = models.ParseIfMatch(r.Header.Get("If-Match"))
 
-	if err := h.taskService.EditTask(parsedTaskID, parsedProjectID, user.UID, &updateTaskDTO); err != nil {
-		log.Println("Failed to update task: ", err)
-		http.Error(w, "Failed to update task", http.StatusInternalServerError)
+	task, err := h.taskService.EditTask(r.Context(), parsedTaskID, parsedProjectID, user.UID, &updateTaskDTO)
+	if err != nil {
+		writeError(w, r, err, "Failed to update task")
 		return
 	}
 
+	w.Header().Set("ETag", models.ETag(task.UpdatedAt))
 	w.WriteHeader(http.StatusCreated)
 	w.Write([]byte("Task updated successfully "))
 }
 
+// parseOverrideBlockers reads ?overrideBlockers=true, with which an owner can complete a task that
+// still has open blockers
+func parseOverrideBlockers(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get("overrideBlockers")
+	if value == "" {
+		return false, nil
+	}
+
+	override, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, apperrors.Validation("invalid query parameter", map[string]string{"overrideBlockers": "must be true or false"})
+	}
+	return override, nil
+}
+
+// PatchTask applies a JSON merge patch (application/merge-patch+json) to a task and returns the result.
+// Fields left out are unchanged; description, dueDate, assignedTo and parentId can be cleared with null.
+// With an If-Match of the task's ETag the patch is only applied if nobody changed the task since it was
+// read, otherwise it fails with 412 and the current task.
+func (h *TaskHandler) PatchTask(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if err != nil {
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	var patch models.PatchTaskDTO
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patch); err != nil {
+		writeError(w, r, apperrors.Validation("Invalid request body", map[string]string{"body": err.Error()}), "Invalid request body")
+		return
+	}
+
+	if patch.OverrideBlockers, err = parseOverrideBlockers(r); err != nil {
+		writeError(w, r, err, "Invalid overrideBlockers parameter")
+		return
+	}
+	patch.IfMatch = models.ParseIfMatch(r.Header.Get("If-Match"))
+
+	task, err := h.taskService.PatchTask(r.Context(), user.UID, projectID, taskID, &patch)
+	if err != nil {
+		writeError(w, r, err, "Failed to update task")
+		return
+	}
+
+	w.Header().Set("ETag", models.ETag(task.UpdatedAt))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(task)
+}
+
+// BulkUpdateTasks changes or deletes many tasks at once, e.g. {"taskIds": [...], "status": "completed"}.
+// It responds 200 with a result per task when the change was applied, and 422 with the same body,
+// saying which tasks failed, when it wasn't applied to any of them.
+func (h *TaskHandler) BulkUpdateTasks(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	var bulk models.BulkTaskDTO
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&bulk); err != nil {
+		writeError(w, r, apperrors.Validation("Invalid request body", map[string]string{"body": err.Error()}), "Invalid request body")
+		return
+	}
+
+	if bulk.OverrideBlockers, err = parseOverrideBlockers(r); err != nil {
+		writeError(w, r, err, "Invalid overrideBlockers parameter")
+		return
+	}
+
+	response, err := h.taskService.BulkUpdateTasks(r.Context(), user.UID, projectID, &bulk)
+	if err != nil {
+		writeError(w, r, err, "Failed to update tasks")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !response.Applied {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// MoveTask places the task on the board, e.g. {"status": "in-progress", "afterTaskId": "..."},
+// and responds with the task at its new rank
+func (h *TaskHandler) MoveTask(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if err != nil {
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	var move models.MoveTaskDTO
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&move); err != nil {
+		writeError(w, r, apperrors.Validation("Invalid request body", map[string]string{"body": err.Error()}), "Invalid request body")
+		return
+	}
+
+	if move.OverrideBlockers, err = parseOverrideBlockers(r); err != nil {
+		writeError(w, r, err, "Invalid overrideBlockers parameter")
+		return
+	}
+
+	task, err := h.taskService.MoveTask(r.Context(), user.UID, projectID, taskID, &move)
+	if err != nil {
+		writeError(w, r, err, "Failed to move task")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(task)
+}
+
+// SetTaskRecurrence makes the task repeat, e.g. {"rule": "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=10"}, or
+// changes the rule of its series, and responds with the task
+func (h *TaskHandler) SetTaskRecurrence(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if err != nil {
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	var recurrence models.SetTaskRecurrenceDTO
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&recurrence); err != nil {
+		writeError(w, r, apperrors.Validation("Invalid request body", map[string]string{"body": err.Error()}), "Invalid request body")
+		return
+	}
+
+	task, err := h.taskService.SetTaskRecurrence(r.Context(), user.UID, projectID, taskID, &recurrence)
+	if err != nil {
+		writeError(w, r, err, "Failed to set task recurrence")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(task)
+}
+
+// StopTaskRecurrence ends the task's series and responds with the task
+func (h *TaskHandler) StopTaskRecurrence(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if err != nil {
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	task, err := h.taskService.StopTaskRecurrence(r.Context(), user.UID, projectID, taskID)
+	if err != nil {
+		writeError(w, r, err, "Failed to stop task recurrence")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(task)
+}
+
+func (h *TaskHandler) GetTaskWatchers(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if err != nil {
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	watchers, err := h.taskService.GetTaskWatchers(r.Context(), user.UID, projectID, taskID)
+	if err != nil {
+		writeError(w, r, err, "Failed to get task watchers")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(watchers)
+}
+
+func (h *TaskHandler) WatchTask(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if err != nil {
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	watchers, err := h.taskService.WatchTask(r.Context(), user.UID, projectID, taskID)
+	if err != nil {
+		writeError(w, r, err, "Failed to watch task")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(watchers)
+}
+
+func (h *TaskHandler) UnwatchTask(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if err != nil {
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	watchers, err := h.taskService.UnwatchTask(r.Context(), user.UID, projectID, taskID)
+	if err != nil {
+		writeError(w, r, err, "Failed to unwatch task")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(watchers)
+}
+
 func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 	taskID := chi.URLParam(r, "taskID")
 	var deleteTaskDTO models.DeleteTaskDTO
@@ -149,8 +620,7 @@ func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 	parsedTaskID, err := uuid.Parse(taskID)
 
 	if err != nil {
-		log.Println("Invalid task ID (must be a valid UUID): ", err)
-		http.Error(w, "Invalid task ID (must be a valid UUID)", http.StatusBadRequest)
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
 		return
 	}
 	deleteTaskDTO.TaskID = parsedTaskID
@@ -160,8 +630,7 @@ func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 	parsedProjectID, err := uuid.Parse(projectID)
 
 	if err != nil {
-		log.Println("Invalid task ID (must be a valid UUID): ", err)
-		http.Error(w, "Invalid task ID (must be a valid UUID)", http.StatusBadRequest)
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
 		return
 	}
 	deleteTaskDTO.ProjectID = parsedProjectID
@@ -170,18 +639,142 @@ func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 	user, err = middlewares.GetFirebaseUser(r)
 
 	if err != nil {
-		log.Println("Unauthorized: ", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 	deleteTaskDTO.FirebaseUID = user.UID
 
-	if err := h.taskService.DeleteTask(&deleteTaskDTO); err != nil {
-		log.Println("Failed to delete task:", err)
-		http.Error(w, "Failed to delete task", http.StatusBadRequest)
+	if err := h.taskService.DeleteTask(r.Context(), &deleteTaskDTO); err != nil {
+		writeError(w, r, err, "Failed to delete task")
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("Task Deleted"))
 }
+
+// parseTaskListQuery reports every invalid parameter at once in the error details
+func parseTaskListQuery(params url.Values) (*models.TaskListQuery, error) {
+	query := &models.TaskListQuery{Cursor: params.Get("cursor")}
+	problems := map[string]string{}
+
+	for _, value := range listParam(params, "status") {
+		status := models.TaskStatus(value)
+		if !status.IsValid() {
+			problems["status"] = fmt.Sprintf("%q is not a status key", value)
+			continue
+		}
+		query.Filter.Statuses = append(query.Filter.Statuses, status)
+	}
+
+	for _, value := range listParam(params, "priority") {
+		priority := models.TaskPriority(value)
+		if !priority.IsValid() {
+			problems["priority"] = fmt.Sprintf("unknown priority %q", value)
+			continue
+		}
+		query.Filter.Priorities = append(query.Filter.Priorities, priority)
+	}
+
+	for _, value := range listParam(params, "assignee") {
+		switch value {
+		case "me":
+			query.Filter.AssignedToMe = true
+		case "unassigned":
+			query.Filter.Unassigned = true
+		default:
+			memberID, err := uuid.Parse(value)
+			if err != nil {
+				problems["assignee"] = fmt.Sprintf("%q is not me, unassigned or a project member ID", value)
+				continue
+			}
+			query.Filter.AssignedTo = append(query.Filter.AssignedTo, memberID)
+		}
+	}
+
+	for name, target := range map[string]**time.Time{"dueAfter": &query.Filter.DueAfter, "dueBefore": &query.Filter.DueBefore} {
+		value := params.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := parseDate(value)
+		if err != nil {
+			problems[name] = "must be an RFC 3339 time or a YYYY-MM-DD date"
+			continue
+		}
+		*target = &parsed
+	}
+
+	for _, value := range listParam(params, "label") {
+		labelID, err := uuid.Parse(value)
+		if err != nil {
+			problems["label"] = fmt.Sprintf("%q is not a label ID", value)
+			continue
+		}
+		query.Filter.Labels = append(query.Filter.Labels, labelID)
+	}
+
+	for _, value := range listParam(params, "sprint") {
+		if value == "backlog" {
+			query.Filter.Backlog = true
+			continue
+		}
+		sprintID, err := uuid.Parse(value)
+		if err != nil {
+			problems["sprint"] = fmt.Sprintf("%q is not backlog or a sprint ID", value)
+			continue
+		}
+		query.Filter.Sprints = append(query.Filter.Sprints, sprintID)
+	}
+
+	query.Filter.Text = strings.TrimSpace(params.Get("q"))
+
+	seen := map[models.TaskSortField]bool{}
+	for _, value := range listParam(params, "sort") {
+		sort := models.TaskSort{Field: models.TaskSortField(strings.TrimPrefix(value, "-")), Descending: strings.HasPrefix(value, "-")}
+		if !sort.Field.IsValid() {
+			problems["sort"] = fmt.Sprintf("unknown sort field %q", sort.Field)
+			continue
+		}
+		if seen[sort.Field] {
+			problems["sort"] = fmt.Sprintf("%s is listed more than once", sort.Field)
+			continue
+		}
+		seen[sort.Field] = true
+		query.Sort = append(query.Sort, sort)
+	}
+
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > repositories.MaxTaskPageSize {
+			problems["limit"] = fmt.Sprintf("must be between 1 and %d", repositories.MaxTaskPageSize)
+		}
+		query.Limit = limit
+	}
+
+	if len(problems) > 0 {
+		return nil, apperrors.Validation("Invalid task list query", problems)
+	}
+
+	return query, nil
+}
+
+// listParam accepts both repeated parameters and comma separated values
+func listParam(params url.Values, name string) []string {
+	var values []string
+	for _, param := range params[name] {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func parseDate(value string) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/middlewares"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/services"
+)
+
+type TaskLinkHandler struct {
+	taskLinkService services.TaskLinkService
+}
+
+func NewTaskLinkHandler(taskLinkService services.TaskLinkService) *TaskLinkHandler {
+	return &TaskLinkHandler{taskLinkService: taskLinkService}
+}
+
+// CreateTaskLink links the task in the URL to the task in the body, e.g. {"type": "blocked-by", "taskId": "..."}
+// reads "this task is blocked by that one", and responds with the new link
+func (h *TaskLinkHandler) CreateTaskLink(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if err != nil {
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
+		return
+	}
+
+	var linkDTO models.CreateTaskLinkDTO
+	if err := json.NewDecoder(r.Body).Decode(&linkDTO); err != nil {
+		badRequest(w, r, "Invalid request body")
+		return
+	}
+	linkDTO.SourceTaskID = taskID
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	link, err := h.taskLinkService.CreateTaskLink(r.Context(), user.UID, projectID, &linkDTO)
+	if err != nil {
+		writeError(w, r, err, "Failed to link tasks")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(link)
+}
+
+func (h *TaskLinkHandler) GetTaskLinks(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if err != nil {
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	links, err := h.taskLinkService.GetTaskLinks(r.Context(), user.UID, projectID, taskID)
+	if err != nil {
+		writeError(w, r, err, "Failed to get task links")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(links)
+}
+
+func (h *TaskLinkHandler) DeleteTaskLink(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if err != nil {
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
+		return
+	}
+
+	linkID, err := uuid.Parse(chi.URLParam(r, "linkID"))
+	if err != nil {
+		badRequest(w, r, "Invalid link ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	if err := h.taskLinkService.DeleteTaskLink(r.Context(), user.UID, projectID, taskID, linkID); err != nil {
+		writeError(w, r, err, "Failed to delete task link")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Task link deleted successfully"))
+}
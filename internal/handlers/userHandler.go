@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 
 	"github.com/sarvochcha01/enlace-backend/internal/middlewares"
@@ -22,14 +21,12 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	var userDTO models.CreateUserDTO
 
 	if err := json.NewDecoder(r.Body).Decode(&userDTO); err != nil {
-		log.Println("Invalid request body: ", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		badRequest(w, r, "Invalid request body")
 		return
 	}
 
-	if err := h.userService.CreateUser(&userDTO); err != nil {
-		log.Println("Failed to register user: ", err)
-		http.Error(w, "Failed to register user", http.StatusInternalServerError)
+	if err := h.userService.CreateUser(r.Context(), &userDTO); err != nil {
+		writeError(w, r, err, "Failed to register user")
 		return
 	}
 
@@ -40,17 +37,15 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized: ", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
 	var userDTO *models.UserResponseDTO
-	userDTO, err = h.userService.GetUserByFirebaseUID(user.UID)
+	userDTO, err = h.userService.GetUserByFirebaseUID(r.Context(), user.UID)
 
 	if err != nil {
-		log.Println("Failed to get user:", err)
-		http.Error(w, "User not found", http.StatusInternalServerError)
+		writeError(w, r, err, "User not found")
 		return
 	}
 
@@ -61,15 +56,13 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
 	user, err := middlewares.GetFirebaseUser(r)
 	if err != nil {
-		log.Println("Unauthorized: ", err)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
 	userEmail, ok := user.Claims["email"].(string)
 	if !ok || userEmail == "" {
-		log.Println("Email not found in Firebase token claims")
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		unauthorized(w, r, "Unauthorized")
 		return
 	}
 
@@ -78,15 +71,13 @@ func (h *UserHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&sq); err != nil {
-		log.Println("Invalid request body:", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		badRequest(w, r, "Invalid request body")
 		return
 	}
 
-	userResponsesDTO, err := h.userService.SearchUsers(sq.Query)
+	userResponsesDTO, err := h.userService.SearchUsers(r.Context(), sq.Query)
 	if err != nil {
-		log.Println("Error searching users:", err)
-		http.Error(w, "Failed to search users", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to search users")
 		return
 	}
 
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/middlewares"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/services"
+)
+
+type WorkflowHandler struct {
+	workflowService services.WorkflowService
+}
+
+func NewWorkflowHandler(workflowService services.WorkflowService) *WorkflowHandler {
+	return &WorkflowHandler{workflowService: workflowService}
+}
+
+func (h *WorkflowHandler) GetWorkflow(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	workflow, err := h.workflowService.GetWorkflow(r.Context(), user.UID, projectID)
+	if err != nil {
+		writeError(w, r, err, "Failed to get workflow")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(workflow)
+}
+
+// UpdateWorkflow replaces the project's statuses with the ones in the body, in board order, e.g.
+// {"statuses": [{"key": "todo", "name": "To do", "category": "todo", "transitions": ["in-review"]}, ...]}
+func (h *WorkflowHandler) UpdateWorkflow(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	var workflowDTO models.WorkflowDTO
+	if err := json.NewDecoder(r.Body).Decode(&workflowDTO); err != nil {
+		badRequest(w, r, "Invalid request body")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	workflow, err := h.workflowService.UpdateWorkflow(r.Context(), user.UID, projectID, &workflowDTO)
+	if err != nil {
+		writeError(w, r, err, "Failed to update workflow")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(workflow)
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/middlewares"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/services"
+)
+
+type WorklogHandler struct {
+	worklogService services.WorklogService
+}
+
+func NewWorklogHandler(worklogService services.WorklogService) *WorklogHandler {
+	return &WorklogHandler{worklogService: worklogService}
+}
+
+// CreateWorklog logs time on the task, e.g. {"duration": 90, "date": "2026-10-17", "note": "..."}
+// with the duration in minutes, and responds with the work log
+func (h *WorklogHandler) CreateWorklog(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if err != nil {
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	var worklogDTO models.CreateWorklogDTO
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&worklogDTO); err != nil {
+		writeError(w, r, apperrors.Validation("Invalid request body", map[string]string{"body": err.Error()}), "Invalid request body")
+		return
+	}
+	worklogDTO.TaskID = taskID
+
+	worklog, err := h.worklogService.CreateWorklog(r.Context(), user.UID, projectID, &worklogDTO)
+	if err != nil {
+		writeError(w, r, err, "Failed to log work")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(worklog)
+}
+
+func (h *WorklogHandler) GetTaskWorklogs(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if err != nil {
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	worklogs, err := h.worklogService.GetTaskWorklogs(r.Context(), user.UID, projectID, taskID)
+	if err != nil {
+		writeError(w, r, err, "Failed to get work logs")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(worklogs)
+}
+
+func (h *WorklogHandler) UpdateWorklog(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if err != nil {
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
+		return
+	}
+
+	worklogID, err := uuid.Parse(chi.URLParam(r, "worklogID"))
+	if err != nil {
+		badRequest(w, r, "Invalid work log ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	var worklogDTO models.UpdateWorklogDTO
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&worklogDTO); err != nil {
+		writeError(w, r, apperrors.Validation("Invalid request body", map[string]string{"body": err.Error()}), "Invalid request body")
+		return
+	}
+
+	worklog, err := h.worklogService.UpdateWorklog(r.Context(), user.UID, projectID, taskID, worklogID, &worklogDTO)
+	if err != nil {
+		writeError(w, r, err, "Failed to update work log")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(worklog)
+}
+
+func (h *WorklogHandler) DeleteWorklog(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if err != nil {
+		badRequest(w, r, "Invalid task ID (must be a valid UUID)")
+		return
+	}
+
+	worklogID, err := uuid.Parse(chi.URLParam(r, "worklogID"))
+	if err != nil {
+		badRequest(w, r, "Invalid work log ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	if err := h.worklogService.DeleteWorklog(r.Context(), user.UID, projectID, taskID, worklogID); err != nil {
+		writeError(w, r, err, "Failed to delete work log")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Work log deleted successfully"))
+}
+
+// GetTimeReport totals the project's logged time per task and member, for ?from=2026-10-01&to=2026-10-31
+// or any part of that range
+func (h *WorklogHandler) GetTimeReport(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "projectID"))
+	if err != nil {
+		badRequest(w, r, "Invalid project ID (must be a valid UUID)")
+		return
+	}
+
+	user, err := middlewares.GetFirebaseUser(r)
+	if err != nil {
+		unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	query, problems := models.ParseTimeReportQuery(r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if problems != nil {
+		writeError(w, r, apperrors.Validation("invalid date range", problems), "Invalid date range")
+		return
+	}
+
+	report, err := h.worklogService.GetTimeReport(r.Context(), user.UID, projectID, query)
+	if err != nil {
+		writeError(w, r, err, "Failed to get time report")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
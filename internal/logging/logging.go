@@ -0,0 +1,42 @@
+// Package logging builds the application's slog logger and carries a request scoped logger in the context
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/sarvochcha01/enlace-backend/internal/config"
+)
+
+type contextKey struct{}
+
+// New returns a logger for cfg, which must already be validated
+func New(w io.Writer, cfg config.LoggingConfig) *slog.Logger {
+	var level slog.Level
+	level.UnmarshalText([]byte(cfg.Level))
+
+	options := &slog.HandlerOptions{Level: level}
+	if cfg.Format == config.LogFormatText {
+		return slog.New(slog.NewTextHandler(w, options))
+	}
+	return slog.New(slog.NewJSONHandler(w, options))
+}
+
+// WithLogger returns a copy of ctx that carries logger
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or the default logger outside of a request
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// With adds attributes to the logger carried by ctx, e.g. the user ID once the request is authenticated
+func With(ctx context.Context, args ...any) context.Context {
+	return WithLogger(ctx, FromContext(ctx).With(args...))
+}
@@ -0,0 +1,115 @@
+// Package metrics exposes the application's counters and gauges in the Prometheus text format
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Metrics are the series served at /metrics. Every method is safe to call on a nil *Metrics,
+// which records nothing, so tests and tools can leave it out.
+type Metrics struct {
+	registry               *Registry
+	httpRequests           *CounterVec
+	httpRequestDuration    *HistogramVec
+	errors                 *CounterVec
+	notificationsCreated   *CounterVec
+	notificationsDelivered *CounterVec
+}
+
+func New() *Metrics {
+	registry := NewRegistry()
+
+	return &Metrics{
+		registry:               registry,
+		httpRequests:           registry.NewCounterVec("enlace_http_requests_total", "HTTP requests served, by route pattern and status.", "method", "route", "status"),
+		httpRequestDuration:    registry.NewHistogramVec("enlace_http_request_duration_seconds", "Time taken to serve HTTP requests, by route pattern.", DefaultBuckets, "method", "route"),
+		errors:                 registry.NewCounterVec("enlace_errors_total", "Error responses, by error kind.", "kind"),
+		notificationsCreated:   registry.NewCounterVec("enlace_notifications_created_total", "Notifications stored for users."),
+		notificationsDelivered: registry.NewCounterVec("enlace_notifications_delivered_total", "Notifications written to a live WebSocket connection."),
+	}
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	if m == nil {
+		return http.NotFoundHandler()
+	}
+	return m.registry.Handler()
+}
+
+// ObserveRequest records a served request. route is the chi route pattern, never the raw path,
+// so that IDs in URLs don't create a series per resource.
+func (m *Metrics) ObserveRequest(method string, route string, status int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.httpRequests.Inc(method, route, strconv.Itoa(status))
+	m.httpRequestDuration.Observe(duration.Seconds(), method, route)
+}
+
+// RecordError counts an error response of the given apperrors kind
+func (m *Metrics) RecordError(kind string) {
+	if m == nil {
+		return
+	}
+	m.errors.Inc(kind)
+}
+
+func (m *Metrics) NotificationCreated() {
+	if m == nil {
+		return
+	}
+	m.notificationsCreated.Inc()
+}
+
+func (m *Metrics) NotificationDelivered() {
+	if m == nil {
+		return
+	}
+	m.notificationsDelivered.Inc()
+}
+
+// RegisterDBStats exposes the connection pool statistics of db, read on every scrape
+func (m *Metrics) RegisterDBStats(db *sql.DB) {
+	if m == nil {
+		return
+	}
+
+	gauge := func(name string, help string, value func(sql.DBStats) int) {
+		m.registry.NewGaugeFunc(name, help, func() float64 { return float64(value(db.Stats())) })
+	}
+	gauge("enlace_db_max_open_connections", "Maximum number of open connections to the database.", func(s sql.DBStats) int { return s.MaxOpenConnections })
+	gauge("enlace_db_open_connections", "Established connections, both in use and idle.", func(s sql.DBStats) int { return s.OpenConnections })
+	gauge("enlace_db_in_use_connections", "Connections currently in use.", func(s sql.DBStats) int { return s.InUse })
+	gauge("enlace_db_idle_connections", "Idle connections.", func(s sql.DBStats) int { return s.Idle })
+
+	m.registry.NewCounterFunc("enlace_db_wait_count_total", "Connections waited for because the pool was exhausted.", func() float64 { return float64(db.Stats().WaitCount) })
+	m.registry.NewCounterFunc("enlace_db_wait_duration_seconds_total", "Time spent waiting for a free connection.", func() float64 { return db.Stats().WaitDuration.Seconds() })
+	m.registry.NewCounterFunc("enlace_db_max_idle_closed_total", "Connections closed due to the idle connection limit.", func() float64 { return float64(db.Stats().MaxIdleClosed) })
+	m.registry.NewCounterFunc("enlace_db_max_lifetime_closed_total", "Connections closed due to the maximum connection lifetime.", func() float64 { return float64(db.Stats().MaxLifetimeClosed) })
+}
+
+// RegisterWebSocketClients exposes the number of open notification sockets, read on every scrape
+func (m *Metrics) RegisterWebSocketClients(count func() int) {
+	if m == nil {
+		return
+	}
+	m.registry.NewGaugeFunc("enlace_websocket_clients", "Open WebSocket connections across all users.", func() float64 { return float64(count()) })
+}
+
+type contextKey struct{}
+
+// WithMetrics returns a copy of ctx that carries m, for code that only sees the request
+func WithMetrics(ctx context.Context, m *Metrics) context.Context {
+	return context.WithValue(ctx, contextKey{}, m)
+}
+
+// FromContext returns the metrics carried by ctx, or nil which records nothing
+func FromContext(ctx context.Context) *Metrics {
+	m, _ := ctx.Value(contextKey{}).(*Metrics)
+	return m
+}
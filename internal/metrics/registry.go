@@ -0,0 +1,284 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets suit request latencies in seconds, from 5ms to 10s
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Registry holds metric families and renders them in the Prometheus text exposition format
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]family
+}
+
+type family interface {
+	write(w *bufio.Writer)
+}
+
+func NewRegistry() *Registry {
+	return &Registry{families: map[string]family{}}
+}
+
+func (r *Registry) register(name string, f family) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.families[name]; ok {
+		panic(fmt.Sprintf("metrics: %s registered twice", name))
+	}
+	r.families[name] = f
+}
+
+// Handler serves every registered family, sorted by name
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mu.Lock()
+		names := make([]string, 0, len(r.families))
+		for name := range r.families {
+			names = append(names, name)
+		}
+		families := make([]family, 0, len(names))
+		sort.Strings(names)
+		for _, name := range names {
+			families = append(families, r.families[name])
+		}
+		r.mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		buf := bufio.NewWriter(w)
+		for _, f := range families {
+			f.write(buf)
+		}
+		buf.Flush()
+	})
+}
+
+type desc struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+}
+
+func (d desc) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.name, escapeHelp(d.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.name, d.kind)
+}
+
+// series identifies one combination of label values
+type series struct {
+	key    string
+	values []string
+}
+
+func newSeries(d desc, values []string) series {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", d.name, len(d.labels), len(values)))
+	}
+	return series{key: strings.Join(values, "\xff"), values: append([]string(nil), values...)}
+}
+
+// labelString renders {a="1",b="2"} including any extra trailing pair, such as a histogram's le
+func labelString(names []string, values []string, extra ...string) string {
+	if len(names) == 0 && len(extra) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, name, escapeLabel(values[i]))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, extra[i], escapeLabel(extra[i+1]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// CounterVec is a monotonically increasing value per combination of label values
+type CounterVec struct {
+	desc
+	mu     sync.Mutex
+	values map[string]*counterSeries
+}
+
+type counterSeries struct {
+	series
+	value float64
+}
+
+func (r *Registry) NewCounterVec(name string, help string, labels ...string) *CounterVec {
+	c := &CounterVec{desc: desc{name: name, help: help, kind: "counter", labels: labels}, values: map[string]*counterSeries{}}
+	r.register(name, c)
+	return c
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("metrics: counter %s cannot decrease", c.name))
+	}
+	s := newSeries(c.desc, labelValues)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.values[s.key]
+	if !ok {
+		entry = &counterSeries{series: s}
+		c.values[s.key] = entry
+	}
+	entry.value += delta
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.writeHeader(w)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// A counter without labels is exposed as 0 before its first increment
+	if len(c.labels) == 0 && len(c.values) == 0 {
+		fmt.Fprintf(w, "%s 0\n", c.name)
+		return
+	}
+	for _, key := range sortedKeys(c.values) {
+		entry := c.values[key]
+		fmt.Fprintf(w, "%s%s %s\n", c.name, labelString(c.labels, entry.values), formatFloat(entry.value))
+	}
+}
+
+// HistogramVec counts observations into cumulative buckets per combination of label values
+type HistogramVec struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	values  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	series
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func (r *Registry) NewHistogramVec(name string, help string, buckets []float64, labels ...string) *HistogramVec {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+
+	h := &HistogramVec{desc: desc{name: name, help: help, kind: "histogram", labels: labels}, buckets: sorted, values: map[string]*histogramSeries{}}
+	r.register(name, h)
+	return h
+}
+
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	s := newSeries(h.desc, labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entry, ok := h.values[s.key]
+	if !ok {
+		entry = &histogramSeries{series: s, counts: make([]uint64, len(h.buckets))}
+		h.values[s.key] = entry
+	}
+
+	// Buckets are stored non-cumulatively and summed when written
+	for i, bound := range h.buckets {
+		if value <= bound {
+			entry.counts[i]++
+			break
+		}
+	}
+	entry.count++
+	entry.sum += value
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.writeHeader(w)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, key := range sortedKeys(h.values) {
+		entry := h.values[key]
+
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += entry.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labelString(h.labels, entry.values, "le", formatFloat(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labelString(h.labels, entry.values, "le", "+Inf"), entry.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, labelString(h.labels, entry.values), formatFloat(entry.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, labelString(h.labels, entry.values), entry.count)
+	}
+}
+
+// funcMetric reads its value when scraped, for state owned elsewhere such as the db pool
+type funcMetric struct {
+	desc
+	value func() float64
+}
+
+func (r *Registry) NewGaugeFunc(name string, help string, value func() float64) {
+	r.register(name, &funcMetric{desc: desc{name: name, help: help, kind: "gauge"}, value: value})
+}
+
+// NewCounterFunc is for totals that are already kept monotonically by someone else
+func (r *Registry) NewCounterFunc(name string, help string, value func() float64) {
+	r.register(name, &funcMetric{desc: desc{name: name, help: help, kind: "counter"}, value: value})
+}
+
+func (f *funcMetric) write(w *bufio.Writer) {
+	f.writeHeader(w)
+	fmt.Fprintf(w, "%s %s\n", f.name, formatFloat(f.value()))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
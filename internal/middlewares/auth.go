@@ -6,46 +6,58 @@ import (
 	"net/http"
 	"strings"
 
-	"firebase.google.com/go/auth"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/auth"
+	"github.com/sarvochcha01/enlace-backend/internal/metrics"
 )
 
+type contextKey string
+
+const authUserKey contextKey = "authUser"
+
 type AuthMiddleware struct {
-	AuthClient *auth.Client
+	Verifier auth.TokenVerifier
 }
 
-func NewAuthMiddleware(authClient *auth.Client) *AuthMiddleware {
-	return &AuthMiddleware{AuthClient: authClient}
+func NewAuthMiddleware(verifier auth.TokenVerifier) *AuthMiddleware {
+	return &AuthMiddleware{Verifier: verifier}
 }
 
-func (am *AuthMiddleware) FirebaseAuthMiddleware(next http.Handler) http.Handler {
+func (am *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
-			http.Error(w, "Authorization header missing", http.StatusUnauthorized)
+			unauthorized(w, r, "Authorization header missing")
 			return
 		}
 
 		tokenParts := strings.Split(authHeader, " ")
 		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-			http.Error(w, "Invalid token format", http.StatusUnauthorized)
+			unauthorized(w, r, "Invalid token format")
 			return
 		}
 
 		idToken := tokenParts[1]
 
-		token, err := am.AuthClient.VerifyIDToken(context.Background(), idToken)
+		token, err := am.Verifier.VerifyToken(r.Context(), idToken)
 		if err != nil {
-			http.Error(w, "Invalid authentication token", http.StatusUnauthorized)
+			unauthorized(w, r, "Invalid authentication token")
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), "firebaseUser", token)
+		ctx := context.WithValue(r.Context(), authUserKey, token)
+		ctx = setUserID(ctx, token.UID)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+func unauthorized(w http.ResponseWriter, r *http.Request, message string) {
+	metrics.FromContext(r.Context()).RecordError(string(apperrors.KindUnauthorized))
+	apperrors.Write(w, apperrors.Unauthorized(message), "")
+}
+
 func GetFirebaseUser(r *http.Request) (*auth.Token, error) {
-	user, ok := r.Context().Value("firebaseUser").(*auth.Token)
+	user, ok := r.Context().Value(authUserKey).(*auth.Token)
 	if !ok {
 		return nil, errors.New("user not authenticated")
 	}
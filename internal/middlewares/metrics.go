@@ -0,0 +1,39 @@
+package middlewares
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/sarvochcha01/enlace-backend/internal/metrics"
+)
+
+// Requests that match no route share one series instead of one per probed URL
+const unmatchedRoute = "unmatched"
+
+// Metrics records the count and latency of every request by route pattern and makes m available
+// to handlers through the request context
+func Metrics(m *metrics.Metrics) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			ctx := metrics.WithMetrics(r.Context(), m)
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			route := unmatchedRoute
+			if routeCtx := chi.RouteContext(ctx); routeCtx != nil && routeCtx.RoutePattern() != "" {
+				route = routeCtx.RoutePattern()
+			}
+
+			m.ObserveRequest(r.Method, route, status, time.Since(start))
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package middlewares
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/logging"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+const requestInfoKey contextKey = "requestInfo"
+
+// IDs from upstream proxies are reused when they look sane, anything else is replaced
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// requestInfo collects what inner middlewares learn about a request for the access log
+type requestInfo struct {
+	userID string
+}
+
+// RequestLogger gives every request an ID, echoes it in the response, puts a logger tagged with it
+// in the request context and writes an access log line once the request has been served.
+func RequestLogger(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			requestID := r.Header.Get(RequestIDHeader)
+			if !validRequestID.MatchString(requestID) {
+				requestID = uuid.NewString()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			requestLogger := logger.With("request_id", requestID)
+			info := &requestInfo{}
+
+			ctx := logging.WithLogger(r.Context(), requestLogger)
+			ctx = context.WithValue(ctx, requestInfoKey, info)
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			attrs := []any{
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", status,
+				"bytes", ww.BytesWritten(),
+				"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if routeCtx := chi.RouteContext(ctx); routeCtx != nil && routeCtx.RoutePattern() != "" {
+				attrs = append(attrs, "route", routeCtx.RoutePattern())
+			}
+			if info.userID != "" {
+				attrs = append(attrs, "user_id", info.userID)
+			}
+
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			requestLogger.Log(ctx, level, "request served", attrs...)
+		})
+	}
+}
+
+// setUserID records the authenticated user for the access log and tags the request logger with it
+func setUserID(ctx context.Context, userID string) context.Context {
+	if info, ok := ctx.Value(requestInfoKey).(*requestInfo); ok {
+		info.userID = userID
+	}
+	return logging.With(ctx, "user_id", userID)
+}
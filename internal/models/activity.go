@@ -0,0 +1,155 @@
+package models
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskActivityField names a task field in the activity history, using its JSON name
+type TaskActivityField string
+
+const (
+	TaskFieldTitle       TaskActivityField = "title"
+	TaskFieldDescription TaskActivityField = "description"
+	TaskFieldStatus      TaskActivityField = "status"
+	TaskFieldPriority    TaskActivityField = "priority"
+	TaskFieldDueDate     TaskActivityField = "dueDate"
+	TaskFieldAssignedTo  TaskActivityField = "assignedTo"
+	TaskFieldParentID    TaskActivityField = "parentId"
+	// Estimates are recorded in minutes
+	TaskFieldOriginalEstimate  TaskActivityField = "originalEstimate"
+	TaskFieldRemainingEstimate TaskActivityField = "remainingEstimate"
+	TaskFieldStoryPoints       TaskActivityField = "storyPoints"
+	TaskFieldSprintID          TaskActivityField = "sprintId"
+)
+
+// TaskActivityFields lists the fields in declaration order, which orders changes made together
+var TaskActivityFields = []TaskActivityField{
+	TaskFieldTitle, TaskFieldDescription, TaskFieldStatus, TaskFieldPriority, TaskFieldDueDate, TaskFieldAssignedTo, TaskFieldParentID,
+	TaskFieldOriginalEstimate, TaskFieldRemainingEstimate, TaskFieldStoryPoints, TaskFieldSprintID,
+}
+
+// TaskFieldChange is one field of a task going from OldValue to NewValue. Values are kept as text:
+// dates in RFC 3339, the assignee as a project member ID, the parent as a task ID and the sprint by its
+// ID, nil when the field is empty.
+type TaskFieldChange struct {
+	Field    TaskActivityField `json:"field"`
+	OldValue *string           `json:"oldValue"`
+	NewValue *string           `json:"newValue"`
+}
+
+type TaskActivityResponseDTO struct {
+	ID     uuid.UUID                `json:"id"`
+	TaskID uuid.UUID                `json:"taskId"`
+	Actor  ProjectMemberResponseDTO `json:"actor"`
+	TaskFieldChange
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type TaskTimelineEntryType string
+
+const (
+	TaskTimelineActivity TaskTimelineEntryType = "activity"
+	TaskTimelineComment  TaskTimelineEntryType = "comment"
+)
+
+// TaskTimelineEntryDTO is either a field change or a comment, whichever Type says
+type TaskTimelineEntryDTO struct {
+	Type      TaskTimelineEntryType    `json:"type"`
+	CreatedAt time.Time                `json:"createdAt"`
+	Activity  *TaskActivityResponseDTO `json:"activity,omitempty"`
+	Comment   *CommentResponseDTO      `json:"comment,omitempty"`
+}
+
+// Changes lists the fields of p that would change task, in the order the fields are declared.
+// Fields that already hold the patched value are left out.
+func (p *PatchTaskDTO) Changes(task *TaskResponseDTO) []TaskFieldChange {
+	var changes []TaskFieldChange
+	add := func(field TaskActivityField, oldValue *string, newValue *string) {
+		if !equalPtr(oldValue, newValue) {
+			changes = append(changes, TaskFieldChange{Field: field, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+
+	if p.Title.Present {
+		add(TaskFieldTitle, &task.Title, p.Title.Value)
+	}
+	if p.Description.Present {
+		add(TaskFieldDescription, task.Description, p.Description.Value)
+	}
+	if p.Status.Present {
+		add(TaskFieldStatus, stringValue(&task.Status), stringValue(p.Status.Value))
+	}
+	if p.Priority.Present {
+		add(TaskFieldPriority, stringValue(&task.Priority), stringValue(p.Priority.Value))
+	}
+	if p.DueDate.Present {
+		add(TaskFieldDueDate, timeValue(task.DueDate), timeValue(p.DueDate.Value))
+	}
+	if p.AssignedTo.Present {
+		var assignedTo *uuid.UUID
+		if task.AssignedTo != nil {
+			assignedTo = &task.AssignedTo.ID
+		}
+		add(TaskFieldAssignedTo, uuidValue(assignedTo), uuidValue(p.AssignedTo.Value))
+	}
+	if p.ParentID.Present {
+		add(TaskFieldParentID, uuidValue(task.ParentID), uuidValue(p.ParentID.Value))
+	}
+	if p.OriginalEstimate.Present {
+		add(TaskFieldOriginalEstimate, intValue(task.OriginalEstimate), intValue(p.OriginalEstimate.Value))
+	}
+	if p.RemainingEstimate.Present {
+		add(TaskFieldRemainingEstimate, intValue(task.RemainingEstimate), intValue(p.RemainingEstimate.Value))
+	}
+	if p.StoryPoints.Present {
+		add(TaskFieldStoryPoints, intValue(task.StoryPoints), intValue(p.StoryPoints.Value))
+	}
+	if p.SprintID.Present {
+		add(TaskFieldSprintID, uuidValue(task.SprintID), uuidValue(p.SprintID.Value))
+	}
+
+	return changes
+}
+
+func equalPtr[T comparable](a *T, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func stringValue[T ~string](value *T) *string {
+	if value == nil {
+		return nil
+	}
+	s := string(*value)
+	return &s
+}
+
+// timeValue normalises to UTC so that the same instant always compares equal
+func timeValue(value *time.Time) *string {
+	if value == nil {
+		return nil
+	}
+	s := value.UTC().Format(time.RFC3339Nano)
+	return &s
+}
+
+func uuidValue(value *uuid.UUID) *string {
+	if value == nil {
+		return nil
+	}
+	s := value.String()
+	return &s
+}
+
+func intValue(value *int) *string {
+	if value == nil {
+		return nil
+	}
+	s := strconv.Itoa(*value)
+	return &s
+}
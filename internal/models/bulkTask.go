@@ -0,0 +1,96 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+)
+
+const MaxBulkTasks = 100
+
+// BulkTaskDTO applies one change to many tasks of a project: either the fields present, as in a
+// merge patch, or deleting them. Nothing is applied unless every task can take the change.
+type BulkTaskDTO struct {
+	TaskIDs    []uuid.UUID              `json:"taskIds"`
+	AssignedTo PatchField[uuid.UUID]    `json:"assignedTo"`
+	Status     PatchField[TaskStatus]   `json:"status"`
+	Priority   PatchField[TaskPriority] `json:"priority"`
+	DueDate    PatchField[time.Time]    `json:"dueDate"`
+	SprintID   PatchField[uuid.UUID]    `json:"sprintId"`
+	Delete     bool                     `json:"delete"`
+	// OverrideBlockers lets an owner complete tasks that still have open blockers
+	OverrideBlockers bool `json:"-"`
+}
+
+// Patch is the change made to each task when the operation isn't a delete
+func (b *BulkTaskDTO) Patch() *PatchTaskDTO {
+	return &PatchTaskDTO{
+		AssignedTo:       b.AssignedTo,
+		Status:           b.Status,
+		Priority:         b.Priority,
+		DueDate:          b.DueDate,
+		SprintID:         b.SprintID,
+		OverrideBlockers: b.OverrideBlockers,
+	}
+}
+
+// Validate reports the problems of the operation as a whole, keyed by their JSON name
+func (b *BulkTaskDTO) Validate() map[string]string {
+	problems := b.Patch().Validate()
+	if problems == nil {
+		problems = map[string]string{}
+	}
+
+	switch {
+	case len(b.TaskIDs) == 0:
+		problems["taskIds"] = "must list at least one task"
+	case len(b.TaskIDs) > MaxBulkTasks:
+		problems["taskIds"] = fmt.Sprintf("must list at most %d tasks", MaxBulkTasks)
+	}
+
+	seen := map[uuid.UUID]bool{}
+	for _, taskID := range b.TaskIDs {
+		if seen[taskID] {
+			problems["taskIds"] = fmt.Sprintf("%s is listed more than once", taskID)
+		}
+		seen[taskID] = true
+	}
+
+	changes := !b.Patch().IsEmpty()
+	if b.Delete && changes {
+		problems["delete"] = "can't be combined with changing fields"
+	} else if !b.Delete && !changes {
+		problems["delete"] = "must be true unless assignedTo, status, priority, dueDate or sprintId is given"
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return problems
+}
+
+// BulkTaskOutcome is what happened to one task of a bulk operation
+type BulkTaskOutcome string
+
+const (
+	BulkTaskUpdated   BulkTaskOutcome = "updated"
+	BulkTaskUnchanged BulkTaskOutcome = "unchanged" // the task already had the values
+	BulkTaskDeleted   BulkTaskOutcome = "deleted"
+	BulkTaskFailed    BulkTaskOutcome = "failed"
+	BulkTaskSkipped   BulkTaskOutcome = "skipped" // the task was fine, but another one failed
+)
+
+type BulkTaskResultDTO struct {
+	TaskID  uuid.UUID           `json:"taskId"`
+	Outcome BulkTaskOutcome     `json:"outcome"`
+	Task    *TaskResponseDTO    `json:"task,omitempty"`  // the task after an update
+	Error   *apperrors.Response `json:"error,omitempty"` // why the task failed
+}
+
+// BulkTaskResponseDTO has one result per task, in the order of the request
+type BulkTaskResponseDTO struct {
+	Applied bool                `json:"applied"`
+	Results []BulkTaskResultDTO `json:"results"`
+}
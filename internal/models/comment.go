@@ -17,8 +17,11 @@ type CommentResponseDTO struct {
 	TaskID    uuid.UUID `json:"taskId"`
 	CreatedBy uuid.UUID `json:"createdBy"`
 	Comment   string    `json:"comment"`
-	CreatedAt string    `json:"createdAt"`
-	UpdatedAt string    `json:"updatedAt"`
+	// CommentHTML is the comment escaped for HTML, with the task keys the reader can see, such as
+	// APO-42, linked to their tasks
+	CommentHTML string `json:"commentHtml"`
+	CreatedAt   string `json:"createdAt"`
+	UpdatedAt   string `json:"updatedAt"`
 }
 
 type UpdateCommentDTO struct {
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ETag is the entity tag of a task or project. It is derived from updated_at, which changes with
+// every write, so it is a version without a column of its own.
+func ETag(updatedAt time.Time) string {
+	return fmt.Sprintf(`"%x"`, updatedAt.UnixMicro())
+}
+
+// ETag is the project's entity tag, or "" if its updated_at can't be read
+func (p *ProjectResponseDTO) ETag() string {
+	updatedAt, err := time.Parse(time.RFC3339Nano, p.UpdatedAt)
+	if err != nil {
+		return ""
+	}
+	return ETag(updatedAt)
+}
+
+// IfMatch is the list of entity tags in an If-Match header, nil when the request has none
+type IfMatch []string
+
+func ParseIfMatch(header string) IfMatch {
+	var tags IfMatch
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// Matches reports whether a write to the version tagged etag may go ahead. Without an If-Match any
+// version may be written and "*" matches every version. If-Match compares strongly, so weak tags
+// (W/"...") never match.
+func (m IfMatch) Matches(etag string) bool {
+	if len(m) == 0 {
+		return true
+	}
+	for _, tag := range m {
+		if tag == "*" || (tag == etag && etag != "") {
+			return true
+		}
+	}
+	return false
+}
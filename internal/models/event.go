@@ -0,0 +1,21 @@
+package models
+
+import "github.com/google/uuid"
+
+type ProjectEventType string
+
+const (
+	ProjectEventTaskMoved ProjectEventType = "task_moved"
+)
+
+// ProjectEventDTO is pushed over the WebSocket to the members of a project so that open views can
+// follow changes live. Unlike notifications events aren't stored, and Type never clashes with a
+// notification type.
+type ProjectEventDTO struct {
+	Type      ProjectEventType `json:"type"`
+	ProjectID uuid.UUID        `json:"projectId"`
+	ActorID   uuid.UUID        `json:"actorId"` // project member who made the change
+	Task      *TaskResponseDTO `json:"task,omitempty"`
+	// Rebalanced is set when the move renumbered the whole column, whose ranks should be reloaded
+	Rebalanced bool `json:"rebalanced,omitempty"`
+}
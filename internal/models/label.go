@@ -0,0 +1,55 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const MaxLabelNameLength = 50
+
+var labelColorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+type CreateLabelDTO struct {
+	ProjectID uuid.UUID `json:"projectId"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+}
+
+type UpdateLabelDTO struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type LabelResponseDTO struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"projectId"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+}
+
+// NormalizeLabel trims the name and lower cases the color, which is how labels are stored
+func NormalizeLabel(name string, color string) (string, string) {
+	return strings.TrimSpace(name), strings.ToLower(strings.TrimSpace(color))
+}
+
+// ValidateLabel reports the problems with a normalized name and color, keyed by their JSON name
+func ValidateLabel(name string, color string) map[string]string {
+	problems := map[string]string{}
+
+	if name == "" {
+		problems["name"] = "must not be empty"
+	} else if utf8.RuneCountInString(name) > MaxLabelNameLength {
+		problems["name"] = "must be at most 50 characters"
+	}
+	if !labelColorPattern.MatchString(color) {
+		problems["color"] = "must be a hex color such as #1f883d"
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return problems
+}
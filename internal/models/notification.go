@@ -13,6 +13,7 @@ const (
 	NotificationTypeTaskAssigned      NotificationType = "task_assigned"
 	NotificationTypeProjectInvitation NotificationType = "project_invitation"
 	NotificationTypeCommentAdded      NotificationType = "comment_added"
+	NotificationTypeTaskUpdated       NotificationType = "task_updated" // a watched task's status, assignee or due date changed
 
 	NotificationStatusUnread NotificationStatus = "unread"
 	NotificationStatusRead   NotificationStatus = "read"
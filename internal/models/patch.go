@@ -0,0 +1,29 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// PatchField is one member of a JSON merge patch (RFC 7386). A field missing from the document
+// is not Present; an explicit null is Present with a nil Value.
+type PatchField[T any] struct {
+	Present bool
+	Value   *T
+}
+
+// UnmarshalJSON is only called for keys that appear in the document, which is what tells absent and null apart
+func (f *PatchField[T]) UnmarshalJSON(data []byte) error {
+	f.Present = true
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		f.Value = nil
+		return nil
+	}
+
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	f.Value = &value
+	return nil
+}
@@ -23,11 +23,35 @@ type ProjectResponseDTO struct {
 	TasksCompleted                 int                        `json:"tasksCompleted"`
 	TotalTasks                     int                        `json:"totalTasks"`
 	ActiveTasksAssignedToUserCount int                        `json:"activeTasksAssignedToUserCount"`
+	TotalSubtasks                  int                        `json:"totalSubtasks"`
+	SubtasksCompleted              int                        `json:"subtasksCompleted"`
+	CompletionPercentage           int                        `json:"completionPercentage"` // of every task, subtasks included
 	CreatedAt                      string                     `json:"createdAt"`
 	UpdatedAt                      string                     `json:"updatedAt"`
 }
 
+// CountTasks sets the task and subtask counters from Tasks
+func (p *ProjectResponseDTO) CountTasks() {
+	p.TotalTasks, p.TasksCompleted, p.TotalSubtasks, p.SubtasksCompleted = 0, 0, 0, 0
+	for _, task := range p.Tasks {
+		completed := task.StatusCategory == StatusCategoryDone
+		p.TotalTasks++
+		if completed {
+			p.TasksCompleted++
+		}
+		if task.ParentID != nil {
+			p.TotalSubtasks++
+			if completed {
+				p.SubtasksCompleted++
+			}
+		}
+	}
+	p.CompletionPercentage = CompletionPercentage(p.TasksCompleted, p.TotalTasks)
+}
+
 type EditProjectDTO struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	// IfMatch makes the edit conditional on the project still being the version the client read
+	IfMatch IfMatch `json:"-"`
 }
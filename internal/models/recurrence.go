@@ -0,0 +1,210 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const maxRecurrenceInterval = 1000
+
+// RecurrenceFrequency is the unit a recurring task repeats in
+type RecurrenceFrequency string
+
+const (
+	RecurDaily   RecurrenceFrequency = "DAILY"
+	RecurWeekly  RecurrenceFrequency = "WEEKLY"
+	RecurMonthly RecurrenceFrequency = "MONTHLY"
+)
+
+var rruleWeekdays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"} // indexed by time.Weekday
+
+// RecurrenceRule is the subset of an iCalendar RRULE (RFC 5545) tasks can repeat by, e.g.
+// FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;COUNT=10. The first occurrence is the task's due date.
+type RecurrenceRule struct {
+	Freq     RecurrenceFrequency
+	Interval int
+	ByDay    []time.Weekday // weekly rules only, in week order from Monday
+	Until    *time.Time     // no occurrence is due after it
+	Count    int            // how many occurrences there are in all, including the first, 0 for no limit
+}
+
+// ParseRecurrenceRule reads a rule such as "FREQ=DAILY;INTERVAL=3". An "RRULE:" prefix is allowed
+// and names are case insensitive. Parts outside the supported subset are rejected.
+func ParseRecurrenceRule(text string) (*RecurrenceRule, error) {
+	text = strings.TrimSpace(text)
+	if len(text) >= len("RRULE:") && strings.EqualFold(text[:len("RRULE:")], "RRULE:") {
+		text = text[len("RRULE:"):]
+	}
+
+	rule := &RecurrenceRule{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(text, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name, value = strings.ToUpper(strings.TrimSpace(name)), strings.ToUpper(strings.TrimSpace(value))
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("%q is not a NAME=VALUE part", part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%s is given more than once", name)
+		}
+		seen[name] = true
+
+		switch name {
+		case "FREQ":
+			rule.Freq = RecurrenceFrequency(value)
+			if rule.Freq != RecurDaily && rule.Freq != RecurWeekly && rule.Freq != RecurMonthly {
+				return nil, fmt.Errorf("FREQ must be DAILY, WEEKLY or MONTHLY")
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 || interval > maxRecurrenceInterval {
+				return nil, fmt.Errorf("INTERVAL must be a whole number from 1 to %d", maxRecurrenceInterval)
+			}
+			rule.Interval = interval
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday := slices.Index(rruleWeekdays, strings.TrimSpace(day))
+				if weekday < 0 {
+					return nil, fmt.Errorf("BYDAY must list days as MO, TU, WE, TH, FR, SA or SU")
+				}
+				if !slices.Contains(rule.ByDay, time.Weekday(weekday)) {
+					rule.ByDay = append(rule.ByDay, time.Weekday(weekday))
+				}
+			}
+			slices.SortFunc(rule.ByDay, func(a, b time.Weekday) int { return weekIndex(a) - weekIndex(b) })
+		case "UNTIL":
+			until, err := parseRRuleTime(value)
+			if err != nil {
+				return nil, fmt.Errorf("UNTIL must be a date such as 20261231 or a UTC time such as 20261231T170000Z")
+			}
+			rule.Until = &until
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("COUNT must be a positive whole number")
+			}
+			rule.Count = count
+		default:
+			return nil, fmt.Errorf("%s is not supported, use FREQ, INTERVAL, BYDAY, UNTIL and COUNT", name)
+		}
+	}
+
+	switch {
+	case rule.Freq == "":
+		return nil, fmt.Errorf("FREQ is required")
+	case len(rule.ByDay) > 0 && rule.Freq != RecurWeekly:
+		return nil, fmt.Errorf("BYDAY is only supported for weekly rules")
+	case rule.Until != nil && rule.Count > 0:
+		return nil, fmt.Errorf("UNTIL and COUNT can't be combined")
+	}
+
+	return rule, nil
+}
+
+func parseRRuleTime(value string) (time.Time, error) {
+	if until, err := time.Parse("20060102T150405Z", value); err == nil {
+		return until, nil
+	}
+	until, err := time.Parse("20060102", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// A date includes the whole day
+	return until.Add(24*time.Hour - time.Second), nil
+}
+
+// String is the rule in the canonical form it is stored in
+func (r *RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = rruleWeekdays[day]
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the due date of the occurrence after the one due at previous, given how many
+// occurrences there have been so far. It is false once the rule's COUNT or UNTIL is reached.
+func (r *RecurrenceRule) Next(previous time.Time, occurrences int) (time.Time, bool) {
+	if r.Count > 0 && occurrences >= r.Count {
+		return time.Time{}, false
+	}
+
+	var next time.Time
+	switch r.Freq {
+	case RecurDaily:
+		next = previous.AddDate(0, 0, r.Interval)
+	case RecurWeekly:
+		next = r.nextWeekly(previous)
+	case RecurMonthly:
+		// Months too short for the day are skipped, like RRULE does
+		for i := 1; ; i++ {
+			next = time.Date(previous.Year(), previous.Month()+time.Month(r.Interval*i), previous.Day(),
+				previous.Hour(), previous.Minute(), previous.Second(), previous.Nanosecond(), previous.Location())
+			if next.Day() == previous.Day() {
+				break
+			}
+		}
+	default:
+		return time.Time{}, false
+	}
+
+	if r.Until != nil && next.After(*r.Until) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// nextWeekly finds the next listed day later in the same week, or else the first listed day of
+// the week Interval weeks on. Weeks start on Monday.
+func (r *RecurrenceRule) nextWeekly(previous time.Time) time.Time {
+	if len(r.ByDay) == 0 {
+		return previous.AddDate(0, 0, 7*r.Interval)
+	}
+
+	for _, day := range r.ByDay {
+		if weekIndex(day) > weekIndex(previous.Weekday()) {
+			return previous.AddDate(0, 0, weekIndex(day)-weekIndex(previous.Weekday()))
+		}
+	}
+
+	monday := previous.AddDate(0, 0, -weekIndex(previous.Weekday()))
+	return monday.AddDate(0, 0, 7*r.Interval+weekIndex(r.ByDay[0]))
+}
+
+// weekIndex counts days from Monday
+func weekIndex(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+// SetTaskRecurrenceDTO makes a task repeat by Rule, or changes the rule of the series it is in
+type SetTaskRecurrenceDTO struct {
+	Rule string `json:"rule"`
+}
+
+// TaskRecurrenceDTO is the series a recurring task belongs to
+type TaskRecurrenceDTO struct {
+	ID          uuid.UUID `json:"id"`
+	Rule        string    `json:"rule"`
+	Occurrences int       `json:"occurrences"` // tasks the series has created so far
+	// CurrentTaskID is the latest occurrence, completing it creates the next one. It is null once
+	// the series has ended.
+	CurrentTaskID *uuid.UUID `json:"currentTaskId"`
+}
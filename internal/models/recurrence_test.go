@@ -0,0 +1,187 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+)
+
+const occurrenceLayout = "2006-01-02 Mon 15:04"
+
+func TestParseRecurrenceRule(t *testing.T) {
+	tests := []struct {
+		text string
+		want string // the canonical rule
+	}{
+		{"FREQ=DAILY", "FREQ=DAILY"},
+		{"rrule:freq=weekly;byday=th,mo,th;interval=2", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH"},
+		{"FREQ=WEEKLY;BYDAY=SU,SA,MO", "FREQ=WEEKLY;BYDAY=MO,SA,SU"},
+		{"FREQ=MONTHLY;INTERVAL=1;COUNT=5", "FREQ=MONTHLY;COUNT=5"},
+		{"FREQ=DAILY;UNTIL=20261231", "FREQ=DAILY;UNTIL=20261231T235959Z"},
+		{"FREQ=DAILY;UNTIL=20261231T120000Z", "FREQ=DAILY;UNTIL=20261231T120000Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			rule, err := models.ParseRecurrenceRule(tt.text)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := rule.String(); got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseRecurrenceRuleErrors(t *testing.T) {
+	tests := []struct {
+		text string
+		want string // part of the error message
+	}{
+		{"", "NAME=VALUE"},
+		{"INTERVAL=2", "FREQ is required"},
+		{"FREQ=YEARLY", "FREQ must be"},
+		{"FREQ=DAILY;FREQ=WEEKLY", "more than once"},
+		{"FREQ=DAILY;INTERVAL=0", "INTERVAL must be"},
+		{"FREQ=DAILY;INTERVAL=1001", "INTERVAL must be"},
+		{"FREQ=DAILY;BYDAY=MO", "only supported for weekly"},
+		{"FREQ=WEEKLY;BYDAY=MO,XX", "BYDAY must list"},
+		{"FREQ=DAILY;UNTIL=2026-12-31", "UNTIL must be"},
+		{"FREQ=DAILY;COUNT=0", "COUNT must be"},
+		{"FREQ=DAILY;COUNT=2;UNTIL=20261231", "can't be combined"},
+		{"FREQ=MONTHLY;BYMONTHDAY=1", "not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			_, err := models.ParseRecurrenceRule(tt.text)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected an error about %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRecurrenceRuleNext(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+		due  string   // the first occurrence
+		want []string // the occurrences after it
+		ends bool     // whether the series ends after want
+	}{
+		{
+			name: "monthly on the 31st skips short months",
+			rule: "FREQ=MONTHLY",
+			due:  "2027-01-31 Sun 09:00",
+			want: []string{"2027-03-31 Wed 09:00", "2027-05-31 Mon 09:00", "2027-07-31 Sat 09:00", "2027-08-31 Tue 09:00", "2027-10-31 Sun 09:00"},
+		},
+		{
+			name: "yearly on Feb 29 waits for a leap year",
+			rule: "FREQ=MONTHLY;INTERVAL=12",
+			due:  "2028-02-29 Tue 09:00",
+			want: []string{"2032-02-29 Sun 09:00", "2036-02-29 Fri 09:00"},
+		},
+		{
+			name: "every other month on the 30th skips February",
+			rule: "FREQ=MONTHLY;INTERVAL=2",
+			due:  "2026-12-30 Wed 09:00",
+			want: []string{"2027-04-30 Fri 09:00", "2027-06-30 Wed 09:00"},
+		},
+		{
+			name: "daily with an interval and a count",
+			rule: "FREQ=DAILY;INTERVAL=3;COUNT=3",
+			due:  "2026-10-19 Mon 09:00",
+			want: []string{"2026-10-22 Thu 09:00", "2026-10-25 Sun 09:00"},
+			ends: true,
+		},
+		{
+			name: "a count of one has no further occurrences",
+			rule: "FREQ=DAILY;COUNT=1",
+			due:  "2026-10-19 Mon 09:00",
+			ends: true,
+		},
+		{
+			name: "weekly with an interval",
+			rule: "FREQ=WEEKLY;INTERVAL=2",
+			due:  "2026-10-19 Mon 09:00",
+			want: []string{"2026-11-02 Mon 09:00", "2026-11-16 Mon 09:00"},
+		},
+		{
+			name: "byday from an unlisted day earlier in the week",
+			rule: "FREQ=WEEKLY;BYDAY=MO,TH",
+			due:  "2026-10-20 Tue 09:00",
+			want: []string{"2026-10-22 Thu 09:00", "2026-10-26 Mon 09:00", "2026-10-29 Thu 09:00"},
+		},
+		{
+			name: "byday from an unlisted day after the last listed one",
+			rule: "FREQ=WEEKLY;BYDAY=MO,TH",
+			due:  "2026-10-23 Fri 09:00",
+			want: []string{"2026-10-26 Mon 09:00", "2026-10-29 Thu 09:00"},
+		},
+		{
+			name: "byday with an interval stays in the week, then skips weeks",
+			rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+			due:  "2026-10-19 Mon 09:00",
+			want: []string{"2026-10-22 Thu 09:00", "2026-11-02 Mon 09:00", "2026-11-05 Thu 09:00", "2026-11-16 Mon 09:00"},
+		},
+		{
+			name: "byday with an interval from an unlisted Sunday counts from its week's Monday",
+			rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+			due:  "2026-10-25 Sun 09:00",
+			want: []string{"2026-11-02 Mon 09:00", "2026-11-05 Thu 09:00"},
+		},
+		{
+			name: "until a date includes the whole day",
+			rule: "FREQ=DAILY;UNTIL=20261221",
+			due:  "2026-12-19 Sat 23:30",
+			want: []string{"2026-12-20 Sun 23:30", "2026-12-21 Mon 23:30"},
+			ends: true,
+		},
+		{
+			name: "until a time excludes later the same day",
+			rule: "FREQ=DAILY;UNTIL=20261221T120000Z",
+			due:  "2026-12-19 Sat 17:00",
+			want: []string{"2026-12-20 Sun 17:00"},
+			ends: true,
+		},
+		{
+			name: "until a time includes earlier the same day",
+			rule: "FREQ=DAILY;UNTIL=20261221T120000Z",
+			due:  "2026-12-19 Sat 09:00",
+			want: []string{"2026-12-20 Sun 09:00", "2026-12-21 Mon 09:00"},
+			ends: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := models.ParseRecurrenceRule(tt.rule)
+			if err != nil {
+				t.Fatalf("parse %s: %v", tt.rule, err)
+			}
+			due, err := time.Parse(occurrenceLayout, tt.due)
+			if err != nil {
+				t.Fatalf("parse due date %s: %v", tt.due, err)
+			}
+
+			occurrences := 1
+			for _, want := range tt.want {
+				next, ok := rule.Next(due, occurrences)
+				if !ok {
+					t.Fatalf("expected %s after %s, the series ended", want, due.Format(occurrenceLayout))
+				}
+				if got := next.Format(occurrenceLayout); got != want {
+					t.Fatalf("expected %s after %s, got %s", want, due.Format(occurrenceLayout), got)
+				}
+				due = next
+				occurrences++
+			}
+
+			next, ok := rule.Next(due, occurrences)
+			if ok == tt.ends {
+				t.Fatalf("expected the series to end after %s: %t, got %s, %t", due.Format(occurrenceLayout), tt.ends, next.Format(occurrenceLayout), ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxSprintNameLength = 100
+	MaxSprintGoalLength = 1000
+	MaxSprintDays       = 90
+	MaxStoryPoints      = 100
+	// VelocitySprints is how many of the last closed sprints the velocity covers, and
+	// VelocityWindow how many of those its average is taken over
+	VelocitySprints = 10
+	VelocityWindow  = 3
+)
+
+type SprintState string
+
+const (
+	SprintPlanned SprintState = "planned"
+	SprintActive  SprintState = "active"
+	SprintClosed  SprintState = "closed"
+)
+
+// ValidateStoryPoints reports what is wrong with a task's story points, or "" if nothing is. No
+// points are fine.
+func ValidateStoryPoints(points *int) string {
+	if points != nil && (*points < 0 || *points > MaxStoryPoints) {
+		return fmt.Sprintf("must be from 0 to %d", MaxStoryPoints)
+	}
+	return ""
+}
+
+type CreateSprintDTO struct {
+	ProjectID uuid.UUID `json:"-"`
+	CreatedBy uuid.UUID `json:"-"`
+	Name      string    `json:"name"`
+	Goal      *string   `json:"goal"`
+	StartDate string    `json:"startDate"` // e.g. 2026-10-19
+	EndDate   string    `json:"endDate"`   // the last day of the sprint
+}
+
+type UpdateSprintDTO struct {
+	Name      string  `json:"name"`
+	Goal      *string `json:"goal"`
+	StartDate string  `json:"startDate"`
+	EndDate   string  `json:"endDate"`
+}
+
+// CloseSprintDTO says where the sprint's unfinished tasks go: to a planned sprint, or back to the
+// backlog when CarryOverTo is null
+type CloseSprintDTO struct {
+	CarryOverTo *uuid.UUID `json:"carryOverTo"`
+}
+
+type SprintResponseDTO struct {
+	ID        uuid.UUID   `json:"id"`
+	ProjectID uuid.UUID   `json:"projectId"`
+	Name      string      `json:"name"`
+	Goal      *string     `json:"goal"`
+	StartDate string      `json:"startDate"`
+	EndDate   string      `json:"endDate"`
+	State     SprintState `json:"state"`
+	// The tasks in the sprint now and their story points, all of them and the done ones
+	TaskCount  int `json:"taskCount"`
+	Points     int `json:"points"`
+	DonePoints int `json:"donePoints"`
+	// Story points in the sprint when it started, and done and carried over when it closed
+	CommittedPoints   *int       `json:"committedPoints"`
+	CompletedPoints   *int       `json:"completedPoints"`
+	CarriedOverPoints *int       `json:"carriedOverPoints"`
+	StartedAt         *time.Time `json:"startedAt"`
+	ClosedAt          *time.Time `json:"closedAt"`
+	CreatedAt         time.Time  `json:"createdAt"`
+	UpdatedAt         time.Time  `json:"updatedAt"`
+}
+
+type CloseSprintResponseDTO struct {
+	Sprint      *SprintResponseDTO `json:"sprint"`
+	CarriedOver []TaskReferenceDTO `json:"carriedOver"` // the unfinished tasks that left the sprint
+}
+
+// NormalizeSprint trims the name and the goal, dropping an empty goal
+func NormalizeSprint(name string, goal *string) (string, *string) {
+	name = strings.TrimSpace(name)
+	if goal != nil {
+		trimmed := strings.TrimSpace(*goal)
+		goal = &trimmed
+		if trimmed == "" {
+			goal = nil
+		}
+	}
+	return name, goal
+}
+
+// ValidateSprint reports the problems of a normalized sprint, keyed by their JSON name
+func ValidateSprint(name string, goal *string, startDate string, endDate string) map[string]string {
+	problems := map[string]string{}
+
+	if name == "" {
+		problems["name"] = "must not be empty"
+	} else if utf8.RuneCountInString(name) > MaxSprintNameLength {
+		problems["name"] = fmt.Sprintf("must be at most %d characters", MaxSprintNameLength)
+	}
+	if goal != nil && utf8.RuneCountInString(*goal) > MaxSprintGoalLength {
+		problems["goal"] = fmt.Sprintf("must be at most %d characters", MaxSprintGoalLength)
+	}
+
+	start, startErr := time.Parse(time.DateOnly, startDate)
+	if startErr != nil {
+		problems["startDate"] = "must be a date such as 2026-10-19"
+	}
+	end, endErr := time.Parse(time.DateOnly, endDate)
+	if endErr != nil {
+		problems["endDate"] = "must be a date such as 2026-11-01"
+	}
+	if startErr == nil && endErr == nil {
+		if end.Before(start) {
+			problems["endDate"] = "must not be before startDate"
+		} else if days := sprintDays(start, end); days > MaxSprintDays {
+			problems["endDate"] = fmt.Sprintf("a sprint can last at most %d days", MaxSprintDays)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return problems
+}
+
+// sprintDays counts the days from start to end, both included
+func sprintDays(start time.Time, end time.Time) int {
+	return int(end.Sub(start).Hours()/24) + 1
+}
+
+// SprintTaskPoints is what the burndown needs to know of a task in the sprint
+type SprintTaskPoints struct {
+	Points      int
+	CompletedAt *time.Time
+}
+
+type BurndownDayDTO struct {
+	Date string `json:"date"`
+	// Remaining is the story points not done by the end of the day, null for days still to come
+	Remaining *int    `json:"remaining"`
+	Ideal     float64 `json:"ideal"` // a straight line from the scope down to 0 on the last day
+}
+
+type SprintBurndownDTO struct {
+	Sprint *SprintResponseDTO `json:"sprint"`
+	// Scope is the story points of the sprint's tasks, including those carried over when it closed
+	Scope     int              `json:"scope"`
+	Completed int              `json:"completed"`
+	Days      []BurndownDayDTO `json:"days"` // every day of the sprint, first to last
+}
+
+// Burndown works out day by day how many of the sprint's story points were left, going by when
+// its tasks were completed, up to now or, for a closed sprint, the day it closed. Days are UTC
+// and a task completed before the sprint started counts as done from the first day.
+func Burndown(sprint *SprintResponseDTO, tasks []SprintTaskPoints, now time.Time) (*SprintBurndownDTO, error) {
+	start, err := time.Parse(time.DateOnly, sprint.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("sprint %s has an unreadable start date: %w", sprint.ID, err)
+	}
+	end, err := time.Parse(time.DateOnly, sprint.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("sprint %s has an unreadable end date: %w", sprint.ID, err)
+	}
+
+	burndown := &SprintBurndownDTO{Sprint: sprint, Days: []BurndownDayDTO{}}
+	for _, task := range tasks {
+		burndown.Scope += task.Points
+		if task.CompletedAt != nil {
+			burndown.Completed += task.Points
+		}
+	}
+	if sprint.CarriedOverPoints != nil {
+		burndown.Scope += *sprint.CarriedOverPoints
+	}
+
+	last := now.UTC()
+	if sprint.ClosedAt != nil {
+		last = sprint.ClosedAt.UTC()
+	}
+	lastDay := last.Format(time.DateOnly)
+
+	days := sprintDays(start, end)
+	for i := range days {
+		date := start.AddDate(0, 0, i).Format(time.DateOnly)
+		day := BurndownDayDTO{Date: date, Ideal: float64(burndown.Scope)}
+		if days > 1 {
+			day.Ideal = math.Round(float64(burndown.Scope)*float64(days-1-i)/float64(days-1)*10) / 10
+		}
+
+		if date <= lastDay {
+			remaining := burndown.Scope
+			for _, task := range tasks {
+				if task.CompletedAt != nil && task.CompletedAt.UTC().Format(time.DateOnly) <= date {
+					remaining -= task.Points
+				}
+			}
+			day.Remaining = &remaining
+		}
+
+		burndown.Days = append(burndown.Days, day)
+	}
+
+	return burndown, nil
+}
+
+type SprintVelocityDTO struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	StartDate string    `json:"startDate"`
+	EndDate   string    `json:"endDate"`
+	Committed int       `json:"committed"` // story points when the sprint started
+	Completed int       `json:"completed"` // story points done when it closed
+}
+
+type VelocityDTO struct {
+	Sprints []SprintVelocityDTO `json:"sprints"` // the last closed sprints, oldest first
+	// Average is the mean of the points completed in the last VelocityWindow of them
+	Average float64 `json:"average"`
+}
+
+// Velocity summarises the closed sprints, which must be given in the order they closed
+func Velocity(closed []SprintResponseDTO) *VelocityDTO {
+	closed = closed[max(0, len(closed)-VelocitySprints):]
+
+	velocity := &VelocityDTO{Sprints: make([]SprintVelocityDTO, len(closed))}
+	for i, sprint := range closed {
+		velocity.Sprints[i] = SprintVelocityDTO{
+			ID:        sprint.ID,
+			Name:      sprint.Name,
+			StartDate: sprint.StartDate,
+			EndDate:   sprint.EndDate,
+		}
+		if sprint.CommittedPoints != nil {
+			velocity.Sprints[i].Committed = *sprint.CommittedPoints
+		}
+		if sprint.CompletedPoints != nil {
+			velocity.Sprints[i].Completed = *sprint.CompletedPoints
+		}
+	}
+
+	window := velocity.Sprints[max(0, len(velocity.Sprints)-VelocityWindow):]
+	if len(window) > 0 {
+		total := 0
+		for _, sprint := range window {
+			total += sprint.Completed
+		}
+		velocity.Average = math.Round(float64(total)/float64(len(window))*10) / 10
+	}
+
+	return velocity
+}
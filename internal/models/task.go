@@ -1,14 +1,17 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// TaskStatus is the key of a status in the task's project workflow
 type TaskStatus string
 type TaskPriority string
 
+// The statuses of the default workflow
 const (
 	Todo       TaskStatus = "todo"
 	InProgress TaskStatus = "in-progress"
@@ -32,25 +35,50 @@ type CreateTaskDTO struct {
 	Status      TaskStatus   `json:"status"`
 	Priority    TaskPriority `json:"priority"`
 	DueDate     *time.Time   `json:"dueDate"`
+	ParentID    *uuid.UUID   `json:"parentId"` // a task in the same project
+	// Estimates are in minutes. The remaining estimate starts out as the original one.
+	OriginalEstimate  *int       `json:"originalEstimate"`
+	RemainingEstimate *int       `json:"remainingEstimate"`
+	StoryPoints       *int       `json:"storyPoints"`
+	SprintID          *uuid.UUID `json:"sprintId"` // a planned or active sprint of the project
+	// Recurrence makes the task the first of a recurring series, see RecurrenceRule
+	Recurrence   *string    `json:"recurrence"`
+	RecurrenceID *uuid.UUID `json:"-"` // the series a new occurrence continues
 }
 
 type TaskResponseDTO struct {
-	ID             uuid.UUID                 `json:"id"`
-	ProjectID      uuid.UUID                 `json:"projectId"`
-	ProjectKey     string                    `json:"projectKey"`
-	ProjectName    string                    `json:"projectName"`
-	CreatedBy      ProjectMemberResponseDTO  `json:"createdBy"`
-	UpdatedBy      ProjectMemberResponseDTO  `json:"updatedBy"`
-	AssignedTo     *ProjectMemberResponseDTO `json:"assignedTo"`
-	Title          string                    `json:"title"`
-	TaskNumber     int                       `json:"taskNumber"`
-	Description    *string                   `json:"description"`
-	Status         TaskStatus                `json:"status"`
-	Priority       TaskPriority              `json:"priority"`
-	DueDate        *time.Time                `json:"dueDate"`
-	AssignedToName string                    `json:"assignedToName"`
-	CreatedAt      time.Time                 `json:"createdAt"`
-	UpdatedAt      time.Time                 `json:"updatedAt"`
+	ID                   uuid.UUID                 `json:"id"`
+	ProjectID            uuid.UUID                 `json:"projectId"`
+	ProjectKey           string                    `json:"projectKey"`
+	ProjectName          string                    `json:"projectName"`
+	CreatedBy            ProjectMemberResponseDTO  `json:"createdBy"`
+	UpdatedBy            ProjectMemberResponseDTO  `json:"updatedBy"`
+	AssignedTo           *ProjectMemberResponseDTO `json:"assignedTo"`
+	Title                string                    `json:"title"`
+	TaskNumber           int                       `json:"taskNumber"`
+	Description          *string                   `json:"description"`
+	Status               TaskStatus                `json:"status"`
+	StatusCategory       TaskStatusCategory        `json:"statusCategory"`
+	StatusPosition       int                       `json:"-"`    // position of the status in the workflow, for sorting
+	Rank                 int                       `json:"rank"` // order within the status column of a board, ties go by task number
+	Priority             TaskPriority              `json:"priority"`
+	DueDate              *time.Time                `json:"dueDate"`
+	AssignedToName       string                    `json:"assignedToName"`
+	ParentID             *uuid.UUID                `json:"parentId"`
+	TotalSubtasks        int                       `json:"totalSubtasks"` // direct subtasks only
+	SubtasksCompleted    int                       `json:"subtasksCompleted"`
+	CompletionPercentage int                       `json:"completionPercentage"`
+	OpenBlockers         []TaskReferenceDTO        `json:"openBlockers"` // blocking tasks whose status isn't done
+	Labels               []LabelResponseDTO        `json:"labels"`
+	Recurrence           *TaskRecurrenceDTO        `json:"recurrence"`        // null unless the task is part of a recurring series
+	OriginalEstimate     *int                      `json:"originalEstimate"`  // minutes
+	RemainingEstimate    *int                      `json:"remainingEstimate"` // minutes
+	TimeLogged           int                       `json:"timeLogged"`        // minutes of work logged on the task, all time
+	StoryPoints          *int                      `json:"storyPoints"`
+	SprintID             *uuid.UUID                `json:"sprintId"`    // null while the task is in the backlog
+	CompletedAt          *time.Time                `json:"completedAt"` // when the task last moved into a done status, null unless it is done
+	CreatedAt            time.Time                 `json:"createdAt"`
+	UpdatedAt            time.Time                 `json:"updatedAt"`
 }
 
 type UpdateTaskDTO struct {
@@ -61,6 +89,10 @@ type UpdateTaskDTO struct {
 	Status      TaskStatus   `json:"status"`
 	Priority    TaskPriority `json:"priority"`
 	DueDate     *time.Time   `json:"dueDate,omitempty"`
+	// OverrideBlockers lets an owner complete a task that still has open blockers
+	OverrideBlockers bool `json:"-"`
+	// IfMatch makes the update conditional on the task still being the version the client read
+	IfMatch IfMatch `json:"-"`
 }
 
 type DeleteTaskDTO struct {
@@ -68,3 +100,167 @@ type DeleteTaskDTO struct {
 	ProjectID   uuid.UUID `json:"projectId"`
 	FirebaseUID string    `json:"firebaseUID"`
 }
+
+// IsValid checks that s is well formed as a status key, whether the project has it is up to its workflow
+func (s TaskStatus) IsValid() bool {
+	return len(s) <= MaxTaskStatusKeyLength && taskStatusKeyPattern.MatchString(string(s))
+}
+
+// Rank orders priorities from low to critical, for sorting
+func (p TaskPriority) Rank() int {
+	switch p {
+	case Low:
+		return 0
+	case Medium:
+		return 1
+	case High:
+		return 2
+	case Critical:
+		return 3
+	}
+	return -1
+}
+
+func (p TaskPriority) IsValid() bool {
+	return p.Rank() >= 0
+}
+
+type TaskSortField string
+
+const (
+	TaskSortNumber    TaskSortField = "number"
+	TaskSortTitle     TaskSortField = "title"
+	TaskSortStatus    TaskSortField = "status"
+	TaskSortPriority  TaskSortField = "priority"
+	TaskSortDueDate   TaskSortField = "dueDate"
+	TaskSortCreatedAt TaskSortField = "createdAt"
+	TaskSortUpdatedAt TaskSortField = "updatedAt"
+	TaskSortRank      TaskSortField = "rank"
+)
+
+func (f TaskSortField) IsValid() bool {
+	switch f {
+	case TaskSortNumber, TaskSortTitle, TaskSortStatus, TaskSortPriority, TaskSortDueDate, TaskSortCreatedAt, TaskSortUpdatedAt, TaskSortRank:
+		return true
+	}
+	return false
+}
+
+type TaskSort struct {
+	Field      TaskSortField
+	Descending bool
+}
+
+// String is the query parameter form, e.g. -dueDate
+func (s TaskSort) String() string {
+	if s.Descending {
+		return "-" + string(s.Field)
+	}
+	return string(s.Field)
+}
+
+// TaskFilter narrows a project's task list. Empty fields match every task, the assignee fields
+// are ORed together.
+type TaskFilter struct {
+	Statuses     []TaskStatus
+	Priorities   []TaskPriority
+	AssignedTo   []uuid.UUID // project member IDs
+	AssignedToMe bool
+	Unassigned   bool
+	DueAfter     *time.Time // inclusive
+	DueBefore    *time.Time // exclusive
+	Text         string
+	ParentID     *uuid.UUID  // only subtasks of this task
+	Labels       []uuid.UUID // tasks with any of these labels
+	Sprints      []uuid.UUID // ORed with Backlog
+	Backlog      bool        // tasks in no sprint
+}
+
+type TaskListQuery struct {
+	Filter TaskFilter
+	// Sort keys in priority order; the task number is always used as the final tiebreaker
+	Sort  []TaskSort
+	Limit int
+	// Cursor is the opaque NextCursor of the previous page
+	Cursor string
+}
+
+type TaskListResponseDTO struct {
+	Tasks      []TaskResponseDTO `json:"tasks"`
+	NextCursor *string           `json:"nextCursor"`
+}
+
+// MoveTaskDTO places a task on the board: in Status, right after AfterTaskID and/or right before
+// BeforeTaskID. With neither neighbour the task goes to the bottom of the column, with both they
+// must still be next to each other.
+type MoveTaskDTO struct {
+	Status       TaskStatus `json:"status"` // empty keeps the current status
+	AfterTaskID  *uuid.UUID `json:"afterTaskId"`
+	BeforeTaskID *uuid.UUID `json:"beforeTaskId"`
+	// OverrideBlockers lets an owner move a task with open blockers into a done status
+	OverrideBlockers bool `json:"-"`
+}
+
+// PatchTaskDTO is a JSON merge patch of a task: absent fields are left alone, null clears a
+// nullable field
+type PatchTaskDTO struct {
+	Title       PatchField[string]       `json:"title"`
+	Description PatchField[string]       `json:"description"`
+	Status      PatchField[TaskStatus]   `json:"status"`
+	Priority    PatchField[TaskPriority] `json:"priority"`
+	DueDate     PatchField[time.Time]    `json:"dueDate"`
+	AssignedTo  PatchField[uuid.UUID]    `json:"assignedTo"`
+	ParentID    PatchField[uuid.UUID]    `json:"parentId"`
+	// Estimates are in minutes
+	OriginalEstimate  PatchField[int]       `json:"originalEstimate"`
+	RemainingEstimate PatchField[int]       `json:"remainingEstimate"`
+	StoryPoints       PatchField[int]       `json:"storyPoints"`
+	SprintID          PatchField[uuid.UUID] `json:"sprintId"` // null moves the task to the backlog
+	// OverrideBlockers lets an owner complete a task that still has open blockers
+	OverrideBlockers bool `json:"-"`
+	// IfMatch makes the patch conditional on the task still being the version the client read
+	IfMatch IfMatch `json:"-"`
+}
+
+// Validate reports every field that can't be applied, keyed by its JSON name
+func (p *PatchTaskDTO) Validate() map[string]string {
+	problems := map[string]string{}
+
+	if p.Title.Present && (p.Title.Value == nil || strings.TrimSpace(*p.Title.Value) == "") {
+		problems["title"] = "must not be empty"
+	}
+	if p.Status.Present && (p.Status.Value == nil || !p.Status.Value.IsValid()) {
+		problems["status"] = "must be " + taskStatusKeyDescription
+	}
+	if p.Priority.Present && (p.Priority.Value == nil || !p.Priority.Value.IsValid()) {
+		problems["priority"] = "must be one of low, medium, high or critical"
+	}
+	if problem := ValidateEstimate(p.OriginalEstimate.Value); problem != "" {
+		problems["originalEstimate"] = problem
+	}
+	if problem := ValidateEstimate(p.RemainingEstimate.Value); problem != "" {
+		problems["remainingEstimate"] = problem
+	}
+	if problem := ValidateStoryPoints(p.StoryPoints.Value); problem != "" {
+		problems["storyPoints"] = problem
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return problems
+}
+
+// IsEmpty is true when the patch doesn't touch any field
+func (p *PatchTaskDTO) IsEmpty() bool {
+	return !p.Title.Present && !p.Description.Present && !p.Status.Present && !p.Priority.Present && !p.DueDate.Present && !p.AssignedTo.Present &&
+		!p.ParentID.Present && !p.OriginalEstimate.Present && !p.RemainingEstimate.Present && !p.StoryPoints.Present && !p.SprintID.Present
+}
+
+// CompletionPercentage is the share of completed out of total, rounded down, and 0 when there is nothing to complete
+func CompletionPercentage(completed int, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return completed * 100 / total
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// taskKeyMention finds task keys written in free text. Project keys are stored upper case, so
+// lower case words like covid-19 aren't taken for keys.
+var taskKeyMention = regexp.MustCompile(`\b[A-Z][A-Z0-9]{0,9}-[1-9][0-9]*\b`)
+
+// TaskKey is how people refer to a task: its project's key and its number, e.g. APO-42
+func TaskKey(projectKey string, taskNumber int) string {
+	return fmt.Sprintf("%s-%d", projectKey, taskNumber)
+}
+
+// ParseTaskKey splits a task key into the project key, upper cased like it is stored, and the
+// task number
+func ParseTaskKey(key string) (projectKey string, taskNumber int, ok bool) {
+	i := strings.LastIndex(key, "-")
+	if i <= 0 {
+		return "", 0, false
+	}
+
+	taskNumber, err := strconv.Atoi(key[i+1:])
+	if err != nil || taskNumber <= 0 || strings.HasPrefix(key[i+1:], "+") {
+		return "", 0, false
+	}
+
+	return strings.ToUpper(strings.TrimSpace(key[:i])), taskNumber, true
+}
+
+// FindTaskKeys returns the distinct task keys mentioned in text, in the order they first appear
+func FindTaskKeys(text string) []string {
+	var keys []string
+	seen := map[string]bool{}
+	for _, key := range taskKeyMention.FindAllString(text, -1) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// TaskKeyLinkDTO is a task a key in some text resolved to
+type TaskKeyLinkDTO struct {
+	Key       string    `json:"key"`
+	TaskID    uuid.UUID `json:"taskId"`
+	ProjectID uuid.UUID `json:"projectId"`
+	Title     string    `json:"title"`
+}
+
+// RenderTaskKeyLinks escapes text for HTML and turns the keys that have a link into anchors to
+// their task's page. Keys without one, e.g. of projects the reader can't see, stay plain text.
+func RenderTaskKeyLinks(text string, links map[string]TaskKeyLinkDTO) string {
+	var b strings.Builder
+	last := 0
+	for _, match := range taskKeyMention.FindAllStringIndex(text, -1) {
+		link, ok := links[text[match[0]:match[1]]]
+		if !ok {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[last:match[0]]))
+		fmt.Fprintf(&b, `<a href="/projects/%s/tasks/%s" title="%s">%s</a>`,
+			link.ProjectID, link.TaskID, html.EscapeString(link.Title), html.EscapeString(link.Key))
+		last = match[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
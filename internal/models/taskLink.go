@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskLinkType says how a task relates to the linked task, read from the task's side:
+// "A blocks B" is the same link as "B blocked-by A"
+type TaskLinkType string
+
+const (
+	TaskLinkBlocks       TaskLinkType = "blocks"
+	TaskLinkBlockedBy    TaskLinkType = "blocked-by"
+	TaskLinkRelatesTo    TaskLinkType = "relates-to"
+	TaskLinkDuplicates   TaskLinkType = "duplicates"
+	TaskLinkDuplicatedBy TaskLinkType = "duplicated-by"
+)
+
+func (t TaskLinkType) IsValid() bool {
+	switch t {
+	case TaskLinkBlocks, TaskLinkBlockedBy, TaskLinkRelatesTo, TaskLinkDuplicates, TaskLinkDuplicatedBy:
+		return true
+	}
+	return false
+}
+
+// Inverse is how the link reads from the other task
+func (t TaskLinkType) Inverse() TaskLinkType {
+	switch t {
+	case TaskLinkBlocks:
+		return TaskLinkBlockedBy
+	case TaskLinkBlockedBy:
+		return TaskLinkBlocks
+	case TaskLinkDuplicates:
+		return TaskLinkDuplicatedBy
+	case TaskLinkDuplicatedBy:
+		return TaskLinkDuplicates
+	}
+	return t
+}
+
+// IsStored is true for the types links are stored as, the others are stored as their inverse
+func (t TaskLinkType) IsStored() bool {
+	return t == TaskLinkBlocks || t == TaskLinkRelatesTo || t == TaskLinkDuplicates
+}
+
+type CreateTaskLinkDTO struct {
+	SourceTaskID uuid.UUID    `json:"sourceTaskId"`
+	TargetTaskID uuid.UUID    `json:"taskId"`
+	Type         TaskLinkType `json:"type"`
+	CreatedBy    uuid.UUID    `json:"createdBy"`
+}
+
+// TaskReferenceDTO is the summary of a task shown where another task refers to it
+type TaskReferenceDTO struct {
+	ID         uuid.UUID  `json:"id"`
+	TaskNumber int        `json:"taskNumber"`
+	Title      string     `json:"title"`
+	Status     TaskStatus `json:"status"`
+}
+
+// TaskLinkResponseDTO is a link as seen from one of its tasks: Type reads from that task to Task
+type TaskLinkResponseDTO struct {
+	ID        uuid.UUID        `json:"id"`
+	Type      TaskLinkType     `json:"type"`
+	Task      TaskReferenceDTO `json:"task"`
+	CreatedBy uuid.UUID        `json:"createdBy"`
+	CreatedAt time.Time        `json:"createdAt"`
+}
@@ -0,0 +1,180 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MaxTaskStatuses          = 20
+	MaxTaskStatusKeyLength   = 30
+	MaxTaskStatusNameLength  = 50
+	taskStatusKeyDescription = "lower case letters, digits and single dashes, e.g. in-review"
+)
+
+var taskStatusKeyPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// TaskStatusCategory groups a project's statuses into not started, started and finished. Stats,
+// blockers and the dashboard go by the category, so they work whatever the statuses are called.
+type TaskStatusCategory string
+
+const (
+	StatusCategoryTodo   TaskStatusCategory = "todo"
+	StatusCategoryActive TaskStatusCategory = "active"
+	StatusCategoryDone   TaskStatusCategory = "done"
+)
+
+func (c TaskStatusCategory) IsValid() bool {
+	return c == StatusCategoryTodo || c == StatusCategoryActive || c == StatusCategoryDone
+}
+
+// TaskStatusDTO is one status of a project's workflow, tasks store its Key as their status
+type TaskStatusDTO struct {
+	Key      TaskStatus         `json:"key"`
+	Name     string             `json:"name"`
+	Category TaskStatusCategory `json:"category"`
+	Position int                `json:"position"` // index in the workflow, set by the server
+	// Transitions lists the statuses a task in this status can move to. Left out of a request it
+	// allows every other status, an empty list makes the status final.
+	Transitions []TaskStatus `json:"transitions"`
+}
+
+// WorkflowDTO is a project's statuses in board order. New tasks start in the first one.
+type WorkflowDTO struct {
+	Statuses []TaskStatusDTO `json:"statuses"`
+}
+
+// DefaultWorkflow is what every project starts with: todo, in-progress and completed, with every
+// transition allowed
+func DefaultWorkflow() *WorkflowDTO {
+	return &WorkflowDTO{Statuses: []TaskStatusDTO{
+		{Key: Todo, Name: "To do", Category: StatusCategoryTodo, Position: 0, Transitions: []TaskStatus{InProgress, Completed}},
+		{Key: InProgress, Name: "In progress", Category: StatusCategoryActive, Position: 1, Transitions: []TaskStatus{Todo, Completed}},
+		{Key: Completed, Name: "Completed", Category: StatusCategoryDone, Position: 2, Transitions: []TaskStatus{Todo, InProgress}},
+	}}
+}
+
+// Status returns the status with the given key, or nil if the workflow doesn't have it
+func (w *WorkflowDTO) Status(key TaskStatus) *TaskStatusDTO {
+	for i := range w.Statuses {
+		if w.Statuses[i].Key == key {
+			return &w.Statuses[i]
+		}
+	}
+	return nil
+}
+
+// Initial is the status new tasks start in
+func (w *WorkflowDTO) Initial() TaskStatus {
+	if len(w.Statuses) == 0 {
+		return Todo
+	}
+	return w.Statuses[0].Key
+}
+
+// Keys lists the status keys in order, for error messages
+func (w *WorkflowDTO) Keys() string {
+	keys := make([]string, len(w.Statuses))
+	for i, status := range w.Statuses {
+		keys[i] = string(status.Key)
+	}
+	return strings.Join(keys, ", ")
+}
+
+// CanTransition is true when a task may move from one status to the other. Staying put always is.
+func (w *WorkflowDTO) CanTransition(from TaskStatus, to TaskStatus) bool {
+	if from == to {
+		return true
+	}
+	status := w.Status(from)
+	return status != nil && slices.Contains(status.Transitions, to)
+}
+
+// Normalize trims names, numbers the statuses in order, expands left out transitions to every
+// other status and drops repeated ones
+func (w *WorkflowDTO) Normalize() {
+	for i := range w.Statuses {
+		status := &w.Statuses[i]
+		status.Key = TaskStatus(strings.TrimSpace(string(status.Key)))
+		status.Name = strings.TrimSpace(status.Name)
+		status.Position = i
+	}
+
+	for i := range w.Statuses {
+		status := &w.Statuses[i]
+		transitions := []TaskStatus{}
+		if status.Transitions == nil {
+			for _, other := range w.Statuses {
+				if other.Key != status.Key {
+					transitions = append(transitions, other.Key)
+				}
+			}
+		}
+		for _, to := range status.Transitions {
+			if !slices.Contains(transitions, to) {
+				transitions = append(transitions, to)
+			}
+		}
+		status.Transitions = transitions
+	}
+}
+
+// Validate reports the problems of a normalized workflow, keyed like statuses[1].key
+func (w *WorkflowDTO) Validate() map[string]string {
+	problems := map[string]string{}
+
+	switch {
+	case len(w.Statuses) == 0:
+		problems["statuses"] = "must have at least one status"
+	case len(w.Statuses) > MaxTaskStatuses:
+		problems["statuses"] = fmt.Sprintf("must have at most %d statuses", MaxTaskStatuses)
+	case w.Statuses[0].Category != StatusCategoryTodo:
+		problems["statuses"] = "the first status is where new tasks start, it must be in the todo category"
+	}
+
+	keys := map[TaskStatus]bool{}
+	for _, status := range w.Statuses {
+		keys[status.Key] = true
+	}
+
+	seen := map[TaskStatus]bool{}
+	for i, status := range w.Statuses {
+		field := func(name string) string {
+			return fmt.Sprintf("statuses[%d].%s", i, name)
+		}
+
+		switch {
+		case !status.Key.IsValid():
+			problems[field("key")] = "must be " + taskStatusKeyDescription
+		case seen[status.Key]:
+			problems[field("key")] = fmt.Sprintf("%s is used by more than one status", status.Key)
+		}
+		seen[status.Key] = true
+
+		if status.Name == "" {
+			problems[field("name")] = "must not be empty"
+		} else if utf8.RuneCountInString(status.Name) > MaxTaskStatusNameLength {
+			problems[field("name")] = fmt.Sprintf("must be at most %d characters", MaxTaskStatusNameLength)
+		}
+
+		if !status.Category.IsValid() {
+			problems[field("category")] = "must be one of todo, active or done"
+		}
+
+		for _, to := range status.Transitions {
+			if to == status.Key {
+				problems[field("transitions")] = "must not include the status itself"
+			} else if !keys[to] {
+				problems[field("transitions")] = fmt.Sprintf("%s is not a status of the workflow", to)
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return problems
+}
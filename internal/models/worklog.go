@@ -0,0 +1,156 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxTimeEstimate        = 1000 * 60 // minutes
+	MaxWorklogDuration     = 24 * 60   // minutes, a day's work
+	MaxWorklogNoteLength   = 2000
+	WorklogDateLayout      = "2006-01-02"
+	worklogDateDescription = "a date such as 2026-10-17"
+)
+
+// ValidateEstimate reports what is wrong with an estimate in minutes, or "" if nothing is. No
+// estimate is fine.
+func ValidateEstimate(minutes *int) string {
+	if minutes != nil && (*minutes < 0 || *minutes > MaxTimeEstimate) {
+		return fmt.Sprintf("must be from 0 to %d minutes", MaxTimeEstimate)
+	}
+	return ""
+}
+
+// CreateWorklogDTO records time spent on a task by the member who logs it
+type CreateWorklogDTO struct {
+	TaskID          uuid.UUID `json:"-"`
+	ProjectMemberID uuid.UUID `json:"-"`
+	Duration        int       `json:"duration"` // minutes
+	Date            string    `json:"date"`     // the day the work was done, today when empty
+	Note            *string   `json:"note"`
+}
+
+type UpdateWorklogDTO struct {
+	Duration int     `json:"duration"` // minutes
+	Date     string  `json:"date"`
+	Note     *string `json:"note"`
+}
+
+type WorklogResponseDTO struct {
+	ID        uuid.UUID                `json:"id"`
+	TaskID    uuid.UUID                `json:"taskId"`
+	Author    ProjectMemberResponseDTO `json:"author"`
+	Duration  int                      `json:"duration"` // minutes
+	Date      string                   `json:"date"`
+	Note      *string                  `json:"note"`
+	CreatedAt time.Time                `json:"createdAt"`
+	UpdatedAt time.Time                `json:"updatedAt"`
+}
+
+// NormalizeWorklog trims the note, dropping an empty one, and fills in today for a missing date
+func NormalizeWorklog(date string, note *string, now time.Time) (string, *string) {
+	date = strings.TrimSpace(date)
+	if date == "" {
+		date = now.UTC().Format(WorklogDateLayout)
+	}
+	if note != nil {
+		trimmed := strings.TrimSpace(*note)
+		note = &trimmed
+		if trimmed == "" {
+			note = nil
+		}
+	}
+	return date, note
+}
+
+// ValidateWorklog reports the problems of a normalized work log, keyed by their JSON name. The
+// date may be up to a day ahead of now, since it is the author's local date.
+func ValidateWorklog(duration int, date string, note *string, now time.Time) map[string]string {
+	problems := map[string]string{}
+
+	if duration <= 0 || duration > MaxWorklogDuration {
+		problems["duration"] = fmt.Sprintf("must be from 1 to %d minutes", MaxWorklogDuration)
+	}
+
+	if day, err := time.Parse(WorklogDateLayout, date); err != nil {
+		problems["date"] = "must be " + worklogDateDescription
+	} else if day.After(now.UTC().AddDate(0, 0, 1)) {
+		problems["date"] = "must not be in the future"
+	}
+
+	if note != nil && utf8.RuneCountInString(*note) > MaxWorklogNoteLength {
+		problems["note"] = fmt.Sprintf("must be at most %d characters", MaxWorklogNoteLength)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return problems
+}
+
+// TimeReportQuery limits a time report to work done between From and To, both inclusive and
+// either left out for no limit
+type TimeReportQuery struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// ParseTimeReportQuery reads the from and to dates of a report
+func ParseTimeReportQuery(from string, to string) (*TimeReportQuery, map[string]string) {
+	query := &TimeReportQuery{}
+	problems := map[string]string{}
+
+	for _, param := range []struct {
+		name  string
+		value string
+		date  **time.Time
+	}{{"from", from, &query.From}, {"to", to, &query.To}} {
+		if param.value == "" {
+			continue
+		}
+		day, err := time.Parse(WorklogDateLayout, param.value)
+		if err != nil {
+			problems[param.name] = "must be " + worklogDateDescription
+			continue
+		}
+		*param.date = &day
+	}
+
+	if query.From != nil && query.To != nil && query.To.Before(*query.From) {
+		problems["to"] = "must not be before from"
+	}
+
+	if len(problems) > 0 {
+		return nil, problems
+	}
+	return query, nil
+}
+
+// TaskTimeDTO is the time logged on a task next to its estimates, all in minutes
+type TaskTimeDTO struct {
+	Task              TaskReferenceDTO `json:"task"`
+	OriginalEstimate  *int             `json:"originalEstimate"`
+	RemainingEstimate *int             `json:"remainingEstimate"`
+	Logged            int              `json:"logged"`
+}
+
+// MemberTimeDTO is the time a member logged, in minutes
+type MemberTimeDTO struct {
+	Member ProjectMemberResponseDTO `json:"member"`
+	Logged int                      `json:"logged"`
+}
+
+// TimeReportDTO totals the work logged in a project over the query's dates. Tasks and members
+// without logged time in the range are left out.
+type TimeReportDTO struct {
+	From    *string         `json:"from"`
+	To      *string         `json:"to"`
+	Logged  int             `json:"logged"`  // minutes, the whole project
+	Tasks   []TaskTimeDTO   `json:"tasks"`   // most time first
+	Members []MemberTimeDTO `json:"members"` // most time first
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -9,14 +10,14 @@ import (
 )
 
 type CommentRepository interface {
-	CreateComment(*models.CreateCommentDTO) error
-	GetComment(uuid.UUID) (*models.CommentResponseDTO, error)
-	UpdateComment(uuid.UUID, string) error
-	DeleteComment(uuid.UUID) error
+	CreateComment(context.Context, *models.CreateCommentDTO) error
+	GetComment(context.Context, uuid.UUID) (*models.CommentResponseDTO, error)
+	UpdateComment(context.Context, uuid.UUID, string) error
+	DeleteComment(context.Context, uuid.UUID) error
 
-	GetAllCommentsForTask(uuid.UUID) ([]models.CommentResponseDTO, error)
+	GetAllCommentsForTask(context.Context, uuid.UUID) ([]models.CommentResponseDTO, error)
 
-	GetCommentCreator(uuid.UUID) (uuid.UUID, error)
+	GetCommentCreator(context.Context, uuid.UUID) (uuid.UUID, error)
 }
 
 type commentRepository struct {
@@ -27,19 +28,19 @@ func NewCommentRepository(db *sql.DB) CommentRepository {
 	return &commentRepository{db: db}
 }
 
-func (r *commentRepository) CreateComment(commentDTO *models.CreateCommentDTO) error {
+func (r *commentRepository) CreateComment(ctx context.Context, commentDTO *models.CreateCommentDTO) error {
 
 	queryString := `
 		INSERT INTO comments (project_id, task_id, created_by, comment)
 		VALUES ($1, $2, $3, $4)
 	`
 
-	_, err := r.db.Exec(queryString, commentDTO.ProjectID, commentDTO.TaskID, commentDTO.CreatedBy, commentDTO.Comment)
+	_, err := r.db.ExecContext(ctx, queryString, commentDTO.ProjectID, commentDTO.TaskID, commentDTO.CreatedBy, commentDTO.Comment)
 
 	return err
 }
 
-func (r *commentRepository) GetComment(commentID uuid.UUID) (*models.CommentResponseDTO, error) {
+func (r *commentRepository) GetComment(ctx context.Context, commentID uuid.UUID) (*models.CommentResponseDTO, error) {
 	var commentDTO models.CommentResponseDTO
 
 	queryString := `
@@ -48,31 +49,31 @@ func (r *commentRepository) GetComment(commentID uuid.UUID) (*models.CommentResp
 		WHERE id = $1	
 	`
 
-	err := r.db.QueryRow(queryString, commentID).Scan(&commentDTO.ID, &commentDTO.ProjectID, &commentDTO.TaskID, &commentDTO.CreatedBy, &commentDTO.Comment, &commentDTO.CreatedAt, &commentDTO.UpdatedAt)
+	err := r.db.QueryRowContext(ctx, queryString, commentID).Scan(&commentDTO.ID, &commentDTO.ProjectID, &commentDTO.TaskID, &commentDTO.CreatedBy, &commentDTO.Comment, &commentDTO.CreatedAt, &commentDTO.UpdatedAt)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, "comment not found")
 	}
 
 	return &commentDTO, nil
 }
 
-func (r *commentRepository) UpdateComment(commentID uuid.UUID, newComment string) error {
+func (r *commentRepository) UpdateComment(ctx context.Context, commentID uuid.UUID, newComment string) error {
 	query := `
 		UPDATE comments
 		SET comment = $1
 		WHERE id = $2
 	`
-	_, err := r.db.Exec(query, newComment, commentID)
+	_, err := r.db.ExecContext(ctx, query, newComment, commentID)
 	return err
 }
 
-func (r *commentRepository) DeleteComment(commentID uuid.UUID) error {
+func (r *commentRepository) DeleteComment(ctx context.Context, commentID uuid.UUID) error {
 	queryString := `
 		DELETE FROM comments
 		WHERE id = $1
 	`
 
-	_, err := r.db.Exec(queryString, commentID)
+	_, err := r.db.ExecContext(ctx, queryString, commentID)
 	if err != nil {
 		return fmt.Errorf("failed to delete comment: %w", err)
 	}
@@ -80,16 +81,16 @@ func (r *commentRepository) DeleteComment(commentID uuid.UUID) error {
 	return nil
 }
 
-func (r *commentRepository) GetCommentCreator(commentID uuid.UUID) (uuid.UUID, error) {
+func (r *commentRepository) GetCommentCreator(ctx context.Context, commentID uuid.UUID) (uuid.UUID, error) {
 	var creatorID uuid.UUID
-	err := r.db.QueryRow("SELECT created_by FROM comments WHERE id = $1", commentID).Scan(&creatorID)
+	err := r.db.QueryRowContext(ctx, "SELECT created_by FROM comments WHERE id = $1", commentID).Scan(&creatorID)
 	if err != nil {
-		return uuid.Nil, err
+		return uuid.Nil, notFound(err, "comment not found")
 	}
 	return creatorID, nil
 }
 
-func (r *commentRepository) GetAllCommentsForTask(taskID uuid.UUID) ([]models.CommentResponseDTO, error) {
+func (r *commentRepository) GetAllCommentsForTask(ctx context.Context, taskID uuid.UUID) ([]models.CommentResponseDTO, error) {
 	comments := []models.CommentResponseDTO{}
 
 	queryString := `
@@ -98,7 +99,7 @@ func (r *commentRepository) GetAllCommentsForTask(taskID uuid.UUID) ([]models.Co
 		WHERE task_id = $1
 	`
 
-	rows, err := r.db.Query(queryString, taskID)
+	rows, err := r.db.QueryContext(ctx, queryString, taskID)
 	if err != nil {
 		return comments, err
 	}
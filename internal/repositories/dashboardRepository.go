@@ -1,21 +1,27 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/sarvochcha01/enlace-backend/internal/models"
 )
 
 type DashboardRepository interface {
-	GetRecentlyAssignedTasks(userID uuid.UUID, limit int) ([]models.TaskResponseDTO, error)
-	GetInProgressTasks(userID uuid.UUID, limit int) ([]models.TaskResponseDTO, error)
-	GetApproachingDeadlineTasks(userID uuid.UUID, limit int) ([]models.TaskResponseDTO, error)
-
-	SearchProjects(userID uuid.UUID, query string) ([]models.ProjectSearchResult, error)
-	SearchTasks(userID uuid.UUID, query string) ([]models.TaskResponseDTO, error)
+	GetRecentlyAssignedTasks(ctx context.Context, userID uuid.UUID, limit int) ([]models.TaskResponseDTO, error)
+	// GetInProgressTasks returns tasks assigned to the user whose status is in the active category
+	GetInProgressTasks(ctx context.Context, userID uuid.UUID, limit int) ([]models.TaskResponseDTO, error)
+	GetApproachingDeadlineTasks(ctx context.Context, userID uuid.UUID, limit int) ([]models.TaskResponseDTO, error)
+
+	SearchProjects(ctx context.Context, userID uuid.UUID, query string) ([]models.ProjectSearchResult, error)
+	// SearchTasks matches query against the task title and project, and when labels is not empty
+	// keeps only tasks with a label of one of those names, compared case insensitively
+	SearchTasks(ctx context.Context, userID uuid.UUID, query string, labels []string) ([]models.TaskResponseDTO, error)
 }
 
 type dashboardRepository struct {
@@ -26,7 +32,7 @@ func NewDashboardRepository(db *sql.DB) DashboardRepository {
 	return &dashboardRepository{db: db}
 }
 
-func (r *dashboardRepository) GetRecentlyAssignedTasks(userID uuid.UUID, limit int) ([]models.TaskResponseDTO, error) {
+func (r *dashboardRepository) GetRecentlyAssignedTasks(ctx context.Context, userID uuid.UUID, limit int) ([]models.TaskResponseDTO, error) {
 	queryString := `
 		SELECT 
 			t.id,
@@ -46,10 +52,10 @@ func (r *dashboardRepository) GetRecentlyAssignedTasks(userID uuid.UUID, limit i
 		LIMIT $2
 	`
 
-	return r.fetchTasks(queryString, userID, limit)
+	return r.fetchTasks(ctx, queryString, userID, limit)
 }
 
-func (r *dashboardRepository) GetInProgressTasks(userID uuid.UUID, limit int) ([]models.TaskResponseDTO, error) {
+func (r *dashboardRepository) GetInProgressTasks(ctx context.Context, userID uuid.UUID, limit int) ([]models.TaskResponseDTO, error) {
 	query := `
 		SELECT 
 			t.id,
@@ -63,17 +69,18 @@ func (r *dashboardRepository) GetInProgressTasks(userID uuid.UUID, limit int) ([
 			t.status
 		FROM tasks t
 		INNER JOIN projects p ON t.project_id = p.id
+		INNER JOIN task_statuses ts ON ts.project_id = t.project_id AND ts.key = t.status
 		LEFT JOIN project_members at_pm ON t.assigned_to = at_pm.id
 		WHERE at_pm.user_id = $1
-		AND t.status = 'in-progress'
+		AND ts.category = 'active'
 		ORDER BY t.updated_at DESC
 		LIMIT $2
 	`
 
-	return r.fetchTasks(query, userID, limit)
+	return r.fetchTasks(ctx, query, userID, limit)
 }
 
-func (r *dashboardRepository) GetApproachingDeadlineTasks(userID uuid.UUID, limit int) ([]models.TaskResponseDTO, error) {
+func (r *dashboardRepository) GetApproachingDeadlineTasks(ctx context.Context, userID uuid.UUID, limit int) ([]models.TaskResponseDTO, error) {
 	query := `
 		SELECT 
 			t.id,
@@ -96,10 +103,10 @@ func (r *dashboardRepository) GetApproachingDeadlineTasks(userID uuid.UUID, limi
 		LIMIT $2
 	`
 
-	return r.fetchTasks(query, userID, limit)
+	return r.fetchTasks(ctx, query, userID, limit)
 }
 
-func (r *dashboardRepository) SearchProjects(userID uuid.UUID, query string) ([]models.ProjectSearchResult, error) {
+func (r *dashboardRepository) SearchProjects(ctx context.Context, userID uuid.UUID, query string) ([]models.ProjectSearchResult, error) {
 	baseQuery := `
 		SELECT
 			p.id,
@@ -107,14 +114,15 @@ func (r *dashboardRepository) SearchProjects(userID uuid.UUID, query string) ([]
 			p.description,
 			p.key,
 			COUNT(t.id) as total_tasks,
-			COUNT(CASE WHEN t.status = 'completed' THEN 1 END) AS completed_tasks,
+			COUNT(CASE WHEN ts.category = 'done' THEN 1 END) AS completed_tasks,
 			COUNT(CASE
-				WHEN t.status IN ('todo', 'in-progress') AND assigned_pm.user_id = $1
+				WHEN ts.category <> 'done' AND assigned_pm.user_id = $1
 				THEN 1
 			END) AS active_tasks_assigned_to_user
 		FROM projects p
 		JOIN project_members pm ON p.id = pm.project_id
 		LEFT JOIN tasks t ON t.project_id = p.id
+		LEFT JOIN task_statuses ts ON ts.project_id = t.project_id AND ts.key = t.status
 		LEFT JOIN project_members assigned_pm ON t.assigned_to = assigned_pm.id
 		WHERE pm.user_id = $1
 		AND pm.status = 'active'`
@@ -134,7 +142,7 @@ func (r *dashboardRepository) SearchProjects(userID uuid.UUID, query string) ([]
 		GROUP BY p.id, p.name, p.description, p.key
 		ORDER BY MAX(p.updated_at) DESC`
 
-	rows, err := r.db.Query(baseQuery, args...)
+	rows, err := r.db.QueryContext(ctx, baseQuery, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -161,7 +169,7 @@ func (r *dashboardRepository) SearchProjects(userID uuid.UUID, query string) ([]
 	return projects, nil
 }
 
-func (r *dashboardRepository) SearchTasks(userID uuid.UUID, query string) ([]models.TaskResponseDTO, error) {
+func (r *dashboardRepository) SearchTasks(ctx context.Context, userID uuid.UUID, query string, labels []string) ([]models.TaskResponseDTO, error) {
 	baseQuery := `
 		SELECT
 			t.id,
@@ -175,7 +183,8 @@ func (r *dashboardRepository) SearchTasks(userID uuid.UUID, query string) ([]mod
 			t.status,
 			t.assigned_to AS assigned_project_member_id, -- project_members.id for assignee
 			assigned_pm.user_id AS assigned_user_id,      -- users.id for assignee
-			assigned_user.name AS assigned_user_name     -- users.name for assignee
+			assigned_user.name AS assigned_user_name,    -- users.name for assignee
+			` + taskLabelsColumn + `
 		FROM tasks t
 		INNER JOIN projects p ON t.project_id = p.id
 		INNER JOIN project_members pm ON p.id = pm.project_id -- For checking user's access
@@ -188,24 +197,46 @@ func (r *dashboardRepository) SearchTasks(userID uuid.UUID, query string) ([]mod
 	args = append(args, userID)
 	argIndex := 2
 
+	// A query that is a task key, such as APO-42, finds that task and puts it first
+	keyMatch := "FALSE"
+
 	if query != "" {
-		// Ensure search terms apply to relevant fields. Original query searched p.name and p.key in tasks.
-		// Adjusted to search t.title and also project context (p.name, p.key, or task_number with project_key prefix).
-		// For simplicity, sticking to the original search scope for query, but this could be enhanced.
-		// Example: (LOWER(t.title) LIKE LOWER($%d) OR (p.key || '-' || t.task_number::TEXT) LIKE LOWER($%d))
-		searchCondition := fmt.Sprintf(" AND (LOWER(t.title) LIKE LOWER($%d) OR LOWER(p.name) LIKE LOWER($%d) OR LOWER(p.key) LIKE LOWER($%d))", argIndex, argIndex, argIndex)
-		baseQuery += searchCondition
+		searchCondition := fmt.Sprintf("LOWER(t.title) LIKE LOWER($%d) OR LOWER(p.name) LIKE LOWER($%d) OR LOWER(p.key) LIKE LOWER($%d)", argIndex, argIndex, argIndex)
 		searchTerm := "%" + strings.ToLower(query) + "%"
 		args = append(args, searchTerm)
+		argIndex++
+
+		if projectKey, taskNumber, ok := models.ParseTaskKey(strings.TrimSpace(query)); ok {
+			keyMatch = fmt.Sprintf("(p.key = $%d AND t.task_number = $%d)", argIndex, argIndex+1)
+			searchCondition += " OR " + keyMatch
+			args = append(args, projectKey, taskNumber)
+			argIndex += 2
+		}
+
+		baseQuery += " AND (" + searchCondition + ")"
+	}
+
+	if len(labels) > 0 {
+		names := make([]string, len(labels))
+		for i, label := range labels {
+			names[i] = strings.ToLower(label)
+		}
+		baseQuery += fmt.Sprintf(`
+		AND EXISTS (
+			SELECT 1 FROM task_labels tl INNER JOIN labels l ON tl.label_id = l.id
+			WHERE tl.task_id = t.id AND LOWER(l.name) = ANY($%d)
+		)`, argIndex)
+		args = append(args, pq.Array(names))
 	}
 
 	baseQuery += `
 		ORDER BY 
+			CASE WHEN ` + keyMatch + ` THEN 0 ELSE 1 END, -- The task the query is the key of first
 			CASE WHEN assigned_pm.user_id = $1 THEN 0 ELSE 1 END, -- Tasks assigned to the current user first
 			t.due_date ASC NULLS LAST,
 			t.updated_at DESC`
 
-	rows, err := r.db.Query(baseQuery, args...)
+	rows, err := r.db.QueryContext(ctx, baseQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute search tasks query: %w", err)
 	}
@@ -217,6 +248,7 @@ func (r *dashboardRepository) SearchTasks(userID uuid.UUID, query string) ([]mod
 		var assignedProjectMemberIDNullable uuid.NullUUID // Use uuid.NullUUID for nullable UUID
 		var assignedUserIDNullable uuid.NullUUID
 		var assignedUserNameNullable sql.NullString // Use sql.NullString for nullable string
+		var labels []byte
 
 		// Note: The TaskResponseDTO has other fields like CreatedBy, UpdatedBy, Description, CreatedAt, UpdatedAt.
 		// These are not selected in the current SQL query and will remain as zero values.
@@ -234,6 +266,7 @@ func (r *dashboardRepository) SearchTasks(userID uuid.UUID, query string) ([]mod
 			&assignedProjectMemberIDNullable,
 			&assignedUserIDNullable,
 			&assignedUserNameNullable,
+			&labels,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan task row: %w", err)
@@ -259,6 +292,10 @@ func (r *dashboardRepository) SearchTasks(userID uuid.UUID, query string) ([]mod
 			task.AssignedToName = "" // No assignee, so name is empty or appropriately handled
 		}
 
+		if err := json.Unmarshal(labels, &task.Labels); err != nil {
+			return nil, fmt.Errorf("failed to read task labels: %w", err)
+		}
+
 		tasks = append(tasks, task)
 	}
 	if err = rows.Err(); err != nil {
@@ -268,8 +305,8 @@ func (r *dashboardRepository) SearchTasks(userID uuid.UUID, query string) ([]mod
 	return tasks, nil
 }
 
-func (r *dashboardRepository) fetchTasks(query string, userID uuid.UUID, limit int) ([]models.TaskResponseDTO, error) {
-	rows, err := r.db.Query(query, userID, limit)
+func (r *dashboardRepository) fetchTasks(ctx context.Context, query string, userID uuid.UUID, limit int) ([]models.TaskResponseDTO, error) {
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+)
+
+const uniqueViolation = "23505"
+
+// notFound turns sql.ErrNoRows into a typed not found error and passes anything else through
+func notFound(err error, message string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperrors.NotFound(message).Wrap(err)
+	}
+	return err
+}
+
+// conflict turns unique constraint violations into a typed conflict error and passes anything else through
+func conflict(err error, message string) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return apperrors.Conflict(message).Wrap(err)
+	}
+	return err
+}
@@ -1,18 +1,19 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
-	"log"
 
 	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/logging"
 	"github.com/sarvochcha01/enlace-backend/internal/models"
 )
 
 type InvitationRepository interface {
-	CreateInvitation(createInvitationDTO *models.CreateInvitationDTO) error
-	GetInvitations(userID uuid.UUID) ([]models.InvitationResponseDTO, error)
-	HasInvitation(userID uuid.UUID, projectID uuid.UUID) bool
-	EditInvitation(editInvitationDTO models.EditInvitationDTO) error
+	CreateInvitation(ctx context.Context, createInvitationDTO *models.CreateInvitationDTO) error
+	GetInvitations(ctx context.Context, userID uuid.UUID) ([]models.InvitationResponseDTO, error)
+	HasInvitation(ctx context.Context, userID uuid.UUID, projectID uuid.UUID) bool
+	EditInvitation(ctx context.Context, editInvitationDTO models.EditInvitationDTO) error
 }
 
 type invitationRepository struct {
@@ -23,19 +24,19 @@ func NewInvitationRepository(db *sql.DB) InvitationRepository {
 	return &invitationRepository{db: db}
 }
 
-func (r *invitationRepository) CreateInvitation(createInvitationDTO *models.CreateInvitationDTO) error {
+func (r *invitationRepository) CreateInvitation(ctx context.Context, createInvitationDTO *models.CreateInvitationDTO) error {
 
 	queryString := `
 		INSERT INTO invitations (invited_by, invited_user_id, project_id)
 		VALUES ($1, $2, $3)
 	`
 
-	_, err := r.db.Exec(queryString, createInvitationDTO.InvitedBy, createInvitationDTO.InvitedUserID, createInvitationDTO.ProjectID)
+	_, err := r.db.ExecContext(ctx, queryString, createInvitationDTO.InvitedBy, createInvitationDTO.InvitedUserID, createInvitationDTO.ProjectID)
 
 	return err
 }
 
-func (r *invitationRepository) GetInvitations(userID uuid.UUID) ([]models.InvitationResponseDTO, error) {
+func (r *invitationRepository) GetInvitations(ctx context.Context, userID uuid.UUID) ([]models.InvitationResponseDTO, error) {
 	invitations := []models.InvitationResponseDTO{}
 	invitationsQuery := `
 		SELECT i.id, i.invited_by, i.invited_user_id, i.project_id, i.status, i.created_at, u.name, u.email, p.name
@@ -44,7 +45,7 @@ func (r *invitationRepository) GetInvitations(userID uuid.UUID) ([]models.Invita
 		LEFT JOIN projects p ON p.id = i.project_id
 		WHERE i.invited_user_id = $1
 	`
-	rows, err := r.db.Query(invitationsQuery, userID)
+	rows, err := r.db.QueryContext(ctx, invitationsQuery, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -64,7 +65,7 @@ func (r *invitationRepository) GetInvitations(userID uuid.UUID) ([]models.Invita
 	return invitations, nil
 }
 
-func (r *invitationRepository) HasInvitation(userID uuid.UUID, projectID uuid.UUID) bool {
+func (r *invitationRepository) HasInvitation(ctx context.Context, userID uuid.UUID, projectID uuid.UUID) bool {
 	queryString := `
 		SELECT EXISTS (
 			SELECT 1 FROM invitations 
@@ -73,22 +74,22 @@ func (r *invitationRepository) HasInvitation(userID uuid.UUID, projectID uuid.UU
 		)
 	`
 	var exists bool
-	err := r.db.QueryRow(queryString, userID, projectID).Scan(&exists)
+	err := r.db.QueryRowContext(ctx, queryString, userID, projectID).Scan(&exists)
 	if err != nil {
-		log.Println("Error checking invitation:", err)
+		logging.FromContext(ctx).Error("failed to check invitation", "project_id", projectID, "error", err)
 		return false
 	}
 	return exists
 }
 
-func (r *invitationRepository) EditInvitation(editInvitationDTO models.EditInvitationDTO) error {
+func (r *invitationRepository) EditInvitation(ctx context.Context, editInvitationDTO models.EditInvitationDTO) error {
 	queryString := `
 		UPDATE invitations
 		SET status = $1
 		WHERE id = $2
 	`
 
-	_, err := r.db.Exec(queryString, editInvitationDTO.Status, editInvitationDTO.InvitationID)
+	_, err := r.db.ExecContext(ctx, queryString, editInvitationDTO.Status, editInvitationDTO.InvitationID)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+)
+
+type LabelRepository interface {
+	CreateLabel(ctx context.Context, labelDTO *models.CreateLabelDTO) (uuid.UUID, error)
+	GetLabel(ctx context.Context, labelID uuid.UUID) (*models.LabelResponseDTO, error)
+	// GetLabels returns the project's labels sorted by name
+	GetLabels(ctx context.Context, projectID uuid.UUID) ([]models.LabelResponseDTO, error)
+	UpdateLabel(ctx context.Context, labelID uuid.UUID, labelDTO *models.UpdateLabelDTO) error
+	DeleteLabel(ctx context.Context, labelID uuid.UUID) error
+
+	// AddTaskLabel attaches a label to a task, doing nothing if it is already attached
+	AddTaskLabel(ctx context.Context, taskID uuid.UUID, labelID uuid.UUID) error
+	RemoveTaskLabel(ctx context.Context, taskID uuid.UUID, labelID uuid.UUID) error
+}
+
+type labelRepository struct {
+	db *sql.DB
+}
+
+func NewLabelRepository(db *sql.DB) LabelRepository {
+	return &labelRepository{db: db}
+}
+
+const duplicateLabelMessage = "a label with this name already exists in the project"
+
+func (r *labelRepository) CreateLabel(ctx context.Context, labelDTO *models.CreateLabelDTO) (uuid.UUID, error) {
+	queryString := `
+		INSERT INTO labels (project_id, name, color)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`
+
+	var labelID uuid.UUID
+	err := r.db.QueryRowContext(ctx, queryString, labelDTO.ProjectID, labelDTO.Name, labelDTO.Color).Scan(&labelID)
+	if err != nil {
+		return uuid.Nil, conflict(err, duplicateLabelMessage)
+	}
+
+	return labelID, nil
+}
+
+func (r *labelRepository) GetLabel(ctx context.Context, labelID uuid.UUID) (*models.LabelResponseDTO, error) {
+	queryString := `
+		SELECT id, project_id, name, color
+		FROM labels
+		WHERE id = $1
+	`
+
+	var label models.LabelResponseDTO
+	err := r.db.QueryRowContext(ctx, queryString, labelID).Scan(&label.ID, &label.ProjectID, &label.Name, &label.Color)
+	if err != nil {
+		return nil, notFound(err, "label not found")
+	}
+
+	return &label, nil
+}
+
+func (r *labelRepository) GetLabels(ctx context.Context, projectID uuid.UUID) ([]models.LabelResponseDTO, error) {
+	queryString := `
+		SELECT id, project_id, name, color
+		FROM labels
+		WHERE project_id = $1
+		ORDER BY LOWER(name)
+	`
+
+	rows, err := r.db.QueryContext(ctx, queryString, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	labels := []models.LabelResponseDTO{}
+	for rows.Next() {
+		var label models.LabelResponseDTO
+		if err := rows.Scan(&label.ID, &label.ProjectID, &label.Name, &label.Color); err != nil {
+			return nil, err
+		}
+		labels = append(labels, label)
+	}
+
+	return labels, rows.Err()
+}
+
+func (r *labelRepository) UpdateLabel(ctx context.Context, labelID uuid.UUID, labelDTO *models.UpdateLabelDTO) error {
+	queryString := `
+		UPDATE labels
+		SET name = $1, color = $2
+		WHERE id = $3
+	`
+
+	result, err := r.db.ExecContext(ctx, queryString, labelDTO.Name, labelDTO.Color, labelID)
+	if err != nil {
+		return conflict(err, duplicateLabelMessage)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return apperrors.NotFound("label not found")
+	}
+
+	return nil
+}
+
+func (r *labelRepository) DeleteLabel(ctx context.Context, labelID uuid.UUID) error {
+	queryString := `
+		DELETE FROM labels
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, queryString, labelID)
+	if err != nil {
+		return fmt.Errorf("failed to delete label: %w", err)
+	}
+
+	return nil
+}
+
+func (r *labelRepository) AddTaskLabel(ctx context.Context, taskID uuid.UUID, labelID uuid.UUID) error {
+	queryString := `
+		INSERT INTO task_labels (task_id, label_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+
+	_, err := r.db.ExecContext(ctx, queryString, taskID, labelID)
+	return err
+}
+
+func (r *labelRepository) RemoveTaskLabel(ctx context.Context, taskID uuid.UUID, labelID uuid.UUID) error {
+	queryString := `
+		DELETE FROM task_labels
+		WHERE task_id = $1 AND label_id = $2
+	`
+
+	_, err := r.db.ExecContext(ctx, queryString, taskID, labelID)
+	return err
+}
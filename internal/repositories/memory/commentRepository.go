@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/repositories"
+)
+
+type commentRepository struct {
+	store *Store
+}
+
+func NewCommentRepository(store *Store) repositories.CommentRepository {
+	return &commentRepository{store: store}
+}
+
+func (r *commentRepository) CreateComment(ctx context.Context, commentDTO *models.CreateCommentDTO) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := r.store.now()
+	id := uuid.New()
+	r.store.data.comments[id] = comment{
+		ID:        id,
+		ProjectID: commentDTO.ProjectID,
+		TaskID:    commentDTO.TaskID,
+		CreatedBy: commentDTO.CreatedBy,
+		Comment:   commentDTO.Comment,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	return nil
+}
+
+func (r *commentRepository) GetComment(ctx context.Context, commentID uuid.UUID) (*models.CommentResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	c, ok := r.store.data.comments[commentID]
+	if !ok {
+		return nil, notFound("comment not found")
+	}
+
+	dto := commentDTO(c)
+	return &dto, nil
+}
+
+func (r *commentRepository) UpdateComment(ctx context.Context, commentID uuid.UUID, newComment string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if c, ok := r.store.data.comments[commentID]; ok {
+		c.Comment = newComment
+		c.UpdatedAt = r.store.now()
+		r.store.data.comments[commentID] = c
+	}
+	return nil
+}
+
+func (r *commentRepository) DeleteComment(ctx context.Context, commentID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.data.comments, commentID)
+	return nil
+}
+
+func (r *commentRepository) GetCommentCreator(ctx context.Context, commentID uuid.UUID) (uuid.UUID, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	c, ok := r.store.data.comments[commentID]
+	if !ok {
+		return uuid.Nil, notFound("comment not found")
+	}
+	return c.CreatedBy, nil
+}
+
+func (r *commentRepository) GetAllCommentsForTask(ctx context.Context, taskID uuid.UUID) ([]models.CommentResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var matching []comment
+	for _, c := range r.store.data.comments {
+		if c.TaskID == taskID {
+			matching = append(matching, c)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		return matching[i].CreatedAt.Before(matching[j].CreatedAt)
+	})
+
+	comments := []models.CommentResponseDTO{}
+	for _, c := range matching {
+		comments = append(comments, commentDTO(c))
+	}
+
+	return comments, nil
+}
+
+func commentDTO(c comment) models.CommentResponseDTO {
+	return models.CommentResponseDTO{
+		ID:        c.ID,
+		ProjectID: c.ProjectID,
+		TaskID:    c.TaskID,
+		CreatedBy: c.CreatedBy,
+		Comment:   c.Comment,
+		CreatedAt: formatTime(c.CreatedAt),
+		UpdatedAt: formatTime(c.UpdatedAt),
+	}
+}
@@ -0,0 +1,232 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/repositories"
+)
+
+type dashboardRepository struct {
+	store *Store
+}
+
+func NewDashboardRepository(store *Store) repositories.DashboardRepository {
+	return &dashboardRepository{store: store}
+}
+
+func (r *dashboardRepository) GetRecentlyAssignedTasks(ctx context.Context, userID uuid.UUID, limit int) ([]models.TaskResponseDTO, error) {
+	tasks := r.assignedTasks(userID, func(t task) bool { return true })
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].UpdatedAt.After(tasks[j].UpdatedAt)
+	})
+	return r.summaries(tasks, limit), nil
+}
+
+func (r *dashboardRepository) GetInProgressTasks(ctx context.Context, userID uuid.UUID, limit int) ([]models.TaskResponseDTO, error) {
+	tasks := r.assignedTasks(userID, func(t task) bool {
+		return r.store.data.taskStatuses[taskStatusKey{t.ProjectID, t.Status}].Category == models.StatusCategoryActive
+	})
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].UpdatedAt.After(tasks[j].UpdatedAt)
+	})
+	return r.summaries(tasks, limit), nil
+}
+
+func (r *dashboardRepository) GetApproachingDeadlineTasks(ctx context.Context, userID uuid.UUID, limit int) ([]models.TaskResponseDTO, error) {
+	now := time.Now()
+	deadline := now.Add(3 * 24 * time.Hour)
+
+	tasks := r.assignedTasks(userID, func(t task) bool {
+		return t.DueDate != nil && !t.DueDate.Before(now) && !t.DueDate.After(deadline)
+	})
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].DueDate.Before(*tasks[j].DueDate)
+	})
+	return r.summaries(tasks, limit), nil
+}
+
+func (r *dashboardRepository) SearchProjects(ctx context.Context, userID uuid.UUID, query string) ([]models.ProjectSearchResult, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	query = strings.ToLower(query)
+
+	var matching []project
+	for _, member := range r.store.data.projectMembers {
+		if member.UserID != userID {
+			continue
+		}
+		p := r.store.data.projects[member.ProjectID]
+		if containsAny(query, p.Name, p.Description, p.Key) {
+			matching = append(matching, p)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		return matching[i].UpdatedAt.After(matching[j].UpdatedAt)
+	})
+
+	var projects []models.ProjectSearchResult
+	for _, p := range matching {
+		description := p.Description
+		result := models.ProjectSearchResult{
+			ID:          p.ID,
+			Name:        p.Name,
+			Description: &description,
+			Key:         p.Key,
+		}
+		for _, t := range r.store.data.tasks {
+			if t.ProjectID != p.ID {
+				continue
+			}
+			result.TotalTasks++
+			if r.store.isDone(t) {
+				result.CompletedTasks++
+			} else if r.isAssignedTo(t, userID) {
+				result.ActiveTasksAssignedToUser++
+			}
+		}
+		projects = append(projects, result)
+	}
+
+	return projects, nil
+}
+
+func (r *dashboardRepository) SearchTasks(ctx context.Context, userID uuid.UUID, query string, labels []string) ([]models.TaskResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	projectKey, taskNumber, isKey := models.ParseTaskKey(strings.TrimSpace(query))
+	isKeyOf := func(t task) bool {
+		return isKey && t.TaskNumber == taskNumber && r.store.data.projects[t.ProjectID].Key == projectKey
+	}
+
+	query = strings.ToLower(query)
+
+	var matching []task
+	for _, t := range r.store.data.tasks {
+		member, ok := r.store.memberOf(userID, t.ProjectID)
+		if !ok || member.Status != models.StatusActive {
+			continue
+		}
+		p := r.store.data.projects[t.ProjectID]
+		if (containsAny(query, t.Title, p.Name, p.Key) || isKeyOf(t)) && r.hasAnyLabel(t, labels) {
+			matching = append(matching, t)
+		}
+	}
+
+	// The task the query is the key of first, then tasks assigned to the user, then by due date
+	// with undated tasks last
+	sort.Slice(matching, func(i, j int) bool {
+		a, b := matching[i], matching[j]
+		if keyA, keyB := isKeyOf(a), isKeyOf(b); keyA != keyB {
+			return keyA
+		}
+		if assignedA, assignedB := r.isAssignedTo(a, userID), r.isAssignedTo(b, userID); assignedA != assignedB {
+			return assignedA
+		}
+		if (a.DueDate == nil) != (b.DueDate == nil) {
+			return a.DueDate != nil
+		}
+		if a.DueDate != nil && !a.DueDate.Equal(*b.DueDate) {
+			return a.DueDate.Before(*b.DueDate)
+		}
+		return a.UpdatedAt.After(b.UpdatedAt)
+	})
+
+	var tasks []models.TaskResponseDTO
+	for _, t := range matching {
+		dto := r.summary(t)
+		dto.Labels = r.store.taskLabelDTOs(t.ID)
+		if t.AssignedTo != nil {
+			member := r.store.data.projectMembers[*t.AssignedTo]
+			name := r.store.data.users[member.UserID].Name
+			dto.AssignedTo = &models.ProjectMemberResponseDTO{ID: member.ID, UserID: member.UserID, Name: name}
+			dto.AssignedToName = name
+		}
+		tasks = append(tasks, dto)
+	}
+
+	return tasks, nil
+}
+
+func (r *dashboardRepository) assignedTasks(userID uuid.UUID, include func(task) bool) []task {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var tasks []task
+	for _, t := range r.store.data.tasks {
+		if r.isAssignedTo(t, userID) && include(t) {
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks
+}
+
+func (r *dashboardRepository) summaries(tasks []task, limit int) []models.TaskResponseDTO {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+
+	var summaries []models.TaskResponseDTO
+	for _, t := range tasks {
+		summaries = append(summaries, r.summary(t))
+	}
+	return summaries
+}
+
+// summary fills the same columns as the dashboard queries. Must be called with r.store.mu held.
+func (r *dashboardRepository) summary(t task) models.TaskResponseDTO {
+	p := r.store.data.projects[t.ProjectID]
+	return models.TaskResponseDTO{
+		ID:          t.ID,
+		Title:       t.Title,
+		TaskNumber:  t.TaskNumber,
+		ProjectID:   p.ID,
+		ProjectKey:  p.Key,
+		ProjectName: p.Name,
+		Priority:    t.Priority,
+		DueDate:     t.DueDate,
+		Status:      t.Status,
+	}
+}
+
+// hasAnyLabel matches label names case insensitively, any task matches when names is empty.
+// Must be called with r.store.mu held.
+func (r *dashboardRepository) hasAnyLabel(t task, names []string) bool {
+	if len(names) == 0 {
+		return true
+	}
+	for attached := range r.store.data.taskLabels {
+		if attached.TaskID != t.ID {
+			continue
+		}
+		name := r.store.data.labels[attached.LabelID].Name
+		if slices.ContainsFunc(names, func(n string) bool { return strings.EqualFold(n, name) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAssignedTo must be called with r.store.mu held
+func (r *dashboardRepository) isAssignedTo(t task, userID uuid.UUID) bool {
+	return t.AssignedTo != nil && r.store.data.projectMembers[*t.AssignedTo].UserID == userID
+}
+
+func containsAny(query string, fields ...string) bool {
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/repositories"
+)
+
+type invitationRepository struct {
+	store *Store
+}
+
+func NewInvitationRepository(store *Store) repositories.InvitationRepository {
+	return &invitationRepository{store: store}
+}
+
+func (r *invitationRepository) CreateInvitation(ctx context.Context, createInvitationDTO *models.CreateInvitationDTO) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	id := uuid.New()
+	r.store.data.invitations[id] = invitation{
+		ID:            id,
+		InvitedBy:     createInvitationDTO.InvitedBy,
+		InvitedUserID: createInvitationDTO.InvitedUserID,
+		ProjectID:     createInvitationDTO.ProjectID,
+		Status:        models.InivtationStatusPending,
+		CreatedAt:     r.store.now(),
+	}
+
+	return nil
+}
+
+func (r *invitationRepository) GetInvitations(ctx context.Context, userID uuid.UUID) ([]models.InvitationResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	invitations := []models.InvitationResponseDTO{}
+	for _, inv := range r.store.data.invitations {
+		if inv.InvitedUserID != userID {
+			continue
+		}
+		inviter := r.store.data.users[inv.InvitedBy]
+		invitations = append(invitations, models.InvitationResponseDTO{
+			ID:            inv.ID,
+			InvitedBy:     inv.InvitedBy,
+			InvitedUserID: inv.InvitedUserID,
+			ProjectID:     inv.ProjectID,
+			ProjectName:   r.store.data.projects[inv.ProjectID].Name,
+			Status:        inv.Status,
+			InvitedAt:     inv.CreatedAt,
+			Name:          inviter.Name,
+			Email:         inviter.Email,
+		})
+	}
+
+	sort.Slice(invitations, func(i, j int) bool {
+		return invitations[i].InvitedAt.Before(invitations[j].InvitedAt)
+	})
+
+	return invitations, nil
+}
+
+func (r *invitationRepository) HasInvitation(ctx context.Context, userID uuid.UUID, projectID uuid.UUID) bool {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, inv := range r.store.data.invitations {
+		if inv.InvitedUserID == userID && inv.ProjectID == projectID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *invitationRepository) EditInvitation(ctx context.Context, editInvitationDTO models.EditInvitationDTO) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if inv, ok := r.store.data.invitations[editInvitationDTO.InvitationID]; ok {
+		inv.Status = models.InivtationStatus(editInvitationDTO.Status)
+		r.store.data.invitations[inv.ID] = inv
+	}
+	return nil
+}
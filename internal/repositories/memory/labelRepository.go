@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/repositories"
+)
+
+type labelRepository struct {
+	store *Store
+}
+
+func NewLabelRepository(store *Store) repositories.LabelRepository {
+	return &labelRepository{store: store}
+}
+
+// checkLabelName enforces the unique index on (project_id, LOWER(name)). Must be called with r.store.mu held.
+func (r *labelRepository) checkLabelName(projectID uuid.UUID, labelID uuid.UUID, name string) error {
+	for _, l := range r.store.data.labels {
+		if l.ProjectID == projectID && l.ID != labelID && strings.EqualFold(l.Name, name) {
+			return apperrors.Conflict("a label with this name already exists in the project")
+		}
+	}
+	return nil
+}
+
+func (r *labelRepository) CreateLabel(ctx context.Context, labelDTO *models.CreateLabelDTO) (uuid.UUID, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.data.projects[labelDTO.ProjectID]; !ok {
+		return uuid.Nil, fmt.Errorf("project %s does not exist", labelDTO.ProjectID)
+	}
+	if err := r.checkLabelName(labelDTO.ProjectID, uuid.Nil, labelDTO.Name); err != nil {
+		return uuid.Nil, err
+	}
+
+	now := r.store.now()
+	id := uuid.New()
+	r.store.data.labels[id] = label{
+		ID:        id,
+		ProjectID: labelDTO.ProjectID,
+		Name:      labelDTO.Name,
+		Color:     labelDTO.Color,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	return id, nil
+}
+
+func (r *labelRepository) GetLabel(ctx context.Context, labelID uuid.UUID) (*models.LabelResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	l, ok := r.store.data.labels[labelID]
+	if !ok {
+		return nil, notFound("label not found")
+	}
+
+	dto := labelDTO(l)
+	return &dto, nil
+}
+
+func (r *labelRepository) GetLabels(ctx context.Context, projectID uuid.UUID) ([]models.LabelResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	labels := []models.LabelResponseDTO{}
+	for _, l := range r.store.data.labels {
+		if l.ProjectID == projectID {
+			labels = append(labels, labelDTO(l))
+		}
+	}
+	slices.SortFunc(labels, compareLabels)
+
+	return labels, nil
+}
+
+func (r *labelRepository) UpdateLabel(ctx context.Context, labelID uuid.UUID, labelDTO *models.UpdateLabelDTO) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	l, ok := r.store.data.labels[labelID]
+	if !ok {
+		return apperrors.NotFound("label not found")
+	}
+	if err := r.checkLabelName(l.ProjectID, labelID, labelDTO.Name); err != nil {
+		return err
+	}
+
+	l.Name = labelDTO.Name
+	l.Color = labelDTO.Color
+	l.UpdatedAt = r.store.now()
+	r.store.data.labels[labelID] = l
+
+	return nil
+}
+
+func (r *labelRepository) DeleteLabel(ctx context.Context, labelID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.data.labels, labelID)
+	for attached := range r.store.data.taskLabels {
+		if attached.LabelID == labelID {
+			delete(r.store.data.taskLabels, attached)
+		}
+	}
+
+	return nil
+}
+
+func (r *labelRepository) AddTaskLabel(ctx context.Context, taskID uuid.UUID, labelID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.data.tasks[taskID]; !ok {
+		return fmt.Errorf("task %s does not exist", taskID)
+	}
+	if _, ok := r.store.data.labels[labelID]; !ok {
+		return fmt.Errorf("label %s does not exist", labelID)
+	}
+
+	attached := taskLabel{TaskID: taskID, LabelID: labelID}
+	if _, ok := r.store.data.taskLabels[attached]; !ok {
+		r.store.data.taskLabels[attached] = r.store.now()
+	}
+
+	return nil
+}
+
+func (r *labelRepository) RemoveTaskLabel(ctx context.Context, taskID uuid.UUID, labelID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.data.taskLabels, taskLabel{TaskID: taskID, LabelID: labelID})
+	return nil
+}
@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/repositories"
+)
+
+type notificationRepository struct {
+	store *Store
+}
+
+func NewNotificationRepository(store *Store) repositories.NotificationRepository {
+	return &notificationRepository{store: store}
+}
+
+func (r *notificationRepository) CreateNotification(ctx context.Context, createNotificationDTO models.CreateNotificationDTO) (*models.NotificationResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	n := notification{
+		ID:        uuid.New(),
+		UserID:    createNotificationDTO.UserID,
+		Type:      createNotificationDTO.Type,
+		Content:   createNotificationDTO.Content,
+		ProjectID: createNotificationDTO.ProjectID,
+		TaskID:    createNotificationDTO.TaskID,
+		Status:    models.NotificationStatusUnread,
+		CreatedAt: r.store.now(),
+	}
+	r.store.data.notifications[n.ID] = n
+
+	dto := notificationDTO(n)
+	return &dto, nil
+}
+
+func (r *notificationRepository) GetAllNotificationsForUser(ctx context.Context, userID uuid.UUID) ([]models.NotificationResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	notifications := []models.NotificationResponseDTO{}
+	for _, n := range r.store.data.notifications {
+		if n.UserID != userID {
+			continue
+		}
+
+		dto := notificationDTO(n)
+		if n.Type == models.NotificationTypeProjectInvitation {
+			for _, inv := range r.store.data.invitations {
+				if inv.ProjectID == n.ProjectID && inv.InvitedUserID == n.UserID {
+					dto.InvitationID = inv.ID
+				}
+			}
+		}
+		notifications = append(notifications, dto)
+	}
+
+	sort.Slice(notifications, func(i, j int) bool {
+		return notifications[i].CreatedAt.After(notifications[j].CreatedAt)
+	})
+
+	return notifications, nil
+}
+
+func (r *notificationRepository) GetNotification(ctx context.Context, notificationID uuid.UUID) (*models.NotificationResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	n, ok := r.store.data.notifications[notificationID]
+	if !ok {
+		return nil, notFound("notification not found")
+	}
+
+	dto := notificationDTO(n)
+	return &dto, nil
+}
+
+func (r *notificationRepository) MarkNotificationAsRead(ctx context.Context, notificationID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if n, ok := r.store.data.notifications[notificationID]; ok {
+		n.Status = models.NotificationStatusRead
+		r.store.data.notifications[notificationID] = n
+	}
+	return nil
+}
+
+func notificationDTO(n notification) models.NotificationResponseDTO {
+	dto := models.NotificationResponseDTO{
+		ID:        n.ID,
+		UserID:    n.UserID,
+		Type:      n.Type,
+		Content:   n.Content,
+		ProjectID: n.ProjectID,
+		Status:    n.Status,
+		CreatedAt: n.CreatedAt,
+	}
+	if n.TaskID != nil {
+		dto.TaskID = *n.TaskID
+	}
+	return dto
+}
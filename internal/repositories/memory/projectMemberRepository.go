@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/repositories"
+)
+
+type projectMemberRepository struct {
+	store *Store
+}
+
+func NewProjectMemberRepository(store *Store) repositories.ProjectMemberRepository {
+	return &projectMemberRepository{store: store}
+}
+
+func (r *projectMemberRepository) CreateProjectMember(ctx context.Context, projectMember *models.CreateProjectMemberDTO) error {
+	_, err := r.create(projectMember)
+	return err
+}
+
+func (r *projectMemberRepository) CreateProjectMemberTx(ctx context.Context, tx repositories.Tx, projectMember *models.CreateProjectMemberDTO) (uuid.UUID, error) {
+	return r.create(projectMember)
+}
+
+func (r *projectMemberRepository) create(memberDTO *models.CreateProjectMemberDTO) (uuid.UUID, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.memberOf(memberDTO.UserID, memberDTO.ProjectID); ok {
+		return uuid.Nil, apperrors.Conflict("already a project member")
+	}
+
+	role := memberDTO.Role
+	if role == "" {
+		role = models.RoleViewer
+	}
+
+	id := uuid.New()
+	r.store.data.projectMembers[id] = projectMember{
+		ID:        id,
+		UserID:    memberDTO.UserID,
+		ProjectID: memberDTO.ProjectID,
+		Role:      role,
+		Status:    models.StatusActive,
+		JoinedAt:  r.store.now(),
+	}
+
+	return id, nil
+}
+
+func (r *projectMemberRepository) GetProjectMemberByUserID(ctx context.Context, userID uuid.UUID, projectID uuid.UUID) (*models.ProjectMemberResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	member, ok := r.store.memberOf(userID, projectID)
+	if !ok {
+		return nil, notFound("project member not found")
+	}
+
+	dto := r.store.memberDTO(member)
+	return &dto, nil
+}
+
+func (r *projectMemberRepository) GetActiveUserIDs(ctx context.Context, projectID uuid.UUID) ([]uuid.UUID, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var userIDs []uuid.UUID
+	for _, member := range r.store.data.projectMembers {
+		if member.ProjectID == projectID && member.Status == models.StatusActive {
+			userIDs = append(userIDs, member.UserID)
+		}
+	}
+
+	return userIDs, nil
+}
+
+func (r *projectMemberRepository) GetProjectMember(ctx context.Context, projectMemberID uuid.UUID) (*models.ProjectMemberResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	member, ok := r.store.data.projectMembers[projectMemberID]
+	if !ok {
+		return nil, notFound("project member not found")
+	}
+
+	dto := r.store.memberDTO(member)
+	return &dto, nil
+}
+
+func (r *projectMemberRepository) GetUserID(ctx context.Context, projectMemberID uuid.UUID) (uuid.UUID, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	member, ok := r.store.data.projectMembers[projectMemberID]
+	if !ok {
+		return uuid.Nil, notFound("project member not found")
+	}
+	return member.UserID, nil
+}
+
+func (r *projectMemberRepository) GetProjectMemberID(ctx context.Context, userID uuid.UUID, projectID uuid.UUID) (uuid.UUID, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	member, ok := r.store.memberOf(userID, projectID)
+	if !ok {
+		return uuid.Nil, notFound("project member not found")
+	}
+	return member.ID, nil
+}
+
+func (r *projectMemberRepository) UpdateProjectMemberStatus(ctx context.Context, projectMemberID uuid.UUID, newStatus models.ProjectMemberStatus) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if member, ok := r.store.data.projectMembers[projectMemberID]; ok {
+		member.Status = newStatus
+		r.store.data.projectMembers[projectMemberID] = member
+	}
+	return nil
+}
+
+func (r *projectMemberRepository) UpdateProjectMemberRole(ctx context.Context, projectMemberID uuid.UUID, newRole models.ProjectMemberRole) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if member, ok := r.store.data.projectMembers[projectMemberID]; ok {
+		member.Role = newRole
+		r.store.data.projectMembers[projectMemberID] = member
+	}
+	return nil
+}
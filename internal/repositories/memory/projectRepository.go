@@ -0,0 +1,252 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/repositories"
+)
+
+type projectRepository struct {
+	store *Store
+}
+
+func NewProjectRepository(store *Store) repositories.ProjectRepository {
+	return &projectRepository{store: store}
+}
+
+func (r *projectRepository) BeginTransaction(ctx context.Context) (repositories.Tx, error) {
+	return r.store.begin(), nil
+}
+
+func (r *projectRepository) CreateProject(ctx context.Context, tx repositories.Tx, projectDTO *models.CreateProjectDTO) (uuid.UUID, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, p := range r.store.data.projects {
+		if p.Key == projectDTO.Key {
+			return uuid.Nil, apperrors.Conflict("a project with this key already exists")
+		}
+	}
+
+	now := r.store.now()
+	id := uuid.New()
+	r.store.data.projects[id] = project{
+		ID:          id,
+		Name:        projectDTO.Name,
+		Description: projectDTO.Description,
+		Key:         projectDTO.Key,
+		CreatedBy:   projectDTO.CreatedBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	r.store.insertWorkflow(id, models.DefaultWorkflow())
+
+	return id, nil
+}
+
+func (r *projectRepository) GetProjectByID(ctx context.Context, projectID uuid.UUID) (*models.ProjectResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	p, ok := r.store.data.projects[projectID]
+	if !ok {
+		return nil, notFound("project not found")
+	}
+
+	projectDTO := r.projectDTO(p)
+
+	projectDTO.ProjectMembers = []models.ProjectMemberResponseDTO{}
+	for _, member := range r.store.data.projectMembers {
+		if member.ProjectID == projectID && member.Status == models.StatusActive {
+			projectDTO.ProjectMembers = append(projectDTO.ProjectMembers, r.store.memberDTO(member))
+		}
+	}
+	sort.Slice(projectDTO.ProjectMembers, func(i, j int) bool {
+		return projectDTO.ProjectMembers[i].JoinedAt < projectDTO.ProjectMembers[j].JoinedAt
+	})
+
+	projectDTO.Invitations = []models.InvitationResponseDTO{}
+	for _, inv := range r.store.data.invitations {
+		if inv.ProjectID != projectID {
+			continue
+		}
+		invited := r.store.data.users[inv.InvitedUserID]
+		projectDTO.Invitations = append(projectDTO.Invitations, models.InvitationResponseDTO{
+			ID:            inv.ID,
+			InvitedBy:     inv.InvitedBy,
+			InvitedUserID: inv.InvitedUserID,
+			ProjectID:     inv.ProjectID,
+			Status:        inv.Status,
+			InvitedAt:     inv.CreatedAt,
+			Name:          invited.Name,
+			Email:         invited.Email,
+		})
+	}
+	sort.Slice(projectDTO.Invitations, func(i, j int) bool {
+		return projectDTO.Invitations[i].InvitedAt.Before(projectDTO.Invitations[j].InvitedAt)
+	})
+
+	projectDTO.Tasks = []models.TaskResponseDTO{}
+	for _, t := range r.store.data.tasks {
+		if t.ProjectID == projectID {
+			projectDTO.Tasks = append(projectDTO.Tasks, r.store.taskDTO(t))
+		}
+	}
+	sort.Slice(projectDTO.Tasks, func(i, j int) bool {
+		a, b := projectDTO.Tasks[i], projectDTO.Tasks[j]
+		if a.StatusPosition != b.StatusPosition {
+			return a.StatusPosition < b.StatusPosition
+		}
+		if a.Rank != b.Rank {
+			return a.Rank < b.Rank
+		}
+		return a.TaskNumber < b.TaskNumber
+	})
+	projectDTO.CountTasks()
+
+	return &projectDTO, nil
+}
+
+func (r *projectRepository) GetAllProjectsForUser(ctx context.Context, userID uuid.UUID) ([]models.ProjectResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var projects []models.ProjectResponseDTO
+	for _, member := range r.store.data.projectMembers {
+		if member.UserID != userID || member.Status != models.StatusActive {
+			continue
+		}
+
+		projectDTO := r.projectDTO(r.store.data.projects[member.ProjectID])
+		for _, t := range r.store.data.tasks {
+			if t.ProjectID != member.ProjectID {
+				continue
+			}
+			projectDTO.TotalTasks++
+			if t.ParentID != nil {
+				projectDTO.TotalSubtasks++
+			}
+			if r.store.isDone(t) {
+				projectDTO.TasksCompleted++
+				if t.ParentID != nil {
+					projectDTO.SubtasksCompleted++
+				}
+			} else if t.AssignedTo != nil && r.store.data.projectMembers[*t.AssignedTo].UserID == userID {
+				projectDTO.ActiveTasksAssignedToUserCount++
+			}
+		}
+		projectDTO.CompletionPercentage = models.CompletionPercentage(projectDTO.TasksCompleted, projectDTO.TotalTasks)
+		projects = append(projects, projectDTO)
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].CreatedAt < projects[j].CreatedAt
+	})
+
+	return projects, nil
+}
+
+func (r *projectRepository) DeleteProject(ctx context.Context, projectID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.data.projects, projectID)
+	for id, t := range r.store.data.tasks {
+		if t.ProjectID == projectID {
+			r.store.deleteTaskRows(id)
+		}
+	}
+	for id, member := range r.store.data.projectMembers {
+		if member.ProjectID == projectID {
+			delete(r.store.data.projectMembers, id)
+		}
+	}
+	r.store.deleteWorkflow(projectID)
+	for id, l := range r.store.data.labels {
+		if l.ProjectID == projectID {
+			delete(r.store.data.labels, id)
+		}
+	}
+	for id, series := range r.store.data.recurrences {
+		if series.ProjectID == projectID {
+			delete(r.store.data.recurrences, id)
+		}
+	}
+	for id, sp := range r.store.data.sprints {
+		if sp.ProjectID == projectID {
+			delete(r.store.data.sprints, id)
+		}
+	}
+	for id, inv := range r.store.data.invitations {
+		if inv.ProjectID == projectID {
+			delete(r.store.data.invitations, id)
+		}
+	}
+	for id, n := range r.store.data.notifications {
+		if n.ProjectID == projectID {
+			delete(r.store.data.notifications, id)
+		}
+	}
+
+	return nil
+}
+
+func (r *projectRepository) GetProjectName(ctx context.Context, projectID uuid.UUID) (string, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	p, ok := r.store.data.projects[projectID]
+	if !ok {
+		return "", notFound("project not found")
+	}
+	return p.Name, nil
+}
+
+func (r *projectRepository) GetProjectCreatorID(ctx context.Context, projectID uuid.UUID) (uuid.UUID, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	p, ok := r.store.data.projects[projectID]
+	if !ok {
+		return uuid.Nil, notFound("project not found")
+	}
+	return p.CreatedBy, nil
+}
+
+func (r *projectRepository) EditProject(ctx context.Context, projectID uuid.UUID, projectDTO *models.EditProjectDTO) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	p, ok := r.store.data.projects[projectID]
+	if !ok {
+		return apperrors.NotFound("project not found")
+	}
+	if !projectDTO.IfMatch.Matches(models.ETag(p.UpdatedAt)) {
+		return apperrors.PreconditionFailed("the project has changed since it was read")
+	}
+
+	p.Name = projectDTO.Name
+	p.Description = projectDTO.Description
+	p.UpdatedAt = r.store.now()
+	r.store.data.projects[projectID] = p
+
+	return nil
+}
+
+// projectDTO must be called with r.store.mu held
+func (r *projectRepository) projectDTO(p project) models.ProjectResponseDTO {
+	creator := r.store.data.users[p.CreatedBy]
+	return models.ProjectResponseDTO{
+		ID:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Key:         p.Key,
+		CreatedBy:   models.UserResponseDTO{ID: creator.ID, Name: creator.Name, Email: creator.Email},
+		CreatedAt:   formatTime(p.CreatedAt),
+		UpdatedAt:   formatTime(p.UpdatedAt),
+	}
+}
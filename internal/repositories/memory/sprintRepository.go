@@ -0,0 +1,286 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/repositories"
+)
+
+type sprintRepository struct {
+	store *Store
+}
+
+func NewSprintRepository(store *Store) repositories.SprintRepository {
+	return &sprintRepository{store: store}
+}
+
+func (r *sprintRepository) CreateSprint(ctx context.Context, sprintDTO *models.CreateSprintDTO) (uuid.UUID, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.data.projects[sprintDTO.ProjectID]; !ok {
+		return uuid.Nil, notFound("project not found")
+	}
+
+	now := r.store.now()
+	id := uuid.New()
+	r.store.data.sprints[id] = sprint{
+		ID:        id,
+		ProjectID: sprintDTO.ProjectID,
+		Name:      sprintDTO.Name,
+		Goal:      sprintDTO.Goal,
+		StartDate: sprintDTO.StartDate,
+		EndDate:   sprintDTO.EndDate,
+		State:     models.SprintPlanned,
+		CreatedBy: &sprintDTO.CreatedBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	return id, nil
+}
+
+// sprintDTO must be called with s.mu held
+func (s *Store) sprintDTO(sp sprint) models.SprintResponseDTO {
+	dto := models.SprintResponseDTO{
+		ID:                sp.ID,
+		ProjectID:         sp.ProjectID,
+		Name:              sp.Name,
+		Goal:              sp.Goal,
+		StartDate:         sp.StartDate,
+		EndDate:           sp.EndDate,
+		State:             sp.State,
+		CommittedPoints:   sp.CommittedPoints,
+		CompletedPoints:   sp.CompletedPoints,
+		CarriedOverPoints: sp.CarriedOverPoints,
+		StartedAt:         sp.StartedAt,
+		ClosedAt:          sp.ClosedAt,
+		CreatedAt:         sp.CreatedAt,
+		UpdatedAt:         sp.UpdatedAt,
+	}
+
+	for _, t := range s.sprintTasks(sp.ID) {
+		dto.TaskCount++
+		dto.Points += storyPoints(t)
+		if s.isDone(t) {
+			dto.DonePoints += storyPoints(t)
+		}
+	}
+
+	return dto
+}
+
+// sprintTasks returns the tasks in the sprint by task number. Must be called with s.mu held.
+func (s *Store) sprintTasks(sprintID uuid.UUID) []task {
+	var tasks []task
+	for _, t := range s.data.tasks {
+		if t.SprintID != nil && *t.SprintID == sprintID {
+			tasks = append(tasks, t)
+		}
+	}
+	slices.SortFunc(tasks, func(a, b task) int {
+		return a.TaskNumber - b.TaskNumber
+	})
+	return tasks
+}
+
+func storyPoints(t task) int {
+	if t.StoryPoints == nil {
+		return 0
+	}
+	return *t.StoryPoints
+}
+
+func (r *sprintRepository) GetSprint(ctx context.Context, sprintID uuid.UUID) (*models.SprintResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	sp, ok := r.store.data.sprints[sprintID]
+	if !ok {
+		return nil, notFound("sprint not found")
+	}
+
+	dto := r.store.sprintDTO(sp)
+	return &dto, nil
+}
+
+func (r *sprintRepository) GetSprints(ctx context.Context, projectID uuid.UUID) ([]models.SprintResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	sprints := []models.SprintResponseDTO{}
+	for _, sp := range r.store.data.sprints {
+		if sp.ProjectID == projectID {
+			sprints = append(sprints, r.store.sprintDTO(sp))
+		}
+	}
+	slices.SortFunc(sprints, func(a, b models.SprintResponseDTO) int {
+		if c := strings.Compare(a.StartDate, b.StartDate); c != 0 {
+			return c
+		}
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return sprints, nil
+}
+
+func (r *sprintRepository) GetClosedSprints(ctx context.Context, projectID uuid.UUID) ([]models.SprintResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	sprints := []models.SprintResponseDTO{}
+	for _, sp := range r.store.data.sprints {
+		if sp.ProjectID == projectID && sp.State == models.SprintClosed {
+			sprints = append(sprints, r.store.sprintDTO(sp))
+		}
+	}
+	slices.SortFunc(sprints, func(a, b models.SprintResponseDTO) int {
+		return a.ClosedAt.Compare(*b.ClosedAt)
+	})
+
+	return sprints, nil
+}
+
+func (r *sprintRepository) UpdateSprint(ctx context.Context, sprintID uuid.UUID, sprintDTO *models.UpdateSprintDTO) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	sp, ok := r.store.data.sprints[sprintID]
+	if !ok {
+		return notFound("sprint not found")
+	}
+
+	sp.Name = sprintDTO.Name
+	sp.Goal = sprintDTO.Goal
+	sp.StartDate = sprintDTO.StartDate
+	sp.EndDate = sprintDTO.EndDate
+	sp.UpdatedAt = r.store.now()
+	r.store.data.sprints[sprintID] = sp
+
+	return nil
+}
+
+func (r *sprintRepository) DeleteSprint(ctx context.Context, sprintID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.data.sprints, sprintID)
+	for _, t := range r.store.sprintTasks(sprintID) {
+		t.SprintID = nil
+		r.store.data.tasks[t.ID] = t
+	}
+
+	return nil
+}
+
+func (r *sprintRepository) StartSprint(ctx context.Context, sprintID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	sp, ok := r.store.data.sprints[sprintID]
+	if !ok {
+		return notFound("sprint not found")
+	}
+	if sp.State != models.SprintPlanned {
+		return apperrors.Conflict("only a planned sprint can be started")
+	}
+
+	for _, other := range r.store.data.sprints {
+		if other.ProjectID == sp.ProjectID && other.State == models.SprintActive {
+			return apperrors.Conflict("the project already has an active sprint, close it first")
+		}
+	}
+
+	committed := 0
+	for _, t := range r.store.sprintTasks(sprintID) {
+		committed += storyPoints(t)
+	}
+
+	now := r.store.now()
+	sp.State = models.SprintActive
+	sp.StartedAt = &now
+	sp.CommittedPoints = &committed
+	sp.UpdatedAt = now
+	r.store.data.sprints[sprintID] = sp
+
+	return nil
+}
+
+func (r *sprintRepository) CloseSprint(ctx context.Context, sprintID uuid.UUID, carryOverTo *uuid.UUID, closedBy uuid.UUID) ([]models.TaskReferenceDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	sp, ok := r.store.data.sprints[sprintID]
+	if !ok {
+		return nil, notFound("sprint not found")
+	}
+	if sp.State != models.SprintActive {
+		return nil, apperrors.Conflict("only an active sprint can be closed")
+	}
+	if carryOverTo != nil {
+		target, ok := r.store.data.sprints[*carryOverTo]
+		if !ok || target.ProjectID != sp.ProjectID || target.State != models.SprintPlanned {
+			return nil, apperrors.Validation("invalid sprint close", map[string]string{"carryOverTo": "must be a planned sprint of this project"})
+		}
+	}
+
+	now := r.store.now()
+	oldValue := sprintID.String()
+	var newValue *string
+	if carryOverTo != nil {
+		sprint := carryOverTo.String()
+		newValue = &sprint
+	}
+
+	completed, carriedOverPoints := 0, 0
+	carriedOver := []models.TaskReferenceDTO{}
+	for _, t := range r.store.sprintTasks(sprintID) {
+		if r.store.isDone(t) {
+			completed += storyPoints(t)
+			continue
+		}
+
+		carriedOver = append(carriedOver, taskReference(t))
+		carriedOverPoints += storyPoints(t)
+
+		t.SprintID = carryOverTo
+		t.UpdatedBy = closedBy
+		t.UpdatedAt = now
+		r.store.data.tasks[t.ID] = t
+
+		id := uuid.New()
+		r.store.data.taskActivity[id] = taskActivity{
+			ID:              id,
+			TaskID:          t.ID,
+			ActorID:         closedBy,
+			TaskFieldChange: models.TaskFieldChange{Field: models.TaskFieldSprintID, OldValue: &oldValue, NewValue: newValue},
+			CreatedAt:       now,
+		}
+	}
+
+	sp.State = models.SprintClosed
+	sp.ClosedAt = &now
+	sp.CompletedPoints = &completed
+	sp.CarriedOverPoints = &carriedOverPoints
+	sp.UpdatedAt = now
+	r.store.data.sprints[sprintID] = sp
+
+	return carriedOver, nil
+}
+
+func (r *sprintRepository) GetSprintTaskPoints(ctx context.Context, sprintID uuid.UUID) ([]models.SprintTaskPoints, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var tasks []models.SprintTaskPoints
+	for _, t := range r.store.sprintTasks(sprintID) {
+		tasks = append(tasks, models.SprintTaskPoints{Points: storyPoints(t), CompletedAt: t.CompletedAt})
+	}
+
+	return tasks, nil
+}
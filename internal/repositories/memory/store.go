@@ -0,0 +1,566 @@
+// Package memory provides in-memory implementations of the repository interfaces. They keep the
+// behaviour the services depend on (generated IDs and task numbers, unique constraints, cascading
+// deletes, typed not found errors) without needing a database, which makes them suitable for tests.
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/repositories"
+)
+
+var errNoSQL = errors.New("in-memory transactions can't run SQL")
+
+type user struct {
+	ID          uuid.UUID
+	FirebaseUID string
+	Name        string
+	Email       string
+	CreatedAt   time.Time
+}
+
+type project struct {
+	ID          uuid.UUID
+	Name        string
+	Description string
+	Key         string
+	CreatedBy   uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type projectMember struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	ProjectID uuid.UUID
+	Role      models.ProjectMemberRole
+	Status    models.ProjectMemberStatus
+	JoinedAt  time.Time
+}
+
+type task struct {
+	ID                uuid.UUID
+	ProjectID         uuid.UUID
+	TaskNumber        int
+	Title             string
+	Description       *string
+	Status            models.TaskStatus
+	Priority          models.TaskPriority
+	DueDate           *time.Time
+	CreatedBy         uuid.UUID
+	UpdatedBy         uuid.UUID
+	AssignedTo        *uuid.UUID
+	ParentID          *uuid.UUID
+	Rank              int
+	RecurrenceID      *uuid.UUID
+	OriginalEstimate  *int
+	RemainingEstimate *int
+	StoryPoints       *int
+	SprintID          *uuid.UUID
+	CompletedAt       *time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+type taskRecurrence struct {
+	ID            uuid.UUID
+	ProjectID     uuid.UUID
+	Rule          string
+	Occurrences   int
+	CurrentTaskID *uuid.UUID
+	CreatedBy     *uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type invitation struct {
+	ID            uuid.UUID
+	InvitedBy     uuid.UUID
+	InvitedUserID uuid.UUID
+	ProjectID     uuid.UUID
+	Status        models.InivtationStatus
+	CreatedAt     time.Time
+}
+
+type notification struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Type      models.NotificationType
+	Content   string
+	ProjectID uuid.UUID
+	TaskID    *uuid.UUID
+	Status    models.NotificationStatus
+	CreatedAt time.Time
+}
+
+type comment struct {
+	ID        uuid.UUID
+	ProjectID uuid.UUID
+	TaskID    uuid.UUID
+	CreatedBy uuid.UUID
+	Comment   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type taskLink struct {
+	ID           uuid.UUID
+	SourceTaskID uuid.UUID
+	TargetTaskID uuid.UUID
+	Type         models.TaskLinkType
+	CreatedBy    uuid.UUID
+	CreatedAt    time.Time
+}
+
+type label struct {
+	ID        uuid.UUID
+	ProjectID uuid.UUID
+	Name      string
+	Color     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type taskLabel struct {
+	TaskID  uuid.UUID
+	LabelID uuid.UUID
+}
+
+type taskWatcher struct {
+	TaskID          uuid.UUID
+	ProjectMemberID uuid.UUID
+}
+
+type worklog struct {
+	ID              uuid.UUID
+	TaskID          uuid.UUID
+	ProjectMemberID uuid.UUID
+	Duration        int
+	Date            string
+	Note            *string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+type sprint struct {
+	ID                uuid.UUID
+	ProjectID         uuid.UUID
+	Name              string
+	Goal              *string
+	StartDate         string
+	EndDate           string
+	State             models.SprintState
+	CommittedPoints   *int
+	CompletedPoints   *int
+	CarriedOverPoints *int
+	StartedAt         *time.Time
+	ClosedAt          *time.Time
+	CreatedBy         *uuid.UUID
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+type taskStatusKey struct {
+	ProjectID uuid.UUID
+	Key       models.TaskStatus
+}
+
+type taskStatus struct {
+	taskStatusKey
+	Name     string
+	Category models.TaskStatusCategory
+	Position int
+}
+
+type taskStatusTransition struct {
+	ProjectID uuid.UUID
+	From      models.TaskStatus
+	To        models.TaskStatus
+}
+
+type taskActivity struct {
+	ID      uuid.UUID
+	TaskID  uuid.UUID
+	ActorID uuid.UUID
+	models.TaskFieldChange
+	CreatedAt time.Time
+}
+
+// tables holds rows by value so that a shallow copy of every map is a consistent snapshot
+type tables struct {
+	users          map[uuid.UUID]user
+	projects       map[uuid.UUID]project
+	projectMembers map[uuid.UUID]projectMember
+	tasks          map[uuid.UUID]task
+	invitations    map[uuid.UUID]invitation
+	notifications  map[uuid.UUID]notification
+	comments       map[uuid.UUID]comment
+	taskActivity   map[uuid.UUID]taskActivity
+	taskLinks      map[uuid.UUID]taskLink
+	labels         map[uuid.UUID]label
+	taskLabels     map[taskLabel]time.Time
+	taskStatuses   map[taskStatusKey]taskStatus
+	transitions    map[taskStatusTransition]bool
+	recurrences    map[uuid.UUID]taskRecurrence
+	worklogs       map[uuid.UUID]worklog
+	sprints        map[uuid.UUID]sprint
+	taskWatchers   map[taskWatcher]time.Time
+}
+
+func (t tables) clone() tables {
+	return tables{
+		users:          maps.Clone(t.users),
+		projects:       maps.Clone(t.projects),
+		projectMembers: maps.Clone(t.projectMembers),
+		tasks:          maps.Clone(t.tasks),
+		invitations:    maps.Clone(t.invitations),
+		notifications:  maps.Clone(t.notifications),
+		comments:       maps.Clone(t.comments),
+		taskActivity:   maps.Clone(t.taskActivity),
+		taskLinks:      maps.Clone(t.taskLinks),
+		labels:         maps.Clone(t.labels),
+		taskLabels:     maps.Clone(t.taskLabels),
+		taskStatuses:   maps.Clone(t.taskStatuses),
+		transitions:    maps.Clone(t.transitions),
+		recurrences:    maps.Clone(t.recurrences),
+		worklogs:       maps.Clone(t.worklogs),
+		sprints:        maps.Clone(t.sprints),
+		taskWatchers:   maps.Clone(t.taskWatchers),
+	}
+}
+
+// Store is the in-memory database shared by the repositories, the counterpart of *sql.DB
+type Store struct {
+	mu       sync.Mutex
+	data     tables
+	lastTime time.Time
+}
+
+func NewStore() *Store {
+	return &Store{
+		data: tables{
+			users:          map[uuid.UUID]user{},
+			projects:       map[uuid.UUID]project{},
+			projectMembers: map[uuid.UUID]projectMember{},
+			tasks:          map[uuid.UUID]task{},
+			invitations:    map[uuid.UUID]invitation{},
+			notifications:  map[uuid.UUID]notification{},
+			comments:       map[uuid.UUID]comment{},
+			taskActivity:   map[uuid.UUID]taskActivity{},
+			taskLinks:      map[uuid.UUID]taskLink{},
+			labels:         map[uuid.UUID]label{},
+			taskLabels:     map[taskLabel]time.Time{},
+			taskStatuses:   map[taskStatusKey]taskStatus{},
+			transitions:    map[taskStatusTransition]bool{},
+			recurrences:    map[uuid.UUID]taskRecurrence{},
+			worklogs:       map[uuid.UUID]worklog{},
+			sprints:        map[uuid.UUID]sprint{},
+			taskWatchers:   map[taskWatcher]time.Time{},
+		},
+	}
+}
+
+// NewRepositories returns every repository backed by store
+func NewRepositories(store *Store) repositories.Repositories {
+	return repositories.Repositories{
+		DB:             store,
+		Users:          NewUserRepository(store),
+		Projects:       NewProjectRepository(store),
+		ProjectMembers: NewProjectMemberRepository(store),
+		Tasks:          NewTaskRepository(store),
+		TaskLinks:      NewTaskLinkRepository(store),
+		Labels:         NewLabelRepository(store),
+		Workflows:      NewWorkflowRepository(store),
+		Sprints:        NewSprintRepository(store),
+		Comments:       NewCommentRepository(store),
+		Worklogs:       NewWorklogRepository(store),
+		Invitations:    NewInvitationRepository(store),
+		Notifications:  NewNotificationRepository(store),
+		Dashboard:      NewDashboardRepository(store),
+	}
+}
+
+// PingContext always succeeds, the store lives in this process
+func (s *Store) PingContext(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// now returns strictly increasing timestamps so that ordering by creation time is deterministic.
+// Must be called with s.mu held.
+func (s *Store) now() time.Time {
+	now := time.Now().UTC()
+	if !now.After(s.lastTime) {
+		now = s.lastTime.Add(time.Microsecond)
+	}
+	s.lastTime = now
+	return now
+}
+
+// begin snapshots the store; rolling the transaction back restores the snapshot. Transactions are not
+// isolated from each other, which is fine for tests that don't run conflicting writes concurrently.
+func (s *Store) begin() *tx {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &tx{store: s, snapshot: s.data.clone()}
+}
+
+type tx struct {
+	store    *Store
+	snapshot tables
+	done     bool
+}
+
+func (t *tx) Commit() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	return nil
+}
+
+func (t *tx) Rollback() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+
+	t.store.mu.Lock()
+	t.store.data = t.snapshot
+	t.store.mu.Unlock()
+	return nil
+}
+
+func (t *tx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return nil, errNoSQL
+}
+
+func (t *tx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return nil, errNoSQL
+}
+
+// QueryRowContext can't report errNoSQL through a *sql.Row, the in-memory repositories never call it
+func (t *tx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	panic(errNoSQL)
+}
+
+// notFound mirrors the Postgres repositories, which wrap sql.ErrNoRows
+func notFound(message string) error {
+	return apperrors.NotFound(message).Wrap(sql.ErrNoRows)
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+// memberDTO must be called with s.mu held
+func (s *Store) memberDTO(member projectMember) models.ProjectMemberResponseDTO {
+	u := s.data.users[member.UserID]
+	return models.ProjectMemberResponseDTO{
+		ID:        member.ID,
+		UserID:    member.UserID,
+		ProjectID: member.ProjectID,
+		Name:      u.Name,
+		Email:     u.Email,
+		Status:    member.Status,
+		Role:      member.Role,
+		JoinedAt:  formatTime(member.JoinedAt),
+	}
+}
+
+// taskDTO must be called with s.mu held
+func (s *Store) taskDTO(t task) models.TaskResponseDTO {
+	p := s.data.projects[t.ProjectID]
+	dto := models.TaskResponseDTO{
+		ID:          t.ID,
+		ProjectID:   t.ProjectID,
+		ProjectKey:  p.Key,
+		ProjectName: p.Name,
+		CreatedBy:   s.memberDTO(s.data.projectMembers[t.CreatedBy]),
+		UpdatedBy:   s.memberDTO(s.data.projectMembers[t.UpdatedBy]),
+		Title:       t.Title,
+		TaskNumber:  t.TaskNumber,
+		Description: t.Description,
+		Status:      t.Status,
+		Priority:    t.Priority,
+		DueDate:     t.DueDate,
+		ParentID:    t.ParentID,
+		Rank:        t.Rank,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+
+	status := s.data.taskStatuses[taskStatusKey{t.ProjectID, t.Status}]
+	dto.StatusCategory = status.Category
+	dto.StatusPosition = status.Position
+
+	for _, subtask := range s.data.tasks {
+		if subtask.ParentID != nil && *subtask.ParentID == t.ID {
+			dto.TotalSubtasks++
+			if s.isDone(subtask) {
+				dto.SubtasksCompleted++
+			}
+		}
+	}
+	dto.CompletionPercentage = models.CompletionPercentage(dto.SubtasksCompleted, dto.TotalSubtasks)
+
+	dto.OpenBlockers = []models.TaskReferenceDTO{}
+	for _, link := range s.data.taskLinks {
+		if link.TargetTaskID != t.ID || link.Type != models.TaskLinkBlocks {
+			continue
+		}
+		if blocker := s.data.tasks[link.SourceTaskID]; !s.isDone(blocker) {
+			dto.OpenBlockers = append(dto.OpenBlockers, taskReference(blocker))
+		}
+	}
+	slices.SortFunc(dto.OpenBlockers, func(a, b models.TaskReferenceDTO) int {
+		return a.TaskNumber - b.TaskNumber
+	})
+
+	dto.Labels = s.taskLabelDTOs(t.ID)
+
+	dto.OriginalEstimate = t.OriginalEstimate
+	dto.RemainingEstimate = t.RemainingEstimate
+	for _, w := range s.data.worklogs {
+		if w.TaskID == t.ID {
+			dto.TimeLogged += w.Duration
+		}
+	}
+
+	dto.StoryPoints = t.StoryPoints
+	dto.SprintID = t.SprintID
+	dto.CompletedAt = t.CompletedAt
+
+	if t.RecurrenceID != nil {
+		series := s.data.recurrences[*t.RecurrenceID]
+		dto.Recurrence = &models.TaskRecurrenceDTO{
+			ID:            series.ID,
+			Rule:          series.Rule,
+			Occurrences:   series.Occurrences,
+			CurrentTaskID: series.CurrentTaskID,
+		}
+	}
+
+	if t.AssignedTo != nil {
+		if member, ok := s.data.projectMembers[*t.AssignedTo]; ok {
+			assignee := s.memberDTO(member)
+			dto.AssignedTo = &assignee
+			dto.AssignedToName = assignee.Name
+		}
+	}
+
+	return dto
+}
+
+// isDone is true when the task's status is in the done category. Must be called with s.mu held.
+func (s *Store) isDone(t task) bool {
+	return s.data.taskStatuses[taskStatusKey{t.ProjectID, t.Status}].Category == models.StatusCategoryDone
+}
+
+// trackCompletion keeps CompletedAt in step with the task's status: it is set when the task
+// becomes done, kept while it moves between done statuses and cleared when it is reopened. Must be
+// called with s.mu held.
+func (s *Store) trackCompletion(t *task, now time.Time) {
+	switch {
+	case !s.isDone(*t):
+		t.CompletedAt = nil
+	case t.CompletedAt == nil:
+		t.CompletedAt = &now
+	}
+}
+
+// taskLabelDTOs returns the labels of a task sorted by name. Must be called with s.mu held.
+func (s *Store) taskLabelDTOs(taskID uuid.UUID) []models.LabelResponseDTO {
+	labels := []models.LabelResponseDTO{}
+	for attached := range s.data.taskLabels {
+		if attached.TaskID == taskID {
+			labels = append(labels, labelDTO(s.data.labels[attached.LabelID]))
+		}
+	}
+	slices.SortFunc(labels, compareLabels)
+	return labels
+}
+
+func labelDTO(l label) models.LabelResponseDTO {
+	return models.LabelResponseDTO{ID: l.ID, ProjectID: l.ProjectID, Name: l.Name, Color: l.Color}
+}
+
+func compareLabels(a, b models.LabelResponseDTO) int {
+	return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+}
+
+func taskReference(t task) models.TaskReferenceDTO {
+	return models.TaskReferenceDTO{ID: t.ID, TaskNumber: t.TaskNumber, Title: t.Title, Status: t.Status}
+}
+
+// memberOf returns the user's membership in a project. Must be called with s.mu held.
+func (s *Store) memberOf(userID uuid.UUID, projectID uuid.UUID) (projectMember, bool) {
+	for _, member := range s.data.projectMembers {
+		if member.UserID == userID && member.ProjectID == projectID {
+			return member, true
+		}
+	}
+	return projectMember{}, false
+}
+
+// deleteTaskRows removes a task together with the rows that reference it and promotes its subtasks
+// to top level tasks. Must be called with s.mu held.
+func (s *Store) deleteTaskRows(taskID uuid.UUID) {
+	delete(s.data.tasks, taskID)
+	for id, t := range s.data.tasks {
+		if t.ParentID != nil && *t.ParentID == taskID {
+			t.ParentID = nil
+			s.data.tasks[id] = t
+		}
+	}
+	for id, c := range s.data.comments {
+		if c.TaskID == taskID {
+			delete(s.data.comments, id)
+		}
+	}
+	for id, n := range s.data.notifications {
+		if n.TaskID != nil && *n.TaskID == taskID {
+			delete(s.data.notifications, id)
+		}
+	}
+	for id, a := range s.data.taskActivity {
+		if a.TaskID == taskID {
+			delete(s.data.taskActivity, id)
+		}
+	}
+	for id, link := range s.data.taskLinks {
+		if link.SourceTaskID == taskID || link.TargetTaskID == taskID {
+			delete(s.data.taskLinks, id)
+		}
+	}
+	for attached := range s.data.taskLabels {
+		if attached.TaskID == taskID {
+			delete(s.data.taskLabels, attached)
+		}
+	}
+	for watcher := range s.data.taskWatchers {
+		if watcher.TaskID == taskID {
+			delete(s.data.taskWatchers, watcher)
+		}
+	}
+	for id, w := range s.data.worklogs {
+		if w.TaskID == taskID {
+			delete(s.data.worklogs, id)
+		}
+	}
+	for id, series := range s.data.recurrences {
+		if series.CurrentTaskID != nil && *series.CurrentTaskID == taskID {
+			series.CurrentTaskID = nil
+			s.data.recurrences[id] = series
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/repositories"
+)
+
+type taskLinkRepository struct {
+	store *Store
+}
+
+func NewTaskLinkRepository(store *Store) repositories.TaskLinkRepository {
+	return &taskLinkRepository{store: store}
+}
+
+func (r *taskLinkRepository) CreateTaskLink(ctx context.Context, projectID uuid.UUID, linkDTO *models.CreateTaskLinkDTO) (uuid.UUID, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if !linkDTO.Type.IsStored() {
+		return uuid.Nil, fmt.Errorf("invalid task link type %q", linkDTO.Type)
+	}
+	if linkDTO.SourceTaskID == linkDTO.TargetTaskID {
+		return uuid.Nil, fmt.Errorf("a task can't be linked to itself")
+	}
+	for _, id := range []uuid.UUID{linkDTO.SourceTaskID, linkDTO.TargetTaskID} {
+		if _, ok := r.store.data.tasks[id]; !ok {
+			return uuid.Nil, fmt.Errorf("task %s does not exist", id)
+		}
+	}
+
+	for _, link := range r.store.data.taskLinks {
+		if link.SourceTaskID == linkDTO.SourceTaskID && link.TargetTaskID == linkDTO.TargetTaskID && link.Type == linkDTO.Type {
+			return uuid.Nil, apperrors.Conflict("these tasks are already linked this way")
+		}
+	}
+	if linkDTO.Type != models.TaskLinkRelatesTo && r.store.linkPathExists(linkDTO.TargetTaskID, linkDTO.SourceTaskID, linkDTO.Type) {
+		return uuid.Nil, apperrors.Validation("invalid task link", map[string]string{"taskId": "the link would create a cycle of " + string(linkDTO.Type) + " links"})
+	}
+
+	id := uuid.New()
+	r.store.data.taskLinks[id] = taskLink{
+		ID:           id,
+		SourceTaskID: linkDTO.SourceTaskID,
+		TargetTaskID: linkDTO.TargetTaskID,
+		Type:         linkDTO.Type,
+		CreatedBy:    linkDTO.CreatedBy,
+		CreatedAt:    r.store.now(),
+	}
+
+	return id, nil
+}
+
+// linkDTO reads link from the side of taskID. Must be called with r.store.mu held.
+func (r *taskLinkRepository) linkDTO(taskID uuid.UUID, link taskLink) models.TaskLinkResponseDTO {
+	dto := models.TaskLinkResponseDTO{ID: link.ID, Type: link.Type, CreatedBy: link.CreatedBy, CreatedAt: link.CreatedAt}
+	if link.SourceTaskID == taskID {
+		dto.Task = taskReference(r.store.data.tasks[link.TargetTaskID])
+	} else {
+		dto.Type = link.Type.Inverse()
+		dto.Task = taskReference(r.store.data.tasks[link.SourceTaskID])
+	}
+	return dto
+}
+
+func (r *taskLinkRepository) GetTaskLink(ctx context.Context, taskID uuid.UUID, linkID uuid.UUID) (*models.TaskLinkResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	link, ok := r.store.data.taskLinks[linkID]
+	if !ok || (link.SourceTaskID != taskID && link.TargetTaskID != taskID) {
+		return nil, notFound("task link not found")
+	}
+
+	dto := r.linkDTO(taskID, link)
+	return &dto, nil
+}
+
+func (r *taskLinkRepository) GetTaskLinks(ctx context.Context, taskID uuid.UUID) ([]models.TaskLinkResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	links := []models.TaskLinkResponseDTO{}
+	for _, link := range r.store.data.taskLinks {
+		if link.SourceTaskID == taskID || link.TargetTaskID == taskID {
+			links = append(links, r.linkDTO(taskID, link))
+		}
+	}
+	slices.SortFunc(links, func(a, b models.TaskLinkResponseDTO) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return links, nil
+}
+
+// linkPathExists reports whether following links of linkType from fromTaskID leads to toTaskID.
+// Must be called with s.mu held.
+func (s *Store) linkPathExists(fromTaskID uuid.UUID, toTaskID uuid.UUID, linkType models.TaskLinkType) bool {
+	reached := map[uuid.UUID]bool{}
+	queue := []uuid.UUID{fromTaskID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, link := range s.data.taskLinks {
+			if link.SourceTaskID != current || link.Type != linkType || reached[link.TargetTaskID] {
+				continue
+			}
+			if link.TargetTaskID == toTaskID {
+				return true
+			}
+			reached[link.TargetTaskID] = true
+			queue = append(queue, link.TargetTaskID)
+		}
+	}
+
+	return false
+}
+
+func (r *taskLinkRepository) DeleteTaskLink(ctx context.Context, linkID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.data.taskLinks, linkID)
+	return nil
+}
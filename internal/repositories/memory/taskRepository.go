@@ -0,0 +1,703 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/repositories"
+)
+
+type taskRepository struct {
+	store *Store
+}
+
+func NewTaskRepository(store *Store) repositories.TaskRepository {
+	return &taskRepository{store: store}
+}
+
+func (r *taskRepository) BeginTransaction(ctx context.Context) (repositories.Tx, error) {
+	return r.store.begin(), nil
+}
+
+func (r *taskRepository) CreateTask(ctx context.Context, taskDTO *models.CreateTaskDTO) (uuid.UUID, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	id, err := r.store.insertTask(taskDTO)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if taskDTO.Recurrence != nil {
+		r.store.createRecurrence(id, *taskDTO.Recurrence, taskDTO.CreatedBy)
+	}
+
+	return id, nil
+}
+
+// insertTask adds a task at the bottom of its status column. Must be called with s.mu held.
+func (s *Store) insertTask(taskDTO *models.CreateTaskDTO) (uuid.UUID, error) {
+	if _, ok := s.data.projects[taskDTO.ProjectID]; !ok {
+		return uuid.Nil, fmt.Errorf("project %s does not exist", taskDTO.ProjectID)
+	}
+	if taskDTO.ParentID != nil {
+		if _, ok := s.data.tasks[*taskDTO.ParentID]; !ok {
+			return uuid.Nil, fmt.Errorf("parent task %s does not exist", *taskDTO.ParentID)
+		}
+	}
+	if err := s.checkTaskFields(taskDTO.ProjectID, taskDTO.Status, taskDTO.Priority); err != nil {
+		return uuid.Nil, err
+	}
+
+	// Task numbers are allocated per project, like the assign_task_number trigger does
+	taskNumber := 1
+	for _, t := range s.data.tasks {
+		if t.ProjectID == taskDTO.ProjectID && t.TaskNumber >= taskNumber {
+			taskNumber = t.TaskNumber + 1
+		}
+	}
+
+	now := s.now()
+	id := uuid.New()
+	t := task{
+		ID:                id,
+		ProjectID:         taskDTO.ProjectID,
+		TaskNumber:        taskNumber,
+		Title:             taskDTO.Title,
+		Description:       taskDTO.Description,
+		Status:            taskDTO.Status,
+		Priority:          taskDTO.Priority,
+		DueDate:           taskDTO.DueDate,
+		CreatedBy:         taskDTO.CreatedBy,
+		UpdatedBy:         taskDTO.UpdatedBy,
+		AssignedTo:        taskDTO.AssignedTo,
+		ParentID:          taskDTO.ParentID,
+		Rank:              s.bottomRank(taskDTO.ProjectID, taskDTO.Status),
+		RecurrenceID:      taskDTO.RecurrenceID,
+		OriginalEstimate:  taskDTO.OriginalEstimate,
+		RemainingEstimate: taskDTO.RemainingEstimate,
+		StoryPoints:       taskDTO.StoryPoints,
+		SprintID:          taskDTO.SprintID,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	s.trackCompletion(&t, now)
+	s.data.tasks[id] = t
+
+	s.watchTask(id, taskDTO.CreatedBy)
+	if taskDTO.AssignedTo != nil {
+		s.watchTask(id, *taskDTO.AssignedTo)
+	}
+
+	return id, nil
+}
+
+// watchTask adds a watcher to a task unless they already watch it. Must be called with s.mu held.
+func (s *Store) watchTask(taskID uuid.UUID, projectMemberID uuid.UUID) {
+	watcher := taskWatcher{TaskID: taskID, ProjectMemberID: projectMemberID}
+	if _, ok := s.data.taskWatchers[watcher]; !ok {
+		s.data.taskWatchers[watcher] = s.now()
+	}
+}
+
+func (r *taskRepository) GetFullTaskByID(ctx context.Context, taskID uuid.UUID) (*models.TaskResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	t, ok := r.store.data.tasks[taskID]
+	if !ok {
+		return nil, notFound("task not found")
+	}
+
+	dto := r.store.taskDTO(t)
+	return &dto, nil
+}
+
+func (r *taskRepository) LockTasks(ctx context.Context, tx repositories.Tx, projectID uuid.UUID, taskIDs []uuid.UUID) (map[uuid.UUID]*models.TaskResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	tasks := make(map[uuid.UUID]*models.TaskResponseDTO, len(taskIDs))
+	for _, taskID := range taskIDs {
+		if t, ok := r.store.data.tasks[taskID]; ok && t.ProjectID == projectID {
+			dto := r.store.taskDTO(t)
+			tasks[taskID] = &dto
+		}
+	}
+
+	return tasks, nil
+}
+
+func (r *taskRepository) GetTaskByKey(ctx context.Context, projectKey string, taskNumber int) (*models.TaskResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, t := range r.store.data.tasks {
+		if t.TaskNumber == taskNumber && r.store.data.projects[t.ProjectID].Key == projectKey {
+			dto := r.store.taskDTO(t)
+			return &dto, nil
+		}
+	}
+
+	return nil, notFound("task not found")
+}
+
+func (r *taskRepository) ResolveTaskKeys(ctx context.Context, userID uuid.UUID, keys []string) (map[string]models.TaskKeyLinkDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	links := map[string]models.TaskKeyLinkDTO{}
+	for _, t := range r.store.data.tasks {
+		key := models.TaskKey(r.store.data.projects[t.ProjectID].Key, t.TaskNumber)
+		if !slices.Contains(keys, key) {
+			continue
+		}
+		if member, ok := r.store.memberOf(userID, t.ProjectID); !ok || member.Status != models.StatusActive {
+			continue
+		}
+		links[key] = models.TaskKeyLinkDTO{Key: key, TaskID: t.ID, ProjectID: t.ProjectID, Title: t.Title}
+	}
+
+	return links, nil
+}
+
+func (r *taskRepository) ListTasks(ctx context.Context, projectID uuid.UUID, query *models.TaskListQuery) (*models.TaskListResponseDTO, error) {
+	order := repositories.TaskListOrder(query.Sort)
+
+	var after []any
+	if query.Cursor != "" {
+		values, err := repositories.DecodeTaskCursor(query.Cursor, order)
+		if err != nil {
+			return nil, err
+		}
+		after = values
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var tasks []models.TaskResponseDTO
+	for _, t := range r.store.data.tasks {
+		if t.ProjectID != projectID || !matchesTaskFilter(t, &query.Filter, r.store.data.taskLabels) {
+			continue
+		}
+		dto := r.store.taskDTO(t)
+		if after != nil && compareSortValues(repositories.TaskSortValues(&dto, order), after, order) <= 0 {
+			continue
+		}
+		tasks = append(tasks, dto)
+	}
+
+	slices.SortFunc(tasks, func(a, b models.TaskResponseDTO) int {
+		return compareSortValues(repositories.TaskSortValues(&a, order), repositories.TaskSortValues(&b, order), order)
+	})
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = repositories.DefaultTaskPageSize
+	}
+
+	page := &models.TaskListResponseDTO{Tasks: []models.TaskResponseDTO{}}
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+		next := repositories.EncodeTaskCursor(&tasks[limit-1], order)
+		page.NextCursor = &next
+	}
+	page.Tasks = append(page.Tasks, tasks...)
+
+	return page, nil
+}
+
+func matchesTaskFilter(t task, filter *models.TaskFilter, labels map[taskLabel]time.Time) bool {
+	if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, t.Status) {
+		return false
+	}
+	if len(filter.Priorities) > 0 && !slices.Contains(filter.Priorities, t.Priority) {
+		return false
+	}
+
+	if len(filter.AssignedTo) > 0 || filter.Unassigned {
+		assigned := t.AssignedTo != nil && slices.Contains(filter.AssignedTo, *t.AssignedTo)
+		unassigned := filter.Unassigned && t.AssignedTo == nil
+		if !assigned && !unassigned {
+			return false
+		}
+	}
+
+	if filter.DueAfter != nil && (t.DueDate == nil || t.DueDate.Before(*filter.DueAfter)) {
+		return false
+	}
+	if filter.DueBefore != nil && (t.DueDate == nil || !t.DueDate.Before(*filter.DueBefore)) {
+		return false
+	}
+
+	if len(filter.Sprints) > 0 || filter.Backlog {
+		inSprint := t.SprintID != nil && slices.Contains(filter.Sprints, *t.SprintID)
+		inBacklog := filter.Backlog && t.SprintID == nil
+		if !inSprint && !inBacklog {
+			return false
+		}
+	}
+
+	if filter.ParentID != nil && (t.ParentID == nil || *t.ParentID != *filter.ParentID) {
+		return false
+	}
+
+	if len(filter.Labels) > 0 && !slices.ContainsFunc(filter.Labels, func(labelID uuid.UUID) bool {
+		_, ok := labels[taskLabel{TaskID: t.ID, LabelID: labelID}]
+		return ok
+	}) {
+		return false
+	}
+
+	if filter.Text != "" {
+		text := strings.ToLower(filter.Text)
+		description := ""
+		if t.Description != nil {
+			description = *t.Description
+		}
+		if !strings.Contains(strings.ToLower(t.Title), text) && !strings.Contains(strings.ToLower(description), text) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compareSortValues orders two results of repositories.TaskSortValues like ORDER BY would
+func compareSortValues(a []any, b []any, order []models.TaskSort) int {
+	for i, s := range order {
+		var c int
+		switch av := a[i].(type) {
+		case int:
+			c = av - b[i].(int)
+		case string:
+			c = strings.Compare(av, b[i].(string))
+		case time.Time:
+			c = av.Compare(b[i].(time.Time))
+		}
+		if s.Descending {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func (r *taskRepository) PatchTask(ctx context.Context, taskID uuid.UUID, updatedBy uuid.UUID, patch *models.PatchTaskDTO) ([]models.TaskFieldChange, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.patchTask(taskID, updatedBy, patch)
+}
+
+func (r *taskRepository) PatchTasks(ctx context.Context, tx repositories.Tx, taskIDs []uuid.UUID, updatedBy uuid.UUID, patch *models.PatchTaskDTO) (map[uuid.UUID][]models.TaskFieldChange, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	changes := make(map[uuid.UUID][]models.TaskFieldChange, len(taskIDs))
+	for _, taskID := range taskIDs {
+		taskChanges, err := r.store.patchTask(taskID, updatedBy, patch)
+		if err != nil {
+			return nil, err
+		}
+		changes[taskID] = taskChanges
+	}
+
+	return changes, nil
+}
+
+// patchTask is PatchTask with s.mu held
+func (s *Store) patchTask(taskID uuid.UUID, updatedBy uuid.UUID, patch *models.PatchTaskDTO) ([]models.TaskFieldChange, error) {
+	t, ok := s.data.tasks[taskID]
+	if !ok {
+		return nil, notFound("task not found")
+	}
+
+	if !patch.IfMatch.Matches(models.ETag(t.UpdatedAt)) {
+		return nil, apperrors.PreconditionFailed("the task has changed since it was read")
+	}
+
+	current := s.taskDTO(t)
+	changes := patch.Changes(&current)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	for _, change := range changes {
+		switch change.Field {
+		case models.TaskFieldTitle:
+			t.Title = *patch.Title.Value
+		case models.TaskFieldDescription:
+			t.Description = patch.Description.Value
+		case models.TaskFieldStatus:
+			t.Status = *patch.Status.Value
+			t.Rank = s.bottomRank(t.ProjectID, t.Status)
+		case models.TaskFieldPriority:
+			t.Priority = *patch.Priority.Value
+		case models.TaskFieldDueDate:
+			t.DueDate = patch.DueDate.Value
+		case models.TaskFieldAssignedTo:
+			t.AssignedTo = patch.AssignedTo.Value
+		case models.TaskFieldParentID:
+			t.ParentID = patch.ParentID.Value
+		case models.TaskFieldOriginalEstimate:
+			t.OriginalEstimate = patch.OriginalEstimate.Value
+		case models.TaskFieldRemainingEstimate:
+			t.RemainingEstimate = patch.RemainingEstimate.Value
+		case models.TaskFieldStoryPoints:
+			t.StoryPoints = patch.StoryPoints.Value
+		case models.TaskFieldSprintID:
+			t.SprintID = patch.SprintID.Value
+		}
+	}
+	if err := s.checkTaskFields(t.ProjectID, t.Status, t.Priority); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	t.UpdatedBy = updatedBy
+	t.UpdatedAt = now
+	s.trackCompletion(&t, now)
+	s.data.tasks[taskID] = t
+
+	for _, change := range changes {
+		id := uuid.New()
+		s.data.taskActivity[id] = taskActivity{
+			ID:              id,
+			TaskID:          taskID,
+			ActorID:         updatedBy,
+			TaskFieldChange: change,
+			CreatedAt:       now,
+		}
+	}
+
+	if t.AssignedTo != nil && slices.ContainsFunc(changes, func(change models.TaskFieldChange) bool {
+		return change.Field == models.TaskFieldAssignedTo
+	}) {
+		s.watchTask(taskID, *t.AssignedTo)
+	}
+
+	return changes, nil
+}
+
+func (r *taskRepository) MoveTask(ctx context.Context, tx repositories.Tx, taskID uuid.UUID, updatedBy uuid.UUID, from models.TaskStatus, move *models.MoveTaskDTO) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	t, ok := r.store.data.tasks[taskID]
+	if !ok {
+		return false, notFound("task not found")
+	}
+	if t.Status != from {
+		return false, apperrors.Conflict("the task changed status while it was being moved, reload the board and try again")
+	}
+
+	var column []task
+	for _, other := range r.store.data.tasks {
+		if other.ProjectID == t.ProjectID && other.Status == move.Status && other.ID != taskID {
+			column = append(column, other)
+		}
+	}
+	slices.SortFunc(column, func(a, b task) int {
+		if a.Rank != b.Rank {
+			return a.Rank - b.Rank
+		}
+		return a.TaskNumber - b.TaskNumber
+	})
+
+	ranked := make([]repositories.RankedTask, len(column))
+	for i, other := range column {
+		ranked[i] = repositories.RankedTask{ID: other.ID, Rank: other.Rank}
+	}
+
+	rank, rebalanced, err := repositories.PlaceTask(ranked, taskID, move)
+	if err != nil {
+		return false, err
+	}
+
+	if move.Status != from {
+		if err := r.store.checkTaskFields(t.ProjectID, move.Status, t.Priority); err != nil {
+			return false, err
+		}
+
+		now := r.store.now()
+		t.Status = move.Status
+		t.UpdatedBy = updatedBy
+		t.UpdatedAt = now
+		r.store.trackCompletion(&t, now)
+
+		oldValue, newValue := string(from), string(move.Status)
+		change := models.TaskFieldChange{Field: models.TaskFieldStatus, OldValue: &oldValue, NewValue: &newValue}
+		id := uuid.New()
+		r.store.data.taskActivity[id] = taskActivity{
+			ID:              id,
+			TaskID:          taskID,
+			ActorID:         updatedBy,
+			TaskFieldChange: change,
+			CreatedAt:       now,
+		}
+	}
+	t.Rank = rank
+	r.store.data.tasks[taskID] = t
+
+	for _, placed := range rebalanced {
+		other := r.store.data.tasks[placed.ID]
+		other.Rank = placed.Rank
+		r.store.data.tasks[placed.ID] = other
+	}
+
+	return len(rebalanced) > 1, nil
+}
+
+// bottomRank is the rank that puts a task after every task of the status column
+func (s *Store) bottomRank(projectID uuid.UUID, status models.TaskStatus) int {
+	rank, found := 0, false
+	for _, t := range s.data.tasks {
+		if t.ProjectID == projectID && t.Status == status && (!found || t.Rank > rank) {
+			rank, found = t.Rank, true
+		}
+	}
+	return rank + repositories.TaskRankStep
+}
+
+func (r *taskRepository) SetTaskRecurrence(ctx context.Context, taskID uuid.UUID, rule string, createdBy uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	t, ok := r.store.data.tasks[taskID]
+	if !ok {
+		return notFound("task not found")
+	}
+
+	if t.RecurrenceID == nil {
+		r.store.createRecurrence(taskID, rule, createdBy)
+		return nil
+	}
+
+	series := r.store.data.recurrences[*t.RecurrenceID]
+	series.Rule = rule
+	series.UpdatedAt = r.store.now()
+	r.store.data.recurrences[series.ID] = series
+	return nil
+}
+
+// createRecurrence starts a series with the task as its first occurrence. Must be called with
+// s.mu held.
+func (s *Store) createRecurrence(taskID uuid.UUID, rule string, createdBy uuid.UUID) {
+	t := s.data.tasks[taskID]
+	now := s.now()
+	series := taskRecurrence{
+		ID:            uuid.New(),
+		ProjectID:     t.ProjectID,
+		Rule:          rule,
+		Occurrences:   1,
+		CurrentTaskID: &taskID,
+		CreatedBy:     &createdBy,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	s.data.recurrences[series.ID] = series
+
+	t.RecurrenceID = &series.ID
+	t.UpdatedAt = now
+	s.data.tasks[taskID] = t
+}
+
+func (r *taskRepository) StopTaskRecurrence(ctx context.Context, taskID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	t, ok := r.store.data.tasks[taskID]
+	if !ok || t.RecurrenceID == nil {
+		return apperrors.NotFound("the task doesn't recur")
+	}
+
+	recurrenceID := *t.RecurrenceID
+	delete(r.store.data.recurrences, recurrenceID)
+	for id, t := range r.store.data.tasks {
+		if t.RecurrenceID != nil && *t.RecurrenceID == recurrenceID {
+			t.RecurrenceID = nil
+			r.store.data.tasks[id] = t
+		}
+	}
+
+	return nil
+}
+
+func (r *taskRepository) AdvanceTaskRecurrence(ctx context.Context, recurrenceID uuid.UUID, fromTaskID uuid.UUID, next *models.CreateTaskDTO) (uuid.UUID, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.advanceRecurrence(recurrenceID, fromTaskID, next)
+}
+
+func (r *taskRepository) AdvanceTaskRecurrenceTx(ctx context.Context, tx repositories.Tx, recurrenceID uuid.UUID, fromTaskID uuid.UUID, next *models.CreateTaskDTO) (uuid.UUID, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.advanceRecurrence(recurrenceID, fromTaskID, next)
+}
+
+// advanceRecurrence is AdvanceTaskRecurrence with s.mu held
+func (s *Store) advanceRecurrence(recurrenceID uuid.UUID, fromTaskID uuid.UUID, next *models.CreateTaskDTO) (uuid.UUID, error) {
+	series, ok := s.data.recurrences[recurrenceID]
+	if !ok {
+		return uuid.Nil, notFound("recurring series not found")
+	}
+	if series.CurrentTaskID == nil || *series.CurrentTaskID != fromTaskID {
+		return uuid.Nil, nil
+	}
+
+	series.UpdatedAt = s.now()
+	if next == nil {
+		series.CurrentTaskID = nil
+		s.data.recurrences[recurrenceID] = series
+		return uuid.Nil, nil
+	}
+
+	next.RecurrenceID = &recurrenceID
+	taskID, err := s.insertTask(next)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	for attached := range s.data.taskLabels {
+		if attached.TaskID == fromTaskID {
+			s.data.taskLabels[taskLabel{TaskID: taskID, LabelID: attached.LabelID}] = series.UpdatedAt
+		}
+	}
+
+	series.CurrentTaskID = &taskID
+	series.Occurrences++
+	s.data.recurrences[recurrenceID] = series
+	return taskID, nil
+}
+
+func (r *taskRepository) GetTaskAncestors(ctx context.Context, taskID uuid.UUID) ([]uuid.UUID, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var ancestors []uuid.UUID
+	for t, ok := r.store.data.tasks[taskID]; ok && t.ParentID != nil; t, ok = r.store.data.tasks[*t.ParentID] {
+		if slices.Contains(ancestors, *t.ParentID) {
+			break
+		}
+		ancestors = append(ancestors, *t.ParentID)
+	}
+
+	return ancestors, nil
+}
+
+func (r *taskRepository) GetTaskActivity(ctx context.Context, taskID uuid.UUID) ([]models.TaskActivityResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	activity := []models.TaskActivityResponseDTO{}
+	for _, a := range r.store.data.taskActivity {
+		if a.TaskID == taskID {
+			activity = append(activity, models.TaskActivityResponseDTO{
+				ID:              a.ID,
+				TaskID:          a.TaskID,
+				Actor:           r.store.memberDTO(r.store.data.projectMembers[a.ActorID]),
+				TaskFieldChange: a.TaskFieldChange,
+				CreatedAt:       a.CreatedAt,
+			})
+		}
+	}
+
+	// One patch writes all of its changes with the same timestamp, so they're kept in field order
+	slices.SortFunc(activity, func(a, b models.TaskActivityResponseDTO) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return slices.Index(models.TaskActivityFields, a.Field) - slices.Index(models.TaskActivityFields, b.Field)
+	})
+
+	return activity, nil
+}
+
+func (r *taskRepository) DeleteTask(ctx context.Context, taskID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.deleteTaskRows(taskID)
+	return nil
+}
+
+func (r *taskRepository) DeleteTasks(ctx context.Context, tx repositories.Tx, taskIDs []uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, taskID := range taskIDs {
+		r.store.deleteTaskRows(taskID)
+	}
+	return nil
+}
+
+func (r *taskRepository) WatchTask(ctx context.Context, taskID uuid.UUID, projectMemberID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.data.tasks[taskID]; !ok {
+		return fmt.Errorf("task %s does not exist", taskID)
+	}
+	if _, ok := r.store.data.projectMembers[projectMemberID]; !ok {
+		return fmt.Errorf("project member %s does not exist", projectMemberID)
+	}
+
+	r.store.watchTask(taskID, projectMemberID)
+	return nil
+}
+
+func (r *taskRepository) UnwatchTask(ctx context.Context, taskID uuid.UUID, projectMemberID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.data.taskWatchers, taskWatcher{TaskID: taskID, ProjectMemberID: projectMemberID})
+	return nil
+}
+
+func (r *taskRepository) GetTaskWatchers(ctx context.Context, taskID uuid.UUID) ([]models.ProjectMemberResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	watchers := []models.ProjectMemberResponseDTO{}
+	for watcher := range r.store.data.taskWatchers {
+		if watcher.TaskID == taskID {
+			watchers = append(watchers, r.store.memberDTO(r.store.data.projectMembers[watcher.ProjectMemberID]))
+		}
+	}
+	slices.SortFunc(watchers, func(a, b models.ProjectMemberResponseDTO) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+
+	return watchers, nil
+}
+
+// checkTaskFields enforces the constraints on the tasks table: the status must be one of the
+// project's and the priority is checked. Must be called with s.mu held.
+func (s *Store) checkTaskFields(projectID uuid.UUID, status models.TaskStatus, priority models.TaskPriority) error {
+	if _, ok := s.data.taskStatuses[taskStatusKey{projectID, status}]; !ok {
+		return fmt.Errorf("invalid task status %q", status)
+	}
+
+	switch priority {
+	case models.Low, models.Medium, models.High, models.Critical:
+	default:
+		return fmt.Errorf("invalid task priority %q", priority)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/repositories"
+)
+
+type userRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) repositories.UserRepository {
+	return &userRepository{store: store}
+}
+
+func (r *userRepository) CreateUser(ctx context.Context, userDTO *models.CreateUserDTO) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, u := range r.store.data.users {
+		if u.FirebaseUID == userDTO.FirebaseUID || u.Email == userDTO.Email {
+			return apperrors.Conflict("user already exists")
+		}
+	}
+
+	id := uuid.New()
+	r.store.data.users[id] = user{
+		ID:          id,
+		FirebaseUID: userDTO.FirebaseUID,
+		Name:        userDTO.Name,
+		Email:       userDTO.Email,
+		CreatedAt:   r.store.now(),
+	}
+
+	return nil
+}
+
+func (r *userRepository) GetUserIDByFirebaseUID(ctx context.Context, firebaseUID string) (uuid.UUID, error) {
+	u, err := r.byFirebaseUID(firebaseUID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return u.ID, nil
+}
+
+func (r *userRepository) GetUserByFirebaseUID(ctx context.Context, firebaseUID string) (*models.UserResponseDTO, error) {
+	u, err := r.byFirebaseUID(firebaseUID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.UserResponseDTO{ID: u.ID, Name: u.Name, Email: u.Email}, nil
+}
+
+func (r *userRepository) SearchUsers(ctx context.Context, searchQuery string) ([]models.UserResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	query := strings.ToLower(searchQuery)
+	users := []models.UserResponseDTO{}
+	for _, u := range r.store.data.users {
+		if strings.Contains(strings.ToLower(u.Email), query) || strings.Contains(strings.ToLower(u.Name), query) {
+			users = append(users, models.UserResponseDTO{ID: u.ID, Name: u.Name, Email: u.Email})
+		}
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Name < users[j].Name
+	})
+
+	if len(users) > 5 {
+		users = users[:5]
+	}
+
+	return users, nil
+}
+
+func (r *userRepository) byFirebaseUID(firebaseUID string) (user, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, u := range r.store.data.users {
+		if u.FirebaseUID == firebaseUID {
+			return u, nil
+		}
+	}
+	return user{}, notFound("user not found")
+}
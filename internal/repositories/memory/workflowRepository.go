@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/repositories"
+)
+
+type workflowRepository struct {
+	store *Store
+}
+
+func NewWorkflowRepository(store *Store) repositories.WorkflowRepository {
+	return &workflowRepository{store: store}
+}
+
+func (r *workflowRepository) GetWorkflow(ctx context.Context, projectID uuid.UUID) (*models.WorkflowDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	workflow := &models.WorkflowDTO{Statuses: []models.TaskStatusDTO{}}
+	for key, status := range r.store.data.taskStatuses {
+		if key.ProjectID != projectID {
+			continue
+		}
+		workflow.Statuses = append(workflow.Statuses, models.TaskStatusDTO{
+			Key:         key.Key,
+			Name:        status.Name,
+			Category:    status.Category,
+			Position:    status.Position,
+			Transitions: []models.TaskStatus{},
+		})
+	}
+	if len(workflow.Statuses) == 0 {
+		return nil, notFound("project not found")
+	}
+
+	slices.SortFunc(workflow.Statuses, func(a, b models.TaskStatusDTO) int {
+		return a.Position - b.Position
+	})
+	for i := range workflow.Statuses {
+		from := &workflow.Statuses[i]
+		for _, to := range workflow.Statuses {
+			if r.store.data.transitions[taskStatusTransition{ProjectID: projectID, From: from.Key, To: to.Key}] {
+				from.Transitions = append(from.Transitions, to.Key)
+			}
+		}
+	}
+
+	return workflow, nil
+}
+
+func (r *workflowRepository) ReplaceWorkflow(ctx context.Context, projectID uuid.UUID, workflow *models.WorkflowDTO) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	inUse := map[string]int{}
+	for _, t := range r.store.data.tasks {
+		if t.ProjectID == projectID && workflow.Status(t.Status) == nil {
+			inUse[string(t.Status)]++
+		}
+	}
+	if len(inUse) > 0 {
+		details := make(map[string]string, len(inUse))
+		for status, count := range inUse {
+			details[status] = fmt.Sprintf("%d tasks are in this status, move them first", count)
+		}
+		return &apperrors.Error{Kind: apperrors.KindConflict, Message: "statuses that still have tasks can't be removed", Details: details}
+	}
+
+	r.store.deleteWorkflow(projectID)
+	r.store.insertWorkflow(projectID, workflow)
+	return nil
+}
+
+// insertWorkflow must be called with s.mu held
+func (s *Store) insertWorkflow(projectID uuid.UUID, workflow *models.WorkflowDTO) {
+	for _, status := range workflow.Statuses {
+		key := taskStatusKey{ProjectID: projectID, Key: status.Key}
+		s.data.taskStatuses[key] = taskStatus{taskStatusKey: key, Name: status.Name, Category: status.Category, Position: status.Position}
+		for _, to := range status.Transitions {
+			s.data.transitions[taskStatusTransition{ProjectID: projectID, From: status.Key, To: to}] = true
+		}
+	}
+}
+
+// deleteWorkflow must be called with s.mu held
+func (s *Store) deleteWorkflow(projectID uuid.UUID) {
+	for key := range s.data.taskStatuses {
+		if key.ProjectID == projectID {
+			delete(s.data.taskStatuses, key)
+		}
+	}
+	for transition := range s.data.transitions {
+		if transition.ProjectID == projectID {
+			delete(s.data.transitions, transition)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/repositories"
+)
+
+type worklogRepository struct {
+	store *Store
+}
+
+func NewWorklogRepository(store *Store) repositories.WorklogRepository {
+	return &worklogRepository{store: store}
+}
+
+func (r *worklogRepository) CreateWorklog(ctx context.Context, worklogDTO *models.CreateWorklogDTO) (uuid.UUID, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.data.tasks[worklogDTO.TaskID]; !ok {
+		return uuid.Nil, notFound("task not found")
+	}
+
+	now := r.store.now()
+	id := uuid.New()
+	r.store.data.worklogs[id] = worklog{
+		ID:              id,
+		TaskID:          worklogDTO.TaskID,
+		ProjectMemberID: worklogDTO.ProjectMemberID,
+		Duration:        worklogDTO.Duration,
+		Date:            worklogDTO.Date,
+		Note:            worklogDTO.Note,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	return id, nil
+}
+
+// worklogDTO must be called with s.mu held
+func (s *Store) worklogDTO(w worklog) models.WorklogResponseDTO {
+	return models.WorklogResponseDTO{
+		ID:        w.ID,
+		TaskID:    w.TaskID,
+		Author:    s.memberDTO(s.data.projectMembers[w.ProjectMemberID]),
+		Duration:  w.Duration,
+		Date:      w.Date,
+		Note:      w.Note,
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}
+}
+
+func (r *worklogRepository) GetWorklog(ctx context.Context, worklogID uuid.UUID) (*models.WorklogResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	w, ok := r.store.data.worklogs[worklogID]
+	if !ok {
+		return nil, notFound("work log not found")
+	}
+
+	dto := r.store.worklogDTO(w)
+	return &dto, nil
+}
+
+func (r *worklogRepository) GetTaskWorklogs(ctx context.Context, taskID uuid.UUID) ([]models.WorklogResponseDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	worklogs := []models.WorklogResponseDTO{}
+	for _, w := range r.store.data.worklogs {
+		if w.TaskID == taskID {
+			worklogs = append(worklogs, r.store.worklogDTO(w))
+		}
+	}
+	slices.SortFunc(worklogs, func(a, b models.WorklogResponseDTO) int {
+		if c := strings.Compare(b.Date, a.Date); c != 0 {
+			return c
+		}
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	return worklogs, nil
+}
+
+func (r *worklogRepository) UpdateWorklog(ctx context.Context, worklogID uuid.UUID, worklogDTO *models.UpdateWorklogDTO) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	w, ok := r.store.data.worklogs[worklogID]
+	if !ok {
+		return notFound("work log not found")
+	}
+
+	w.Duration = worklogDTO.Duration
+	w.Date = worklogDTO.Date
+	w.Note = worklogDTO.Note
+	w.UpdatedAt = r.store.now()
+	r.store.data.worklogs[worklogID] = w
+
+	return nil
+}
+
+func (r *worklogRepository) DeleteWorklog(ctx context.Context, worklogID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.data.worklogs, worklogID)
+	return nil
+}
+
+func (r *worklogRepository) GetTimeReport(ctx context.Context, projectID uuid.UUID, query *models.TimeReportQuery) (*models.TimeReportDTO, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// Dates in the layout compare like the days they stand for
+	format := func(date *time.Time) *string {
+		if date == nil {
+			return nil
+		}
+		formatted := date.Format(models.WorklogDateLayout)
+		return &formatted
+	}
+	report := &models.TimeReportDTO{From: format(query.From), To: format(query.To)}
+
+	byTask := map[uuid.UUID]int{}
+	byMember := map[uuid.UUID]int{}
+	for _, w := range r.store.data.worklogs {
+		if r.store.data.tasks[w.TaskID].ProjectID != projectID ||
+			(report.From != nil && w.Date < *report.From) || (report.To != nil && w.Date > *report.To) {
+			continue
+		}
+		byTask[w.TaskID] += w.Duration
+		byMember[w.ProjectMemberID] += w.Duration
+		report.Logged += w.Duration
+	}
+
+	report.Tasks = []models.TaskTimeDTO{}
+	for taskID, logged := range byTask {
+		t := r.store.data.tasks[taskID]
+		report.Tasks = append(report.Tasks, models.TaskTimeDTO{
+			Task:              taskReference(t),
+			OriginalEstimate:  t.OriginalEstimate,
+			RemainingEstimate: t.RemainingEstimate,
+			Logged:            logged,
+		})
+	}
+	slices.SortFunc(report.Tasks, func(a, b models.TaskTimeDTO) int {
+		return cmp.Or(b.Logged-a.Logged, a.Task.TaskNumber-b.Task.TaskNumber)
+	})
+
+	report.Members = []models.MemberTimeDTO{}
+	for memberID, logged := range byMember {
+		report.Members = append(report.Members, models.MemberTimeDTO{
+			Member: r.store.memberDTO(r.store.data.projectMembers[memberID]),
+			Logged: logged,
+		})
+	}
+	slices.SortFunc(report.Members, func(a, b models.MemberTimeDTO) int {
+		return cmp.Or(b.Logged-a.Logged, strings.Compare(a.Member.Name, b.Member.Name))
+	})
+
+	return report, nil
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/google/uuid"
@@ -8,10 +9,10 @@ import (
 )
 
 type NotificationRepository interface {
-	CreateNotification(createNotificationDTO models.CreateNotificationDTO) (*models.NotificationResponseDTO, error)
-	GetNotification(notificationID uuid.UUID) (*models.NotificationResponseDTO, error)
-	GetAllNotificationsForUser(userID uuid.UUID) ([]models.NotificationResponseDTO, error)
-	MarkNotificationAsRead(notificationID uuid.UUID) error
+	CreateNotification(ctx context.Context, createNotificationDTO models.CreateNotificationDTO) (*models.NotificationResponseDTO, error)
+	GetNotification(ctx context.Context, notificationID uuid.UUID) (*models.NotificationResponseDTO, error)
+	GetAllNotificationsForUser(ctx context.Context, userID uuid.UUID) ([]models.NotificationResponseDTO, error)
+	MarkNotificationAsRead(ctx context.Context, notificationID uuid.UUID) error
 }
 
 type notificationRepository struct {
@@ -22,7 +23,7 @@ func NewNotificationRepository(db *sql.DB) NotificationRepository {
 	return &notificationRepository{db: db}
 }
 
-func (r *notificationRepository) CreateNotification(createNotificationDTO models.CreateNotificationDTO) (*models.NotificationResponseDTO, error) {
+func (r *notificationRepository) CreateNotification(ctx context.Context, createNotificationDTO models.CreateNotificationDTO) (*models.NotificationResponseDTO, error) {
 	var notification models.NotificationResponseDTO
 
 	queryString := `
@@ -32,7 +33,7 @@ func (r *notificationRepository) CreateNotification(createNotificationDTO models
 		RETURNING id, user_id, type, content, related_project_id, related_task_id, status, created_at
 	`
 
-	err := r.db.QueryRow(
+	err := r.db.QueryRowContext(ctx,
 		queryString,
 		createNotificationDTO.UserID,
 		createNotificationDTO.Type,
@@ -57,7 +58,7 @@ func (r *notificationRepository) CreateNotification(createNotificationDTO models
 	return &notification, nil
 }
 
-func (r *notificationRepository) GetAllNotificationsForUser(userID uuid.UUID) ([]models.NotificationResponseDTO, error) {
+func (r *notificationRepository) GetAllNotificationsForUser(ctx context.Context, userID uuid.UUID) ([]models.NotificationResponseDTO, error) {
 	notifications := []models.NotificationResponseDTO{}
 
 	queryString := `
@@ -80,7 +81,7 @@ func (r *notificationRepository) GetAllNotificationsForUser(userID uuid.UUID) ([
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.Query(queryString, userID)
+	rows, err := r.db.QueryContext(ctx, queryString, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -111,7 +112,7 @@ func (r *notificationRepository) GetAllNotificationsForUser(userID uuid.UUID) ([
 	return notifications, nil
 }
 
-func (r *notificationRepository) GetNotification(notificationID uuid.UUID) (*models.NotificationResponseDTO, error) {
+func (r *notificationRepository) GetNotification(ctx context.Context, notificationID uuid.UUID) (*models.NotificationResponseDTO, error) {
 
 	var notification models.NotificationResponseDTO
 
@@ -122,7 +123,7 @@ func (r *notificationRepository) GetNotification(notificationID uuid.UUID) (*mod
 		ORDER BY created_at DESC
 	`
 
-	if err := r.db.QueryRow(queryString, notificationID).Scan(
+	if err := r.db.QueryRowContext(ctx, queryString, notificationID).Scan(
 		&notification.ID,
 		&notification.UserID,
 		&notification.Type,
@@ -132,20 +133,20 @@ func (r *notificationRepository) GetNotification(notificationID uuid.UUID) (*mod
 		&notification.Status,
 		&notification.CreatedAt,
 	); err != nil {
-		return nil, err
+		return nil, notFound(err, "notification not found")
 	}
 
 	return &notification, nil
 }
 
-func (r *notificationRepository) MarkNotificationAsRead(notificationID uuid.UUID) error {
+func (r *notificationRepository) MarkNotificationAsRead(ctx context.Context, notificationID uuid.UUID) error {
 	queryString := `
 		UPDATE notifications
 		SET status = $1
 		WHERE id = $2
 	`
 
-	_, err := r.db.Exec(queryString, models.NotificationStatusRead, notificationID)
+	_, err := r.db.ExecContext(ctx, queryString, models.NotificationStatusRead, notificationID)
 	if err != nil {
 		return err
 	}
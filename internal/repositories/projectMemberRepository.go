@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/google/uuid"
@@ -8,14 +9,16 @@ import (
 )
 
 type ProjectMemberRepository interface {
-	CreateProjectMember(*models.CreateProjectMemberDTO) error
-	CreateProjectMemberTx(*sql.Tx, *models.CreateProjectMemberDTO) (uuid.UUID, error)
-	GetUserID(projectMemberID uuid.UUID) (uuid.UUID, error)
-	GetProjectMemberID(uuid.UUID, uuid.UUID) (uuid.UUID, error)
-	GetProjectMember(uuid.UUID) (*models.ProjectMemberResponseDTO, error)
-	UpdateProjectMemberStatus(uuid.UUID, models.ProjectMemberStatus) error
-	UpdateProjectMemberRole(projectMemberID uuid.UUID, newRole models.ProjectMemberRole) error
-	GetProjectMemberByUserID(userID uuid.UUID, projectID uuid.UUID) (*models.ProjectMemberResponseDTO, error)
+	CreateProjectMember(context.Context, *models.CreateProjectMemberDTO) error
+	CreateProjectMemberTx(context.Context, Tx, *models.CreateProjectMemberDTO) (uuid.UUID, error)
+	GetUserID(ctx context.Context, projectMemberID uuid.UUID) (uuid.UUID, error)
+	GetProjectMemberID(context.Context, uuid.UUID, uuid.UUID) (uuid.UUID, error)
+	GetProjectMember(context.Context, uuid.UUID) (*models.ProjectMemberResponseDTO, error)
+	UpdateProjectMemberStatus(context.Context, uuid.UUID, models.ProjectMemberStatus) error
+	UpdateProjectMemberRole(ctx context.Context, projectMemberID uuid.UUID, newRole models.ProjectMemberRole) error
+	GetProjectMemberByUserID(ctx context.Context, userID uuid.UUID, projectID uuid.UUID) (*models.ProjectMemberResponseDTO, error)
+	// GetActiveUserIDs returns the user IDs of the project's active members
+	GetActiveUserIDs(ctx context.Context, projectID uuid.UUID) ([]uuid.UUID, error)
 }
 
 type projectMemberRepository struct {
@@ -26,37 +29,37 @@ func NewProjectMemberRepository(db *sql.DB) ProjectMemberRepository {
 	return &projectMemberRepository{db: db}
 }
 
-func (r *projectMemberRepository) CreateProjectMember(projectMember *models.CreateProjectMemberDTO) error {
+func (r *projectMemberRepository) CreateProjectMember(ctx context.Context, projectMember *models.CreateProjectMemberDTO) error {
 
 	queryString := `INSERT INTO project_members (user_id, project_id, role) VALUES ($1, $2, $3) RETURNING id`
 
 	var newID uuid.UUID
 
-	err := r.db.QueryRow(queryString, projectMember.UserID, projectMember.ProjectID, projectMember.Role).Scan(&newID)
+	err := r.db.QueryRowContext(ctx, queryString, projectMember.UserID, projectMember.ProjectID, projectMember.Role).Scan(&newID)
 
 	if err != nil {
-		return err
+		return conflict(err, "already a project member")
 	}
 
 	return nil
 }
 
-func (r *projectMemberRepository) CreateProjectMemberTx(tx *sql.Tx, projectMember *models.CreateProjectMemberDTO) (uuid.UUID, error) {
+func (r *projectMemberRepository) CreateProjectMemberTx(ctx context.Context, tx Tx, projectMember *models.CreateProjectMemberDTO) (uuid.UUID, error) {
 
 	queryString := `INSERT INTO project_members (user_id, project_id, role) VALUES ($1, $2, $3) RETURNING id`
 
 	var newID uuid.UUID
 
-	err := tx.QueryRow(queryString, projectMember.UserID, projectMember.ProjectID, projectMember.Role).Scan(&newID)
+	err := tx.QueryRowContext(ctx, queryString, projectMember.UserID, projectMember.ProjectID, projectMember.Role).Scan(&newID)
 
 	if err != nil {
-		return uuid.Nil, err
+		return uuid.Nil, conflict(err, "already a project member")
 	}
 
 	return newID, nil
 }
 
-func (r *projectMemberRepository) GetProjectMemberByUserID(userID uuid.UUID, projectID uuid.UUID) (*models.ProjectMemberResponseDTO, error) {
+func (r *projectMemberRepository) GetProjectMemberByUserID(ctx context.Context, userID uuid.UUID, projectID uuid.UUID) (*models.ProjectMemberResponseDTO, error) {
 
 	var projectMember models.ProjectMemberResponseDTO
 
@@ -66,7 +69,7 @@ func (r *projectMemberRepository) GetProjectMemberByUserID(userID uuid.UUID, pro
 		WHERE user_id = $1 AND project_id = $2
 	`
 
-	err := r.db.QueryRow(queryString, userID, projectID).Scan(
+	err := r.db.QueryRowContext(ctx, queryString, userID, projectID).Scan(
 		&projectMember.ID,
 		&projectMember.UserID,
 		&projectMember.ProjectID,
@@ -75,13 +78,13 @@ func (r *projectMemberRepository) GetProjectMemberByUserID(userID uuid.UUID, pro
 		&projectMember.Status)
 
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, "project member not found")
 	}
 
 	return &projectMember, nil
 }
 
-func (r *projectMemberRepository) GetProjectMember(projectMemberID uuid.UUID) (*models.ProjectMemberResponseDTO, error) {
+func (r *projectMemberRepository) GetProjectMember(ctx context.Context, projectMemberID uuid.UUID) (*models.ProjectMemberResponseDTO, error) {
 
 	var projectMember models.ProjectMemberResponseDTO
 
@@ -91,7 +94,7 @@ func (r *projectMemberRepository) GetProjectMember(projectMemberID uuid.UUID) (*
 		WHERE id = $1
 	`
 
-	err := r.db.QueryRow(queryString, projectMemberID).Scan(
+	err := r.db.QueryRowContext(ctx, queryString, projectMemberID).Scan(
 		&projectMember.ID,
 		&projectMember.UserID,
 		&projectMember.ProjectID,
@@ -100,13 +103,37 @@ func (r *projectMemberRepository) GetProjectMember(projectMemberID uuid.UUID) (*
 		&projectMember.Status)
 
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, "project member not found")
 	}
 
 	return &projectMember, nil
 }
 
-func (r *projectMemberRepository) GetUserID(projectMemberID uuid.UUID) (uuid.UUID, error) {
+func (r *projectMemberRepository) GetActiveUserIDs(ctx context.Context, projectID uuid.UUID) ([]uuid.UUID, error) {
+	queryString := `
+		SELECT user_id FROM project_members
+		WHERE project_id = $1 AND status = $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, queryString, projectID, models.StatusActive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []uuid.UUID
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, rows.Err()
+}
+
+func (r *projectMemberRepository) GetUserID(ctx context.Context, projectMemberID uuid.UUID) (uuid.UUID, error) {
 
 	var userID uuid.UUID
 
@@ -115,17 +142,17 @@ func (r *projectMemberRepository) GetUserID(projectMemberID uuid.UUID) (uuid.UUI
 		WHERE id = $1
 	`
 
-	err := r.db.QueryRow(queryString, projectMemberID).Scan(&userID)
+	err := r.db.QueryRowContext(ctx, queryString, projectMemberID).Scan(&userID)
 
 	if err != nil {
-		return uuid.Nil, err
+		return uuid.Nil, notFound(err, "project member not found")
 	}
 
 	return userID, nil
 
 }
 
-func (r *projectMemberRepository) GetProjectMemberID(userID uuid.UUID, projectID uuid.UUID) (uuid.UUID, error) {
+func (r *projectMemberRepository) GetProjectMemberID(ctx context.Context, userID uuid.UUID, projectID uuid.UUID) (uuid.UUID, error) {
 
 	var projectMemberID uuid.UUID
 
@@ -134,16 +161,16 @@ func (r *projectMemberRepository) GetProjectMemberID(userID uuid.UUID, projectID
 		WHERE user_id = $1 AND project_id = $2
 	`
 
-	err := r.db.QueryRow(queryString, userID, projectID).Scan(&projectMemberID)
+	err := r.db.QueryRowContext(ctx, queryString, userID, projectID).Scan(&projectMemberID)
 
 	if err != nil {
-		return uuid.Nil, err
+		return uuid.Nil, notFound(err, "project member not found")
 	}
 
 	return projectMemberID, nil
 }
 
-func (r *projectMemberRepository) UpdateProjectMemberStatus(projectMemberID uuid.UUID, newStatus models.ProjectMemberStatus) error {
+func (r *projectMemberRepository) UpdateProjectMemberStatus(ctx context.Context, projectMemberID uuid.UUID, newStatus models.ProjectMemberStatus) error {
 
 	queryString := `
 		UPDATE project_members
@@ -151,7 +178,7 @@ func (r *projectMemberRepository) UpdateProjectMemberStatus(projectMemberID uuid
 		WHERE id = $2
 	`
 
-	_, err := r.db.Exec(queryString, newStatus, projectMemberID)
+	_, err := r.db.ExecContext(ctx, queryString, newStatus, projectMemberID)
 	if err != nil {
 		return err
 	}
@@ -159,7 +186,7 @@ func (r *projectMemberRepository) UpdateProjectMemberStatus(projectMemberID uuid
 	return nil
 }
 
-func (r *projectMemberRepository) UpdateProjectMemberRole(projectMemberID uuid.UUID, newRole models.ProjectMemberRole) error {
+func (r *projectMemberRepository) UpdateProjectMemberRole(ctx context.Context, projectMemberID uuid.UUID, newRole models.ProjectMemberRole) error {
 
 	queryString := `
 		UPDATE project_members
@@ -167,7 +194,7 @@ func (r *projectMemberRepository) UpdateProjectMemberRole(projectMemberID uuid.U
 		WHERE id = $2
 	`
 
-	_, err := r.db.Exec(queryString, newRole, projectMemberID)
+	_, err := r.db.ExecContext(ctx, queryString, newRole, projectMemberID)
 	if err != nil {
 		return err
 	}
@@ -1,23 +1,26 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
-	"errors"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
 	"github.com/sarvochcha01/enlace-backend/internal/models"
 )
 
 type ProjectRepository interface {
-	BeginTransaction() (*sql.Tx, error)
-	CreateProject(*sql.Tx, *models.CreateProjectDTO) (uuid.UUID, error)
-	GetAllProjectsForUser(uuid.UUID) ([]models.ProjectResponseDTO, error)
-	EditProject(uuid.UUID, *models.EditProjectDTO) error
-	DeleteProject(projectID uuid.UUID) error
-
-	GetProjectByID(uuid.UUID) (*models.ProjectResponseDTO, error)
-	GetProjectName(uuid.UUID) (string, error)
-	GetProjectCreatorID(projectID uuid.UUID) (uuid.UUID, error)
+	BeginTransaction(ctx context.Context) (Tx, error)
+	CreateProject(context.Context, Tx, *models.CreateProjectDTO) (uuid.UUID, error)
+	GetAllProjectsForUser(context.Context, uuid.UUID) ([]models.ProjectResponseDTO, error)
+	// EditProject fails as a precondition, without writing, if the DTO's IfMatch doesn't match the project
+	EditProject(context.Context, uuid.UUID, *models.EditProjectDTO) error
+	DeleteProject(ctx context.Context, projectID uuid.UUID) error
+
+	GetProjectByID(context.Context, uuid.UUID) (*models.ProjectResponseDTO, error)
+	GetProjectName(context.Context, uuid.UUID) (string, error)
+	GetProjectCreatorID(ctx context.Context, projectID uuid.UUID) (uuid.UUID, error)
 	// JoinProject(userID uuid.UUID, projectID uuid.UUID) error
 }
 
@@ -29,18 +32,22 @@ func NewProjectRepository(db *sql.DB) ProjectRepository {
 	return &projectRepository{db: db}
 }
 
-func (r *projectRepository) BeginTransaction() (*sql.Tx, error) {
-	return r.db.Begin()
+func (r *projectRepository) BeginTransaction(ctx context.Context) (Tx, error) {
+	return r.db.BeginTx(ctx, nil)
 }
 
-func (r *projectRepository) CreateProject(tx *sql.Tx, projectDTO *models.CreateProjectDTO) (uuid.UUID, error) {
+func (r *projectRepository) CreateProject(ctx context.Context, tx Tx, projectDTO *models.CreateProjectDTO) (uuid.UUID, error) {
 
 	queryString := `INSERT INTO projects (name, description, key, created_by) VALUES ($1, $2, $3, $4) RETURNING id`
 
 	var newProjectID uuid.UUID
-	err := tx.QueryRow(queryString, projectDTO.Name, projectDTO.Description, projectDTO.Key, projectDTO.CreatedBy).Scan(&newProjectID)
+	err := tx.QueryRowContext(ctx, queryString, projectDTO.Name, projectDTO.Description, projectDTO.Key, projectDTO.CreatedBy).Scan(&newProjectID)
 
 	if err != nil {
+		return uuid.Nil, conflict(err, "a project with this key already exists")
+	}
+
+	if err := insertWorkflow(ctx, tx, newProjectID, models.DefaultWorkflow()); err != nil {
 		return uuid.Nil, err
 	}
 
@@ -48,7 +55,7 @@ func (r *projectRepository) CreateProject(tx *sql.Tx, projectDTO *models.CreateP
 
 }
 
-func (r *projectRepository) GetProjectByID(projectID uuid.UUID) (*models.ProjectResponseDTO, error) {
+func (r *projectRepository) GetProjectByID(ctx context.Context, projectID uuid.UUID) (*models.ProjectResponseDTO, error) {
 	var projectDTO models.ProjectResponseDTO
 
 	// Query basic project information with creator details
@@ -58,7 +65,7 @@ func (r *projectRepository) GetProjectByID(projectID uuid.UUID) (*models.Project
         JOIN users u ON p.created_by = u.id
         WHERE p.id = $1
     `
-	err := r.db.QueryRow(queryString, projectID).Scan(
+	err := r.db.QueryRowContext(ctx, queryString, projectID).Scan(
 		&projectDTO.ID,
 		&projectDTO.Name,
 		&projectDTO.Description,
@@ -69,7 +76,7 @@ func (r *projectRepository) GetProjectByID(projectID uuid.UUID) (*models.Project
 		&projectDTO.CreatedAt,
 		&projectDTO.UpdatedAt)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, "project not found")
 	}
 
 	// Query project members
@@ -81,7 +88,7 @@ func (r *projectRepository) GetProjectByID(projectID uuid.UUID) (*models.Project
         WHERE pm.project_id = $1
 		AND pm.status = 'active'
     `
-	rows, err := r.db.Query(membersQuery, projectID)
+	rows, err := r.db.QueryContext(ctx, membersQuery, projectID)
 	if err != nil {
 		return nil, err
 	}
@@ -107,7 +114,7 @@ func (r *projectRepository) GetProjectByID(projectID uuid.UUID) (*models.Project
 		LEFT JOIN users u ON u.id = i.invited_user_id
 		WHERE project_id = $1
 	`
-	invitationRows, err := r.db.Query(invitationsQuery, projectID)
+	invitationRows, err := r.db.QueryContext(ctx, invitationsQuery, projectID)
 	if err != nil {
 		return nil, err
 	}
@@ -126,149 +133,30 @@ func (r *projectRepository) GetProjectByID(projectID uuid.UUID) (*models.Project
 
 	// Query tasks with creator, updater, and assignee details
 	projectDTO.Tasks = []models.TaskResponseDTO{}
-	tasksQuery := `
-        SELECT t.id, t.project_id, t.task_number, t.title, t.description, t.status, t.priority, t.due_date, t.created_at, t.updated_at,
-               -- Created by details
-               cb_pm.id, cb_u.id, cb_u.name, cb_u.email, cb_pm.role, cb_pm.joined_at,
-               -- Updated by details
-               ub_pm.id, ub_u.id, ub_u.name, ub_u.email, ub_pm.role, ub_pm.joined_at,
-               -- Assigned to details
-               at_pm.id, at_u.id, at_u.name, at_u.email, at_pm.role, at_pm.joined_at
-        FROM tasks t
-        LEFT JOIN project_members cb_pm ON t.created_by = cb_pm.id 
-        LEFT JOIN users cb_u ON cb_pm.user_id = cb_u.id
-        LEFT JOIN project_members ub_pm ON t.updated_by = ub_pm.id
-        LEFT JOIN users ub_u ON ub_pm.user_id = ub_u.id
-        LEFT JOIN project_members at_pm ON t.assigned_to = at_pm.id
-        LEFT JOIN users at_u ON at_pm.user_id = at_u.id
-        WHERE t.project_id = $1
-        ORDER BY t.task_number ASC
-    `
-
-	taskRows, err := r.db.Query(tasksQuery, projectID)
+	taskRows, err := r.db.QueryContext(ctx, taskSelect+"WHERE t.project_id = $1\nORDER BY ts.position, t.rank, t.task_number", projectID)
 	if err != nil {
 		return nil, err
 	}
 	defer taskRows.Close()
 
 	for taskRows.Next() {
-		var task models.TaskResponseDTO
-
-		// Variables for nullable project member fields
-		var createdByID, createdByUserID, createdByName, createdByEmail, createdByRole, createdByJoined sql.NullString
-		var updatedByID, updatedByUserID, updatedByName, updatedByEmail, updatedByRole, updatedByJoined sql.NullString
-		var assignedToID, assignedToUserID, assignedToName, assignedToEmail, assignedToRole, assignedToJoined sql.NullString
-
-		err := taskRows.Scan(
-			&task.ID,
-			&task.ProjectID,
-			&task.TaskNumber,
-			&task.Title,
-			&task.Description,
-			&task.Status,
-			&task.Priority,
-			&task.DueDate,
-			&task.CreatedAt,
-			&task.UpdatedAt,
-			// Created by fields
-			&createdByID,
-			&createdByUserID,
-			&createdByName,
-			&createdByEmail,
-			&createdByRole,
-			&createdByJoined,
-			// Updated by fields
-			&updatedByID,
-			&updatedByUserID,
-			&updatedByName,
-			&updatedByEmail,
-			&updatedByRole,
-			&updatedByJoined,
-			// Assigned to fields
-			&assignedToID,
-			&assignedToUserID,
-			&assignedToName,
-			&assignedToEmail,
-			&assignedToRole,
-			&assignedToJoined,
-		)
+		task, err := scanTask(taskRows)
 		if err != nil {
 			return nil, err
 		}
-
-		// Set CreatedBy if not null
-		if createdByID.Valid {
-			var createdByUUID, createdByUserUUID uuid.UUID
-			if err := createdByUUID.Scan(createdByID.String); err != nil {
-				return nil, err
-			}
-			if err := createdByUserUUID.Scan(createdByUserID.String); err != nil {
-				return nil, err
-			}
-
-			task.CreatedBy = models.ProjectMemberResponseDTO{
-				ID:       createdByUUID,
-				UserID:   createdByUserUUID,
-				Name:     createdByName.String,
-				Email:    createdByEmail.String,
-				Role:     models.ProjectMemberRole(createdByRole.String),
-				JoinedAt: createdByJoined.String,
-			}
-		}
-
-		// Set UpdatedBy if not null
-		if updatedByID.Valid {
-			var updatedByUUID, updatedByUserUUID uuid.UUID
-			if err := updatedByUUID.Scan(updatedByID.String); err != nil {
-				return nil, err
-			}
-			if err := updatedByUserUUID.Scan(updatedByUserID.String); err != nil {
-				return nil, err
-			}
-
-			task.UpdatedBy = models.ProjectMemberResponseDTO{
-				ID:       updatedByUUID,
-				UserID:   updatedByUserUUID,
-				Name:     updatedByName.String,
-				Email:    updatedByEmail.String,
-				Role:     models.ProjectMemberRole(updatedByRole.String),
-				JoinedAt: updatedByJoined.String,
-			}
-		}
-
-		task.AssignedTo = nil
-
-		// Set AssignedTo if not null
-		if assignedToID.Valid {
-			var assignedToUUID, assignedToUserUUID uuid.UUID
-			if err := assignedToUUID.Scan(assignedToID.String); err != nil {
-				return nil, err
-			}
-			if err := assignedToUserUUID.Scan(assignedToUserID.String); err != nil {
-				return nil, err
-			}
-
-			task.AssignedTo = &models.ProjectMemberResponseDTO{
-				ID:       assignedToUUID,
-				UserID:   assignedToUserUUID,
-				Name:     assignedToName.String,
-				Email:    assignedToEmail.String,
-				Role:     models.ProjectMemberRole(assignedToRole.String),
-				JoinedAt: assignedToJoined.String,
-			}
-		}
-
-		projectDTO.Tasks = append(projectDTO.Tasks, task)
+		projectDTO.Tasks = append(projectDTO.Tasks, *task)
 	}
 
 	if err := taskRows.Err(); err != nil {
 		return nil, err
 	}
 
+	projectDTO.CountTasks()
+
 	return &projectDTO, nil
 }
 
-func (r *projectRepository) GetAllProjectsForUser(userID uuid.UUID) ([]models.ProjectResponseDTO, error) {
+func (r *projectRepository) GetAllProjectsForUser(ctx context.Context, userID uuid.UUID) ([]models.ProjectResponseDTO, error) {
 	var projects []models.ProjectResponseDTO
 
 	queryString := `
@@ -283,15 +171,18 @@ func (r *projectRepository) GetAllProjectsForUser(userID uuid.UUID) ([]models.Pr
 			p.created_at, 
 			p.updated_at, 
 			COUNT(t.id) as total_tasks, 
-			COUNT(CASE WHEN t.status = 'completed' THEN 1 END) AS completed_tasks,
+			COUNT(CASE WHEN ts.category = 'done' THEN 1 END) AS completed_tasks,
+			COUNT(t.parent_id) AS total_subtasks,
+			COUNT(CASE WHEN t.parent_id IS NOT NULL AND ts.category = 'done' THEN 1 END) AS completed_subtasks,
 			COUNT(CASE 
-            WHEN t.status IN ('todo', 'in-progress') AND assigned_pm.user_id = $1 
+            WHEN ts.category <> 'done' AND assigned_pm.user_id = $1 
             THEN 1 
         END) AS active_tasks_assigned_to_user
         FROM projects p
         JOIN project_members pm ON p.id = pm.project_id
 		JOIN users u ON p.created_by = u.id											
 		LEFT JOIN tasks t ON t.project_id = p.id
+		LEFT JOIN task_statuses ts ON ts.project_id = t.project_id AND ts.key = t.status
 		LEFT JOIN project_members assigned_pm ON t.assigned_to = assigned_pm.id
         WHERE pm.user_id = $1
 		AND pm.status = 'active'
@@ -300,7 +191,7 @@ func (r *projectRepository) GetAllProjectsForUser(userID uuid.UUID) ([]models.Pr
             u.id, u.name, u.email
     `
 
-	rows, err := r.db.Query(queryString, userID)
+	rows, err := r.db.QueryContext(ctx, queryString, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -310,10 +201,11 @@ func (r *projectRepository) GetAllProjectsForUser(userID uuid.UUID) ([]models.Pr
 	for rows.Next() {
 		var project models.ProjectResponseDTO
 		var createdBy models.UserResponseDTO
-		err := rows.Scan(&project.ID, &project.Name, &project.Description, &project.Key, &createdBy.ID, &createdBy.Name, &createdBy.Email, &project.CreatedAt, &project.UpdatedAt, &project.TotalTasks, &project.TasksCompleted, &project.ActiveTasksAssignedToUserCount)
+		err := rows.Scan(&project.ID, &project.Name, &project.Description, &project.Key, &createdBy.ID, &createdBy.Name, &createdBy.Email, &project.CreatedAt, &project.UpdatedAt, &project.TotalTasks, &project.TasksCompleted, &project.TotalSubtasks, &project.SubtasksCompleted, &project.ActiveTasksAssignedToUserCount)
 		if err != nil {
 			return nil, err
 		}
+		project.CompletionPercentage = models.CompletionPercentage(project.TasksCompleted, project.TotalTasks)
 		project.CreatedBy = createdBy
 		projects = append(projects, project)
 	}
@@ -323,30 +215,30 @@ func (r *projectRepository) GetAllProjectsForUser(userID uuid.UUID) ([]models.Pr
 	return projects, nil
 }
 
-func (r *projectRepository) DeleteProject(projectID uuid.UUID) error {
+func (r *projectRepository) DeleteProject(ctx context.Context, projectID uuid.UUID) error {
 	querystring := `
 		DELETE FROM projects WHERE id = $1
 	`
-	_, err := r.db.Exec(querystring, projectID)
+	_, err := r.db.ExecContext(ctx, querystring, projectID)
 
 	return err
 }
 
-func (r *projectRepository) GetProjectName(projectID uuid.UUID) (string, error) {
+func (r *projectRepository) GetProjectName(ctx context.Context, projectID uuid.UUID) (string, error) {
 	queryString := `
 		SELECT name FROM projects WHERE id = $1
 	`
 
 	var name string
-	err := r.db.QueryRow(queryString, projectID).Scan(&name)
+	err := r.db.QueryRowContext(ctx, queryString, projectID).Scan(&name)
 	if err != nil {
-		return "", err
+		return "", notFound(err, "project not found")
 	}
 
 	return name, nil
 }
 
-func (r *projectRepository) GetProjectCreatorID(projectID uuid.UUID) (uuid.UUID, error) {
+func (r *projectRepository) GetProjectCreatorID(ctx context.Context, projectID uuid.UUID) (uuid.UUID, error) {
 
 	var creatorID uuid.UUID
 	queryString := `
@@ -355,14 +247,29 @@ func (r *projectRepository) GetProjectCreatorID(projectID uuid.UUID) (uuid.UUID,
 		WHERE id = $1
 	`
 
-	if err := r.db.QueryRow(queryString, projectID).Scan(&creatorID); err != nil {
-		return uuid.Nil, err
+	if err := r.db.QueryRowContext(ctx, queryString, projectID).Scan(&creatorID); err != nil {
+		return uuid.Nil, notFound(err, "project not found")
 	}
 
 	return creatorID, nil
 }
 
-func (r *projectRepository) EditProject(projectID uuid.UUID, projectDTO *models.EditProjectDTO) error {
+func (r *projectRepository) EditProject(ctx context.Context, projectID uuid.UUID, projectDTO *models.EditProjectDTO) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The row lock keeps the project from changing between the If-Match check and the update
+	var updatedAt time.Time
+	if err := tx.QueryRowContext(ctx, `SELECT updated_at FROM projects WHERE id = $1 FOR UPDATE`, projectID).Scan(&updatedAt); err != nil {
+		return notFound(err, "project not found")
+	}
+	if !projectDTO.IfMatch.Matches(models.ETag(updatedAt)) {
+		return apperrors.PreconditionFailed("the project has changed since it was read")
+	}
+
 	queryString := `
 		UPDATE projects
 		SET name = $1,
@@ -370,18 +277,9 @@ func (r *projectRepository) EditProject(projectID uuid.UUID, projectDTO *models.
 		WHERE id = $3
 	`
 
-	result, err := r.db.Exec(queryString, projectDTO.Name, projectDTO.Description, projectID)
-	if err != nil {
-		return err
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
+	if _, err := tx.ExecContext(ctx, queryString, projectDTO.Name, projectDTO.Description, projectID); err != nil {
 		return err
 	}
-	if rowsAffected == 0 {
-		return errors.New("project not found")
-	}
 
-	return nil
+	return tx.Commit()
 }
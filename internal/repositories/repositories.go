@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Tx is the part of *sql.Tx that services and repositories rely on. Keeping it an interface lets the
+// in-memory repositories hand out transactions of their own.
+type Tx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	Commit() error
+	Rollback() error
+}
+
+// Pinger reports whether the store behind the repositories is reachable
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Repositories bundles every repository the routes are wired with
+type Repositories struct {
+	DB             Pinger
+	Users          UserRepository
+	Projects       ProjectRepository
+	ProjectMembers ProjectMemberRepository
+	Tasks          TaskRepository
+	TaskLinks      TaskLinkRepository
+	Labels         LabelRepository
+	Workflows      WorkflowRepository
+	Sprints        SprintRepository
+	Comments       CommentRepository
+	Worklogs       WorklogRepository
+	Invitations    InvitationRepository
+	Notifications  NotificationRepository
+	Dashboard      DashboardRepository
+}
+
+// New returns the Postgres backed repositories
+func New(db *sql.DB) Repositories {
+	return Repositories{
+		DB:             db,
+		Users:          NewUserRepository(db),
+		Projects:       NewProjectRepository(db),
+		ProjectMembers: NewProjectMemberRepository(db),
+		Tasks:          NewTaskRepository(db),
+		TaskLinks:      NewTaskLinkRepository(db),
+		Labels:         NewLabelRepository(db),
+		Workflows:      NewWorkflowRepository(db),
+		Sprints:        NewSprintRepository(db),
+		Comments:       NewCommentRepository(db),
+		Worklogs:       NewWorklogRepository(db),
+		Invitations:    NewInvitationRepository(db),
+		Notifications:  NewNotificationRepository(db),
+		Dashboard:      NewDashboardRepository(db),
+	}
+}
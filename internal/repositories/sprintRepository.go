@@ -0,0 +1,295 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+)
+
+type SprintRepository interface {
+	CreateSprint(ctx context.Context, sprintDTO *models.CreateSprintDTO) (uuid.UUID, error)
+	GetSprint(ctx context.Context, sprintID uuid.UUID) (*models.SprintResponseDTO, error)
+	// GetSprints returns the project's sprints by start date
+	GetSprints(ctx context.Context, projectID uuid.UUID) ([]models.SprintResponseDTO, error)
+	// GetClosedSprints returns the project's closed sprints in the order they closed
+	GetClosedSprints(ctx context.Context, projectID uuid.UUID) ([]models.SprintResponseDTO, error)
+	UpdateSprint(ctx context.Context, sprintID uuid.UUID, sprintDTO *models.UpdateSprintDTO) error
+	DeleteSprint(ctx context.Context, sprintID uuid.UUID) error
+	// StartSprint makes a planned sprint active and records the story points committed to it. A
+	// project has one active sprint at a time, so another one is a conflict.
+	StartSprint(ctx context.Context, sprintID uuid.UUID) error
+	// CloseSprint closes an active sprint, recording its completed and carried over story points.
+	// Its unfinished tasks move to the sprint carryOverTo, or to the backlog when it is nil, with
+	// the move recorded in their activity. It returns the tasks that moved. carryOverTo must be a
+	// planned sprint of the same project, which is checked along with the close.
+	CloseSprint(ctx context.Context, sprintID uuid.UUID, carryOverTo *uuid.UUID, closedBy uuid.UUID) ([]models.TaskReferenceDTO, error)
+	// GetSprintTaskPoints returns the story points and completion times of the sprint's tasks
+	GetSprintTaskPoints(ctx context.Context, sprintID uuid.UUID) ([]models.SprintTaskPoints, error)
+}
+
+type sprintRepository struct {
+	db *sql.DB
+}
+
+func NewSprintRepository(db *sql.DB) SprintRepository {
+	return &sprintRepository{db: db}
+}
+
+func (r *sprintRepository) CreateSprint(ctx context.Context, sprintDTO *models.CreateSprintDTO) (uuid.UUID, error) {
+	queryString := `
+		INSERT INTO sprints (project_id, name, goal, start_date, end_date, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	var sprintID uuid.UUID
+	err := r.db.QueryRowContext(ctx, queryString, sprintDTO.ProjectID, sprintDTO.Name, sprintDTO.Goal, sprintDTO.StartDate, sprintDTO.EndDate,
+		sprintDTO.CreatedBy).Scan(&sprintID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return sprintID, nil
+}
+
+// sprintSelect loads a sprint with the count and story points of its tasks, in the column order
+// scanSprint expects
+const sprintSelect = `
+	SELECT s.id, s.project_id, s.name, s.goal, to_char(s.start_date, 'YYYY-MM-DD'), to_char(s.end_date, 'YYYY-MM-DD'), s.state,
+	       st.tasks, st.points, st.done_points,
+	       s.committed_points, s.completed_points, s.carried_over_points, s.started_at, s.closed_at, s.created_at, s.updated_at
+	FROM sprints s
+	LEFT JOIN LATERAL (
+	    SELECT COUNT(*) AS tasks, COALESCE(SUM(t.story_points), 0) AS points,
+	           COALESCE(SUM(t.story_points) FILTER (WHERE ts.category = 'done'), 0) AS done_points
+	    FROM tasks t
+	    INNER JOIN task_statuses ts ON ts.project_id = t.project_id AND ts.key = t.status
+	    WHERE t.sprint_id = s.id
+	) st ON TRUE
+`
+
+func scanSprint(row rowScanner) (*models.SprintResponseDTO, error) {
+	var sprint models.SprintResponseDTO
+	err := row.Scan(&sprint.ID, &sprint.ProjectID, &sprint.Name, &sprint.Goal, &sprint.StartDate, &sprint.EndDate, &sprint.State,
+		&sprint.TaskCount, &sprint.Points, &sprint.DonePoints,
+		&sprint.CommittedPoints, &sprint.CompletedPoints, &sprint.CarriedOverPoints, &sprint.StartedAt, &sprint.ClosedAt, &sprint.CreatedAt, &sprint.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &sprint, nil
+}
+
+func (r *sprintRepository) GetSprint(ctx context.Context, sprintID uuid.UUID) (*models.SprintResponseDTO, error) {
+	sprint, err := scanSprint(r.db.QueryRowContext(ctx, sprintSelect+"WHERE s.id = $1", sprintID))
+	if err != nil {
+		return nil, notFound(err, "sprint not found")
+	}
+
+	return sprint, nil
+}
+
+func (r *sprintRepository) GetSprints(ctx context.Context, projectID uuid.UUID) ([]models.SprintResponseDTO, error) {
+	return r.querySprints(ctx, sprintSelect+"WHERE s.project_id = $1\nORDER BY s.start_date, s.created_at", projectID)
+}
+
+func (r *sprintRepository) GetClosedSprints(ctx context.Context, projectID uuid.UUID) ([]models.SprintResponseDTO, error) {
+	return r.querySprints(ctx, sprintSelect+"WHERE s.project_id = $1 AND s.state = 'closed'\nORDER BY s.closed_at", projectID)
+}
+
+func (r *sprintRepository) querySprints(ctx context.Context, queryString string, args ...any) ([]models.SprintResponseDTO, error) {
+	rows, err := r.db.QueryContext(ctx, queryString, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sprints := []models.SprintResponseDTO{}
+	for rows.Next() {
+		sprint, err := scanSprint(rows)
+		if err != nil {
+			return nil, err
+		}
+		sprints = append(sprints, *sprint)
+	}
+
+	return sprints, rows.Err()
+}
+
+func (r *sprintRepository) UpdateSprint(ctx context.Context, sprintID uuid.UUID, sprintDTO *models.UpdateSprintDTO) error {
+	queryString := `
+		UPDATE sprints
+		SET name = $2, goal = $3, start_date = $4, end_date = $5
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, queryString, sprintID, sprintDTO.Name, sprintDTO.Goal, sprintDTO.StartDate, sprintDTO.EndDate)
+	if err != nil {
+		return err
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return apperrors.NotFound("sprint not found")
+	}
+
+	return nil
+}
+
+func (r *sprintRepository) DeleteSprint(ctx context.Context, sprintID uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM sprints WHERE id = $1`, sprintID); err != nil {
+		return fmt.Errorf("failed to delete sprint: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sprintRepository) StartSprint(ctx context.Context, sprintID uuid.UUID) error {
+	queryString := `
+		UPDATE sprints
+		SET state = 'active', started_at = NOW(),
+		    committed_points = (SELECT COALESCE(SUM(story_points), 0) FROM tasks WHERE sprint_id = $1)
+		WHERE id = $1 AND state = 'planned'
+	`
+
+	result, err := r.db.ExecContext(ctx, queryString, sprintID)
+	if err != nil {
+		return conflict(err, "the project already has an active sprint, close it first")
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return apperrors.Conflict("only a planned sprint can be started")
+	}
+
+	return nil
+}
+
+func (r *sprintRepository) CloseSprint(ctx context.Context, sprintID uuid.UUID, carryOverTo *uuid.UUID, closedBy uuid.UUID) ([]models.TaskReferenceDTO, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Closing the same sprint twice at once must not carry its tasks over twice
+	var projectID uuid.UUID
+	var state models.SprintState
+	if err := tx.QueryRowContext(ctx, `SELECT project_id, state FROM sprints WHERE id = $1 FOR UPDATE`, sprintID).Scan(&projectID, &state); err != nil {
+		return nil, notFound(err, "sprint not found")
+	}
+	if state != models.SprintActive {
+		return nil, apperrors.Conflict("only an active sprint can be closed")
+	}
+
+	// The target is locked too, so it can't start or be deleted while the tasks move into it
+	if carryOverTo != nil {
+		var targetProjectID uuid.UUID
+		var targetState models.SprintState
+		err := tx.QueryRowContext(ctx, `SELECT project_id, state FROM sprints WHERE id = $1 FOR UPDATE`, *carryOverTo).Scan(&targetProjectID, &targetState)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		if err != nil || targetProjectID != projectID || targetState != models.SprintPlanned {
+			return nil, apperrors.Validation("invalid sprint close", map[string]string{"carryOverTo": "must be a planned sprint of this project"})
+		}
+	}
+
+	var completedPoints int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(t.story_points), 0)
+		FROM tasks t
+		INNER JOIN task_statuses ts ON ts.project_id = t.project_id AND ts.key = t.status
+		WHERE t.sprint_id = $1 AND ts.category = 'done'
+	`, sprintID).Scan(&completedPoints)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT t.id, t.task_number, t.title, t.status, COALESCE(t.story_points, 0)
+		FROM tasks t
+		INNER JOIN task_statuses ts ON ts.project_id = t.project_id AND ts.key = t.status
+		WHERE t.sprint_id = $1 AND ts.category <> 'done'
+		ORDER BY t.task_number
+		FOR UPDATE OF t
+	`, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	carriedOver := []models.TaskReferenceDTO{}
+	carriedOverIDs := []uuid.UUID{}
+	carriedOverPoints := 0
+	for rows.Next() {
+		var task models.TaskReferenceDTO
+		var points int
+		if err := rows.Scan(&task.ID, &task.TaskNumber, &task.Title, &task.Status, &points); err != nil {
+			return nil, err
+		}
+		carriedOver = append(carriedOver, task)
+		carriedOverIDs = append(carriedOverIDs, task.ID)
+		carriedOverPoints += points
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(carriedOverIDs) > 0 {
+		_, err := tx.ExecContext(ctx, `UPDATE tasks SET sprint_id = $2, updated_by = $3 WHERE id = ANY($1)`, pq.Array(carriedOverIDs), carryOverTo, closedBy)
+		if err != nil {
+			return nil, err
+		}
+
+		var newValue *string
+		if carryOverTo != nil {
+			sprint := carryOverTo.String()
+			newValue = &sprint
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO task_activity (task_id, actor_id, field, old_value, new_value)
+			SELECT id, $2, $3, $4, $5 FROM unnest($1::uuid[]) AS id
+		`, pq.Array(carriedOverIDs), closedBy, models.TaskFieldSprintID, sprintID.String(), newValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record task activity: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE sprints
+		SET state = 'closed', closed_at = NOW(), completed_points = $2, carried_over_points = $3
+		WHERE id = $1
+	`, sprintID, completedPoints, carriedOverPoints)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return carriedOver, nil
+}
+
+func (r *sprintRepository) GetSprintTaskPoints(ctx context.Context, sprintID uuid.UUID) ([]models.SprintTaskPoints, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT COALESCE(story_points, 0), completed_at FROM tasks WHERE sprint_id = $1`, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []models.SprintTaskPoints
+	for rows.Next() {
+		var task models.SprintTaskPoints
+		if err := rows.Scan(&task.Points, &task.CompletedAt); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, rows.Err()
+}
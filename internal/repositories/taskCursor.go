@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+)
+
+const (
+	DefaultTaskPageSize = 50
+	MaxTaskPageSize     = 200
+)
+
+// Tasks without a due date sort after every dated task in either direction, so they stand in for
+// the latest possible date ascending and the earliest descending
+var (
+	undatedLast  = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	undatedFirst = time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// TaskListOrder returns sort with the task number appended as tiebreaker, which makes the order
+// total within a project as keyset pagination requires
+func TaskListOrder(sort []models.TaskSort) []models.TaskSort {
+	order := make([]models.TaskSort, 0, len(sort)+1)
+	for _, s := range sort {
+		order = append(order, s)
+		if s.Field == models.TaskSortNumber {
+			return order
+		}
+	}
+	return append(order, models.TaskSort{Field: models.TaskSortNumber})
+}
+
+// TaskSortValues returns the values a task is ordered by: ints for number, status (its position
+// in the workflow), priority and rank, lower case strings for the title and times for dates
+func TaskSortValues(task *models.TaskResponseDTO, order []models.TaskSort) []any {
+	values := make([]any, len(order))
+	for i, s := range order {
+		switch s.Field {
+		case models.TaskSortNumber:
+			values[i] = task.TaskNumber
+		case models.TaskSortTitle:
+			values[i] = strings.ToLower(task.Title)
+		case models.TaskSortStatus:
+			values[i] = task.StatusPosition
+		case models.TaskSortPriority:
+			values[i] = task.Priority.Rank()
+		case models.TaskSortDueDate:
+			values[i] = dueDateSortValue(task.DueDate, s.Descending)
+		case models.TaskSortCreatedAt:
+			values[i] = task.CreatedAt.UTC()
+		case models.TaskSortUpdatedAt:
+			values[i] = task.UpdatedAt.UTC()
+		case models.TaskSortRank:
+			values[i] = task.Rank
+		}
+	}
+	return values
+}
+
+func dueDateSortValue(dueDate *time.Time, descending bool) time.Time {
+	switch {
+	case dueDate != nil:
+		return dueDate.UTC()
+	case descending:
+		return undatedFirst
+	default:
+		return undatedLast
+	}
+}
+
+// taskCursor records the sort it was made for so that it can't be replayed against another order
+type taskCursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+func sortSpec(order []models.TaskSort) string {
+	parts := make([]string, len(order))
+	for i, s := range order {
+		parts[i] = s.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// EncodeTaskCursor returns the cursor for the page that starts after task
+func EncodeTaskCursor(task *models.TaskResponseDTO, order []models.TaskSort) string {
+	values := TaskSortValues(task, order)
+
+	cursor := taskCursor{Sort: sortSpec(order), Values: make([]string, len(values))}
+	for i, value := range values {
+		switch v := value.(type) {
+		case int:
+			cursor.Values[i] = strconv.Itoa(v)
+		case string:
+			cursor.Values[i] = v
+		case time.Time:
+			cursor.Values[i] = v.Format(time.RFC3339Nano)
+		}
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeTaskCursor returns the sort values stored in cursor, typed as TaskSortValues returns them
+func DecodeTaskCursor(encoded string, order []models.TaskSort) ([]any, error) {
+	invalid := apperrors.Validation("invalid cursor", map[string]string{"cursor": "not a cursor returned by this endpoint"})
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, invalid.Wrap(err)
+	}
+
+	var cursor taskCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, invalid.Wrap(err)
+	}
+
+	if cursor.Sort != sortSpec(order) || len(cursor.Values) != len(order) {
+		return nil, apperrors.Validation("invalid cursor", map[string]string{"cursor": "the cursor was made for a different sort order"})
+	}
+
+	values := make([]any, len(order))
+	for i, s := range order {
+		raw := cursor.Values[i]
+		switch s.Field {
+		case models.TaskSortNumber, models.TaskSortStatus, models.TaskSortPriority, models.TaskSortRank:
+			values[i], err = strconv.Atoi(raw)
+		case models.TaskSortTitle:
+			values[i] = raw
+		default:
+			values[i], err = time.Parse(time.RFC3339Nano, raw)
+		}
+		if err != nil {
+			return nil, invalid.Wrap(fmt.Errorf("sort key %s: %w", s.Field, err))
+		}
+	}
+
+	return values, nil
+}
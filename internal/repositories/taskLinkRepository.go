@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+)
+
+type TaskLinkRepository interface {
+	// CreateTaskLink stores a link between two tasks of the project, whose Type must be one of the
+	// stored types. A blocks or duplicates link that would close a cycle of links of its type fails
+	// validation; the check and the insert are one step, so concurrent links can't form one either.
+	CreateTaskLink(ctx context.Context, projectID uuid.UUID, linkDTO *models.CreateTaskLinkDTO) (uuid.UUID, error)
+	// GetTaskLink returns a link as seen from taskID, which must be one of its two tasks
+	GetTaskLink(ctx context.Context, taskID uuid.UUID, linkID uuid.UUID) (*models.TaskLinkResponseDTO, error)
+	// GetTaskLinks returns every link of the task in either direction, as seen from the task, oldest first
+	GetTaskLinks(ctx context.Context, taskID uuid.UUID) ([]models.TaskLinkResponseDTO, error)
+	DeleteTaskLink(ctx context.Context, linkID uuid.UUID) error
+}
+
+type taskLinkRepository struct {
+	db *sql.DB
+}
+
+func NewTaskLinkRepository(db *sql.DB) TaskLinkRepository {
+	return &taskLinkRepository{db: db}
+}
+
+func (r *taskLinkRepository) CreateTaskLink(ctx context.Context, projectID uuid.UUID, linkDTO *models.CreateTaskLinkDTO) (uuid.UUID, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback()
+
+	// Locking the project makes its links get created one at a time, so two links that would each
+	// close the other's cycle can't both pass the check. NO KEY UPDATE leaves task inserts alone.
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM projects WHERE id = $1 FOR NO KEY UPDATE`, projectID); err != nil {
+		return uuid.Nil, err
+	}
+
+	if linkDTO.Type != models.TaskLinkRelatesTo {
+		cycle, err := linkPathExists(ctx, tx, linkDTO.TargetTaskID, linkDTO.SourceTaskID, linkDTO.Type)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if cycle {
+			return uuid.Nil, apperrors.Validation("invalid task link", map[string]string{"taskId": "the link would create a cycle of " + string(linkDTO.Type) + " links"})
+		}
+	}
+
+	queryString := `
+		INSERT INTO task_links (source_task_id, target_task_id, type, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	var linkID uuid.UUID
+	err = tx.QueryRowContext(ctx, queryString, linkDTO.SourceTaskID, linkDTO.TargetTaskID, linkDTO.Type, linkDTO.CreatedBy).Scan(&linkID)
+	if err != nil {
+		return uuid.Nil, conflict(err, "these tasks are already linked this way")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, err
+	}
+
+	return linkID, nil
+}
+
+// taskLinkSelect reads links from the side of the task in $1, joined to the task at the other end
+const taskLinkSelect = `
+	SELECT l.id, l.type, l.source_task_id = $1, o.id, o.task_number, o.title, o.status, l.created_by, l.created_at
+	FROM task_links l
+	INNER JOIN tasks o ON o.id = CASE WHEN l.source_task_id = $1 THEN l.target_task_id ELSE l.source_task_id END
+	WHERE (l.source_task_id = $1 OR l.target_task_id = $1)
+`
+
+func scanTaskLink(row rowScanner) (*models.TaskLinkResponseDTO, error) {
+	var link models.TaskLinkResponseDTO
+	var outgoing bool
+
+	err := row.Scan(&link.ID, &link.Type, &outgoing, &link.Task.ID, &link.Task.TaskNumber, &link.Task.Title, &link.Task.Status, &link.CreatedBy, &link.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if !outgoing {
+		link.Type = link.Type.Inverse()
+	}
+
+	return &link, nil
+}
+
+func (r *taskLinkRepository) GetTaskLink(ctx context.Context, taskID uuid.UUID, linkID uuid.UUID) (*models.TaskLinkResponseDTO, error) {
+	link, err := scanTaskLink(r.db.QueryRowContext(ctx, taskLinkSelect+"AND l.id = $2", taskID, linkID))
+	if err != nil {
+		return nil, notFound(err, "task link not found")
+	}
+
+	return link, nil
+}
+
+func (r *taskLinkRepository) GetTaskLinks(ctx context.Context, taskID uuid.UUID) ([]models.TaskLinkResponseDTO, error) {
+	rows, err := r.db.QueryContext(ctx, taskLinkSelect+"ORDER BY l.created_at, l.id", taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []models.TaskLinkResponseDTO{}
+	for rows.Next() {
+		link, err := scanTaskLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, *link)
+	}
+
+	return links, rows.Err()
+}
+
+// linkPathExists reports whether following links of linkType from fromTaskID leads to toTaskID
+func linkPathExists(ctx context.Context, tx *sql.Tx, fromTaskID uuid.UUID, toTaskID uuid.UUID, linkType models.TaskLinkType) (bool, error) {
+	// UNION stops at tasks already reached, so the walk ends even if the graph has a cycle
+	queryString := `
+		WITH RECURSIVE reachable (id) AS (
+			SELECT target_task_id FROM task_links WHERE source_task_id = $1 AND type = $3
+			UNION
+			SELECT l.target_task_id FROM task_links l INNER JOIN reachable r ON l.source_task_id = r.id WHERE l.type = $3
+		)
+		SELECT EXISTS (SELECT 1 FROM reachable WHERE id = $2)
+	`
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, queryString, fromTaskID, toTaskID, linkType).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *taskLinkRepository) DeleteTaskLink(ctx context.Context, linkID uuid.UUID) error {
+	queryString := `
+		DELETE FROM task_links
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, queryString, linkID)
+	if err != nil {
+		return fmt.Errorf("failed to delete task link: %w", err)
+	}
+
+	return nil
+}
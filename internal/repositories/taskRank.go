@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+)
+
+// TaskRankStep is the gap left between neighbouring tasks, so that most moves fit between two ranks
+// without touching any other task
+const TaskRankStep = 1 << 16
+
+// RankedTask is a task's place in a board column
+type RankedTask struct {
+	ID   uuid.UUID
+	Rank int
+}
+
+// PlaceTask returns the rank of a task moved into column, which is in board order and doesn't
+// include the task. When there is no gap left where the task goes, every task of the column
+// including the moved one is renumbered and the new ranks are returned in rebalanced.
+func PlaceTask(column []RankedTask, taskID uuid.UUID, move *models.MoveTaskDTO) (rank int, rebalanced []RankedTask, err error) {
+	find := func(field string, neighbourID uuid.UUID) (int, error) {
+		i := slices.IndexFunc(column, func(t RankedTask) bool { return t.ID == neighbourID })
+		if i < 0 {
+			return 0, apperrors.Validation("invalid move", map[string]string{field: "must be another task in the target status"})
+		}
+		return i, nil
+	}
+
+	// at is the index in column the task is inserted before
+	at := len(column)
+	switch {
+	case move.AfterTaskID != nil:
+		after, err := find("afterTaskId", *move.AfterTaskID)
+		if err != nil {
+			return 0, nil, err
+		}
+		at = after + 1
+		if move.BeforeTaskID != nil && (at == len(column) || column[at].ID != *move.BeforeTaskID) {
+			if _, err := find("beforeTaskId", *move.BeforeTaskID); err != nil {
+				return 0, nil, err
+			}
+			return 0, nil, apperrors.Conflict("the neighbours are no longer next to each other, reload the board and try again")
+		}
+	case move.BeforeTaskID != nil:
+		if at, err = find("beforeTaskId", *move.BeforeTaskID); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	switch {
+	case len(column) == 0:
+		return TaskRankStep, nil, nil
+	case at == 0:
+		return column[0].Rank - TaskRankStep, nil, nil
+	case at == len(column):
+		return column[at-1].Rank + TaskRankStep, nil, nil
+	case column[at].Rank-column[at-1].Rank >= 2:
+		return column[at-1].Rank + (column[at].Rank-column[at-1].Rank)/2, nil, nil
+	}
+
+	rebalanced = make([]RankedTask, 0, len(column)+1)
+	rebalanced = append(rebalanced, column[:at]...)
+	rebalanced = append(rebalanced, RankedTask{ID: taskID})
+	rebalanced = append(rebalanced, column[at:]...)
+	for i := range rebalanced {
+		rebalanced[i].Rank = (i + 1) * TaskRankStep
+	}
+	return (at + 1) * TaskRankStep, rebalanced, nil
+}
@@ -1,19 +1,71 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
-	"log"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
 	"github.com/sarvochcha01/enlace-backend/internal/models"
 )
 
 type TaskRepository interface {
-	CreateTask(*models.CreateTaskDTO) (uuid.UUID, error)
-	GetFullTaskByID(uuid.UUID) (*models.TaskResponseDTO, error)
-	EditTask(uuid.UUID, *models.UpdateTaskDTO) error
-	DeleteTask(uuid.UUID) error
+	BeginTransaction(ctx context.Context) (Tx, error)
+	CreateTask(context.Context, *models.CreateTaskDTO) (uuid.UUID, error)
+	GetFullTaskByID(context.Context, uuid.UUID) (*models.TaskResponseDTO, error)
+	// GetTaskByKey finds a task by its project's key and its number, e.g. APO and 42 for APO-42
+	GetTaskByKey(ctx context.Context, projectKey string, taskNumber int) (*models.TaskResponseDTO, error)
+	// ResolveTaskKeys looks up task keys in the projects userID is an active member of, keys that
+	// don't resolve are left out of the result
+	ResolveTaskKeys(ctx context.Context, userID uuid.UUID, keys []string) (map[string]models.TaskKeyLinkDTO, error)
+	// LockTasks locks those of the tasks that belong to the project until tx ends, along with the
+	// project so that no links can be added to them meanwhile, and returns them as they are now
+	LockTasks(ctx context.Context, tx Tx, projectID uuid.UUID, taskIDs []uuid.UUID) (map[uuid.UUID]*models.TaskResponseDTO, error)
+	// ListTasks returns one page of a project's tasks matching query
+	ListTasks(ctx context.Context, projectID uuid.UUID, query *models.TaskListQuery) (*models.TaskListResponseDTO, error)
+	// PatchTask writes the fields of patch that differ from the stored task, plus updated_by, and
+	// records each of them in the task's activity history in the same transaction. It returns
+	// the changes, none if the patch matched the task and nothing was written. A patch whose
+	// IfMatch doesn't match the task fails as a precondition and writes nothing.
+	PatchTask(ctx context.Context, taskID uuid.UUID, updatedBy uuid.UUID, patch *models.PatchTaskDTO) ([]models.TaskFieldChange, error)
+	// PatchTasks is PatchTask for each of the tasks within tx, it returns the changes by task
+	PatchTasks(ctx context.Context, tx Tx, taskIDs []uuid.UUID, updatedBy uuid.UUID, patch *models.PatchTaskDTO) (map[uuid.UUID][]models.TaskFieldChange, error)
+	// MoveTask puts the task, which must still be in status from, into move.Status at the place move
+	// asks for within tx, recording a status change in the task's activity. It reports whether the
+	// column had to be renumbered to make room.
+	MoveTask(ctx context.Context, tx Tx, taskID uuid.UUID, updatedBy uuid.UUID, from models.TaskStatus, move *models.MoveTaskDTO) (rebalanced bool, err error)
+	// SetTaskRecurrence makes the task the first occurrence of a series repeating by rule or, if it
+	// already belongs to a series, changes that series' rule
+	SetTaskRecurrence(ctx context.Context, taskID uuid.UUID, rule string, createdBy uuid.UUID) error
+	// StopTaskRecurrence deletes the series the task belongs to. Its tasks stay, no longer recurring.
+	StopTaskRecurrence(ctx context.Context, taskID uuid.UUID) error
+	// AdvanceTaskRecurrence moves a series on from its current occurrence fromTaskID: it creates
+	// next with the labels of fromTaskID and makes it the current occurrence, or ends the series
+	// when next is nil. It returns the new task's ID, uuid.Nil if there is none, and does nothing
+	// when fromTaskID is no longer the current occurrence.
+	AdvanceTaskRecurrence(ctx context.Context, recurrenceID uuid.UUID, fromTaskID uuid.UUID, next *models.CreateTaskDTO) (uuid.UUID, error)
+	// AdvanceTaskRecurrenceTx is AdvanceTaskRecurrence within tx
+	AdvanceTaskRecurrenceTx(ctx context.Context, tx Tx, recurrenceID uuid.UUID, fromTaskID uuid.UUID, next *models.CreateTaskDTO) (uuid.UUID, error)
+	// GetTaskAncestors returns the IDs of the task's parent, its parent's parent and so on, in no particular order
+	GetTaskAncestors(ctx context.Context, taskID uuid.UUID) ([]uuid.UUID, error)
+	// GetTaskActivity returns the task's field changes, oldest first
+	GetTaskActivity(ctx context.Context, taskID uuid.UUID) ([]models.TaskActivityResponseDTO, error)
+	DeleteTask(context.Context, uuid.UUID) error
+	// DeleteTasks deletes the tasks within tx
+	DeleteTasks(ctx context.Context, tx Tx, taskIDs []uuid.UUID) error
+	// WatchTask makes the member a watcher of the task, which they may already be. Creators and
+	// assignees become watchers on their own when the task is created or assigned.
+	WatchTask(ctx context.Context, taskID uuid.UUID, projectMemberID uuid.UUID) error
+	// UnwatchTask stops the member watching the task, if they were
+	UnwatchTask(ctx context.Context, taskID uuid.UUID, projectMemberID uuid.UUID) error
+	// GetTaskWatchers returns the members watching the task by name
+	GetTaskWatchers(ctx context.Context, taskID uuid.UUID) ([]models.ProjectMemberResponseDTO, error)
 }
 
 type taskRepository struct {
@@ -24,37 +76,88 @@ func NewTaskRepository(db *sql.DB) TaskRepository {
 	return &taskRepository{db: db}
 }
 
-func (r *taskRepository) CreateTask(taskDTO *models.CreateTaskDTO) (uuid.UUID, error) {
+func (r *taskRepository) BeginTransaction(ctx context.Context) (Tx, error) {
+	return r.db.BeginTx(ctx, nil)
+}
+
+func (r *taskRepository) CreateTask(ctx context.Context, taskDTO *models.CreateTaskDTO) (uuid.UUID, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback()
+
+	taskID, err := insertTask(ctx, tx, taskDTO)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if taskDTO.Recurrence != nil {
+		if err := createRecurrence(ctx, tx, taskID, *taskDTO.Recurrence, taskDTO.CreatedBy); err != nil {
+			return uuid.Nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, err
+	}
+
+	return taskID, nil
+}
+
+// insertTask adds a task at the bottom of its status column, watched by its creator and assignee,
+// and returns its ID
+func insertTask(ctx context.Context, tx Tx, taskDTO *models.CreateTaskDTO) (uuid.UUID, error) {
 	queryString := `
 	INSERT INTO tasks 
-	(project_id, created_by, updated_by, assigned_to, title, description, status, priority, due_date) 
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) 
+	(project_id, created_by, updated_by, assigned_to, title, description, status, priority, due_date, parent_id, recurrence_id,
+	 original_estimate, remaining_estimate, story_points, sprint_id, rank, completed_at) 
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, (SELECT COALESCE(MAX(rank), 0) + $16 FROM tasks WHERE project_id = $1 AND status = $7),
+	 CASE WHEN (SELECT category FROM task_statuses WHERE project_id = $1 AND key = $7) = 'done' THEN NOW() END) 
 	RETURNING id
 	`
 
 	var taskID uuid.UUID
 
-	err := r.db.QueryRow(queryString, taskDTO.ProjectID, taskDTO.CreatedBy, taskDTO.UpdatedBy, taskDTO.AssignedTo,
-		taskDTO.Title, taskDTO.Description, taskDTO.Status, taskDTO.Priority, taskDTO.DueDate,
+	err := tx.QueryRowContext(ctx, queryString, taskDTO.ProjectID, taskDTO.CreatedBy, taskDTO.UpdatedBy, taskDTO.AssignedTo,
+		taskDTO.Title, taskDTO.Description, taskDTO.Status, taskDTO.Priority, taskDTO.DueDate, taskDTO.ParentID, taskDTO.RecurrenceID,
+		taskDTO.OriginalEstimate, taskDTO.RemainingEstimate, taskDTO.StoryPoints, taskDTO.SprintID, TaskRankStep,
 	).Scan(&taskID)
 
 	if err != nil {
-		log.Println("Failed to insert task:", err)
 		return uuid.Nil, err
 	}
 
-	return taskID, nil
+	watchers := []uuid.UUID{taskDTO.CreatedBy}
+	if taskDTO.AssignedTo != nil {
+		watchers = append(watchers, *taskDTO.AssignedTo)
+	}
+	for _, projectMemberID := range watchers {
+		if _, err := tx.ExecContext(ctx, watchTaskQuery, taskID, projectMemberID); err != nil {
+			return uuid.Nil, fmt.Errorf("failed to add task watcher: %w", err)
+		}
+	}
 
+	return taskID, nil
 }
 
-func (r *taskRepository) GetFullTaskByID(taskID uuid.UUID) (*models.TaskResponseDTO, error) {
-	var task models.TaskResponseDTO
-	var assignedToID, assignedToUserID sql.NullString
-	var assignedToName, assignedToEmail, assignedToRole sql.NullString
-	var assignedToJoinedAt sql.NullString
+// watchTaskQuery adds a watcher to a task unless they already watch it
+const watchTaskQuery = `
+	INSERT INTO task_watchers (task_id, project_member_id)
+	VALUES ($1, $2)
+	ON CONFLICT DO NOTHING
+`
 
-	queryString := `
-        SELECT t.id, t.project_id, t.task_number, t.title, t.description, t.status, t.priority, t.due_date, t.created_at, t.updated_at,
+// taskSelect loads a task with the project it belongs to, the members who created, last updated
+// and are assigned to it, its subtask counts, open blockers, labels and where its status sits in
+// the workflow, the recurring series it belongs to, the time logged on it and its sprint, in the
+// column order scanTask expects
+const taskSelect = `
+        SELECT t.id, t.project_id, p.key, p.name, t.task_number, t.title, t.description, t.status, t.priority, t.due_date, t.created_at, t.updated_at,
+               t.parent_id, st.total, st.completed, ob.blockers, ` + taskLabelsColumn + `, ts.category, ts.position, t.rank,
+               rc.id, rc.rule, rc.occurrences, rc.current_task_id,
+               t.original_estimate, t.remaining_estimate, (SELECT COALESCE(SUM(w.duration), 0) FROM worklogs w WHERE w.task_id = t.id),
+               t.story_points, t.sprint_id, t.completed_at,
                -- Created by details
                cb_pm.id, cb_u.id, cb_u.name, cb_u.email, cb_pm.role, cb_pm.joined_at,
                -- Updated by details
@@ -62,18 +165,58 @@ func (r *taskRepository) GetFullTaskByID(taskID uuid.UUID) (*models.TaskResponse
                -- Assigned to details (might be NULL)
                at_pm.id, at_u.id, at_u.name, at_u.email, at_pm.role, at_pm.joined_at
         FROM tasks t
+        INNER JOIN projects p ON t.project_id = p.id
+        INNER JOIN task_statuses ts ON ts.project_id = t.project_id AND ts.key = t.status
         LEFT JOIN project_members cb_pm ON t.created_by = cb_pm.id
         LEFT JOIN users cb_u ON cb_pm.user_id = cb_u.id
         LEFT JOIN project_members ub_pm ON t.updated_by = ub_pm.id
         LEFT JOIN users ub_u ON ub_pm.user_id = ub_u.id
         LEFT JOIN project_members at_pm ON t.assigned_to = at_pm.id
         LEFT JOIN users at_u ON at_pm.user_id = at_u.id
-        WHERE t.id = $1
-    `
+        LEFT JOIN task_recurrences rc ON t.recurrence_id = rc.id
+        LEFT JOIN LATERAL (
+            SELECT COUNT(*) AS total, COUNT(*) FILTER (WHERE ss.category = 'done') AS completed
+            FROM tasks s
+            INNER JOIN task_statuses ss ON ss.project_id = s.project_id AND ss.key = s.status
+            WHERE s.parent_id = t.id
+        ) st ON TRUE
+        LEFT JOIN LATERAL (
+            SELECT COALESCE(json_agg(json_build_object('id', b.id, 'taskNumber', b.task_number, 'title', b.title, 'status', b.status) ORDER BY b.task_number), '[]') AS blockers
+            FROM task_links l
+            INNER JOIN tasks b ON l.source_task_id = b.id
+            INNER JOIN task_statuses bs ON bs.project_id = b.project_id AND bs.key = b.status
+            WHERE l.target_task_id = t.id AND l.type = 'blocks' AND bs.category <> 'done'
+        ) ob ON TRUE
+`
+
+// taskLabelsColumn selects the labels of task t as a JSON array sorted by name
+const taskLabelsColumn = `COALESCE((
+            SELECT json_agg(json_build_object('id', l.id, 'projectId', l.project_id, 'name', l.name, 'color', l.color) ORDER BY LOWER(l.name))
+            FROM task_labels tl
+            INNER JOIN labels l ON tl.label_id = l.id
+            WHERE tl.task_id = t.id
+        ), '[]')`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
 
-	err := r.db.QueryRow(queryString, taskID).Scan(
+func scanTask(row rowScanner) (*models.TaskResponseDTO, error) {
+	var task models.TaskResponseDTO
+	var assignedToID, assignedToUserID uuid.NullUUID
+	var assignedToName, assignedToEmail, assignedToRole sql.NullString
+	var assignedToJoinedAt sql.NullString
+	var openBlockers, labels []byte
+	var recurrenceID, recurrenceCurrentTaskID uuid.NullUUID
+	var recurrenceRule sql.NullString
+	var recurrenceOccurrences sql.NullInt64
+
+	err := row.Scan(
 		&task.ID,
 		&task.ProjectID,
+		&task.ProjectKey,
+		&task.ProjectName,
 		&task.TaskNumber,
 		&task.Title,
 		&task.Description,
@@ -82,6 +225,27 @@ func (r *taskRepository) GetFullTaskByID(taskID uuid.UUID) (*models.TaskResponse
 		&task.DueDate,
 		&task.CreatedAt,
 		&task.UpdatedAt,
+		&task.ParentID,
+		&task.TotalSubtasks,
+		&task.SubtasksCompleted,
+		&openBlockers,
+		&labels,
+		&task.StatusCategory,
+		&task.StatusPosition,
+		&task.Rank,
+		// Recurring series (might be NULL)
+		&recurrenceID,
+		&recurrenceRule,
+		&recurrenceOccurrences,
+		&recurrenceCurrentTaskID,
+		// Time tracking
+		&task.OriginalEstimate,
+		&task.RemainingEstimate,
+		&task.TimeLogged,
+		// Planning
+		&task.StoryPoints,
+		&task.SprintID,
+		&task.CompletedAt,
 		// Created by
 		&task.CreatedBy.ID,
 		&task.CreatedBy.UserID,
@@ -104,65 +268,768 @@ func (r *taskRepository) GetFullTaskByID(taskID uuid.UUID) (*models.TaskResponse
 		&assignedToRole,
 		&assignedToJoinedAt,
 	)
-
 	if err != nil {
-		return nil, fmt.Errorf("error fetching task: %w", err)
+		return nil, err
 	}
 
 	if assignedToID.Valid {
-		var assignedToUUID, assignedToUserUUID uuid.UUID
-
-		if err := assignedToUUID.Scan(assignedToID.String); err != nil {
-			return nil, fmt.Errorf("error converting assigned_to ID: %w", err)
-		}
-
-		if err := assignedToUserUUID.Scan(assignedToUserID.String); err != nil {
-			return nil, fmt.Errorf("error converting assigned_to user ID: %w", err)
-		}
-
 		task.AssignedTo = &models.ProjectMemberResponseDTO{
-			ID:       assignedToUUID,
-			UserID:   assignedToUserUUID,
+			ID:       assignedToID.UUID,
+			UserID:   assignedToUserID.UUID,
 			Name:     assignedToName.String,
 			Email:    assignedToEmail.String,
 			Role:     models.ProjectMemberRole(assignedToRole.String),
 			JoinedAt: assignedToJoinedAt.String,
 		}
-	} else {
-		task.AssignedTo = nil
+		task.AssignedToName = assignedToName.String
+	}
+	if recurrenceID.Valid {
+		task.Recurrence = &models.TaskRecurrenceDTO{
+			ID:          recurrenceID.UUID,
+			Rule:        recurrenceRule.String,
+			Occurrences: int(recurrenceOccurrences.Int64),
+		}
+		if recurrenceCurrentTaskID.Valid {
+			task.Recurrence.CurrentTaskID = &recurrenceCurrentTaskID.UUID
+		}
+	}
+	task.CompletionPercentage = models.CompletionPercentage(task.SubtasksCompleted, task.TotalSubtasks)
+
+	if err := json.Unmarshal(openBlockers, &task.OpenBlockers); err != nil {
+		return nil, fmt.Errorf("failed to read open blockers: %w", err)
+	}
+	if err := json.Unmarshal(labels, &task.Labels); err != nil {
+		return nil, fmt.Errorf("failed to read labels: %w", err)
 	}
 
 	return &task, nil
 }
 
-func (r *taskRepository) EditTask(taskID uuid.UUID, updateTaskDTO *models.UpdateTaskDTO) error {
+func (r *taskRepository) GetFullTaskByID(ctx context.Context, taskID uuid.UUID) (*models.TaskResponseDTO, error) {
+	task, err := scanTask(r.db.QueryRowContext(ctx, taskSelect+"WHERE t.id = $1", taskID))
+	if err != nil {
+		return nil, notFound(err, "task not found")
+	}
+
+	return task, nil
+}
+
+func (r *taskRepository) LockTasks(ctx context.Context, tx Tx, projectID uuid.UUID, taskIDs []uuid.UUID) (map[uuid.UUID]*models.TaskResponseDTO, error) {
+	// NO KEY UPDATE, like creating a link, which it waits for, while tasks can still be created
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM projects WHERE id = $1 FOR NO KEY UPDATE`, projectID); err != nil {
+		return nil, err
+	}
+
+	// Locking in ID order keeps two operations on overlapping tasks from deadlocking
+	_, err := tx.ExecContext(ctx, `SELECT id FROM tasks WHERE id = ANY($1) AND project_id = $2 ORDER BY id FOR UPDATE`, pq.Array(taskIDs), projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, taskSelect+"WHERE t.id = ANY($1) AND t.project_id = $2", pq.Array(taskIDs), projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := make(map[uuid.UUID]*models.TaskResponseDTO, len(taskIDs))
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks[task.ID] = task
+	}
+
+	return tasks, rows.Err()
+}
+
+func (r *taskRepository) GetTaskByKey(ctx context.Context, projectKey string, taskNumber int) (*models.TaskResponseDTO, error) {
+	task, err := scanTask(r.db.QueryRowContext(ctx, taskSelect+"WHERE p.key = $1 AND t.task_number = $2", projectKey, taskNumber))
+	if err != nil {
+		return nil, notFound(err, "task not found")
+	}
+
+	return task, nil
+}
+
+func (r *taskRepository) ResolveTaskKeys(ctx context.Context, userID uuid.UUID, keys []string) (map[string]models.TaskKeyLinkDTO, error) {
+	links := map[string]models.TaskKeyLinkDTO{}
+	if len(keys) == 0 {
+		return links, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT p.key || '-' || t.task_number, t.id, t.project_id, t.title
+		FROM tasks t
+		INNER JOIN projects p ON p.id = t.project_id
+		INNER JOIN project_members pm ON pm.project_id = p.id AND pm.user_id = $1 AND pm.status = 'active'
+		WHERE p.key || '-' || t.task_number = ANY($2)
+	`, userID, pq.Array(keys))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var link models.TaskKeyLinkDTO
+		if err := rows.Scan(&link.Key, &link.TaskID, &link.ProjectID, &link.Title); err != nil {
+			return nil, err
+		}
+		links[link.Key] = link
+	}
+
+	return links, rows.Err()
+}
+
+func (r *taskRepository) ListTasks(ctx context.Context, projectID uuid.UUID, query *models.TaskListQuery) (*models.TaskListResponseDTO, error) {
+	order := TaskListOrder(query.Sort)
+
+	var args []any
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := []string{"t.project_id = " + arg(projectID)}
+	conditions = append(conditions, taskFilterConditions(&query.Filter, arg)...)
+
+	if query.Cursor != "" {
+		values, err := DecodeTaskCursor(query.Cursor, order)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, taskKeysetCondition(order, values, arg))
+	}
+
+	orderBy := make([]string, len(order))
+	for i, s := range order {
+		orderBy[i] = taskSortExpression(s)
+		if s.Descending {
+			orderBy[i] += " DESC"
+		}
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultTaskPageSize
+	}
+
+	// One extra row tells whether there is a next page
+	queryString := taskSelect + "WHERE " + strings.Join(conditions, " AND ") +
+		"\nORDER BY " + strings.Join(orderBy, ", ") +
+		"\nLIMIT " + arg(limit+1)
+
+	rows, err := r.db.QueryContext(ctx, queryString, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &models.TaskListResponseDTO{Tasks: []models.TaskResponseDTO{}}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Tasks = append(page.Tasks, *task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Tasks) > limit {
+		page.Tasks = page.Tasks[:limit]
+		next := EncodeTaskCursor(&page.Tasks[limit-1], order)
+		page.NextCursor = &next
+	}
+
+	return page, nil
+}
+
+func taskFilterConditions(filter *models.TaskFilter, arg func(any) string) []string {
+	var conditions []string
+
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			statuses[i] = string(status)
+		}
+		conditions = append(conditions, "t.status = ANY("+arg(pq.Array(statuses))+")")
+	}
+
+	if len(filter.Priorities) > 0 {
+		priorities := make([]string, len(filter.Priorities))
+		for i, priority := range filter.Priorities {
+			priorities[i] = string(priority)
+		}
+		conditions = append(conditions, "t.priority = ANY("+arg(pq.Array(priorities))+")")
+	}
+
+	var assignee []string
+	if len(filter.AssignedTo) > 0 {
+		members := make([]string, len(filter.AssignedTo))
+		for i, member := range filter.AssignedTo {
+			members[i] = member.String()
+		}
+		assignee = append(assignee, "t.assigned_to = ANY("+arg(pq.Array(members))+"::uuid[])")
+	}
+	if filter.Unassigned {
+		assignee = append(assignee, "t.assigned_to IS NULL")
+	}
+	if len(assignee) > 0 {
+		conditions = append(conditions, "("+strings.Join(assignee, " OR ")+")")
+	}
+
+	if filter.DueAfter != nil {
+		conditions = append(conditions, "t.due_date >= "+arg(*filter.DueAfter))
+	}
+	if filter.DueBefore != nil {
+		conditions = append(conditions, "t.due_date < "+arg(*filter.DueBefore))
+	}
+
+	if len(filter.Labels) > 0 {
+		labels := make([]string, len(filter.Labels))
+		for i, label := range filter.Labels {
+			labels[i] = label.String()
+		}
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM task_labels tl WHERE tl.task_id = t.id AND tl.label_id = ANY("+arg(pq.Array(labels))+"::uuid[]))")
+	}
+
+	var sprint []string
+	if len(filter.Sprints) > 0 {
+		sprints := make([]string, len(filter.Sprints))
+		for i, sprintID := range filter.Sprints {
+			sprints[i] = sprintID.String()
+		}
+		sprint = append(sprint, "t.sprint_id = ANY("+arg(pq.Array(sprints))+"::uuid[])")
+	}
+	if filter.Backlog {
+		sprint = append(sprint, "t.sprint_id IS NULL")
+	}
+	if len(sprint) > 0 {
+		conditions = append(conditions, "("+strings.Join(sprint, " OR ")+")")
+	}
+
+	if filter.ParentID != nil {
+		conditions = append(conditions, "t.parent_id = "+arg(*filter.ParentID))
+	}
+
+	if filter.Text != "" {
+		pattern := arg("%" + escapeLike(filter.Text) + "%")
+		conditions = append(conditions, "(t.title ILIKE "+pattern+" OR t.description ILIKE "+pattern+")")
+	}
+
+	return conditions
+}
+
+// taskSortExpression must agree with TaskSortValues, which builds the cursor from a task
+func taskSortExpression(s models.TaskSort) string {
+	switch s.Field {
+	case models.TaskSortTitle:
+		return "LOWER(t.title)"
+	case models.TaskSortStatus:
+		return "ts.position"
+	case models.TaskSortPriority:
+		return "CASE t.priority WHEN 'low' THEN 0 WHEN 'medium' THEN 1 WHEN 'high' THEN 2 WHEN 'critical' THEN 3 END"
+	case models.TaskSortDueDate:
+		return fmt.Sprintf("COALESCE(t.due_date, '%s'::timestamptz)", dueDateSortValue(nil, s.Descending).Format(time.RFC3339))
+	case models.TaskSortCreatedAt:
+		return "t.created_at"
+	case models.TaskSortUpdatedAt:
+		return "t.updated_at"
+	case models.TaskSortRank:
+		return "t.rank"
+	default:
+		return "t.task_number"
+	}
+}
+
+// taskKeysetCondition matches the rows that come after values in order:
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ..., with < for descending keys
+func taskKeysetCondition(order []models.TaskSort, values []any, arg func(any) string) string {
+	expressions := make([]string, len(order))
+	placeholders := make([]string, len(order))
+	for i, s := range order {
+		expressions[i] = taskSortExpression(s)
+		placeholders[i] = arg(values[i])
+	}
+
+	alternatives := make([]string, len(order))
+	for i, s := range order {
+		var terms []string
+		for j := 0; j < i; j++ {
+			terms = append(terms, expressions[j]+" = "+placeholders[j])
+		}
+		op := " > "
+		if s.Descending {
+			op = " < "
+		}
+		terms = append(terms, expressions[i]+op+placeholders[i])
+		alternatives[i] = "(" + strings.Join(terms, " AND ") + ")"
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")"
+}
+
+// escapeLike makes user input match literally inside a LIKE pattern
+func escapeLike(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(text)
+}
+
+func (r *taskRepository) PatchTask(ctx context.Context, taskID uuid.UUID, updatedBy uuid.UUID, patch *models.PatchTaskDTO) ([]models.TaskFieldChange, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	changes, err := patchTask(ctx, tx, taskID, updatedBy, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+func (r *taskRepository) PatchTasks(ctx context.Context, tx Tx, taskIDs []uuid.UUID, updatedBy uuid.UUID, patch *models.PatchTaskDTO) (map[uuid.UUID][]models.TaskFieldChange, error) {
+	changes := make(map[uuid.UUID][]models.TaskFieldChange, len(taskIDs))
+	for _, taskID := range taskIDs {
+		var err error
+		if changes[taskID], err = patchTask(ctx, tx, taskID, updatedBy, patch); err != nil {
+			return nil, err
+		}
+	}
+
+	return changes, nil
+}
+
+// patchTask is PatchTask within the caller's transaction
+func patchTask(ctx context.Context, tx Tx, taskID uuid.UUID, updatedBy uuid.UUID, patch *models.PatchTaskDTO) ([]models.TaskFieldChange, error) {
+	// The row lock makes the old values in the history the ones this update replaces
+	var current models.TaskResponseDTO
+	var assignedTo uuid.NullUUID
+	err := tx.QueryRowContext(ctx, `
+		SELECT title, description, status, priority, due_date, assigned_to, parent_id, original_estimate, remaining_estimate, story_points, sprint_id, updated_at
+		FROM tasks
+		WHERE id = $1
+		FOR UPDATE
+	`, taskID).Scan(&current.Title, &current.Description, &current.Status, &current.Priority, &current.DueDate, &assignedTo, &current.ParentID,
+		&current.OriginalEstimate, &current.RemainingEstimate, &current.StoryPoints, &current.SprintID, &current.UpdatedAt)
+	if err != nil {
+		return nil, notFound(err, "task not found")
+	}
+	// Checked under the lock too, as the task may have changed since the caller last read it
+	if !patch.IfMatch.Matches(models.ETag(current.UpdatedAt)) {
+		return nil, apperrors.PreconditionFailed("the task has changed since it was read")
+	}
+	if assignedTo.Valid {
+		current.AssignedTo = &models.ProjectMemberResponseDTO{ID: assignedTo.UUID}
+	}
+
+	changes := patch.Changes(&current)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	args := []any{taskID, updatedBy}
+	assignments := []string{"updated_by = $2"}
+	set := func(column string, value any) {
+		args = append(args, value)
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	for _, change := range changes {
+		switch change.Field {
+		case models.TaskFieldTitle:
+			set("title", *patch.Title.Value)
+		case models.TaskFieldDescription:
+			set("description", patch.Description.Value)
+		case models.TaskFieldStatus:
+			set("status", *patch.Status.Value)
+			// A task that changes status goes to the bottom of its new column
+			assignments = append(assignments, fmt.Sprintf(
+				"rank = (SELECT COALESCE(MAX(c.rank), 0) + %d FROM tasks c WHERE c.project_id = tasks.project_id AND c.status = $%d)", TaskRankStep, len(args)))
+			assignments = append(assignments, completedAtAssignment(fmt.Sprintf("$%d", len(args))))
+		case models.TaskFieldPriority:
+			set("priority", *patch.Priority.Value)
+		case models.TaskFieldDueDate:
+			set("due_date", patch.DueDate.Value)
+		case models.TaskFieldAssignedTo:
+			set("assigned_to", patch.AssignedTo.Value)
+		case models.TaskFieldParentID:
+			set("parent_id", patch.ParentID.Value)
+		case models.TaskFieldOriginalEstimate:
+			set("original_estimate", patch.OriginalEstimate.Value)
+		case models.TaskFieldRemainingEstimate:
+			set("remaining_estimate", patch.RemainingEstimate.Value)
+		case models.TaskFieldStoryPoints:
+			set("story_points", patch.StoryPoints.Value)
+		case models.TaskFieldSprintID:
+			set("sprint_id", patch.SprintID.Value)
+		}
+	}
+
+	queryString := "UPDATE tasks SET " + strings.Join(assignments, ", ") + " WHERE id = $1"
+	if _, err := tx.ExecContext(ctx, queryString, args...); err != nil {
+		return nil, err
+	}
+
+	for _, change := range changes {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO task_activity (task_id, actor_id, field, old_value, new_value)
+			VALUES ($1, $2, $3, $4, $5)
+		`, taskID, updatedBy, change.Field, change.OldValue, change.NewValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record task activity: %w", err)
+		}
+	}
+
+	if patch.AssignedTo.Present && patch.AssignedTo.Value != nil && slices.ContainsFunc(changes, func(change models.TaskFieldChange) bool {
+		return change.Field == models.TaskFieldAssignedTo
+	}) {
+		if _, err := tx.ExecContext(ctx, watchTaskQuery, taskID, *patch.AssignedTo.Value); err != nil {
+			return nil, fmt.Errorf("failed to add task watcher: %w", err)
+		}
+	}
+
+	return changes, nil
+}
+
+// completedAtAssignment keeps completed_at in step with a task moving to the status in placeholder:
+// it is set when the task becomes done, kept while it moves between done statuses and cleared
+// when it is reopened
+func completedAtAssignment(placeholder string) string {
+	return "completed_at = CASE WHEN (SELECT s.category FROM task_statuses s WHERE s.project_id = tasks.project_id AND s.key = " + placeholder +
+		") = 'done' THEN COALESCE(tasks.completed_at, NOW()) END"
+}
+
+func (r *taskRepository) MoveTask(ctx context.Context, tx Tx, taskID uuid.UUID, updatedBy uuid.UUID, from models.TaskStatus, move *models.MoveTaskDTO) (bool, error) {
+	var projectID uuid.UUID
+	if err := tx.QueryRowContext(ctx, `SELECT project_id FROM tasks WHERE id = $1`, taskID).Scan(&projectID); err != nil {
+		return false, notFound(err, "task not found")
+	}
+
+	// Moves within a project take turns, so two of them never rank against the same stale column
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM projects WHERE id = $1 FOR UPDATE`, projectID); err != nil {
+		return false, err
+	}
+
+	var current models.TaskStatus
+	if err := tx.QueryRowContext(ctx, `SELECT status FROM tasks WHERE id = $1 FOR UPDATE`, taskID).Scan(&current); err != nil {
+		return false, notFound(err, "task not found")
+	}
+	if current != from {
+		return false, apperrors.Conflict("the task changed status while it was being moved, reload the board and try again")
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, rank
+		FROM tasks
+		WHERE project_id = $1 AND status = $2 AND id <> $3
+		ORDER BY rank, task_number
+		FOR UPDATE
+	`, projectID, move.Status, taskID)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	var column []RankedTask
+	for rows.Next() {
+		var ranked RankedTask
+		if err := rows.Scan(&ranked.ID, &ranked.Rank); err != nil {
+			return false, err
+		}
+		column = append(column, ranked)
+	}
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+
+	rank, rebalanced, err := PlaceTask(column, taskID, move)
+	if err != nil {
+		return false, err
+	}
+
+	if move.Status != from {
+		_, err := tx.ExecContext(ctx, `UPDATE tasks SET status = $2, updated_by = $3, rank = $4, `+completedAtAssignment("$2")+` WHERE id = $1`,
+			taskID, move.Status, updatedBy, rank)
+		if err != nil {
+			return false, err
+		}
+
+		oldValue, newValue := string(from), string(move.Status)
+		change := models.TaskFieldChange{Field: models.TaskFieldStatus, OldValue: &oldValue, NewValue: &newValue}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO task_activity (task_id, actor_id, field, old_value, new_value)
+			VALUES ($1, $2, $3, $4, $5)
+		`, taskID, updatedBy, change.Field, change.OldValue, change.NewValue)
+		if err != nil {
+			return false, fmt.Errorf("failed to record task activity: %w", err)
+		}
+	}
+
+	if rebalanced == nil {
+		rebalanced = []RankedTask{{ID: taskID, Rank: rank}}
+	}
+	for _, ranked := range rebalanced {
+		if _, err := tx.ExecContext(ctx, `UPDATE tasks SET rank = $2 WHERE id = $1`, ranked.ID, ranked.Rank); err != nil {
+			return false, err
+		}
+	}
+
+	return len(rebalanced) > 1, nil
+}
+
+func (r *taskRepository) SetTaskRecurrence(ctx context.Context, taskID uuid.UUID, rule string, createdBy uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var recurrenceID uuid.NullUUID
+	if err := tx.QueryRowContext(ctx, `SELECT recurrence_id FROM tasks WHERE id = $1 FOR UPDATE`, taskID).Scan(&recurrenceID); err != nil {
+		return notFound(err, "task not found")
+	}
+
+	if recurrenceID.Valid {
+		_, err = tx.ExecContext(ctx, `UPDATE task_recurrences SET rule = $2 WHERE id = $1`, recurrenceID.UUID, rule)
+	} else {
+		err = createRecurrence(ctx, tx, taskID, rule, createdBy)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// createRecurrence starts a series with the task as its first occurrence
+func createRecurrence(ctx context.Context, tx Tx, taskID uuid.UUID, rule string, createdBy uuid.UUID) error {
+	var recurrenceID uuid.UUID
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO task_recurrences (project_id, rule, current_task_id, created_by)
+		SELECT project_id, $2, id, $3 FROM tasks WHERE id = $1
+		RETURNING id
+	`, taskID, rule, createdBy).Scan(&recurrenceID)
+	if err != nil {
+		return notFound(err, "task not found")
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE tasks SET recurrence_id = $2 WHERE id = $1`, taskID, recurrenceID)
+	return err
+}
+
+func (r *taskRepository) StopTaskRecurrence(ctx context.Context, taskID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM task_recurrences
+		WHERE id = (SELECT recurrence_id FROM tasks WHERE id = $1)
+	`, taskID)
+	if err != nil {
+		return err
+	}
+
+	if deleted, err := result.RowsAffected(); err != nil {
+		return err
+	} else if deleted == 0 {
+		return apperrors.NotFound("the task doesn't recur")
+	}
+
+	return nil
+}
+
+func (r *taskRepository) AdvanceTaskRecurrence(ctx context.Context, recurrenceID uuid.UUID, fromTaskID uuid.UUID, next *models.CreateTaskDTO) (uuid.UUID, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback()
+
+	taskID, err := r.AdvanceTaskRecurrenceTx(ctx, tx, recurrenceID, fromTaskID, next)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, err
+	}
+
+	return taskID, nil
+}
+
+func (r *taskRepository) AdvanceTaskRecurrenceTx(ctx context.Context, tx Tx, recurrenceID uuid.UUID, fromTaskID uuid.UUID, next *models.CreateTaskDTO) (uuid.UUID, error) {
+	// Completing the same occurrence twice at once must not create two next ones
+	var current uuid.NullUUID
+	err := tx.QueryRowContext(ctx, `SELECT current_task_id FROM task_recurrences WHERE id = $1 FOR UPDATE`, recurrenceID).Scan(&current)
+	if err != nil {
+		return uuid.Nil, notFound(err, "recurring series not found")
+	}
+	if !current.Valid || current.UUID != fromTaskID {
+		return uuid.Nil, nil
+	}
+
+	if next == nil {
+		_, err := tx.ExecContext(ctx, `UPDATE task_recurrences SET current_task_id = NULL WHERE id = $1`, recurrenceID)
+		return uuid.Nil, err
+	}
+
+	next.RecurrenceID = &recurrenceID
+	taskID, err := insertTask(ctx, tx, next)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO task_labels (task_id, label_id)
+		SELECT $2, label_id FROM task_labels WHERE task_id = $1
+	`, fromTaskID, taskID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE task_recurrences
+		SET current_task_id = $2, occurrences = occurrences + 1
+		WHERE id = $1
+	`, recurrenceID, taskID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return taskID, nil
+}
+
+func (r *taskRepository) GetTaskAncestors(ctx context.Context, taskID uuid.UUID) ([]uuid.UUID, error) {
+	// UNION rather than UNION ALL stops at a repeated row, so even a cycle can't recurse forever
 	queryString := `
-	    UPDATE tasks
-	    SET updated_by = $1,
-	        assigned_to = $2,
-	        title = $3,
-	        description = $4,
-	        status = $5,
-	        priority = $6,
-	        due_date = $7
-	    WHERE id = $8
+		WITH RECURSIVE ancestors (id) AS (
+			SELECT parent_id FROM tasks WHERE id = $1
+			UNION
+			SELECT t.parent_id FROM tasks t INNER JOIN ancestors a ON t.id = a.id
+		)
+		SELECT id FROM ancestors WHERE id IS NOT NULL
 	`
 
-	_, err := r.db.Exec(queryString, updateTaskDTO.UpdatedBy, updateTaskDTO.AssignedTo, updateTaskDTO.Title, updateTaskDTO.Description, updateTaskDTO.Status, updateTaskDTO.Priority, updateTaskDTO.DueDate, taskID)
+	rows, err := r.db.QueryContext(ctx, queryString, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	return err
+	var ancestors []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ancestors = append(ancestors, id)
+	}
+
+	return ancestors, rows.Err()
+}
+
+func (r *taskRepository) GetTaskActivity(ctx context.Context, taskID uuid.UUID) ([]models.TaskActivityResponseDTO, error) {
+	queryString := `
+		SELECT a.id, a.task_id, a.field, a.old_value, a.new_value, a.created_at,
+		       pm.id, pm.user_id, pm.project_id, u.name, u.email, pm.role, pm.status, pm.joined_at
+		FROM task_activity a
+		INNER JOIN project_members pm ON a.actor_id = pm.id
+		INNER JOIN users u ON pm.user_id = u.id
+		WHERE a.task_id = $1
+		-- Changes written together share a timestamp and keep the order of the task's fields
+		ORDER BY a.created_at, array_position(ARRAY['title', 'description', 'status', 'priority', 'dueDate', 'assignedTo', 'parentId', 'originalEstimate', 'remainingEstimate', 'storyPoints', 'sprintId'], a.field)
+	`
+
+	rows, err := r.db.QueryContext(ctx, queryString, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	activity := []models.TaskActivityResponseDTO{}
+	for rows.Next() {
+		var entry models.TaskActivityResponseDTO
+		err := rows.Scan(&entry.ID, &entry.TaskID, &entry.Field, &entry.OldValue, &entry.NewValue, &entry.CreatedAt,
+			&entry.Actor.ID, &entry.Actor.UserID, &entry.Actor.ProjectID, &entry.Actor.Name, &entry.Actor.Email,
+			&entry.Actor.Role, &entry.Actor.Status, &entry.Actor.JoinedAt)
+		if err != nil {
+			return nil, err
+		}
+		activity = append(activity, entry)
+	}
+
+	return activity, rows.Err()
 }
 
-func (r *taskRepository) DeleteTask(taskID uuid.UUID) error {
+func (r *taskRepository) DeleteTask(ctx context.Context, taskID uuid.UUID) error {
 	queryString := `
 		DELETE FROM tasks
 		WHERE id = $1
 	`
 
-	_, err := r.db.Exec(queryString, taskID)
+	_, err := r.db.ExecContext(ctx, queryString, taskID)
 	if err != nil {
 		return fmt.Errorf("failed to delete task: %w", err)
 	}
 
 	return nil
 }
+
+func (r *taskRepository) DeleteTasks(ctx context.Context, tx Tx, taskIDs []uuid.UUID) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tasks WHERE id = ANY($1)`, pq.Array(taskIDs)); err != nil {
+		return fmt.Errorf("failed to delete tasks: %w", err)
+	}
+
+	return nil
+}
+
+func (r *taskRepository) WatchTask(ctx context.Context, taskID uuid.UUID, projectMemberID uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, watchTaskQuery, taskID, projectMemberID); err != nil {
+		return fmt.Errorf("failed to add task watcher: %w", err)
+	}
+
+	return nil
+}
+
+func (r *taskRepository) UnwatchTask(ctx context.Context, taskID uuid.UUID, projectMemberID uuid.UUID) error {
+	queryString := `DELETE FROM task_watchers WHERE task_id = $1 AND project_member_id = $2`
+	if _, err := r.db.ExecContext(ctx, queryString, taskID, projectMemberID); err != nil {
+		return fmt.Errorf("failed to remove task watcher: %w", err)
+	}
+
+	return nil
+}
+
+func (r *taskRepository) GetTaskWatchers(ctx context.Context, taskID uuid.UUID) ([]models.ProjectMemberResponseDTO, error) {
+	queryString := `
+		SELECT pm.id, pm.user_id, pm.project_id, u.name, u.email, pm.role, pm.status, pm.joined_at
+		FROM task_watchers tw
+		INNER JOIN project_members pm ON tw.project_member_id = pm.id
+		INNER JOIN users u ON pm.user_id = u.id
+		WHERE tw.task_id = $1
+		ORDER BY u.name, pm.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, queryString, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	watchers := []models.ProjectMemberResponseDTO{}
+	for rows.Next() {
+		var watcher models.ProjectMemberResponseDTO
+		if err := rows.Scan(&watcher.ID, &watcher.UserID, &watcher.ProjectID, &watcher.Name, &watcher.Email, &watcher.Role, &watcher.Status, &watcher.JoinedAt); err != nil {
+			return nil, err
+		}
+		watchers = append(watchers, watcher)
+	}
+
+	return watchers, rows.Err()
+}
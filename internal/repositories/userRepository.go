@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 
@@ -9,10 +10,10 @@ import (
 )
 
 type UserRepository interface {
-	CreateUser(userDTO *models.CreateUserDTO) error
-	GetUserIDByFirebaseUID(firebaseUID string) (uuid.UUID, error)
-	GetUserByFirebaseUID(firebaseUID string) (*models.UserResponseDTO, error)
-	SearchUsers(searchQuery string) ([]models.UserResponseDTO, error)
+	CreateUser(ctx context.Context, userDTO *models.CreateUserDTO) error
+	GetUserIDByFirebaseUID(ctx context.Context, firebaseUID string) (uuid.UUID, error)
+	GetUserByFirebaseUID(ctx context.Context, firebaseUID string) (*models.UserResponseDTO, error)
+	SearchUsers(ctx context.Context, searchQuery string) ([]models.UserResponseDTO, error)
 }
 
 type userRepository struct {
@@ -23,31 +24,31 @@ func NewUserRepository(db *sql.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
-func (r *userRepository) CreateUser(userDTO *models.CreateUserDTO) error {
+func (r *userRepository) CreateUser(ctx context.Context, userDTO *models.CreateUserDTO) error {
 	queryString := `INSERT INTO users (firebase_uid, name, email) VALUES ($1, $2, $3)`
 
-	_, err := r.db.Exec(queryString, userDTO.FirebaseUID, userDTO.Name, userDTO.Email)
+	_, err := r.db.ExecContext(ctx, queryString, userDTO.FirebaseUID, userDTO.Name, userDTO.Email)
 
 	if err != nil {
-		return err
+		return conflict(err, "user already exists")
 	}
 
 	return nil
 }
 
-func (r *userRepository) GetUserIDByFirebaseUID(firebaseUID string) (uuid.UUID, error) {
+func (r *userRepository) GetUserIDByFirebaseUID(ctx context.Context, firebaseUID string) (uuid.UUID, error) {
 	var userID uuid.UUID
 	query := "SELECT id FROM users WHERE firebase_uid = $1"
 
-	err := r.db.QueryRow(query, firebaseUID).Scan(&userID)
+	err := r.db.QueryRowContext(ctx, query, firebaseUID).Scan(&userID)
 	if err != nil {
-		return uuid.UUID{}, err
+		return uuid.UUID{}, notFound(err, "user not found")
 	}
 
 	return userID, nil
 }
 
-func (r *userRepository) GetUserByFirebaseUID(firebaseUID string) (*models.UserResponseDTO, error) {
+func (r *userRepository) GetUserByFirebaseUID(ctx context.Context, firebaseUID string) (*models.UserResponseDTO, error) {
 
 	var user models.UserResponseDTO
 	queryString := `
@@ -56,15 +57,15 @@ func (r *userRepository) GetUserByFirebaseUID(firebaseUID string) (*models.UserR
 		WHERE firebase_uid = $1
 	`
 
-	err := r.db.QueryRow(queryString, firebaseUID).Scan(&user.ID, &user.Email, &user.Name)
+	err := r.db.QueryRowContext(ctx, queryString, firebaseUID).Scan(&user.ID, &user.Email, &user.Name)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, notFound(err, "user not found")
 	}
 
 	return &user, nil
 }
 
-func (r *userRepository) SearchUsers(searchQuery string) ([]models.UserResponseDTO, error) {
+func (r *userRepository) SearchUsers(ctx context.Context, searchQuery string) ([]models.UserResponseDTO, error) {
 
 	queryString := `
 		SELECT id, email, name
@@ -74,7 +75,7 @@ func (r *userRepository) SearchUsers(searchQuery string) ([]models.UserResponseD
 		LIMIT 5
 	`
 
-	rows, err := r.db.Query(queryString, "%"+searchQuery+"%")
+	rows, err := r.db.QueryContext(ctx, queryString, "%"+searchQuery+"%")
 	if err != nil {
 		return nil, errors.New("failed to search users")
 	}
@@ -0,0 +1,165 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+)
+
+type WorkflowRepository interface {
+	GetWorkflow(ctx context.Context, projectID uuid.UUID) (*models.WorkflowDTO, error)
+	// ReplaceWorkflow makes workflow the project's statuses. Statuses left out are deleted, which
+	// is a conflict while tasks are still in them.
+	ReplaceWorkflow(ctx context.Context, projectID uuid.UUID, workflow *models.WorkflowDTO) error
+}
+
+type workflowRepository struct {
+	db *sql.DB
+}
+
+func NewWorkflowRepository(db *sql.DB) WorkflowRepository {
+	return &workflowRepository{db: db}
+}
+
+// execer is satisfied by *sql.DB, *sql.Tx and Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func (r *workflowRepository) GetWorkflow(ctx context.Context, projectID uuid.UUID) (*models.WorkflowDTO, error) {
+	queryString := `
+		SELECT s.key, s.name, s.category, s.position,
+		       COALESCE(ARRAY(
+		           SELECT tr.to_status
+		           FROM task_status_transitions tr
+		           INNER JOIN task_statuses ts ON ts.project_id = tr.project_id AND ts.key = tr.to_status
+		           WHERE tr.project_id = s.project_id AND tr.from_status = s.key
+		           ORDER BY ts.position
+		       ), '{}')
+		FROM task_statuses s
+		WHERE s.project_id = $1
+		ORDER BY s.position
+	`
+
+	rows, err := r.db.QueryContext(ctx, queryString, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	workflow := &models.WorkflowDTO{Statuses: []models.TaskStatusDTO{}}
+	for rows.Next() {
+		var status models.TaskStatusDTO
+		var transitions []string
+		if err := rows.Scan(&status.Key, &status.Name, &status.Category, &status.Position, pq.Array(&transitions)); err != nil {
+			return nil, err
+		}
+		status.Transitions = make([]models.TaskStatus, len(transitions))
+		for i, to := range transitions {
+			status.Transitions[i] = models.TaskStatus(to)
+		}
+		workflow.Statuses = append(workflow.Statuses, status)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(workflow.Statuses) == 0 {
+		return nil, apperrors.NotFound("project not found")
+	}
+
+	return workflow, nil
+}
+
+func (r *workflowRepository) ReplaceWorkflow(ctx context.Context, projectID uuid.UUID, workflow *models.WorkflowDTO) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Locking the project keeps tasks from being created in a status while it is removed
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM projects WHERE id = $1 FOR UPDATE`, projectID); err != nil {
+		return err
+	}
+
+	keys := make([]string, len(workflow.Statuses))
+	for i, status := range workflow.Statuses {
+		keys[i] = string(status.Key)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT status, COUNT(*)
+		FROM tasks
+		WHERE project_id = $1 AND NOT (status = ANY($2))
+		GROUP BY status
+	`, projectID, pq.Array(keys))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	inUse := map[string]string{}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return err
+		}
+		inUse[status] = fmt.Sprintf("%d tasks are in this status, move them first", count)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(inUse) > 0 {
+		return &apperrors.Error{Kind: apperrors.KindConflict, Message: "statuses that still have tasks can't be removed", Details: inUse}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM task_statuses WHERE project_id = $1 AND NOT (key = ANY($2))`, projectID, pq.Array(keys)); err != nil {
+		return err
+	}
+
+	if err := insertWorkflow(ctx, tx, projectID, workflow); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertWorkflow upserts the statuses of workflow and replaces the project's transitions with its own
+func insertWorkflow(ctx context.Context, db execer, projectID uuid.UUID, workflow *models.WorkflowDTO) error {
+	for _, status := range workflow.Statuses {
+		_, err := db.ExecContext(ctx, `
+			INSERT INTO task_statuses (project_id, key, name, category, position)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (project_id, key) DO UPDATE
+			SET name = EXCLUDED.name, category = EXCLUDED.category, position = EXCLUDED.position
+		`, projectID, status.Key, status.Name, status.Category, status.Position)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := db.ExecContext(ctx, `DELETE FROM task_status_transitions WHERE project_id = $1`, projectID); err != nil {
+		return err
+	}
+
+	for _, status := range workflow.Statuses {
+		for _, to := range status.Transitions {
+			_, err := db.ExecContext(ctx, `
+				INSERT INTO task_status_transitions (project_id, from_status, to_status)
+				VALUES ($1, $2, $3)
+			`, projectID, status.Key, to)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
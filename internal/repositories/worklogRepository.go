@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+)
+
+type WorklogRepository interface {
+	CreateWorklog(ctx context.Context, worklogDTO *models.CreateWorklogDTO) (uuid.UUID, error)
+	GetWorklog(ctx context.Context, worklogID uuid.UUID) (*models.WorklogResponseDTO, error)
+	// GetTaskWorklogs returns the task's work logs, most recent work first
+	GetTaskWorklogs(ctx context.Context, taskID uuid.UUID) ([]models.WorklogResponseDTO, error)
+	UpdateWorklog(ctx context.Context, worklogID uuid.UUID, worklogDTO *models.UpdateWorklogDTO) error
+	DeleteWorklog(ctx context.Context, worklogID uuid.UUID) error
+	// GetTimeReport totals the time logged on the project's tasks over the query's dates
+	GetTimeReport(ctx context.Context, projectID uuid.UUID, query *models.TimeReportQuery) (*models.TimeReportDTO, error)
+}
+
+type worklogRepository struct {
+	db *sql.DB
+}
+
+func NewWorklogRepository(db *sql.DB) WorklogRepository {
+	return &worklogRepository{db: db}
+}
+
+func (r *worklogRepository) CreateWorklog(ctx context.Context, worklogDTO *models.CreateWorklogDTO) (uuid.UUID, error) {
+	queryString := `
+		INSERT INTO worklogs (task_id, project_member_id, duration, work_date, note)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	var worklogID uuid.UUID
+	err := r.db.QueryRowContext(ctx, queryString, worklogDTO.TaskID, worklogDTO.ProjectMemberID, worklogDTO.Duration, worklogDTO.Date, worklogDTO.Note).Scan(&worklogID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return worklogID, nil
+}
+
+// worklogSelect loads a work log with its author, in the column order scanWorklog expects
+const worklogSelect = `
+	SELECT w.id, w.task_id, w.duration, to_char(w.work_date, 'YYYY-MM-DD'), w.note, w.created_at, w.updated_at,
+	       pm.id, pm.user_id, pm.project_id, u.name, u.email, pm.role, pm.status, pm.joined_at
+	FROM worklogs w
+	INNER JOIN project_members pm ON w.project_member_id = pm.id
+	INNER JOIN users u ON pm.user_id = u.id
+`
+
+func scanWorklog(row rowScanner) (*models.WorklogResponseDTO, error) {
+	var worklog models.WorklogResponseDTO
+	err := row.Scan(&worklog.ID, &worklog.TaskID, &worklog.Duration, &worklog.Date, &worklog.Note, &worklog.CreatedAt, &worklog.UpdatedAt,
+		&worklog.Author.ID, &worklog.Author.UserID, &worklog.Author.ProjectID, &worklog.Author.Name, &worklog.Author.Email,
+		&worklog.Author.Role, &worklog.Author.Status, &worklog.Author.JoinedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &worklog, nil
+}
+
+func (r *worklogRepository) GetWorklog(ctx context.Context, worklogID uuid.UUID) (*models.WorklogResponseDTO, error) {
+	worklog, err := scanWorklog(r.db.QueryRowContext(ctx, worklogSelect+"WHERE w.id = $1", worklogID))
+	if err != nil {
+		return nil, notFound(err, "work log not found")
+	}
+
+	return worklog, nil
+}
+
+func (r *worklogRepository) GetTaskWorklogs(ctx context.Context, taskID uuid.UUID) ([]models.WorklogResponseDTO, error) {
+	rows, err := r.db.QueryContext(ctx, worklogSelect+"WHERE w.task_id = $1\nORDER BY w.work_date DESC, w.created_at DESC", taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	worklogs := []models.WorklogResponseDTO{}
+	for rows.Next() {
+		worklog, err := scanWorklog(rows)
+		if err != nil {
+			return nil, err
+		}
+		worklogs = append(worklogs, *worklog)
+	}
+
+	return worklogs, rows.Err()
+}
+
+func (r *worklogRepository) UpdateWorklog(ctx context.Context, worklogID uuid.UUID, worklogDTO *models.UpdateWorklogDTO) error {
+	queryString := `
+		UPDATE worklogs
+		SET duration = $2, work_date = $3, note = $4
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, queryString, worklogID, worklogDTO.Duration, worklogDTO.Date, worklogDTO.Note)
+	if err != nil {
+		return err
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return apperrors.NotFound("work log not found")
+	}
+
+	return nil
+}
+
+func (r *worklogRepository) DeleteWorklog(ctx context.Context, worklogID uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM worklogs WHERE id = $1`, worklogID); err != nil {
+		return fmt.Errorf("failed to delete work log: %w", err)
+	}
+
+	return nil
+}
+
+func (r *worklogRepository) GetTimeReport(ctx context.Context, projectID uuid.UUID, query *models.TimeReportQuery) (*models.TimeReportDTO, error) {
+	// Dates that are left out match every work log
+	const inRange = `t.project_id = $1 AND ($2::date IS NULL OR w.work_date >= $2) AND ($3::date IS NULL OR w.work_date <= $3)`
+
+	report := &models.TimeReportDTO{
+		From:    formatReportDate(query.From),
+		To:      formatReportDate(query.To),
+		Tasks:   []models.TaskTimeDTO{},
+		Members: []models.MemberTimeDTO{},
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT t.id, t.task_number, t.title, t.status, t.original_estimate, t.remaining_estimate, SUM(w.duration)
+		FROM worklogs w
+		INNER JOIN tasks t ON w.task_id = t.id
+		WHERE `+inRange+`
+		GROUP BY t.id
+		ORDER BY SUM(w.duration) DESC, t.task_number
+	`, projectID, query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var task models.TaskTimeDTO
+		err := rows.Scan(&task.Task.ID, &task.Task.TaskNumber, &task.Task.Title, &task.Task.Status,
+			&task.OriginalEstimate, &task.RemainingEstimate, &task.Logged)
+		if err != nil {
+			return nil, err
+		}
+		report.Tasks = append(report.Tasks, task)
+		report.Logged += task.Logged
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT pm.id, pm.user_id, pm.project_id, u.name, u.email, pm.role, pm.status, pm.joined_at, SUM(w.duration)
+		FROM worklogs w
+		INNER JOIN tasks t ON w.task_id = t.id
+		INNER JOIN project_members pm ON w.project_member_id = pm.id
+		INNER JOIN users u ON pm.user_id = u.id
+		WHERE `+inRange+`
+		GROUP BY pm.id, u.id
+		ORDER BY SUM(w.duration) DESC, u.name
+	`, projectID, query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var member models.MemberTimeDTO
+		err := rows.Scan(&member.Member.ID, &member.Member.UserID, &member.Member.ProjectID, &member.Member.Name, &member.Member.Email,
+			&member.Member.Role, &member.Member.Status, &member.Member.JoinedAt, &member.Logged)
+		if err != nil {
+			return nil, err
+		}
+		report.Members = append(report.Members, member)
+	}
+
+	return report, rows.Err()
+}
+
+func formatReportDate(date *time.Time) *string {
+	if date == nil {
+		return nil
+	}
+	formatted := date.Format(models.WorklogDateLayout)
+	return &formatted
+}
@@ -0,0 +1,310 @@
+package routes_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/sarvochcha01/enlace-backend/internal/auth"
+	"github.com/sarvochcha01/enlace-backend/internal/config"
+	"github.com/sarvochcha01/enlace-backend/internal/metrics"
+	"github.com/sarvochcha01/enlace-backend/internal/middlewares"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/repositories/memory"
+	"github.com/sarvochcha01/enlace-backend/internal/routes"
+	"github.com/sarvochcha01/enlace-backend/internal/websockets"
+)
+
+// fakeVerifier accepts the bearer tokens handed out by testServer.signUp
+type fakeVerifier struct {
+	mu     sync.Mutex
+	tokens map[string]*auth.Token
+}
+
+func (v *fakeVerifier) VerifyToken(ctx context.Context, idToken string) (*auth.Token, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	token, ok := v.tokens[idToken]
+	if !ok {
+		return nil, auth.ErrInvalidToken
+	}
+	return token, nil
+}
+
+// testServer serves the real routes, services and handlers on top of the in-memory repositories
+type testServer struct {
+	t        *testing.T
+	server   *httptest.Server
+	verifier *fakeVerifier
+	hub      *websockets.WebSocketHub
+}
+
+// testUser is a signed up user; token is the bearer token to send as them
+type testUser struct {
+	uid   string
+	name  string
+	email string
+	token string
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	verifier := &fakeVerifier{tokens: map[string]*auth.Token{}}
+	m := metrics.New()
+	hub := websockets.NewWebSocketHub(verifier, []string{"*"}, m)
+	m.RegisterWebSocketClients(hub.ConnectedClients)
+	go hub.Run()
+
+	router := chi.NewRouter()
+	router.Use(middlewares.RequestLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	router.Use(middlewares.Metrics(m))
+	routes.SetupRoutes(router, memory.NewRepositories(memory.NewStore()), verifier, hub, config.Default(), m)
+	server := httptest.NewServer(router)
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := hub.Shutdown(ctx); err != nil {
+			t.Errorf("hub shutdown: %v", err)
+		}
+		server.Close()
+	})
+
+	return &testServer{t: t, server: server, verifier: verifier, hub: hub}
+}
+
+func (s *testServer) signUp(uid string, name string, email string) testUser {
+	s.t.Helper()
+
+	resp := s.do(http.MethodPost, "/api/v1/users/create", nil, models.CreateUserDTO{FirebaseUID: uid, Name: name, Email: email})
+	expectStatus(s.t, resp, http.StatusCreated)
+
+	u := testUser{uid: uid, name: name, email: email, token: "token-" + uid}
+
+	s.verifier.mu.Lock()
+	s.verifier.tokens[u.token] = &auth.Token{UID: uid, Claims: map[string]interface{}{"email": email}}
+	s.verifier.mu.Unlock()
+
+	return u
+}
+
+// do sends body as JSON, as user when user is not nil
+func (s *testServer) do(method string, path string, user *testUser, body any) *http.Response {
+	s.t.Helper()
+
+	return s.doWithHeader(method, path, user, body, nil)
+}
+
+// doWithHeader is do with extra request headers, e.g. If-Match
+func (s *testServer) doWithHeader(method string, path string, user *testUser, body any, header http.Header) *http.Response {
+	s.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			s.t.Fatalf("encode request body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, s.server.URL+path, reader)
+	if err != nil {
+		s.t.Fatalf("build request: %v", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if user != nil {
+		req.Header.Set("Authorization", "Bearer "+user.token)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := s.server.Client().Do(req)
+	if err != nil {
+		s.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	s.t.Cleanup(func() { resp.Body.Close() })
+
+	return resp
+}
+
+// getJSON expects a 200 and decodes the response into out
+func (s *testServer) getJSON(path string, user *testUser, out any) {
+	s.t.Helper()
+
+	resp := s.do(http.MethodGet, path, user, nil)
+	expectStatus(s.t, resp, http.StatusOK)
+	decode(s.t, resp, out)
+}
+
+// createProject creates a project owned by user and returns it with its members loaded
+func (s *testServer) createProject(user testUser, name string, key string) models.ProjectResponseDTO {
+	s.t.Helper()
+
+	resp := s.do(http.MethodPost, "/api/v1/projects", &user, models.CreateProjectDTO{Name: name, Key: key})
+	expectStatus(s.t, resp, http.StatusCreated)
+
+	var projects []models.ProjectResponseDTO
+	s.getJSON("/api/v1/projects", &user, &projects)
+	for _, project := range projects {
+		if project.Key == strings.ToUpper(key) {
+			var full models.ProjectResponseDTO
+			s.getJSON("/api/v1/projects/"+project.ID.String(), &user, &full)
+			return full
+		}
+	}
+
+	s.t.Fatalf("project %s was not created", key)
+	return models.ProjectResponseDTO{}
+}
+
+// createTask creates a task and returns it, found as the project's highest numbered task
+func (s *testServer) createTask(user testUser, projectID uuid.UUID, task models.CreateTaskDTO) models.TaskResponseDTO {
+	s.t.Helper()
+
+	task.ProjectID = projectID
+	if task.Status == "" {
+		task.Status = models.Todo
+	}
+	if task.Priority == "" {
+		task.Priority = models.Medium
+	}
+
+	path := "/api/v1/projects/" + projectID.String() + "/tasks"
+	expectStatus(s.t, s.do(http.MethodPost, path, &user, task), http.StatusCreated)
+
+	var page models.TaskListResponseDTO
+	s.getJSON(path+"?sort=-number&limit=1", &user, &page)
+	if len(page.Tasks) != 1 {
+		s.t.Fatalf("task %q was not created", task.Title)
+	}
+	return page.Tasks[0]
+}
+
+// addMember invites user to the project as owner, accepts as user and gives them role
+func (s *testServer) addMember(owner testUser, projectID uuid.UUID, user testUser, role models.ProjectMemberRole) models.ProjectMemberResponseDTO {
+	s.t.Helper()
+
+	var invited models.UserResponseDTO
+	s.getJSON("/api/v1/users", &user, &invited)
+
+	resp := s.do(http.MethodPost, "/api/v1/invitations", &owner, models.CreateInvitationDTO{InvitedUserID: invited.ID, ProjectID: projectID})
+	expectStatus(s.t, resp, http.StatusCreated)
+
+	var invitations []models.InvitationResponseDTO
+	s.getJSON("/api/v1/invitations", &user, &invitations)
+	for _, invitation := range invitations {
+		if invitation.ProjectID == projectID && invitation.Status == models.InivtationStatusPending {
+			accept := models.EditInvitationDTO{Status: string(models.InivtationStatusAccepted), ProjectID: projectID}
+			expectStatus(s.t, s.do(http.MethodPut, "/api/v1/invitations/"+invitation.ID.String(), &user, accept), http.StatusOK)
+		}
+	}
+
+	projectPath := "/api/v1/projects/" + projectID.String()
+	var project models.ProjectResponseDTO
+	s.getJSON(projectPath, &owner, &project)
+	for _, member := range project.ProjectMembers {
+		if member.UserID != invited.ID {
+			continue
+		}
+		if member.Role != role {
+			resp := s.do(http.MethodPut, projectPath+"/project-members/"+member.ID.String(), &owner, models.UpdateProjectMemberDTO{Role: role})
+			expectStatus(s.t, resp, http.StatusOK)
+			member.Role = role
+		}
+		return member
+	}
+
+	s.t.Fatalf("%s did not join the project", user.name)
+	return models.ProjectMemberResponseDTO{}
+}
+
+// connect opens the notification WebSocket as user and waits until the hub has registered it
+func (s *testServer) connect(user testUser) *websocket.Conn {
+	s.t.Helper()
+
+	connected := s.hub.ConnectedClients()
+
+	url := "ws" + strings.TrimPrefix(s.server.URL, "http") + "/api/v1/notifications/ws?token=" + user.token
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		s.t.Fatalf("dial websocket: %v", err)
+	}
+	s.t.Cleanup(func() { conn.Close() })
+
+	deadline := time.Now().Add(5 * time.Second)
+	for s.hub.ConnectedClients() <= connected {
+		if time.Now().After(deadline) {
+			s.t.Fatal("websocket client was never registered")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	return conn
+}
+
+func expectStatus(t *testing.T, resp *http.Response, status int) {
+	t.Helper()
+
+	if resp.StatusCode != status {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("%s %s: expected status %d, got %d: %s", resp.Request.Method, resp.Request.URL.Path, status, resp.StatusCode, body)
+	}
+}
+
+func decode(t *testing.T, resp *http.Response, out any) {
+	t.Helper()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		t.Fatalf("decode %s response: %v", resp.Request.URL.Path, err)
+	}
+}
+
+// expectError checks both the status code and the code in the JSON error envelope
+func expectError(t *testing.T, resp *http.Response, status int, code string) {
+	t.Helper()
+
+	expectStatus(t, resp, status)
+
+	var envelope struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	decode(t, resp, &envelope)
+
+	if envelope.Code != code {
+		t.Fatalf("expected error code %q, got %q (%s)", code, envelope.Code, envelope.Message)
+	}
+	if envelope.Message == "" {
+		t.Fatal("expected an error message")
+	}
+}
+
+// readNotification waits for the next notification pushed over the WebSocket
+func readNotification(t *testing.T, conn *websocket.Conn) models.NotificationResponseDTO {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var notification models.NotificationResponseDTO
+	if err := conn.ReadJSON(&notification); err != nil {
+		t.Fatalf("read notification: %v", err)
+	}
+	return notification
+}
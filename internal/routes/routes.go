@@ -1,57 +1,83 @@
 package routes
 
 import (
-	"database/sql"
+	"context"
+	"errors"
 	"net/http"
 
-	"firebase.google.com/go/auth"
 	"github.com/go-chi/chi/v5"
+	"github.com/sarvochcha01/enlace-backend/internal/auth"
+	"github.com/sarvochcha01/enlace-backend/internal/config"
 	"github.com/sarvochcha01/enlace-backend/internal/handlers"
+	"github.com/sarvochcha01/enlace-backend/internal/metrics"
 	"github.com/sarvochcha01/enlace-backend/internal/middlewares"
 	"github.com/sarvochcha01/enlace-backend/internal/repositories"
 	"github.com/sarvochcha01/enlace-backend/internal/services"
 	"github.com/sarvochcha01/enlace-backend/internal/websockets"
 )
 
-func SetupRoutes(r chi.Router, db *sql.DB, authClient *auth.Client) {
+func SetupRoutes(r chi.Router, repos repositories.Repositories, verifier auth.TokenVerifier, wsHub *websockets.WebSocketHub, cfg *config.Config, m *metrics.Metrics) {
 
-	userRepository := repositories.NewUserRepository(db)
-	userService := services.NewUserService(userRepository)
+	userService := services.NewUserService(repos.Users)
 	userHandler := handlers.NewUserHandler(userService)
 
-	wsHub := websockets.NewWebSocketHub(authClient)
 	wsHub.SetUserFinder(userService)
-	go wsHub.Run()
 
-	notificationRepository := repositories.NewNotificationRepository(db)
-	notificationService := services.NewNotificationService(notificationRepository, wsHub, userService)
+	notificationService := services.NewNotificationService(repos.Notifications, wsHub, userService, m)
 	notificationHandler := handlers.NewNotificationHandler(notificationService, userService)
 
-	projectMemberRepository := repositories.NewProjectMemberRepository(db)
-	projectMemberService := services.NewProjectMemberService(projectMemberRepository, userService)
+	projectMemberService := services.NewProjectMemberService(repos.ProjectMembers, userService)
 	projectMemberHandler := handlers.NewProjectMemberHandler(projectMemberService)
 
-	projectRepository := repositories.NewProjectRepository(db)
-	projectService := services.NewProjectService(projectRepository, userService, projectMemberService)
+	projectService := services.NewProjectService(repos.Projects, userService, projectMemberService)
 	projectHandler := handlers.NewProjectHandler(projectService)
 
-	taskRepository := repositories.NewTaskRepository(db)
-	taskService := services.NewTaskService(taskRepository, userService, projectMemberService, notificationService)
+	commentService := services.NewCommentService(repos.Comments, repos.Tasks, userService, projectMemberService, notificationService)
+	commentHandler := handlers.NewCommentHandler(commentService)
+
+	workflowService := services.NewWorkflowService(repos.Workflows, projectMemberService)
+	workflowHandler := handlers.NewWorkflowHandler(workflowService)
+
+	taskService := services.NewTaskService(repos.Tasks, repos.Sprints, userService, projectMemberService, notificationService, commentService, workflowService)
 	taskHandler := handlers.NewTaskHandler(taskService)
 
-	commentRepository := repositories.NewCommentRepository(db)
-	commentService := services.NewCommentService(commentRepository, userService, projectMemberService)
-	commentHandler := handlers.NewCommentHandler(commentService)
+	taskLinkService := services.NewTaskLinkService(repos.TaskLinks, taskService, projectMemberService)
+	taskLinkHandler := handlers.NewTaskLinkHandler(taskLinkService)
+
+	labelService := services.NewLabelService(repos.Labels, taskService, projectMemberService)
+	labelHandler := handlers.NewLabelHandler(labelService)
+
+	worklogService := services.NewWorklogService(repos.Worklogs, taskService, projectMemberService)
+	worklogHandler := handlers.NewWorklogHandler(worklogService)
+
+	sprintService := services.NewSprintService(repos.Sprints, projectMemberService)
+	sprintHandler := handlers.NewSprintHandler(sprintService)
 
-	invitationRepository := repositories.NewInvitationRepository(db)
-	invitationService := services.NewInvitationService(invitationRepository, userService, projectService, projectMemberService, notificationService)
+	invitationService := services.NewInvitationService(repos.Invitations, userService, projectService, projectMemberService, notificationService)
 	invitationHandler := handlers.NewInvitationHandler(invitationService)
 
-	dashboardRepository := repositories.NewDashboardRepository(db)
-	dashboardService := services.NewDashboardService(dashboardRepository, userService)
+	dashboardService := services.NewDashboardService(repos.Dashboard, userService)
 	dashboardHandler := handlers.NewDashboardHandler(dashboardService)
 
-	authMiddleware := middlewares.NewAuthMiddleware(authClient)
+	authMiddleware := middlewares.NewAuthMiddleware(verifier)
+
+	healthHandler := handlers.NewHealthHandler(
+		handlers.HealthCheck{Name: "database", Check: repos.DB.PingContext},
+		handlers.HealthCheck{Name: "websocketHub", Check: wsHub.Ping},
+		handlers.HealthCheck{Name: "auth", Check: func(ctx context.Context) error {
+			if verifier == nil {
+				return errors.New("no token verifier configured")
+			}
+			return nil
+		}},
+	)
+
+	r.Get("/healthz", healthHandler.Liveness)
+	r.Get("/readyz", healthHandler.Readiness)
+
+	if cfg.Features.Metrics {
+		r.Method(http.MethodGet, "/metrics", m.Handler())
+	}
 
 	r.Route("/api/v1", func(api chi.Router) {
 
@@ -62,13 +88,13 @@ func SetupRoutes(r chi.Router, db *sql.DB, authClient *auth.Client) {
 
 		api.Route("/users", func(r chi.Router) {
 			r.Post("/create", userHandler.CreateUser)
-			r.With(authMiddleware.FirebaseAuthMiddleware).Get("/", userHandler.GetUser)
-			r.With(authMiddleware.FirebaseAuthMiddleware).Post("/search", userHandler.SearchUsers)
+			r.With(authMiddleware.Authenticate).Get("/", userHandler.GetUser)
+			r.With(authMiddleware.Authenticate).Post("/search", userHandler.SearchUsers)
 
 		})
 
 		api.Route("/projects", func(r chi.Router) {
-			r.Use(authMiddleware.FirebaseAuthMiddleware)
+			r.Use(authMiddleware.Authenticate)
 			r.Post("/", projectHandler.CreateProject)
 			r.Get("/", projectHandler.GetAllProjectsForUser)
 
@@ -90,13 +116,73 @@ func SetupRoutes(r chi.Router, db *sql.DB, authClient *auth.Client) {
 				// TODO: Group join and leave, as well as updating the member roles (to be added) owner, editor, viewer into one handler func, such as projectHandler.UpdateMember or something
 				r.Post("/leave", projectHandler.LeaveProject)
 
+				r.Get("/workflow", workflowHandler.GetWorkflow)
+				r.Put("/workflow", workflowHandler.UpdateWorkflow)
+
+				r.Route("/labels", func(r chi.Router) {
+					r.Get("/", labelHandler.GetLabels)
+					r.Post("/", labelHandler.CreateLabel)
+					r.Put("/{labelID}", labelHandler.UpdateLabel)
+					r.Delete("/{labelID}", labelHandler.DeleteLabel)
+				})
+
+				r.Get("/worklogs/totals", worklogHandler.GetTimeReport)
+
+				r.Route("/sprints", func(r chi.Router) {
+					r.Get("/", sprintHandler.GetSprints)
+					r.Post("/", sprintHandler.CreateSprint)
+					r.Get("/velocity", sprintHandler.GetVelocity)
+
+					r.Route("/{sprintID}", func(r chi.Router) {
+						r.Get("/", sprintHandler.GetSprint)
+						r.Put("/", sprintHandler.UpdateSprint)
+						r.Delete("/", sprintHandler.DeleteSprint)
+						r.Post("/start", sprintHandler.StartSprint)
+						r.Post("/close", sprintHandler.CloseSprint)
+						r.Get("/burndown", sprintHandler.GetBurndown)
+					})
+				})
+
 				r.Route("/tasks", func(r chi.Router) {
+					r.Get("/", taskHandler.ListTasks)
 					r.Post("/", taskHandler.CreateTask)
+					r.Post("/bulk", taskHandler.BulkUpdateTasks)
 
 					r.Route("/{taskID}", func(r chi.Router) {
 						r.Get("/", taskHandler.GetTaskByID)
 						r.Put("/", taskHandler.EditTask)
+						r.Patch("/", taskHandler.PatchTask)
 						r.Delete("/", taskHandler.DeleteTask)
+						r.Get("/activity", taskHandler.GetTaskActivity)
+						r.Post("/move", taskHandler.MoveTask)
+						r.Put("/recurrence", taskHandler.SetTaskRecurrence)
+						r.Delete("/recurrence", taskHandler.StopTaskRecurrence)
+						r.Get("/watchers", taskHandler.GetTaskWatchers)
+						r.Put("/watch", taskHandler.WatchTask)
+						r.Delete("/watch", taskHandler.UnwatchTask)
+
+						r.Route("/subtasks", func(r chi.Router) {
+							r.Get("/", taskHandler.ListSubtasks)
+							r.Post("/", taskHandler.CreateSubtask)
+						})
+
+						r.Route("/links", func(r chi.Router) {
+							r.Get("/", taskLinkHandler.GetTaskLinks)
+							r.Post("/", taskLinkHandler.CreateTaskLink)
+							r.Delete("/{linkID}", taskLinkHandler.DeleteTaskLink)
+						})
+
+						r.Route("/labels", func(r chi.Router) {
+							r.Put("/{labelID}", labelHandler.AddTaskLabel)
+							r.Delete("/{labelID}", labelHandler.RemoveTaskLabel)
+						})
+
+						r.Route("/worklogs", func(r chi.Router) {
+							r.Get("/", worklogHandler.GetTaskWorklogs)
+							r.Post("/", worklogHandler.CreateWorklog)
+							r.Put("/{worklogID}", worklogHandler.UpdateWorklog)
+							r.Delete("/{worklogID}", worklogHandler.DeleteWorklog)
+						})
 
 						r.Route("/comments", func(r chi.Router) {
 							r.Post("/", commentHandler.CreateComment)
@@ -111,8 +197,13 @@ func SetupRoutes(r chi.Router, db *sql.DB, authClient *auth.Client) {
 			})
 		})
 
+		api.Route("/tasks", func(r chi.Router) {
+			r.Use(authMiddleware.Authenticate)
+			r.Get("/by-key/{taskKey}", taskHandler.GetTaskByKey)
+		})
+
 		api.Route("/invitations", func(r chi.Router) {
-			r.Use(authMiddleware.FirebaseAuthMiddleware)
+			r.Use(authMiddleware.Authenticate)
 			r.Post("/", invitationHandler.CreateInvitation)
 			r.Get("/", invitationHandler.GetInvitations)
 
@@ -125,7 +216,7 @@ func SetupRoutes(r chi.Router, db *sql.DB, authClient *auth.Client) {
 		})
 
 		api.Route("/dashboard", func(r chi.Router) {
-			r.Use(authMiddleware.FirebaseAuthMiddleware)
+			r.Use(authMiddleware.Authenticate)
 			r.Get("/recently-assigned", dashboardHandler.GetRecentlyAssignedTasks)
 			r.Get("/in-progress", dashboardHandler.GetInProgressTasks)
 			r.Get("/approaching-deadline", dashboardHandler.GetApproachingDeadlineTasks)
@@ -135,12 +226,14 @@ func SetupRoutes(r chi.Router, db *sql.DB, authClient *auth.Client) {
 		api.Route("/notifications", func(r chi.Router) {
 
 			r.Group(func(r chi.Router) {
-				r.Use(authMiddleware.FirebaseAuthMiddleware)
+				r.Use(authMiddleware.Authenticate)
 				r.Get("/", notificationHandler.GetAllNotificationsForUser)
 				r.Post("/{notificationID}/read", notificationHandler.MarkNotificationAsRead)
 			})
 
-			r.Get("/ws", wsHub.HandleWebSocket)
+			if cfg.Features.WebSockets {
+				r.Get("/ws", wsHub.HandleWebSocket)
+			}
 		})
 
 	})
@@ -0,0 +1,284 @@
+package routes_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+)
+
+func TestInviteMemberAndAssignTask(t *testing.T) {
+	s := newTestServer(t)
+
+	alice := s.signUp("alice-uid", "Alice", "alice@example.com")
+	bob := s.signUp("bob-uid", "Bob", "bob@example.com")
+	bobSocket := s.connect(bob)
+
+	resp := s.do(http.MethodPost, "/api/v1/projects", &alice, models.CreateProjectDTO{Name: "Apollo", Description: "Moon landing", Key: "apo"})
+	expectStatus(t, resp, http.StatusCreated)
+
+	var projects []models.ProjectResponseDTO
+	s.getJSON("/api/v1/projects", &alice, &projects)
+	if len(projects) != 1 || projects[0].Key != "APO" {
+		t.Fatalf("expected alice to have project APO, got %+v", projects)
+	}
+	projectID := projects[0].ID
+	projectPath := "/api/v1/projects/" + projectID.String()
+
+	expectError(t, s.do(http.MethodGet, projectPath, &bob, nil), http.StatusForbidden, "forbidden")
+
+	var found []models.UserResponseDTO
+	resp = s.do(http.MethodPost, "/api/v1/users/search", &alice, map[string]string{"query": "bob"})
+	expectStatus(t, resp, http.StatusOK)
+	decode(t, resp, &found)
+	if len(found) != 1 || found[0].Email != bob.email {
+		t.Fatalf("expected search to find bob, got %+v", found)
+	}
+	bobID := found[0].ID
+
+	resp = s.do(http.MethodPost, "/api/v1/invitations", &alice, models.CreateInvitationDTO{InvitedUserID: bobID, ProjectID: projectID})
+	expectStatus(t, resp, http.StatusCreated)
+
+	if n := readNotification(t, bobSocket); n.Type != models.NotificationTypeProjectInvitation || n.ProjectID != projectID {
+		t.Fatalf("expected a live invitation notification, got %+v", n)
+	}
+
+	var invitations []models.InvitationResponseDTO
+	s.getJSON("/api/v1/invitations", &bob, &invitations)
+	if len(invitations) != 1 || invitations[0].ProjectName != "Apollo" || invitations[0].Status != models.InivtationStatusPending {
+		t.Fatalf("expected a pending invitation to Apollo, got %+v", invitations)
+	}
+	invitationID := invitations[0].ID
+
+	resp = s.do(http.MethodPut, "/api/v1/invitations/"+invitationID.String(), &bob, models.EditInvitationDTO{Status: string(models.InivtationStatusAccepted), ProjectID: projectID})
+	expectStatus(t, resp, http.StatusOK)
+
+	var project models.ProjectResponseDTO
+	s.getJSON(projectPath, &bob, &project)
+
+	var bobMember *models.ProjectMemberResponseDTO
+	for i, member := range project.ProjectMembers {
+		if member.UserID == bobID {
+			bobMember = &project.ProjectMembers[i]
+		}
+	}
+	if bobMember == nil || bobMember.Role != models.RoleViewer {
+		t.Fatalf("expected bob to have joined as a viewer, got %+v", project.ProjectMembers)
+	}
+
+	newTask := models.CreateTaskDTO{ProjectID: projectID, Title: "Land on the moon", Status: models.Todo, Priority: models.High}
+	expectError(t, s.do(http.MethodPost, projectPath+"/tasks", &bob, newTask), http.StatusForbidden, "forbidden")
+
+	resp = s.do(http.MethodPost, projectPath+"/tasks", &alice, newTask)
+	expectStatus(t, resp, http.StatusCreated)
+
+	s.getJSON(projectPath, &alice, &project)
+	if len(project.Tasks) != 1 || project.Tasks[0].TaskNumber != 1 {
+		t.Fatalf("expected task APO-1, got %+v", project.Tasks)
+	}
+	taskID := project.Tasks[0].ID
+	taskPath := projectPath + "/tasks/" + taskID.String()
+
+	resp = s.do(http.MethodPut, taskPath, &alice, models.UpdateTaskDTO{
+		Title:      newTask.Title,
+		Status:     models.InProgress,
+		Priority:   newTask.Priority,
+		AssignedTo: &bobMember.ID,
+	})
+	expectStatus(t, resp, http.StatusCreated)
+
+	if n := readNotification(t, bobSocket); n.Type != models.NotificationTypeTaskAssigned || n.TaskID != taskID {
+		t.Fatalf("expected a live assignment notification, got %+v", n)
+	}
+
+	var task models.TaskResponseDTO
+	s.getJSON(taskPath, &bob, &task)
+	if task.AssignedTo == nil || task.AssignedTo.UserID != bobID || task.Status != models.InProgress {
+		t.Fatalf("expected the task to be in progress and assigned to bob, got %+v", task)
+	}
+
+	var notifications []models.NotificationResponseDTO
+	s.getJSON("/api/v1/notifications", &bob, &notifications)
+	if len(notifications) != 2 {
+		t.Fatalf("expected 2 notifications, got %+v", notifications)
+	}
+	if notifications[0].Type != models.NotificationTypeTaskAssigned {
+		t.Errorf("expected the newest notification to be the assignment, got %s", notifications[0].Type)
+	}
+	if notifications[1].InvitationID != invitationID {
+		t.Errorf("expected the invitation notification to link invitation %s, got %s", invitationID, notifications[1].InvitationID)
+	}
+
+	var inProgress []models.TaskResponseDTO
+	s.getJSON("/api/v1/dashboard/in-progress?limit=5", &bob, &inProgress)
+	if len(inProgress) != 1 || inProgress[0].ID != taskID {
+		t.Fatalf("expected the task on bob's dashboard, got %+v", inProgress)
+	}
+}
+
+func TestErrorResponses(t *testing.T) {
+	s := newTestServer(t)
+
+	alice := s.signUp("alice-uid", "Alice", "alice@example.com")
+	bob := s.signUp("bob-uid", "Bob", "bob@example.com")
+
+	expectError(t, s.do(http.MethodGet, "/api/v1/projects", nil, nil), http.StatusUnauthorized, "unauthorized")
+
+	stranger := testUser{token: "not-a-token"}
+	expectError(t, s.do(http.MethodGet, "/api/v1/projects", &stranger, nil), http.StatusUnauthorized, "unauthorized")
+
+	expectError(t, s.do(http.MethodGet, "/api/v1/projects/not-a-uuid", &alice, nil), http.StatusBadRequest, "validation")
+
+	resp := s.do(http.MethodPost, "/api/v1/projects", &alice, models.CreateProjectDTO{Name: "Apollo", Key: "APO"})
+	expectStatus(t, resp, http.StatusCreated)
+	expectError(t, s.do(http.MethodPost, "/api/v1/projects", &bob, models.CreateProjectDTO{Name: "Artemis", Key: "apo"}), http.StatusConflict, "conflict")
+
+	var projects []models.ProjectResponseDTO
+	s.getJSON("/api/v1/projects", &alice, &projects)
+	projectPath := "/api/v1/projects/" + projects[0].ID.String()
+
+	expectError(t, s.do(http.MethodGet, projectPath+"/tasks/"+uuid.NewString(), &alice, nil), http.StatusNotFound, "not_found")
+	expectError(t, s.do(http.MethodDelete, projectPath, &bob, nil), http.StatusForbidden, "forbidden")
+	expectError(t, s.do(http.MethodPost, "/api/v1/users/create", nil, models.CreateUserDTO{FirebaseUID: "alice-uid", Name: "Alice", Email: "alice@example.com"}), http.StatusConflict, "conflict")
+}
+
+func TestRequestIDs(t *testing.T) {
+	s := newTestServer(t)
+
+	resp := s.do(http.MethodGet, "/api/v1/projects", nil, nil)
+	resp.Body.Close()
+	if _, err := uuid.Parse(resp.Header.Get("X-Request-ID")); err != nil {
+		t.Fatalf("generated request ID %q is not a UUID", resp.Header.Get("X-Request-ID"))
+	}
+
+	for sent, want := range map[string]string{
+		"edge-1234.abc":       "edge-1234.abc",
+		"bad id\twith spaces": "",
+	} {
+		req, err := http.NewRequest(http.MethodGet, s.server.URL+"/api/v1/projects", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-Request-ID", sent)
+
+		resp, err := s.server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		got := resp.Header.Get("X-Request-ID")
+		if want != "" && got != want {
+			t.Errorf("request ID %q: got %q back", sent, got)
+		}
+		if want == "" && (got == sent || got == "") {
+			t.Errorf("request ID %q should have been replaced, got %q", sent, got)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	s := newTestServer(t)
+
+	alice := s.signUp("alice-uid", "Alice", "alice@example.com")
+	bob := s.signUp("bob-uid", "Bob", "bob@example.com")
+	bobSocket := s.connect(bob)
+
+	resp := s.do(http.MethodPost, "/api/v1/projects", &alice, models.CreateProjectDTO{Name: "Apollo", Key: "apo"})
+	expectStatus(t, resp, http.StatusCreated)
+
+	var projects []models.ProjectResponseDTO
+	s.getJSON("/api/v1/projects", &alice, &projects)
+
+	var bobUser models.UserResponseDTO
+	s.getJSON("/api/v1/users", &bob, &bobUser)
+
+	expectError(t, s.do(http.MethodGet, "/api/v1/projects/"+uuid.NewString(), &bob, nil), http.StatusForbidden, "forbidden")
+	expectError(t, s.do(http.MethodGet, "/api/v1/projects", nil, nil), http.StatusUnauthorized, "unauthorized")
+
+	resp = s.do(http.MethodPost, "/api/v1/invitations", &alice, models.CreateInvitationDTO{InvitedUserID: bobUser.ID, ProjectID: projects[0].ID})
+	expectStatus(t, resp, http.StatusCreated)
+	readNotification(t, bobSocket)
+
+	scrape := func() string {
+		resp := s.do(http.MethodGet, "/metrics", nil, nil)
+		expectStatus(t, resp, http.StatusOK)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+
+	// The delivery is counted on the socket's writer goroutine just after bob has received it
+	body := scrape()
+	for deadline := time.Now().Add(2 * time.Second); !strings.Contains(body, "enlace_notifications_delivered_total 1\n") && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		body = scrape()
+	}
+
+	for _, want := range []string{
+		`enlace_http_requests_total{method="POST",route="/api/v1/projects",status="201"} 1`,
+		`enlace_http_requests_total{method="GET",route="/api/v1/projects/{projectID}",status="403"} 1`,
+		`enlace_http_request_duration_seconds_count{method="GET",route="/api/v1/projects"} 2`,
+		`enlace_errors_total{kind="forbidden"} 1`,
+		`enlace_errors_total{kind="unauthorized"} 1`,
+		`enlace_notifications_created_total 1`,
+		`enlace_notifications_delivered_total 1`,
+		`enlace_websocket_clients 1`,
+	} {
+		if !strings.Contains(body, want+"\n") {
+			t.Errorf("metrics missing %q in:\n%s", want, body)
+		}
+	}
+}
+
+func TestHealthProbes(t *testing.T) {
+	s := newTestServer(t)
+
+	resp := s.do(http.MethodGet, "/healthz", nil, nil)
+	expectStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+
+	type readiness struct {
+		Status       string `json:"status"`
+		Dependencies map[string]struct {
+			Status string `json:"status"`
+			Error  string `json:"error"`
+		} `json:"dependencies"`
+	}
+
+	var ready readiness
+	resp = s.do(http.MethodGet, "/readyz", nil, nil)
+	expectStatus(t, resp, http.StatusOK)
+	decode(t, resp, &ready)
+	for _, name := range []string{"database", "websocketHub", "auth"} {
+		if ready.Dependencies[name].Status != "ok" {
+			t.Errorf("expected %s to be ok, got %+v", name, ready.Dependencies[name])
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.hub.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	ready = readiness{}
+	resp = s.do(http.MethodGet, "/readyz", nil, nil)
+	expectStatus(t, resp, http.StatusServiceUnavailable)
+	decode(t, resp, &ready)
+	if ready.Status != "unavailable" || ready.Dependencies["websocketHub"].Status != "unavailable" || ready.Dependencies["database"].Status != "ok" {
+		t.Fatalf("expected only the stopped hub to fail readiness, got %+v", ready)
+	}
+
+	resp = s.do(http.MethodGet, "/healthz", nil, nil)
+	expectStatus(t, resp, http.StatusOK)
+	resp.Body.Close()
+}
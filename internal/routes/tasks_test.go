@@ -0,0 +1,1323 @@
+package routes_test
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+)
+
+func TestListTasks(t *testing.T) {
+	s := newTestServer(t)
+
+	alice := s.signUp("alice-uid", "Alice", "alice@example.com")
+	bob := s.signUp("bob-uid", "Bob", "bob@example.com")
+	project := s.createProject(alice, "Apollo", "apo")
+	aliceMember := project.ProjectMembers[0].ID
+	tasksPath := "/api/v1/projects/" + project.ID.String() + "/tasks"
+
+	day := func(d int) *time.Time {
+		due := time.Date(2030, time.January, d, 12, 0, 0, 0, time.UTC)
+		return &due
+	}
+	description := "needs a bigger rocket"
+
+	// APO-1 .. APO-5
+	s.createTask(alice, project.ID, models.CreateTaskDTO{Title: "Build rocket", Priority: models.Critical, DueDate: day(10), AssignedTo: &aliceMember, Description: &description})
+	s.createTask(alice, project.ID, models.CreateTaskDTO{Title: "Train crew", Status: models.InProgress, Priority: models.High, DueDate: day(5)})
+	s.createTask(alice, project.ID, models.CreateTaskDTO{Title: "Pick landing site", Status: models.Completed, Priority: models.Low})
+	s.createTask(alice, project.ID, models.CreateTaskDTO{Title: "Launch", Priority: models.Critical, DueDate: day(20), AssignedTo: &aliceMember})
+	s.createTask(alice, project.ID, models.CreateTaskDTO{Title: "Plant flag", Priority: models.Medium})
+
+	numbers := func(query string) []int {
+		t.Helper()
+		var page models.TaskListResponseDTO
+		s.getJSON(tasksPath+"?"+query, &alice, &page)
+		result := []int{}
+		for _, task := range page.Tasks {
+			result = append(result, task.TaskNumber)
+		}
+		return result
+	}
+
+	cases := []struct {
+		query string
+		want  []int
+	}{
+		{"", []int{1, 2, 3, 4, 5}},
+		{"status=todo,in-progress", []int{1, 2, 4, 5}},
+		{"status=todo&status=completed&priority=critical", []int{1, 4}},
+		{"assignee=me", []int{1, 4}},
+		{"assignee=unassigned", []int{2, 3, 5}},
+		{"assignee=" + aliceMember.String() + ",unassigned&priority=critical,high", []int{1, 2, 4}},
+		{"dueAfter=2030-01-05&dueBefore=" + url.QueryEscape("2030-01-20T12:00:00Z"), []int{1, 2}},
+		{"q=ROCKET", []int{1}},
+		{"sort=-priority,dueDate", []int{1, 4, 2, 5, 3}},
+		{"sort=-dueDate", []int{4, 1, 2, 3, 5}},
+		{"sort=status,-number", []int{5, 4, 1, 2, 3}},
+		{"sort=title", []int{1, 4, 3, 5, 2}},
+	}
+	for _, c := range cases {
+		if got := numbers(c.query); !slices.Equal(got, c.want) {
+			t.Errorf("?%s: expected tasks %v, got %v", c.query, c.want, got)
+		}
+	}
+
+	// Walking the pages must visit every task exactly once, in order
+	var visited []int
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("pagination never ended")
+		}
+		var page models.TaskListResponseDTO
+		s.getJSON(tasksPath+"?sort=-priority,dueDate&limit=2&cursor="+cursor, &alice, &page)
+		for _, task := range page.Tasks {
+			visited = append(visited, task.TaskNumber)
+		}
+		if page.NextCursor == nil {
+			break
+		}
+		cursor = url.QueryEscape(*page.NextCursor)
+	}
+	if want := []int{1, 4, 2, 5, 3}; !slices.Equal(visited, want) {
+		t.Errorf("expected pages to visit %v, got %v", want, visited)
+	}
+
+	var first models.TaskListResponseDTO
+	s.getJSON(tasksPath+"?limit=2", &alice, &first)
+	expectError(t, s.do(http.MethodGet, tasksPath+"?sort=title&cursor="+url.QueryEscape(*first.NextCursor), &alice, nil), http.StatusBadRequest, "validation")
+	expectError(t, s.do(http.MethodGet, tasksPath+"?cursor=garbage", &alice, nil), http.StatusBadRequest, "validation")
+	expectError(t, s.do(http.MethodGet, tasksPath+"?status=done&sort=colour&limit=0", &alice, nil), http.StatusBadRequest, "validation")
+	expectError(t, s.do(http.MethodGet, tasksPath, &bob, nil), http.StatusForbidden, "forbidden")
+	expectError(t, s.do(http.MethodGet, "/api/v1/projects/"+uuid.NewString()+"/tasks", &alice, nil), http.StatusForbidden, "forbidden")
+}
+
+func TestPatchTask(t *testing.T) {
+	s := newTestServer(t)
+
+	alice := s.signUp("alice-uid", "Alice", "alice@example.com")
+	bob := s.signUp("bob-uid", "Bob", "bob@example.com")
+	project := s.createProject(alice, "Apollo", "apo")
+	other := s.createProject(bob, "Gemini", "gem")
+
+	due := time.Date(2030, time.January, 10, 12, 0, 0, 0, time.UTC)
+	description := "needs a bigger rocket"
+	task := s.createTask(alice, project.ID, models.CreateTaskDTO{Title: "Build rocket", Priority: models.High, DueDate: &due, Description: &description})
+	taskPath := "/api/v1/projects/" + project.ID.String() + "/tasks/" + task.ID.String()
+
+	patch := func(body string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPatch, s.server.URL+taskPath, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		req.Header.Set("Authorization", "Bearer "+alice.token)
+		resp, err := s.server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	var patched models.TaskResponseDTO
+	resp := patch(`{"status": "in-progress"}`)
+	expectStatus(t, resp, http.StatusOK)
+	decode(t, resp, &patched)
+	if patched.Status != models.InProgress || patched.Title != task.Title || patched.Priority != models.High ||
+		patched.DueDate == nil || !patched.DueDate.Equal(due) || patched.Description == nil || *patched.Description != description {
+		t.Fatalf("expected only the status to change, got %+v", patched)
+	}
+
+	resp = patch(`{"dueDate": null, "description": null, "assignedTo": "` + project.ProjectMembers[0].ID.String() + `"}`)
+	expectStatus(t, resp, http.StatusOK)
+	decode(t, resp, &patched)
+	if patched.DueDate != nil || patched.Description != nil || patched.AssignedTo == nil || patched.Status != models.InProgress {
+		t.Fatalf("expected due date and description cleared and alice assigned, got %+v", patched)
+	}
+
+	// Nothing changes, so nothing is written
+	var unchanged models.TaskResponseDTO
+	resp = patch(`{"title": "Build rocket", "dueDate": null}`)
+	expectStatus(t, resp, http.StatusOK)
+	decode(t, resp, &unchanged)
+	if !unchanged.UpdatedAt.Equal(patched.UpdatedAt) {
+		t.Errorf("expected a no-op patch not to touch updatedAt, %s became %s", patched.UpdatedAt, unchanged.UpdatedAt)
+	}
+
+	resp = patch(`{"status": "done", "priority": null, "title": ""}`)
+	expectError(t, resp, http.StatusBadRequest, "validation")
+
+	expectError(t, patch(`{"colour": "red"}`), http.StatusBadRequest, "validation")
+	expectError(t, patch(`{"assignedTo": "`+other.ProjectMembers[0].ID.String()+`"}`), http.StatusBadRequest, "validation")
+	expectError(t, s.do(http.MethodPatch, taskPath, &bob, map[string]string{"status": "completed"}), http.StatusForbidden, "forbidden")
+	expectError(t, s.do(http.MethodPatch, "/api/v1/projects/"+project.ID.String()+"/tasks/"+uuid.NewString(), &alice, map[string]string{"status": "completed"}), http.StatusNotFound, "not_found")
+}
+
+func TestTaskActivity(t *testing.T) {
+	s := newTestServer(t)
+
+	alice := s.signUp("alice-uid", "Alice", "alice@example.com")
+	bob := s.signUp("bob-uid", "Bob", "bob@example.com")
+	project := s.createProject(alice, "Apollo", "apo")
+	task := s.createTask(alice, project.ID, models.CreateTaskDTO{Title: "Build rocket"})
+	taskPath := "/api/v1/projects/" + project.ID.String() + "/tasks/" + task.ID.String()
+
+	// A full update only records the fields it actually changes
+	expectStatus(t, s.do(http.MethodPut, taskPath, &alice, models.UpdateTaskDTO{
+		Title:    "Build a bigger rocket",
+		Status:   models.InProgress,
+		Priority: models.Medium,
+	}), http.StatusCreated)
+	expectStatus(t, s.do(http.MethodPost, taskPath+"/comments", &alice, map[string]string{"comment": "on it"}), http.StatusCreated)
+	expectStatus(t, s.do(http.MethodPatch, taskPath, &alice, map[string]string{"priority": "critical"}), http.StatusOK)
+
+	var timeline []models.TaskTimelineEntryDTO
+	s.getJSON(taskPath+"/activity", &alice, &timeline)
+
+	type entry struct {
+		kind     models.TaskTimelineEntryType
+		field    models.TaskActivityField
+		oldValue string
+		newValue string
+	}
+	var got []entry
+	for _, e := range timeline {
+		switch e.Type {
+		case models.TaskTimelineActivity:
+			if e.Activity.Actor.ID != project.ProjectMembers[0].ID || e.Activity.OldValue == nil || e.Activity.NewValue == nil {
+				t.Fatalf("unexpected activity entry %+v", e.Activity)
+			}
+			got = append(got, entry{e.Type, e.Activity.Field, *e.Activity.OldValue, *e.Activity.NewValue})
+		case models.TaskTimelineComment:
+			got = append(got, entry{kind: e.Type, newValue: e.Comment.Comment})
+		}
+	}
+
+	want := []entry{
+		{models.TaskTimelineActivity, models.TaskFieldTitle, "Build rocket", "Build a bigger rocket"},
+		{models.TaskTimelineActivity, models.TaskFieldStatus, "todo", "in-progress"},
+		{kind: models.TaskTimelineComment, newValue: "on it"},
+		{models.TaskTimelineActivity, models.TaskFieldPriority, "medium", "critical"},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected timeline %+v, got %+v", want, got)
+	}
+
+	expectError(t, s.do(http.MethodGet, taskPath+"/activity", &bob, nil), http.StatusForbidden, "forbidden")
+	expectError(t, s.do(http.MethodGet, "/api/v1/projects/"+project.ID.String()+"/tasks/"+uuid.NewString()+"/activity", &alice, nil), http.StatusNotFound, "not_found")
+}
+
+func TestSubtasks(t *testing.T) {
+	s := newTestServer(t)
+
+	alice := s.signUp("alice-uid", "Alice", "alice@example.com")
+	bob := s.signUp("bob-uid", "Bob", "bob@example.com")
+	project := s.createProject(alice, "Apollo", "apo")
+	other := s.createProject(bob, "Gemini", "gem")
+	projectPath := "/api/v1/projects/" + project.ID.String()
+
+	parent := s.createTask(alice, project.ID, models.CreateTaskDTO{Title: "Launch"})
+	parentPath := projectPath + "/tasks/" + parent.ID.String()
+
+	createSubtask := func(title string, status models.TaskStatus) models.TaskResponseDTO {
+		t.Helper()
+		var subtask models.TaskResponseDTO
+		resp := s.do(http.MethodPost, parentPath+"/subtasks", &alice, models.CreateTaskDTO{Title: title, Status: status, Priority: models.Medium})
+		expectStatus(t, resp, http.StatusCreated)
+		decode(t, resp, &subtask)
+		if subtask.ParentID == nil || *subtask.ParentID != parent.ID {
+			t.Fatalf("expected %q to be a subtask of the launch, got %+v", title, subtask.ParentID)
+		}
+		return subtask
+	}
+	fuel := createSubtask("Fuel", models.Completed)
+	countdown := createSubtask("Countdown", models.Todo)
+
+	var loaded models.TaskResponseDTO
+	s.getJSON(parentPath, &alice, &loaded)
+	if loaded.TotalSubtasks != 2 || loaded.SubtasksCompleted != 1 || loaded.CompletionPercentage != 50 {
+		t.Errorf("expected 1 of 2 subtasks completed, got %d of %d (%d%%)", loaded.SubtasksCompleted, loaded.TotalSubtasks, loaded.CompletionPercentage)
+	}
+
+	var page models.TaskListResponseDTO
+	s.getJSON(parentPath+"/subtasks?sort=title", &alice, &page)
+	if len(page.Tasks) != 2 || page.Tasks[0].ID != countdown.ID || page.Tasks[1].ID != fuel.ID {
+		t.Errorf("expected the two subtasks sorted by title, got %+v", page.Tasks)
+	}
+
+	var full models.ProjectResponseDTO
+	s.getJSON(projectPath, &alice, &full)
+	if full.TotalTasks != 3 || full.TotalSubtasks != 2 || full.SubtasksCompleted != 1 || full.CompletionPercentage != 33 {
+		t.Errorf("unexpected project counts %d tasks, %d of %d subtasks completed, %d%%",
+			full.TotalTasks, full.SubtasksCompleted, full.TotalSubtasks, full.CompletionPercentage)
+	}
+
+	// Parents must be in the same project and can't create a cycle
+	foreign := s.createTask(bob, other.ID, models.CreateTaskDTO{Title: "Orbit"})
+	expectError(t, s.do(http.MethodPost, projectPath+"/tasks", &alice, models.CreateTaskDTO{
+		ProjectID: project.ID, Title: "Stray", Status: models.Todo, Priority: models.Low, ParentID: &foreign.ID,
+	}), http.StatusBadRequest, "validation")
+	expectError(t, s.do(http.MethodPatch, parentPath, &alice, map[string]string{"parentId": parent.ID.String()}), http.StatusBadRequest, "validation")
+	expectError(t, s.do(http.MethodPatch, parentPath, &alice, map[string]string{"parentId": fuel.ID.String()}), http.StatusBadRequest, "validation")
+
+	// Moving a subtask under its sibling is fine
+	var moved models.TaskResponseDTO
+	resp := s.do(http.MethodPatch, projectPath+"/tasks/"+fuel.ID.String(), &alice, map[string]string{"parentId": countdown.ID.String()})
+	expectStatus(t, resp, http.StatusOK)
+	decode(t, resp, &moved)
+	if moved.ParentID == nil || *moved.ParentID != countdown.ID {
+		t.Fatalf("expected fuel to move under the countdown, got %+v", moved.ParentID)
+	}
+	expectError(t, s.do(http.MethodPatch, parentPath, &alice, map[string]string{"parentId": fuel.ID.String()}), http.StatusBadRequest, "validation")
+
+	expectError(t, s.do(http.MethodGet, parentPath+"/subtasks", &bob, nil), http.StatusForbidden, "forbidden")
+	expectError(t, s.do(http.MethodPost, projectPath+"/tasks/"+uuid.NewString()+"/subtasks", &alice, models.CreateTaskDTO{Title: "Lost"}), http.StatusNotFound, "not_found")
+
+	// Deleting a parent promotes its subtasks
+	expectStatus(t, s.do(http.MethodDelete, parentPath, &alice, nil), http.StatusOK)
+	s.getJSON(projectPath+"/tasks/"+countdown.ID.String(), &alice, &loaded)
+	if loaded.ParentID != nil || loaded.TotalSubtasks != 1 {
+		t.Errorf("expected the countdown to be a top level task with one subtask, got parent %v and %d subtasks", loaded.ParentID, loaded.TotalSubtasks)
+	}
+}
+
+func TestTaskLinks(t *testing.T) {
+	s := newTestServer(t)
+
+	alice := s.signUp("alice-uid", "Alice", "alice@example.com")
+	bob := s.signUp("bob-uid", "Bob", "bob@example.com")
+	project := s.createProject(alice, "Apollo", "apo")
+	s.addMember(alice, project.ID, bob, models.RoleEditor)
+	projectPath := "/api/v1/projects/" + project.ID.String()
+	taskPath := func(task models.TaskResponseDTO) string {
+		return projectPath + "/tasks/" + task.ID.String()
+	}
+
+	fuel := s.createTask(alice, project.ID, models.CreateTaskDTO{Title: "Fuel"})
+	checks := s.createTask(alice, project.ID, models.CreateTaskDTO{Title: "Checks"})
+	launch := s.createTask(alice, project.ID, models.CreateTaskDTO{Title: "Launch"})
+
+	link := func(from models.TaskResponseDTO, linkType models.TaskLinkType, to models.TaskResponseDTO) *http.Response {
+		t.Helper()
+		return s.do(http.MethodPost, taskPath(from)+"/links", &bob, models.CreateTaskLinkDTO{Type: linkType, TargetTaskID: to.ID})
+	}
+
+	// Both directions are stored as the same kind of link
+	var created models.TaskLinkResponseDTO
+	resp := link(launch, models.TaskLinkBlockedBy, fuel)
+	expectStatus(t, resp, http.StatusCreated)
+	decode(t, resp, &created)
+	if created.Type != models.TaskLinkBlockedBy || created.Task.ID != fuel.ID {
+		t.Fatalf("expected launch to be blocked by the fuel, got %+v", created)
+	}
+	expectStatus(t, link(checks, models.TaskLinkBlocks, launch), http.StatusCreated)
+	expectStatus(t, link(fuel, models.TaskLinkRelatesTo, checks), http.StatusCreated)
+
+	var links []models.TaskLinkResponseDTO
+	s.getJSON(taskPath(fuel)+"/links", &alice, &links)
+	if len(links) != 2 || links[0].Type != models.TaskLinkBlocks || links[0].Task.ID != launch.ID ||
+		links[1].Type != models.TaskLinkRelatesTo || links[1].Task.ID != checks.ID {
+		t.Fatalf("unexpected links of the fuel task %+v", links)
+	}
+
+	expectError(t, link(checks, models.TaskLinkRelatesTo, fuel), http.StatusConflict, "conflict")
+	expectError(t, link(launch, models.TaskLinkBlocks, fuel), http.StatusBadRequest, "validation")
+	expectError(t, link(fuel, models.TaskLinkBlocks, fuel), http.StatusBadRequest, "validation")
+	expectError(t, link(fuel, "causes", launch), http.StatusBadRequest, "validation")
+
+	var loaded models.TaskResponseDTO
+	s.getJSON(taskPath(launch), &alice, &loaded)
+	if len(loaded.OpenBlockers) != 2 || loaded.OpenBlockers[0].ID != fuel.ID || loaded.OpenBlockers[1].ID != checks.ID {
+		t.Fatalf("expected the fuel and checks to block the launch, got %+v", loaded.OpenBlockers)
+	}
+
+	// Completing a blocker unblocks the task
+	expectStatus(t, s.do(http.MethodPatch, taskPath(fuel), &bob, map[string]string{"status": "completed"}), http.StatusOK)
+	s.getJSON(taskPath(launch), &alice, &loaded)
+	if len(loaded.OpenBlockers) != 1 || loaded.OpenBlockers[0].ID != checks.ID {
+		t.Fatalf("expected only the checks to block the launch, got %+v", loaded.OpenBlockers)
+	}
+
+	complete := map[string]string{"status": "completed"}
+	expectError(t, s.do(http.MethodPatch, taskPath(launch), &bob, complete), http.StatusConflict, "conflict")
+	expectError(t, s.do(http.MethodPut, taskPath(launch), &alice, models.UpdateTaskDTO{Title: "Launch", Status: models.Completed, Priority: models.Medium}), http.StatusConflict, "conflict")
+	expectError(t, s.do(http.MethodPatch, taskPath(launch)+"?overrideBlockers=true", &bob, complete), http.StatusForbidden, "forbidden")
+
+	resp = s.do(http.MethodPatch, taskPath(launch)+"?overrideBlockers=true", &alice, complete)
+	expectStatus(t, resp, http.StatusOK)
+	decode(t, resp, &loaded)
+	if loaded.Status != models.Completed {
+		t.Fatalf("expected the owner to complete the launch, got %s", loaded.Status)
+	}
+
+	expectStatus(t, s.do(http.MethodDelete, taskPath(launch)+"/links/"+created.ID.String(), &bob, nil), http.StatusOK)
+	s.getJSON(taskPath(launch)+"/links", &alice, &links)
+	if len(links) != 1 || links[0].Type != models.TaskLinkBlockedBy || links[0].Task.ID != checks.ID {
+		t.Fatalf("expected only the checks link to remain, got %+v", links)
+	}
+	expectError(t, s.do(http.MethodDelete, taskPath(fuel)+"/links/"+links[0].ID.String(), &bob, nil), http.StatusNotFound, "not_found")
+}
+
+func TestLabels(t *testing.T) {
+	s := newTestServer(t)
+
+	alice := s.signUp("alice-uid", "Alice", "alice@example.com")
+	carol := s.signUp("carol-uid", "Carol", "carol@example.com")
+	project := s.createProject(alice, "Apollo", "apo")
+	s.addMember(alice, project.ID, carol, models.RoleViewer)
+	projectPath := "/api/v1/projects/" + project.ID.String()
+
+	createLabel := func(user testUser, name string, color string) *http.Response {
+		t.Helper()
+		return s.do(http.MethodPost, projectPath+"/labels", &user, models.CreateLabelDTO{Name: name, Color: color})
+	}
+
+	var bug models.LabelResponseDTO
+	resp := createLabel(alice, "  Bug ", "#D73A4A")
+	expectStatus(t, resp, http.StatusCreated)
+	decode(t, resp, &bug)
+	if bug.Name != "Bug" || bug.Color != "#d73a4a" || bug.ProjectID != project.ID {
+		t.Fatalf("expected a normalized label, got %+v", bug)
+	}
+
+	expectError(t, createLabel(carol, "Docs", "#0075ca"), http.StatusForbidden, "forbidden")
+	expectError(t, createLabel(alice, "bug", "#0075ca"), http.StatusConflict, "conflict")
+	expectError(t, createLabel(alice, "Docs", "blue"), http.StatusBadRequest, "validation")
+
+	var docs models.LabelResponseDTO
+	resp = createLabel(alice, "Docs", "#0075ca")
+	expectStatus(t, resp, http.StatusCreated)
+	decode(t, resp, &docs)
+
+	var labels []models.LabelResponseDTO
+	s.getJSON(projectPath+"/labels", &carol, &labels)
+	if len(labels) != 2 || labels[0].ID != bug.ID || labels[1].ID != docs.ID {
+		t.Fatalf("expected the viewer to see both labels, got %+v", labels)
+	}
+
+	crash := s.createTask(alice, project.ID, models.CreateTaskDTO{ProjectID: project.ID, Title: "Fix crash"})
+	s.createTask(alice, project.ID, models.CreateTaskDTO{ProjectID: project.ID, Title: "Fix typo"})
+	crashPath := projectPath + "/tasks/" + crash.ID.String()
+
+	var labelled models.TaskResponseDTO
+	resp = s.do(http.MethodPut, crashPath+"/labels/"+bug.ID.String(), &alice, nil)
+	expectStatus(t, resp, http.StatusOK)
+	decode(t, resp, &labelled)
+	if len(labelled.Labels) != 1 || labelled.Labels[0].ID != bug.ID {
+		t.Fatalf("expected the crash to be labelled a bug, got %+v", labelled.Labels)
+	}
+	expectStatus(t, s.do(http.MethodPut, crashPath+"/labels/"+bug.ID.String(), &alice, nil), http.StatusOK)
+	expectError(t, s.do(http.MethodPut, crashPath+"/labels/"+docs.ID.String(), &carol, nil), http.StatusForbidden, "forbidden")
+
+	var page models.TaskListResponseDTO
+	s.getJSON(projectPath+"/tasks?label="+bug.ID.String()+","+docs.ID.String(), &alice, &page)
+	if len(page.Tasks) != 1 || page.Tasks[0].ID != crash.ID {
+		t.Fatalf("expected only the crash to have a label, got %+v", page.Tasks)
+	}
+	expectError(t, s.do(http.MethodGet, projectPath+"/tasks?label=bug", &alice, nil), http.StatusBadRequest, "validation")
+
+	var result models.SearchResult
+	s.getJSON("/api/v1/dashboard/search?labels=BUG", &alice, &result)
+	if len(result.Tasks) != 1 || result.Tasks[0].ID != crash.ID || len(result.Tasks[0].Labels) != 1 {
+		t.Fatalf("expected the search by label to find the crash, got %+v", result.Tasks)
+	}
+	s.getJSON("/api/v1/dashboard/search?query=fix&labels=docs", &alice, &result)
+	if len(result.Tasks) != 0 {
+		t.Fatalf("expected no tasks labelled docs, got %+v", result.Tasks)
+	}
+
+	// Deleting a label takes it off its tasks
+	expectStatus(t, s.do(http.MethodDelete, projectPath+"/labels/"+bug.ID.String(), &alice, nil), http.StatusOK)
+	s.getJSON(crashPath, &alice, &labelled)
+	if len(labelled.Labels) != 0 {
+		t.Fatalf("expected the deleted label to be gone from the task, got %+v", labelled.Labels)
+	}
+}
+
+func TestWorkflow(t *testing.T) {
+	s := newTestServer(t)
+
+	alice := s.signUp("alice-uid", "Alice", "alice@example.com")
+	bob := s.signUp("bob-uid", "Bob", "bob@example.com")
+	project := s.createProject(alice, "Apollo", "apo")
+	bobMember := s.addMember(alice, project.ID, bob, models.RoleEditor)
+	projectPath := "/api/v1/projects/" + project.ID.String()
+
+	var workflow models.WorkflowDTO
+	s.getJSON(projectPath+"/workflow", &bob, &workflow)
+	if workflow.Keys() != "todo, in-progress, completed" || !workflow.CanTransition(models.Todo, models.Completed) {
+		t.Fatalf("expected the default workflow, got %+v", workflow)
+	}
+
+	// Review sits between in-progress and completed, and completed tasks can only be reopened
+	custom := models.WorkflowDTO{Statuses: []models.TaskStatusDTO{
+		{Key: "todo", Name: "To do", Category: models.StatusCategoryTodo, Transitions: []models.TaskStatus{"in-progress"}},
+		{Key: "in-progress", Name: "In progress", Category: models.StatusCategoryActive},
+		{Key: "in-review", Name: "In review", Category: models.StatusCategoryActive, Transitions: []models.TaskStatus{"in-progress", "completed"}},
+		{Key: "completed", Name: "Completed", Category: models.StatusCategoryDone, Transitions: []models.TaskStatus{"todo"}},
+	}}
+	expectError(t, s.do(http.MethodPut, projectPath+"/workflow", &bob, custom), http.StatusForbidden, "forbidden")
+	invalid := models.WorkflowDTO{Statuses: []models.TaskStatusDTO{
+		{Key: "Doing", Name: "Doing", Category: models.StatusCategoryActive, Transitions: []models.TaskStatus{"done"}},
+	}}
+	expectError(t, s.do(http.MethodPut, projectPath+"/workflow", &alice, invalid), http.StatusBadRequest, "validation")
+
+	resp := s.do(http.MethodPut, projectPath+"/workflow", &alice, custom)
+	expectStatus(t, resp, http.StatusOK)
+	decode(t, resp, &workflow)
+	if review := workflow.Status("in-review"); review == nil || review.Position != 2 || len(workflow.Status("in-progress").Transitions) != 3 {
+		t.Fatalf("expected the custom workflow with in-progress open to every status, got %+v", workflow)
+	}
+
+	task := s.createTask(alice, project.ID, models.CreateTaskDTO{Title: "Launch", AssignedTo: &bobMember.ID})
+	taskPath := projectPath + "/tasks/" + task.ID.String()
+	move := func(status models.TaskStatus) *http.Response {
+		t.Helper()
+		return s.do(http.MethodPatch, taskPath, &bob, map[string]models.TaskStatus{"status": status})
+	}
+
+	expectError(t, move("completed"), http.StatusConflict, "conflict")
+	expectError(t, move("done"), http.StatusBadRequest, "validation")
+	expectStatus(t, move("in-progress"), http.StatusOK)
+
+	resp = move("in-review")
+	expectStatus(t, resp, http.StatusOK)
+	decode(t, resp, &task)
+	if task.Status != "in-review" || task.StatusCategory != models.StatusCategoryActive {
+		t.Fatalf("expected the task to be in review, got %s (%s)", task.Status, task.StatusCategory)
+	}
+
+	// The dashboard goes by category, so a task in review counts as in progress
+	var inProgress []models.TaskResponseDTO
+	s.getJSON("/api/v1/dashboard/in-progress?limit=5", &bob, &inProgress)
+	if len(inProgress) != 1 || inProgress[0].ID != task.ID {
+		t.Fatalf("expected the task in review to be in progress, got %+v", inProgress)
+	}
+
+	expectStatus(t, move("completed"), http.StatusOK)
+	var loaded models.ProjectResponseDTO
+	s.getJSON(projectPath, &alice, &loaded)
+	if loaded.TasksCompleted != 1 {
+		t.Fatalf("expected the completed task to count, got %d", loaded.TasksCompleted)
+	}
+
+	// A status can't be removed while tasks are in it
+	withoutCompleted := models.WorkflowDTO{Statuses: custom.Statuses[:2]}
+	withoutCompleted.Statuses[0].Transitions = nil
+	expectError(t, s.do(http.MethodPut, projectPath+"/workflow", &alice, withoutCompleted), http.StatusConflict, "conflict")
+}
+
+func TestMoveTask(t *testing.T) {
+	s := newTestServer(t)
+
+	alice := s.signUp("alice-uid", "Alice", "alice@example.com")
+	bob := s.signUp("bob-uid", "Bob", "bob@example.com")
+	carol := s.signUp("carol-uid", "Carol", "carol@example.com")
+	project := s.createProject(alice, "Apollo", "apo")
+	s.addMember(alice, project.ID, bob, models.RoleEditor)
+	s.addMember(alice, project.ID, carol, models.RoleViewer)
+	projectPath := "/api/v1/projects/" + project.ID.String()
+	conn := s.connect(bob)
+
+	tasks := map[string]models.TaskResponseDTO{}
+	for _, title := range []string{"A", "B", "C", "D"} {
+		tasks[title] = s.createTask(alice, project.ID, models.CreateTaskDTO{Title: title})
+	}
+	id := func(title string) *uuid.UUID {
+		taskID := tasks[title].ID
+		return &taskID
+	}
+	move := func(user testUser, title string, body models.MoveTaskDTO) *http.Response {
+		t.Helper()
+		return s.do(http.MethodPost, projectPath+"/tasks/"+tasks[title].ID.String()+"/move", &user, body)
+	}
+	column := func(status models.TaskStatus) string {
+		t.Helper()
+		var page models.TaskListResponseDTO
+		s.getJSON(projectPath+"/tasks?sort=rank&status="+string(status), &alice, &page)
+		titles := make([]string, len(page.Tasks))
+		for i, task := range page.Tasks {
+			titles[i] = task.Title
+		}
+		return strings.Join(titles, ",")
+	}
+
+	if got := column(models.Todo); got != "A,B,C,D" {
+		t.Fatalf("expected new tasks at the bottom of the column, got %s", got)
+	}
+
+	resp := move(alice, "D", models.MoveTaskDTO{BeforeTaskID: id("A")})
+	expectStatus(t, resp, http.StatusOK)
+	if got := column(models.Todo); got != "D,A,B,C" {
+		t.Fatalf("expected D at the top, got %s", got)
+	}
+
+	var event models.ProjectEventDTO
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err := conn.ReadJSON(&event); err != nil {
+		t.Fatalf("read event: %v", err)
+	}
+	if event.Type != models.ProjectEventTaskMoved || event.Task == nil || event.Task.ID != tasks["D"].ID {
+		t.Fatalf("expected a task_moved event for D, got %+v", event)
+	}
+
+	// Moving to another column changes the status, and the project lists tasks in board order
+	expectStatus(t, move(bob, "B", models.MoveTaskDTO{Status: models.InProgress}), http.StatusOK)
+	resp = move(bob, "C", models.MoveTaskDTO{Status: models.InProgress, BeforeTaskID: id("B")})
+	expectStatus(t, resp, http.StatusOK)
+	var moved models.TaskResponseDTO
+	decode(t, resp, &moved)
+	if moved.Status != models.InProgress {
+		t.Fatalf("expected C to be in progress, got %s", moved.Status)
+	}
+	var loaded models.ProjectResponseDTO
+	s.getJSON(projectPath, &alice, &loaded)
+	var board []string
+	for _, task := range loaded.Tasks {
+		board = append(board, task.Title)
+	}
+	if got := strings.Join(board, ","); got != "D,A,C,B" {
+		t.Fatalf("expected the project's tasks in board order, got %s", got)
+	}
+
+	expectError(t, move(bob, "A", models.MoveTaskDTO{Status: models.InProgress, AfterTaskID: id("B"), BeforeTaskID: id("C")}), http.StatusConflict, "conflict")
+	expectError(t, move(bob, "A", models.MoveTaskDTO{AfterTaskID: id("B")}), http.StatusBadRequest, "validation")
+	expectError(t, move(bob, "A", models.MoveTaskDTO{AfterTaskID: id("A")}), http.StatusBadRequest, "validation")
+	expectError(t, move(carol, "A", models.MoveTaskDTO{BeforeTaskID: id("D")}), http.StatusForbidden, "forbidden")
+
+	// Moving into the same gap over and over runs out of room, and the column is renumbered
+	rebalanced := false
+	for i := 0; i < 20; i++ {
+		title := []string{"B", "C"}[i%2]
+		expectStatus(t, move(bob, title, models.MoveTaskDTO{Status: models.Todo, AfterTaskID: id("D")}), http.StatusOK)
+		for {
+			conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			if err := conn.ReadJSON(&event); err != nil {
+				t.Fatalf("read event: %v", err)
+			}
+			if event.Type == models.ProjectEventTaskMoved && event.Task.Title == title {
+				break
+			}
+		}
+		rebalanced = rebalanced || event.Rebalanced
+	}
+	if !rebalanced {
+		t.Fatal("expected a move to renumber the column")
+	}
+	if got := column(models.Todo); got != "D,C,B,A" {
+		t.Fatalf("expected the last moved task first after D, got %s", got)
+	}
+}
+
+func TestTaskKeys(t *testing.T) {
+	s := newTestServer(t)
+
+	alice := s.signUp("alice-uid", "Alice", "alice@example.com")
+	bob := s.signUp("bob-uid", "Bob", "bob@example.com")
+	apollo := s.createProject(alice, "Apollo", "apo")
+	zeus := s.createProject(bob, "Zeus", "zeu")
+	var loaded models.ProjectResponseDTO
+	s.getJSON("/api/v1/projects/"+apollo.ID.String(), &alice, &loaded)
+	aliceMember := loaded.ProjectMembers[0]
+
+	followUp := s.createTask(alice, apollo.ID, models.CreateTaskDTO{Title: "Follow up on apo-2", AssignedTo: &aliceMember.ID})
+	launch := s.createTask(alice, apollo.ID, models.CreateTaskDTO{Title: "Launch"})
+	s.createTask(bob, zeus.ID, models.CreateTaskDTO{Title: "Secret"})
+
+	var task models.TaskResponseDTO
+	s.getJSON("/api/v1/tasks/by-key/apo-2", &alice, &task)
+	if task.ID != launch.ID {
+		t.Fatalf("expected APO-2 to be the launch task, got %+v", task)
+	}
+	expectError(t, s.do(http.MethodGet, "/api/v1/tasks/by-key/APO-99", &alice, nil), http.StatusNotFound, "not_found")
+	expectError(t, s.do(http.MethodGet, "/api/v1/tasks/by-key/ZEU-1", &alice, nil), http.StatusNotFound, "not_found")
+	expectError(t, s.do(http.MethodGet, "/api/v1/tasks/by-key/launch", &alice, nil), http.StatusBadRequest, "validation")
+
+	// The exact key comes before a title that merely mentions it, even one assigned to the searcher
+	var result models.SearchResult
+	s.getJSON("/api/v1/dashboard/search?query=APO-2", &alice, &result)
+	if len(result.Tasks) != 2 || result.Tasks[0].ID != launch.ID || result.Tasks[1].ID != followUp.ID {
+		t.Fatalf("expected APO-2 first and then the task mentioning it, got %+v", result.Tasks)
+	}
+
+	taskPath := "/api/v1/projects/" + apollo.ID.String() + "/tasks/" + followUp.ID.String()
+	expectStatus(t, s.do(http.MethodPost, taskPath+"/comments", &alice, map[string]string{"comment": "Waiting on APO-2, see ZEU-1 & <b>APO-9</b>"}), http.StatusCreated)
+
+	var comments []models.CommentResponseDTO
+	s.getJSON(taskPath+"/comments", &alice, &comments)
+	want := `Waiting on <a href="/projects/` + apollo.ID.String() + `/tasks/` + launch.ID.String() + `" title="Launch">APO-2</a>, see ZEU-1 &amp; &lt;b&gt;APO-9&lt;/b&gt;`
+	if len(comments) != 1 || comments[0].CommentHTML != want {
+		t.Fatalf("expected only the visible key to be linked\nwant %s\ngot  %+v", want, comments)
+	}
+
+	// A single comment is for members only, and only on the task it was made on
+	commentPath := taskPath + "/comments/" + comments[0].ID.String()
+	var comment models.CommentResponseDTO
+	s.getJSON(commentPath, &alice, &comment)
+	if comment.ID != comments[0].ID || comment.CommentHTML != want {
+		t.Fatalf("expected the comment rendered like in the list, got %+v", comment)
+	}
+	expectError(t, s.do(http.MethodGet, commentPath, &bob, nil), http.StatusForbidden, "forbidden")
+	launchPath := "/api/v1/projects/" + apollo.ID.String() + "/tasks/" + launch.ID.String()
+	expectError(t, s.do(http.MethodGet, launchPath+"/comments/"+comments[0].ID.String(), &alice, nil), http.StatusNotFound, "not_found")
+}
+
+func TestBulkTasks(t *testing.T) {
+	s := newTestServer(t)
+
+	alice := s.signUp("alice-uid", "Alice", "alice@example.com")
+	bob := s.signUp("bob-uid", "Bob", "bob@example.com")
+	carol := s.signUp("carol-uid", "Carol", "carol@example.com")
+	project := s.createProject(alice, "Apollo", "apo")
+	bobMember := s.addMember(alice, project.ID, bob, models.RoleEditor)
+	s.addMember(alice, project.ID, carol, models.RoleViewer)
+	projectPath := "/api/v1/projects/" + project.ID.String()
+
+	var ids []uuid.UUID
+	for _, title := range []string{"Design", "Build", "Ship"} {
+		ids = append(ids, s.createTask(alice, project.ID, models.CreateTaskDTO{Title: title}).ID)
+	}
+	bobsTask := s.createTask(bob, project.ID, models.CreateTaskDTO{Title: "Notes"})
+
+	bulk := func(user testUser, body map[string]any) *http.Response {
+		t.Helper()
+		return s.do(http.MethodPost, projectPath+"/tasks/bulk", &user, body)
+	}
+
+	resp := bulk(alice, map[string]any{"taskIds": ids, "assignedTo": bobMember.ID, "priority": "high"})
+	expectStatus(t, resp, http.StatusOK)
+	var result models.BulkTaskResponseDTO
+	decode(t, resp, &result)
+	if !result.Applied || len(result.Results) != 3 {
+		t.Fatalf("expected the assignment to be applied to 3 tasks, got %+v", result)
+	}
+	for i, item := range result.Results {
+		if item.TaskID != ids[i] || item.Outcome != models.BulkTaskUpdated || item.Task.Priority != models.High || item.Task.AssignedTo.ID != bobMember.ID {
+			t.Fatalf("expected task %d to be assigned to bob with high priority, got %+v", i, item)
+		}
+	}
+
+	// The three assignments make one notification
+	var notifications []models.NotificationResponseDTO
+	s.getJSON("/api/v1/notifications", &bob, &notifications)
+	var assigned []models.NotificationResponseDTO
+	for _, notification := range notifications {
+		if notification.Type == models.NotificationTypeTaskAssigned {
+			assigned = append(assigned, notification)
+		}
+	}
+	if len(assigned) != 1 || !strings.Contains(assigned[0].Content, "3 tasks") {
+		t.Fatalf("expected one notification about 3 tasks, got %+v", assigned)
+	}
+
+	resp = bulk(bob, map[string]any{"taskIds": ids[:2], "priority": "high"})
+	expectStatus(t, resp, http.StatusOK)
+	decode(t, resp, &result)
+	if result.Results[0].Outcome != models.BulkTaskUnchanged || result.Results[1].Outcome != models.BulkTaskUnchanged {
+		t.Fatalf("expected tasks that already have the priority to be unchanged, got %+v", result.Results)
+	}
+
+	// Bob may only delete his own tasks, so nothing is deleted
+	resp = bulk(bob, map[string]any{"taskIds": []uuid.UUID{bobsTask.ID, ids[0], uuid.New()}, "delete": true})
+	expectStatus(t, resp, http.StatusUnprocessableEntity)
+	decode(t, resp, &result)
+	outcomes := []models.BulkTaskOutcome{result.Results[0].Outcome, result.Results[1].Outcome, result.Results[2].Outcome}
+	if result.Applied || !slices.Equal(outcomes, []models.BulkTaskOutcome{models.BulkTaskSkipped, models.BulkTaskFailed, models.BulkTaskFailed}) ||
+		result.Results[1].Error.Code != "forbidden" || result.Results[2].Error.Code != "not_found" {
+		t.Fatalf("expected bob's task to be skipped and the others to fail, got %+v", result)
+	}
+	expectStatus(t, s.do(http.MethodGet, projectPath+"/tasks/"+bobsTask.ID.String(), &bob, nil), http.StatusOK)
+
+	expectError(t, bulk(carol, map[string]any{"taskIds": ids, "priority": "low"}), http.StatusForbidden, "forbidden")
+	expectError(t, bulk(alice, map[string]any{"taskIds": []uuid.UUID{}, "priority": "low"}), http.StatusBadRequest, "validation")
+	expectError(t, bulk(alice, map[string]any{"taskIds": ids, "priority": "low", "delete": true}), http.StatusBadRequest, "validation")
+
+	resp = bulk(alice, map[string]any{"taskIds": []uuid.UUID{bobsTask.ID, ids[0]}, "delete": true})
+	expectStatus(t, resp, http.StatusOK)
+	decode(t, resp, &result)
+	if !result.Applied || result.Results[0].Outcome != models.BulkTaskDeleted {
+		t.Fatalf("expected the owner to delete both tasks, got %+v", result)
+	}
+	expectError(t, s.do(http.MethodGet, projectPath+"/tasks/"+ids[0].String(), &alice, nil), http.StatusNotFound, "not_found")
+
+	// Owning another project doesn't reach this one's tasks through its URL, one at a time or in bulk
+	carolsPath := "/api/v1/projects/" + s.createProject(carol, "Zeus", "zeu").ID.String()
+	expectError(t, s.do(http.MethodDelete, carolsPath+"/tasks/"+ids[1].String(), &carol, nil), http.StatusNotFound, "not_found")
+	resp = s.do(http.MethodPost, carolsPath+"/tasks/bulk", &carol, map[string]any{"taskIds": []uuid.UUID{ids[1]}, "delete": true})
+	expectStatus(t, resp, http.StatusUnprocessableEntity)
+	expectStatus(t, s.do(http.MethodGet, projectPath+"/tasks/"+ids[1].String(), &alice, nil), http.StatusOK)
+}
+
+func TestRecurringTasks(t *testing.T) {
+	s := newTestServer(t)
+
+	alice := s.signUp("alice-uid", "Alice", "alice@example.com")
+	bob := s.signUp("bob-uid", "Bob", "bob@example.com")
+	project := s.createProject(alice, "Apollo", "apo")
+	s.addMember(alice, project.ID, bob, models.RoleViewer)
+	projectPath := "/api/v1/projects/" + project.ID.String()
+
+	// Thursday 22 October 2026
+	due := time.Date(2026, 10, 22, 10, 0, 0, 0, time.UTC)
+	rule := "rrule:freq=weekly;byday=th,mo;count=3"
+	standup := s.createTask(alice, project.ID, models.CreateTaskDTO{Title: "Standup notes", DueDate: &due, Recurrence: &rule})
+	if standup.Recurrence == nil || standup.Recurrence.Rule != "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=3" || *standup.Recurrence.CurrentTaskID != standup.ID {
+		t.Fatalf("expected the task to start a weekly series, got %+v", standup.Recurrence)
+	}
+
+	complete := func(task models.TaskResponseDTO) models.TaskResponseDTO {
+		t.Helper()
+		resp := s.do(http.MethodPatch, projectPath+"/tasks/"+task.ID.String(), &alice, map[string]any{"status": "completed"})
+		expectStatus(t, resp, http.StatusOK)
+		var completed models.TaskResponseDTO
+		decode(t, resp, &completed)
+		return completed
+	}
+	next := func(task models.TaskResponseDTO, wantDue time.Time) models.TaskResponseDTO {
+		t.Helper()
+		if task.Recurrence == nil || task.Recurrence.CurrentTaskID == nil || *task.Recurrence.CurrentTaskID == task.ID {
+			t.Fatalf("expected completing %q to create the next occurrence, got %+v", task.Title, task.Recurrence)
+		}
+		var occurrence models.TaskResponseDTO
+		s.getJSON(projectPath+"/tasks/"+task.Recurrence.CurrentTaskID.String(), &alice, &occurrence)
+		if occurrence.Title != task.Title || occurrence.Status != models.Todo || occurrence.DueDate == nil || !occurrence.DueDate.Equal(wantDue) {
+			t.Fatalf("expected a todo %q due %s, got %+v", task.Title, wantDue, occurrence)
+		}
+		return occurrence
+	}
+
+	monday := next(complete(standup), time.Date(2026, 10, 26, 10, 0, 0, 0, time.UTC))
+
+	// A full update completes the task the same way
+	resp := s.do(http.MethodPut, projectPath+"/tasks/"+monday.ID.String(), &alice, models.UpdateTaskDTO{
+		Title: monday.Title, Status: models.Completed, Priority: monday.Priority, DueDate: monday.DueDate,
+	})
+	expectStatus(t, resp, http.StatusCreated)
+	s.getJSON(projectPath+"/tasks/"+monday.ID.String(), &alice, &monday)
+	thursday := next(monday, time.Date(2026, 10, 29, 10, 0, 0, 0, time.UTC))
+	if thursday.Recurrence.Occurrences != 3 {
+		t.Fatalf("expected the series to have 3 occurrences, got %d", thursday.Recurrence.Occurrences)
+	}
+
+	// COUNT=3 is reached, so the series ends
+	if ended := complete(thursday); ended.Recurrence == nil || ended.Recurrence.CurrentTaskID != nil {
+		t.Fatalf("expected the series to end, got %+v", ended.Recurrence)
+	}
+	var page models.TaskListResponseDTO
+	s.getJSON(projectPath+"/tasks", &alice, &page)
+	if len(page.Tasks) != 3 {
+		t.Fatalf("expected 3 tasks, got %d", len(page.Tasks))
+	}
+
+	report := s.createTask(alice, project.ID, models.CreateTaskDTO{Title: "Report", DueDate: &due})
+	recurrencePath := projectPath + "/tasks/" + report.ID.String() + "/recurrence"
+	expectError(t, s.do(http.MethodPut, recurrencePath, &alice, map[string]any{"rule": "FREQ=YEARLY"}), http.StatusBadRequest, "validation")
+	expectError(t, s.do(http.MethodPut, recurrencePath, &alice, map[string]any{"rule": "FREQ=DAILY;BYDAY=MO"}), http.StatusBadRequest, "validation")
+	expectError(t, s.do(http.MethodPut, recurrencePath, &bob, map[string]any{"rule": "FREQ=DAILY"}), http.StatusForbidden, "forbidden")
+	expectError(t, s.do(http.MethodDelete, recurrencePath, &alice, nil), http.StatusNotFound, "not_found")
+
+	resp = s.do(http.MethodPut, recurrencePath, &alice, map[string]any{"rule": "FREQ=MONTHLY;INTERVAL=2"})
+	expectStatus(t, resp, http.StatusOK)
+	decode(t, resp, &report)
+	if report.Recurrence == nil || report.Recurrence.Rule != "FREQ=MONTHLY;INTERVAL=2" {
+		t.Fatalf("expected the report to recur every other month, got %+v", report.Recurrence)
+	}
+
+	resp = s.do(http.MethodDelete, recurrencePath, &alice, nil)
+	expectStatus(t, resp, http.StatusOK)
+	decode(t, resp, &report)
+	if report.Recurrence != nil {
+		t.Fatalf("expected the series to be stopped, got %+v", report.Recurrence)
+	}
+	if completed := complete(report); completed.Recurrence != nil {
+		t.Fatalf("expected a stopped series not to continue, got %+v", completed.Recurrence)
+	}
+	s.getJSON(projectPath+"/tasks", &alice, &page)
+	if len(page.Tasks) != 4 {
+		t.Fatalf("expected 4 tasks, got %d", len(page.Tasks))
+	}
+
+	// A bulk completion continues the series before it answers
+	daily := "FREQ=DAILY"
+	backup := s.createTask(alice, project.ID, models.CreateTaskDTO{Title: "Backup", DueDate: &due, Recurrence: &daily})
+	resp = s.do(http.MethodPost, projectPath+"/tasks/bulk", &alice, map[string]any{"taskIds": []uuid.UUID{backup.ID, report.ID}, "status": "completed"})
+	expectStatus(t, resp, http.StatusOK)
+	var bulk models.BulkTaskResponseDTO
+	decode(t, resp, &bulk)
+	if !bulk.Applied || bulk.Results[0].Task == nil {
+		t.Fatalf("expected the bulk completion to apply, got %+v", bulk)
+	}
+	next(*bulk.Results[0].Task, time.Date(2026, 10, 23, 10, 0, 0, 0, time.UTC))
+}
+
+func TestTimeTracking(t *testing.T) {
+	s := newTestServer(t)
+
+	alice := s.signUp("alice-uid", "Alice", "alice@example.com")
+	bob := s.signUp("bob-uid", "Bob", "bob@example.com")
+	carol := s.signUp("carol-uid", "Carol", "carol@example.com")
+	project := s.createProject(alice, "Apollo", "apo")
+	s.addMember(alice, project.ID, bob, models.RoleEditor)
+	s.addMember(alice, project.ID, carol, models.RoleViewer)
+	projectPath := "/api/v1/projects/" + project.ID.String()
+
+	estimate := 240
+	engine := s.createTask(alice, project.ID, models.CreateTaskDTO{Title: "Build engine", OriginalEstimate: &estimate})
+	if engine.OriginalEstimate == nil || *engine.OriginalEstimate != 240 || engine.RemainingEstimate == nil || *engine.RemainingEstimate != 240 {
+		t.Fatalf("expected the remaining estimate to start at the original, got %v and %v", engine.OriginalEstimate, engine.RemainingEstimate)
+	}
+	fuel := s.createTask(alice, project.ID, models.CreateTaskDTO{Title: "Load fuel"})
+	enginePath := projectPath + "/tasks/" + engine.ID.String()
+
+	resp := s.do(http.MethodPatch, enginePath, &alice, map[string]any{"remainingEstimate": 180})
+	expectStatus(t, resp, http.StatusOK)
+	decode(t, resp, &engine)
+	if *engine.RemainingEstimate != 180 || *engine.OriginalEstimate != 240 {
+		t.Fatalf("expected only the remaining estimate to change, got %v and %v", *engine.OriginalEstimate, *engine.RemainingEstimate)
+	}
+	expectError(t, s.do(http.MethodPatch, enginePath, &alice, map[string]any{"originalEstimate": -5}), http.StatusBadRequest, "validation")
+
+	var timeline []models.TaskTimelineEntryDTO
+	s.getJSON(enginePath+"/activity", &alice, &timeline)
+	if len(timeline) != 1 || timeline[0].Activity.Field != models.TaskFieldRemainingEstimate || *timeline[0].Activity.NewValue != "180" {
+		t.Fatalf("expected the estimate change in the activity, got %+v", timeline)
+	}
+
+	logWork := func(user testUser, taskID uuid.UUID, worklog map[string]any) models.WorklogResponseDTO {
+		t.Helper()
+		resp := s.do(http.MethodPost, projectPath+"/tasks/"+taskID.String()+"/worklogs", &user, worklog)
+		expectStatus(t, resp, http.StatusCreated)
+		var created models.WorklogResponseDTO
+		decode(t, resp, &created)
+		return created
+	}
+
+	first := logWork(alice, engine.ID, map[string]any{"duration": 90, "date": "2026-10-01", "note": " Mounted the nozzle "})
+	if first.Author.Name != "Alice" || first.Date != "2026-10-01" || first.Note == nil || *first.Note != "Mounted the nozzle" {
+		t.Fatalf("unexpected work log %+v", first)
+	}
+	second := logWork(bob, engine.ID, map[string]any{"duration": 60, "date": "2026-10-05"})
+	logWork(bob, fuel.ID, map[string]any{"duration": 30, "date": "2026-10-09"})
+	if today := logWork(alice, fuel.ID, map[string]any{"duration": 15}); today.Date != time.Now().UTC().Format(models.WorklogDateLayout) {
+		t.Fatalf("expected a work log without a date to be for today, got %q", today.Date)
+	}
+
+	worklogsPath := enginePath + "/worklogs"
+	expectError(t, s.do(http.MethodPost, worklogsPath, &alice, map[string]any{"duration": 0}), http.StatusBadRequest, "validation")
+	expectError(t, s.do(http.MethodPost, worklogsPath, &alice, map[string]any{"duration": 30, "date": "01/10/2026"}), http.StatusBadRequest, "validation")
+	expectError(t, s.do(http.MethodPost, worklogsPath, &alice, map[string]any{"duration": 30, "date": "2099-01-01"}), http.StatusBadRequest, "validation")
+	expectError(t, s.do(http.MethodPost, worklogsPath, &carol, map[string]any{"duration": 30}), http.StatusForbidden, "forbidden")
+
+	s.getJSON(enginePath, &carol, &engine)
+	if engine.TimeLogged != 150 {
+		t.Fatalf("expected 150 minutes logged on the engine, got %d", engine.TimeLogged)
+	}
+	var worklogs []models.WorklogResponseDTO
+	s.getJSON(worklogsPath, &carol, &worklogs)
+	if len(worklogs) != 2 || worklogs[0].ID != second.ID || worklogs[1].ID != first.ID {
+		t.Fatalf("expected the most recent work first, got %+v", worklogs)
+	}
+
+	// Only the author edits a work log, while the owner may also delete it
+	secondPath := worklogsPath + "/" + second.ID.String()
+	expectError(t, s.do(http.MethodPut, secondPath, &alice, map[string]any{"duration": 45, "date": "2026-10-05"}), http.StatusForbidden, "forbidden")
+	expectError(t, s.do(http.MethodDelete, worklogsPath+"/"+first.ID.String(), &bob, nil), http.StatusForbidden, "forbidden")
+	expectError(t, s.do(http.MethodPut, projectPath+"/tasks/"+fuel.ID.String()+"/worklogs/"+second.ID.String(), &bob, map[string]any{"duration": 45, "date": "2026-10-05"}), http.StatusNotFound, "not_found")
+
+	resp = s.do(http.MethodPut, secondPath, &bob, map[string]any{"duration": 45, "date": "2026-10-06"})
+	expectStatus(t, resp, http.StatusOK)
+	decode(t, resp, &second)
+	if second.Duration != 45 || second.Date != "2026-10-06" {
+		t.Fatalf("expected the work log to be updated, got %+v", second)
+	}
+
+	var report models.TimeReportDTO
+	s.getJSON(projectPath+"/worklogs/totals?from=2026-10-01&to=2026-10-06", &carol, &report)
+	if report.Logged != 135 || len(report.Tasks) != 1 || report.Tasks[0].Task.ID != engine.ID || report.Tasks[0].Logged != 135 ||
+		*report.Tasks[0].RemainingEstimate != 180 {
+		t.Fatalf("unexpected task totals %+v", report)
+	}
+	if len(report.Members) != 2 || report.Members[0].Member.Name != "Alice" || report.Members[0].Logged != 90 || report.Members[1].Logged != 45 {
+		t.Fatalf("unexpected member totals %+v", report.Members)
+	}
+	s.getJSON(projectPath+"/worklogs/totals?from=2026-10-06", &alice, &report)
+	if report.Logged != 90 || len(report.Tasks) != 2 || report.Tasks[0].Task.ID != engine.ID {
+		t.Fatalf("unexpected totals from 6 October %+v", report)
+	}
+	expectError(t, s.do(http.MethodGet, projectPath+"/worklogs/totals?from=2026-10-06&to=2026-10-01", &alice, nil), http.StatusBadRequest, "validation")
+
+	expectStatus(t, s.do(http.MethodDelete, secondPath, &alice, nil), http.StatusOK)
+	s.getJSON(enginePath, &alice, &engine)
+	if engine.TimeLogged != 90 {
+		t.Fatalf("expected 90 minutes logged after the delete, got %d", engine.TimeLogged)
+	}
+}
+
+func TestSprints(t *testing.T) {
+	s := newTestServer(t)
+
+	alice := s.signUp("alice-uid", "Alice", "alice@example.com")
+	carol := s.signUp("carol-uid", "Carol", "carol@example.com")
+	project := s.createProject(alice, "Apollo", "apo")
+	other := s.createProject(alice, "Gemini", "gem")
+	s.addMember(alice, project.ID, carol, models.RoleViewer)
+	projectPath := "/api/v1/projects/" + project.ID.String()
+	sprintsPath := projectPath + "/sprints"
+
+	today := time.Now().UTC()
+	date := func(days int) string {
+		return today.AddDate(0, 0, days).Format(time.DateOnly)
+	}
+	createSprint := func(projectPath string, name string, start int, end int) models.SprintResponseDTO {
+		t.Helper()
+		resp := s.do(http.MethodPost, projectPath+"/sprints", &alice, map[string]any{"name": name, "startDate": date(start), "endDate": date(end)})
+		expectStatus(t, resp, http.StatusCreated)
+		var sprint models.SprintResponseDTO
+		decode(t, resp, &sprint)
+		return sprint
+	}
+
+	first := createSprint(projectPath, " Sprint 1 ", -2, 7)
+	if first.Name != "Sprint 1" || first.State != models.SprintPlanned || first.StartDate != date(-2) {
+		t.Fatalf("unexpected sprint %+v", first)
+	}
+	second := createSprint(projectPath, "Sprint 2", 8, 17)
+	foreign := createSprint("/api/v1/projects/"+other.ID.String(), "Gemini 1", 0, 13)
+	expectError(t, s.do(http.MethodPost, sprintsPath, &alice, map[string]any{"name": "Backwards", "startDate": date(3), "endDate": date(1)}), http.StatusBadRequest, "validation")
+	expectError(t, s.do(http.MethodPost, sprintsPath, &alice, map[string]any{"name": "Endless", "startDate": date(0), "endDate": date(120)}), http.StatusBadRequest, "validation")
+	expectError(t, s.do(http.MethodPost, sprintsPath, &carol, map[string]any{"name": "Mine", "startDate": date(0), "endDate": date(6)}), http.StatusForbidden, "forbidden")
+
+	points := func(n int) *int { return &n }
+	engine := s.createTask(alice, project.ID, models.CreateTaskDTO{Title: "Build engine", StoryPoints: points(5), SprintID: &first.ID})
+	fuel := s.createTask(alice, project.ID, models.CreateTaskDTO{Title: "Load fuel", StoryPoints: points(3)})
+	flag := s.createTask(alice, project.ID, models.CreateTaskDTO{Title: "Plant flag"})
+	taskPath := func(task models.TaskResponseDTO) string { return projectPath + "/tasks/" + task.ID.String() }
+	if engine.SprintID == nil || *engine.SprintID != first.ID || *engine.StoryPoints != 5 || engine.CompletedAt != nil {
+		t.Fatalf("expected the engine in the first sprint, got %+v", engine)
+	}
+
+	resp := s.do(http.MethodPatch, taskPath(fuel), &alice, map[string]any{"sprintId": first.ID})
+	expectStatus(t, resp, http.StatusOK)
+	expectError(t, s.do(http.MethodPatch, taskPath(flag), &alice, map[string]any{"storyPoints": 101}), http.StatusBadRequest, "validation")
+	expectError(t, s.do(http.MethodPatch, taskPath(flag), &alice, map[string]any{"sprintId": foreign.ID}), http.StatusBadRequest, "validation")
+
+	// Starting commits to the sprint's points, and only one sprint runs at a time
+	resp = s.do(http.MethodPost, sprintsPath+"/"+first.ID.String()+"/start", &alice, nil)
+	expectStatus(t, resp, http.StatusOK)
+	decode(t, resp, &first)
+	if first.State != models.SprintActive || first.CommittedPoints == nil || *first.CommittedPoints != 8 || first.TaskCount != 2 {
+		t.Fatalf("unexpected started sprint %+v", first)
+	}
+	expectError(t, s.do(http.MethodPost, sprintsPath+"/"+first.ID.String()+"/start", &alice, nil), http.StatusConflict, "conflict")
+	expectError(t, s.do(http.MethodPost, sprintsPath+"/"+second.ID.String()+"/start", &alice, nil), http.StatusConflict, "conflict")
+	expectError(t, s.do(http.MethodDelete, sprintsPath+"/"+first.ID.String(), &alice, nil), http.StatusConflict, "conflict")
+	expectError(t, s.do(http.MethodGet, sprintsPath+"/"+second.ID.String()+"/burndown", &alice, nil), http.StatusConflict, "conflict")
+
+	resp = s.do(http.MethodPatch, taskPath(engine), &alice, map[string]any{"status": "completed"})
+	expectStatus(t, resp, http.StatusOK)
+	decode(t, resp, &engine)
+	if engine.CompletedAt == nil {
+		t.Fatal("expected completing the engine to record when it was completed")
+	}
+
+	var burndown models.SprintBurndownDTO
+	s.getJSON(sprintsPath+"/"+first.ID.String()+"/burndown", &carol, &burndown)
+	if burndown.Scope != 8 || burndown.Completed != 5 || len(burndown.Days) != 10 {
+		t.Fatalf("unexpected burndown %+v", burndown)
+	}
+	if days := burndown.Days; *days[0].Remaining != 8 || *days[2].Remaining != 3 || days[3].Remaining != nil || days[0].Ideal != 8 || days[9].Ideal != 0 {
+		t.Fatalf("unexpected burndown days %+v", days)
+	}
+
+	numbers := func(query string) []int {
+		t.Helper()
+		var page models.TaskListResponseDTO
+		s.getJSON(projectPath+"/tasks?"+query, &alice, &page)
+		result := []int{}
+		for _, task := range page.Tasks {
+			result = append(result, task.TaskNumber)
+		}
+		return result
+	}
+	if got := numbers("sprint=" + first.ID.String()); !slices.Equal(got, []int{1, 2}) {
+		t.Fatalf("expected the first sprint's tasks, got %v", got)
+	}
+	if got := numbers("sprint=backlog"); !slices.Equal(got, []int{3}) {
+		t.Fatalf("expected the backlog, got %v", got)
+	}
+	expectError(t, s.do(http.MethodGet, projectPath+"/tasks?sprint=next", &alice, nil), http.StatusBadRequest, "validation")
+
+	var result models.BulkTaskResponseDTO
+	resp = s.do(http.MethodPost, projectPath+"/tasks/bulk", &alice, map[string]any{"taskIds": []uuid.UUID{flag.ID}, "sprintId": second.ID})
+	expectStatus(t, resp, http.StatusOK)
+	decode(t, resp, &result)
+	if !result.Applied || *result.Results[0].Task.SprintID != second.ID {
+		t.Fatalf("expected the flag to be planned into the second sprint, got %+v", result)
+	}
+
+	// Closing carries the unfinished fuel over to the next sprint
+	expectError(t, s.do(http.MethodPost, sprintsPath+"/"+first.ID.String()+"/close", &alice, map[string]any{"carryOverTo": foreign.ID}), http.StatusBadRequest, "validation")
+	resp = s.do(http.MethodPost, sprintsPath+"/"+first.ID.String()+"/close", &alice, map[string]any{"carryOverTo": second.ID})
+	expectStatus(t, resp, http.StatusOK)
+	var closed models.CloseSprintResponseDTO
+	decode(t, resp, &closed)
+	if closed.Sprint.State != models.SprintClosed || *closed.Sprint.CompletedPoints != 5 || *closed.Sprint.CarriedOverPoints != 3 ||
+		len(closed.CarriedOver) != 1 || closed.CarriedOver[0].ID != fuel.ID {
+		t.Fatalf("unexpected closed sprint %+v", closed)
+	}
+	expectError(t, s.do(http.MethodPatch, taskPath(flag), &alice, map[string]any{"sprintId": first.ID}), http.StatusBadRequest, "validation")
+
+	s.getJSON(taskPath(fuel), &alice, &fuel)
+	if fuel.SprintID == nil || *fuel.SprintID != second.ID {
+		t.Fatalf("expected the fuel in the second sprint, got %v", fuel.SprintID)
+	}
+	var timeline []models.TaskTimelineEntryDTO
+	s.getJSON(taskPath(fuel)+"/activity", &alice, &timeline)
+	if !slices.ContainsFunc(timeline, func(entry models.TaskTimelineEntryDTO) bool {
+		activity := entry.Activity
+		return activity != nil && activity.Field == models.TaskFieldSprintID && activity.OldValue != nil && *activity.OldValue == first.ID.String() && *activity.NewValue == second.ID.String()
+	}) {
+		t.Fatalf("expected the carry over in the activity, got %+v", timeline)
+	}
+
+	s.getJSON(sprintsPath+"/"+first.ID.String()+"/burndown", &alice, &burndown)
+	if burndown.Scope != 8 || *burndown.Days[2].Remaining != 3 {
+		t.Fatalf("expected the closed sprint's burndown to keep the carried over points, got %+v", burndown)
+	}
+
+	var velocity models.VelocityDTO
+	s.getJSON(sprintsPath+"/velocity", &carol, &velocity)
+	if len(velocity.Sprints) != 1 || velocity.Sprints[0].Committed != 8 || velocity.Sprints[0].Completed != 5 || velocity.Average != 5 {
+		t.Fatalf("unexpected velocity %+v", velocity)
+	}
+
+	// Deleting a planned sprint puts its tasks back in the backlog
+	expectStatus(t, s.do(http.MethodDelete, sprintsPath+"/"+second.ID.String(), &alice, nil), http.StatusOK)
+	if got := numbers("sprint=backlog"); !slices.Equal(got, []int{2, 3}) {
+		t.Fatalf("expected the second sprint's tasks back in the backlog, got %v", got)
+	}
+	var sprints []models.SprintResponseDTO
+	s.getJSON(sprintsPath, &carol, &sprints)
+	if len(sprints) != 1 || sprints[0].ID != first.ID {
+		t.Fatalf("expected only the closed sprint left, got %+v", sprints)
+	}
+}
+
+func TestTaskWatchers(t *testing.T) {
+	s := newTestServer(t)
+
+	alice := s.signUp("alice-uid", "Alice", "alice@example.com")
+	bob := s.signUp("bob-uid", "Bob", "bob@example.com")
+	carol := s.signUp("carol-uid", "Carol", "carol@example.com")
+	dave := s.signUp("dave-uid", "Dave", "dave@example.com")
+	project := s.createProject(alice, "Apollo", "apo")
+	bobMember := s.addMember(alice, project.ID, bob, models.RoleEditor)
+	carolMember := s.addMember(alice, project.ID, carol, models.RoleViewer)
+	projectPath := "/api/v1/projects/" + project.ID.String()
+	bobSocket := s.connect(bob)
+	carolSocket := s.connect(carol)
+
+	// The creator and the assignee watch the task from the start
+	engine := s.createTask(alice, project.ID, models.CreateTaskDTO{Title: "Build engine", AssignedTo: &bobMember.ID})
+	fuel := s.createTask(bob, project.ID, models.CreateTaskDTO{Title: "Load fuel"})
+	enginePath := projectPath + "/tasks/" + engine.ID.String()
+
+	watcherNames := func(watchers []models.ProjectMemberResponseDTO) string {
+		names := make([]string, len(watchers))
+		for i, watcher := range watchers {
+			names[i] = watcher.Name
+		}
+		return strings.Join(names, ",")
+	}
+	var watchers []models.ProjectMemberResponseDTO
+	s.getJSON(enginePath+"/watchers", &carol, &watchers)
+	if got := watcherNames(watchers); got != "Alice,Bob" {
+		t.Fatalf("expected the creator and assignee to watch, got %s", got)
+	}
+
+	// Viewers may watch too, and watching twice is fine
+	expectStatus(t, s.do(http.MethodPut, enginePath+"/watch", &carol, nil), http.StatusOK)
+	resp := s.do(http.MethodPut, enginePath+"/watch", &carol, nil)
+	expectStatus(t, resp, http.StatusOK)
+	decode(t, resp, &watchers)
+	if got := watcherNames(watchers); got != "Alice,Bob,Carol" {
+		t.Fatalf("expected carol to watch, got %s", got)
+	}
+	expectError(t, s.do(http.MethodPut, enginePath+"/watch", &dave, nil), http.StatusForbidden, "forbidden")
+	expectError(t, s.do(http.MethodPut, projectPath+"/tasks/"+uuid.NewString()+"/watch", &carol, nil), http.StatusNotFound, "not_found")
+
+	expectNotification := func(conn *websocket.Conn, notificationType models.NotificationType, content string) models.NotificationResponseDTO {
+		t.Helper()
+		n := readNotification(t, conn)
+		if n.Type != notificationType || n.Content != content {
+			t.Fatalf("expected a %s notification %q, got %s %q", notificationType, content, n.Type, n.Content)
+		}
+		return n
+	}
+
+	// Watchers hear about changes other members make
+	expectStatus(t, s.do(http.MethodPatch, enginePath, &bob, map[string]any{"status": "in-progress", "priority": "high"}), http.StatusOK)
+	if n := expectNotification(carolSocket, models.NotificationTypeTaskUpdated, "Bob updated Build engine: status changed to in-progress"); n.TaskID != engine.ID {
+		t.Fatalf("expected the notification to point at the task, got %s", n.TaskID)
+	}
+	expectStatus(t, s.do(http.MethodPatch, enginePath, &bob, map[string]any{"priority": "low"}), http.StatusOK)
+
+	expectStatus(t, s.do(http.MethodPost, enginePath+"/comments", &alice, map[string]string{"comment": "Nozzle\n  is mounted"}), http.StatusCreated)
+	expectNotification(bobSocket, models.NotificationTypeCommentAdded, "Alice commented on Build engine: Nozzle is mounted")
+	expectNotification(carolSocket, models.NotificationTypeCommentAdded, "Alice commented on Build engine: Nozzle is mounted")
+
+	resp = s.do(http.MethodDelete, enginePath+"/watch", &carol, nil)
+	expectStatus(t, resp, http.StatusOK)
+	decode(t, resp, &watchers)
+	if got := watcherNames(watchers); got != "Alice,Bob" {
+		t.Fatalf("expected carol to stop watching, got %s", got)
+	}
+
+	// A new assignee is told about the assignment rather than the change, and starts watching
+	expectStatus(t, s.do(http.MethodPatch, enginePath, &alice, map[string]any{"dueDate": "2030-01-10T00:00:00Z", "assignedTo": carolMember.ID}), http.StatusOK)
+	expectNotification(carolSocket, models.NotificationTypeTaskAssigned, "You have been assigned to task: Build engine")
+	expectNotification(bobSocket, models.NotificationTypeTaskUpdated, "Alice updated Build engine: due date changed to 2030-01-10, assigned to Carol")
+
+	// A bulk change makes one notification per watcher
+	resp = s.do(http.MethodPost, projectPath+"/tasks/bulk", &alice, map[string]any{"taskIds": []uuid.UUID{engine.ID, fuel.ID}, "status": "completed"})
+	expectStatus(t, resp, http.StatusOK)
+	expectNotification(bobSocket, models.NotificationTypeTaskUpdated, "Alice updated 2 tasks you watch: Build engine, Load fuel")
+	expectNotification(carolSocket, models.NotificationTypeTaskUpdated, "Alice updated Build engine: status changed to completed")
+
+	var notifications []models.NotificationResponseDTO
+	s.getJSON("/api/v1/notifications", &alice, &notifications)
+	if len(notifications) != 1 || notifications[0].Content != "Bob updated Build engine: status changed to in-progress" {
+		t.Fatalf("expected alice to hear only about bob's change, got %+v", notifications)
+	}
+
+	// Membership of another project doesn't let dave comment on the task, whether the task is in
+	// the URL or the body
+	zeus := s.createProject(dave, "Zeus", "zeu")
+	zeusPath := "/api/v1/projects/" + zeus.ID.String()
+	bolt := s.createTask(dave, zeus.ID, models.CreateTaskDTO{Title: "Forge bolt"})
+	expectError(t, s.do(http.MethodPost, zeusPath+"/tasks/"+engine.ID.String()+"/comments", &dave, map[string]string{"comment": "Spam"}), http.StatusNotFound, "not_found")
+	resp = s.do(http.MethodPost, zeusPath+"/tasks/"+bolt.ID.String()+"/comments", &dave,
+		map[string]any{"comment": "Spam", "projectId": project.ID, "taskId": engine.ID})
+	expectStatus(t, resp, http.StatusCreated)
+
+	var comments []models.CommentResponseDTO
+	s.getJSON(enginePath+"/comments", &bob, &comments)
+	for _, comment := range comments {
+		if comment.Comment == "Spam" {
+			t.Fatalf("expected dave's comment to stay on his own task, got %+v", comments)
+		}
+	}
+	s.getJSON("/api/v1/notifications", &bob, &notifications)
+	for _, n := range notifications {
+		if strings.HasPrefix(n.Content, "Dave") {
+			t.Fatalf("expected bob not to hear from dave, got %q", n.Content)
+		}
+	}
+	expectError(t, s.do(http.MethodGet, zeusPath+"/tasks/"+engine.ID.String()+"/comments", &dave, nil), http.StatusNotFound, "not_found")
+}
+
+func TestOptimisticConcurrency(t *testing.T) {
+	s := newTestServer(t)
+
+	alice := s.signUp("alice-uid", "Alice", "alice@example.com")
+	bob := s.signUp("bob-uid", "Bob", "bob@example.com")
+	project := s.createProject(alice, "Apollo", "apo")
+	s.addMember(alice, project.ID, bob, models.RoleEditor)
+	projectPath := "/api/v1/projects/" + project.ID.String()
+
+	engine := s.createTask(alice, project.ID, models.CreateTaskDTO{Title: "Build engine"})
+	enginePath := projectPath + "/tasks/" + engine.ID.String()
+
+	ifMatch := func(etag string) http.Header {
+		return http.Header{"If-Match": {etag}}
+	}
+	etagOf := func(resp *http.Response) string {
+		t.Helper()
+		etag := resp.Header.Get("ETag")
+		if etag == "" {
+			t.Fatalf("%s %s: expected an ETag", resp.Request.Method, resp.Request.URL.Path)
+		}
+		return etag
+	}
+	var stale struct {
+		Code    string                 `json:"code"`
+		Details map[string]string      `json:"details"`
+		Current models.TaskResponseDTO `json:"current"`
+	}
+
+	resp := s.do(http.MethodGet, enginePath, &alice, nil)
+	expectStatus(t, resp, http.StatusOK)
+	read := etagOf(resp)
+
+	// Alice saves against the version she read, which moves the ETag on
+	resp = s.doWithHeader(http.MethodPatch, enginePath, &alice, map[string]any{"title": "Build main engine"}, ifMatch(read))
+	expectStatus(t, resp, http.StatusOK)
+	saved := etagOf(resp)
+	if saved == read {
+		t.Fatalf("expected the ETag to change with the task, still %s", saved)
+	}
+	resp = s.do(http.MethodGet, enginePath, &bob, nil)
+	expectStatus(t, resp, http.StatusOK)
+	if got := etagOf(resp); got != saved {
+		t.Fatalf("expected the task to be at %s, got %s", saved, got)
+	}
+
+	// Bob's edit of the version before is refused with the task as it is now
+	resp = s.doWithHeader(http.MethodPatch, enginePath, &bob, map[string]any{"priority": "high"}, ifMatch(read))
+	expectStatus(t, resp, http.StatusPreconditionFailed)
+	decode(t, resp, &stale)
+	if stale.Code != "precondition_failed" || stale.Details["etag"] != saved || stale.Current.Title != "Build main engine" {
+		t.Fatalf("expected the current task and its ETag, got %+v", stale)
+	}
+	resp = s.doWithHeader(http.MethodPut, enginePath, &bob, models.UpdateTaskDTO{Title: "Build engine", Status: engine.Status, Priority: "high"}, ifMatch(read))
+	expectError(t, resp, http.StatusPreconditionFailed, "precondition_failed")
+	expectError(t, s.doWithHeader(http.MethodPatch, enginePath, &bob, map[string]any{"priority": "high"}, ifMatch("W/"+saved)), http.StatusPreconditionFailed, "precondition_failed")
+
+	var task models.TaskResponseDTO
+	s.getJSON(enginePath, &alice, &task)
+	if task.Priority == "high" {
+		t.Fatalf("expected the stale edits to change nothing, got priority %s", task.Priority)
+	}
+
+	// Once he has the current version, or with *, or without If-Match at all, the edit goes through
+	resp = s.doWithHeader(http.MethodPut, enginePath, &bob, models.UpdateTaskDTO{Title: "Build main engine", Status: engine.Status, Priority: "high"}, ifMatch(saved))
+	expectStatus(t, resp, http.StatusCreated)
+	if etagOf(resp) == saved {
+		t.Fatalf("expected the ETag to change with the task, still %s", saved)
+	}
+	expectStatus(t, s.doWithHeader(http.MethodPatch, enginePath, &bob, map[string]any{"priority": "critical"}, ifMatch("*")), http.StatusOK)
+	expectStatus(t, s.do(http.MethodPatch, enginePath, &alice, map[string]any{"priority": "low"}), http.StatusOK)
+
+	// Projects work the same way
+	resp = s.do(http.MethodGet, projectPath, &alice, nil)
+	expectStatus(t, resp, http.StatusOK)
+	read = etagOf(resp)
+
+	resp = s.doWithHeader(http.MethodPut, projectPath, &alice, models.EditProjectDTO{Name: "Apollo 11"}, ifMatch(read))
+	expectStatus(t, resp, http.StatusCreated)
+	saved = etagOf(resp)
+	if saved == read {
+		t.Fatalf("expected the ETag to change with the project, still %s", saved)
+	}
+
+	var staleProject struct {
+		Details map[string]string         `json:"details"`
+		Current models.ProjectResponseDTO `json:"current"`
+	}
+	resp = s.doWithHeader(http.MethodPut, projectPath, &alice, models.EditProjectDTO{Name: "Apollo 12"}, ifMatch(read))
+	expectStatus(t, resp, http.StatusPreconditionFailed)
+	decode(t, resp, &staleProject)
+	if staleProject.Details["etag"] != saved || staleProject.Current.Name != "Apollo 11" {
+		t.Fatalf("expected the current project and its ETag, got %+v", staleProject)
+	}
+	expectStatus(t, s.do(http.MethodPut, projectPath, &alice, models.EditProjectDTO{Name: "Apollo 12"}), http.StatusCreated)
+}
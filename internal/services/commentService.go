@@ -1,115 +1,238 @@
 package services
 
 import (
-	"errors"
+	"context"
 	"fmt"
-	"log"
+	"slices"
+	"strings"
 
 	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/logging"
 	"github.com/sarvochcha01/enlace-backend/internal/models"
 	"github.com/sarvochcha01/enlace-backend/internal/repositories"
 )
 
 type CommentService interface {
-	CreateComment(*models.CreateCommentDTO, string) error
-	GetComment(uuid.UUID) (*models.CommentResponseDTO, error)
-	UpdateComment(*models.UpdateCommentDTO, string) error
-	DeleteComment(*models.DeleteCommentDTO, string) error
+	CreateComment(context.Context, *models.CreateCommentDTO, string) error
+	GetComment(ctx context.Context, projectID uuid.UUID, taskID uuid.UUID, commentID uuid.UUID, firebaseUID string) (*models.CommentResponseDTO, error)
+	UpdateComment(context.Context, *models.UpdateCommentDTO, string) error
+	DeleteComment(context.Context, *models.DeleteCommentDTO, string) error
 
-	GetAllCommentsForTask(taskID uuid.UUID, projectID uuid.UUID, firebaseUID string) ([]models.CommentResponseDTO, error)
+	GetAllCommentsForTask(ctx context.Context, taskID uuid.UUID, projectID uuid.UUID, firebaseUID string) ([]models.CommentResponseDTO, error)
 }
 
 type commentService struct {
 	commentRepository    repositories.CommentRepository
+	taskRepository       repositories.TaskRepository
 	userService          UserService
 	projectMemberService ProjectMemberService
+	notificationService  NotificationService
 }
 
-func NewCommentService(cr repositories.CommentRepository, us UserService, pms ProjectMemberService) CommentService {
-	return &commentService{commentRepository: cr, userService: us, projectMemberService: pms}
+func NewCommentService(cr repositories.CommentRepository, tr repositories.TaskRepository, us UserService, pms ProjectMemberService, ns NotificationService) CommentService {
+	return &commentService{commentRepository: cr, taskRepository: tr, userService: us, projectMemberService: pms, notificationService: ns}
 }
 
-func (s *commentService) CreateComment(commentDTO *models.CreateCommentDTO, firebaseUID string) error {
+func (s *commentService) CreateComment(ctx context.Context, commentDTO *models.CreateCommentDTO, firebaseUID string) error {
 
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+	user, err := s.userService.GetUserByFirebaseUID(ctx, firebaseUID)
 
 	if err != nil {
-		log.Println("UserID not found: ", err)
-		return errors.New("UserID not found: " + err.Error())
+		return err
 	}
 
 	var projectMemberID uuid.UUID
-	projectMemberID, err = s.projectMemberService.GetProjectMemberID(userID, commentDTO.ProjectID)
+	projectMemberID, err = s.projectMemberService.GetProjectMemberID(ctx, user.ID, commentDTO.ProjectID)
 
 	if err != nil {
-		log.Println("Project Member not found: ", err)
-		return errors.New("Project Member not found: " + err.Error())
+		return requireMember(err)
+	}
+
+	task, err := s.getProjectTask(ctx, commentDTO.ProjectID, commentDTO.TaskID)
+	if err != nil {
+		return err
 	}
 
 	commentDTO.CreatedBy = projectMemberID
 
-	return s.commentRepository.CreateComment(commentDTO)
+	if err := s.commentRepository.CreateComment(ctx, commentDTO); err != nil {
+		return err
+	}
+
+	s.notifyWatchers(ctx, user.Name, task, commentDTO)
+	return nil
 }
 
-func (r *commentService) GetComment(commentID uuid.UUID) (*models.CommentResponseDTO, error) {
-	return r.commentRepository.GetComment(commentID)
+// getProjectTask answers not found for tasks of other projects as well as missing ones, so that
+// membership of one project can't be used to comment on another's tasks
+func (s *commentService) getProjectTask(ctx context.Context, projectID uuid.UUID, taskID uuid.UUID) (*models.TaskResponseDTO, error) {
+	task, err := s.taskRepository.GetFullTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.ProjectID != projectID {
+		return nil, apperrors.NotFound("task not found")
+	}
+	return task, nil
 }
 
-func (s *commentService) UpdateComment(updateCommentDTO *models.UpdateCommentDTO, firebaseUID string) error {
+// notifyWatchers tells the task's watchers about a new comment, except for its author. A failed
+// notification is logged rather than failing the comment.
+func (s *commentService) notifyWatchers(ctx context.Context, authorName string, task *models.TaskResponseDTO, commentDTO *models.CreateCommentDTO) {
+	logger := logging.FromContext(ctx)
 
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+	watchers, err := s.taskRepository.GetTaskWatchers(ctx, task.ID)
 	if err != nil {
-		return errors.New("user not found")
+		logger.Error("failed to notify watchers", "task_id", task.ID, "error", err)
+		return
+	}
+
+	excerpt := strings.Join(strings.Fields(commentDTO.Comment), " ")
+	if runes := []rune(excerpt); len(runes) > maxCommentExcerptLength {
+		excerpt = string(runes[:maxCommentExcerptLength]) + "…"
 	}
 
-	projectMemberID, err := s.projectMemberService.GetProjectMemberID(userID, updateCommentDTO.ProjectID)
+	for _, watcher := range watchersToNotify(watchers, commentDTO.CreatedBy) {
+		notification := models.CreateNotificationDTO{
+			UserID:    watcher.UserID,
+			Type:      models.NotificationTypeCommentAdded,
+			Content:   fmt.Sprintf("%s commented on %s: %s", authorName, task.Title, excerpt),
+			ProjectID: task.ProjectID,
+			TaskID:    &task.ID,
+		}
+		if err := s.notificationService.CreateNotification(ctx, notification); err != nil {
+			logger.Error("failed to notify watcher", "task_id", task.ID, "user_id", watcher.UserID, "error", err)
+		}
+	}
+}
+
+// maxCommentExcerptLength is how much of a comment a notification about it quotes, in characters
+const maxCommentExcerptLength = 100
+
+// GetComment is open to members of the project, for a comment on the task in the URL
+func (s *commentService) GetComment(ctx context.Context, projectID uuid.UUID, taskID uuid.UUID, commentID uuid.UUID, firebaseUID string) (*models.CommentResponseDTO, error) {
+	if _, err := s.projectMemberService.GetProjectMemberIDByFirebaseUID(ctx, firebaseUID, projectID); err != nil {
+		return nil, requireMember(err)
+	}
+
+	if _, err := s.getProjectTask(ctx, projectID, taskID); err != nil {
+		return nil, err
+	}
+
+	comment, err := s.commentRepository.GetComment(ctx, commentID)
 	if err != nil {
-		return errors.New("Project Member not found: " + err.Error())
+		return nil, err
 	}
+	if comment.TaskID != taskID {
+		return nil, apperrors.NotFound("comment not found")
+	}
+
+	comments := []models.CommentResponseDTO{*comment}
+	if err := s.renderTaskKeys(ctx, firebaseUID, comments); err != nil {
+		return nil, err
+	}
+
+	return &comments[0], nil
+}
 
-	creatorID, err := s.commentRepository.GetCommentCreator(updateCommentDTO.CommentID)
+func (s *commentService) UpdateComment(ctx context.Context, updateCommentDTO *models.UpdateCommentDTO, firebaseUID string) error {
+
+	userID, err := s.userService.GetUserIDByFirebaseUID(ctx, firebaseUID)
+	if err != nil {
+		return err
+	}
+
+	projectMemberID, err := s.projectMemberService.GetProjectMemberID(ctx, userID, updateCommentDTO.ProjectID)
 	if err != nil {
-		return errors.New("comment creator not found")
+		return requireMember(err)
+	}
+
+	creatorID, err := s.commentRepository.GetCommentCreator(ctx, updateCommentDTO.CommentID)
+	if err != nil {
+		return err
 	}
 
 	if creatorID != projectMemberID {
-		return errors.New("unauthorized: you can only edit your own comments")
+		return apperrors.Forbidden("you can only edit your own comments")
 	}
 
-	return s.commentRepository.UpdateComment(updateCommentDTO.CommentID, updateCommentDTO.Comment)
+	return s.commentRepository.UpdateComment(ctx, updateCommentDTO.CommentID, updateCommentDTO.Comment)
 }
 
-func (s *commentService) DeleteComment(deleteCommentDTO *models.DeleteCommentDTO, firebaseUID string) error {
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+func (s *commentService) DeleteComment(ctx context.Context, deleteCommentDTO *models.DeleteCommentDTO, firebaseUID string) error {
+	userID, err := s.userService.GetUserIDByFirebaseUID(ctx, firebaseUID)
 	if err != nil {
-		return fmt.Errorf("user not found: %v", err.Error())
+		return err
 	}
 
-	projectMemberID, err := s.projectMemberService.GetProjectMemberID(userID, deleteCommentDTO.ProjectID)
+	projectMemberID, err := s.projectMemberService.GetProjectMemberID(ctx, userID, deleteCommentDTO.ProjectID)
 	if err != nil {
-		return fmt.Errorf("project Member not found: %v", err.Error())
+		return requireMember(err)
 	}
 
-	creatorID, err := s.commentRepository.GetCommentCreator(deleteCommentDTO.CommentID)
+	creatorID, err := s.commentRepository.GetCommentCreator(ctx, deleteCommentDTO.CommentID)
 	if err != nil {
-		return fmt.Errorf("comment creator not found: %v", err.Error())
+		return err
 	}
 
 	if creatorID != projectMemberID {
-		return fmt.Errorf("unauthorized: you can only edit your own comments")
+		return apperrors.Forbidden("you can only delete your own comments")
 	}
 
-	return s.commentRepository.DeleteComment(deleteCommentDTO.CommentID)
+	return s.commentRepository.DeleteComment(ctx, deleteCommentDTO.CommentID)
 }
 
-func (s *commentService) GetAllCommentsForTask(taskID uuid.UUID, projectID uuid.UUID, firebaseUID string) ([]models.CommentResponseDTO, error) {
+func (s *commentService) GetAllCommentsForTask(ctx context.Context, taskID uuid.UUID, projectID uuid.UUID, firebaseUID string) ([]models.CommentResponseDTO, error) {
+
+	_, err := s.projectMemberService.GetProjectMemberIDByFirebaseUID(ctx, firebaseUID, projectID)
+
+	if err != nil {
+		return nil, requireMember(err)
+	}
 
-	_, err := s.projectMemberService.GetProjectMemberIDByFirebaseUID(firebaseUID, projectID)
+	if _, err := s.getProjectTask(ctx, projectID, taskID); err != nil {
+		return nil, err
+	}
 
+	comments, err := s.commentRepository.GetAllCommentsForTask(ctx, taskID)
 	if err != nil {
-		log.Println("Failed to get Comments. Only projects members can access comments", err)
-		return nil, errors.New("failed to get Comments. Only projects members can access comments")
+		return nil, err
+	}
+
+	if err := s.renderTaskKeys(ctx, firebaseUID, comments); err != nil {
+		return nil, err
 	}
 
-	return s.commentRepository.GetAllCommentsForTask(taskID)
+	return comments, nil
+}
+
+// renderTaskKeys fills in CommentHTML. Keys only link to tasks of projects the reader is a member
+// of, so a comment can't reveal the titles of other projects' tasks.
+func (s *commentService) renderTaskKeys(ctx context.Context, firebaseUID string, comments []models.CommentResponseDTO) error {
+	var keys []string
+	for _, comment := range comments {
+		for _, key := range models.FindTaskKeys(comment.Comment) {
+			if !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	links := map[string]models.TaskKeyLinkDTO{}
+	if len(keys) > 0 {
+		userID, err := s.userService.GetUserIDByFirebaseUID(ctx, firebaseUID)
+		if err != nil {
+			return err
+		}
+
+		if links, err = s.taskRepository.ResolveTaskKeys(ctx, userID, keys); err != nil {
+			return err
+		}
+	}
+
+	for i := range comments {
+		comments[i].CommentHTML = models.RenderTaskKeyLinks(comments[i].Comment, links)
+	}
+	return nil
 }
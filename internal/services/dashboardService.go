@@ -1,16 +1,19 @@
 package services
 
 import (
+	"context"
 	"github.com/sarvochcha01/enlace-backend/internal/models"
 	"github.com/sarvochcha01/enlace-backend/internal/repositories"
 )
 
 type DashboardService interface {
-	GetRecentlyAssignedTasks(firebaseUID string, limit int) ([]models.TaskResponseDTO, error)
-	GetInProgressTasks(firebaseUID string, limit int) ([]models.TaskResponseDTO, error)
-	GetApproachingDeadlineTasks(firebaseUID string, limit int) ([]models.TaskResponseDTO, error)
+	GetRecentlyAssignedTasks(ctx context.Context, firebaseUID string, limit int) ([]models.TaskResponseDTO, error)
+	GetInProgressTasks(ctx context.Context, firebaseUID string, limit int) ([]models.TaskResponseDTO, error)
+	GetApproachingDeadlineTasks(ctx context.Context, firebaseUID string, limit int) ([]models.TaskResponseDTO, error)
 
-	Search(firebaseUID string, query string) (*models.SearchResult, error)
+	// Search matches query against projects and tasks. Tasks can be narrowed to those with any of
+	// the named labels, and projects are only searched when there is a query.
+	Search(ctx context.Context, firebaseUID string, query string, labels []string) (*models.SearchResult, error)
 }
 
 type dashboardService struct {
@@ -25,33 +28,33 @@ func NewDashboardService(dr repositories.DashboardRepository, us UserService) Da
 	}
 }
 
-func (s *dashboardService) GetRecentlyAssignedTasks(firebaseUID string, limit int) ([]models.TaskResponseDTO, error) {
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+func (s *dashboardService) GetRecentlyAssignedTasks(ctx context.Context, firebaseUID string, limit int) ([]models.TaskResponseDTO, error) {
+	userID, err := s.userService.GetUserIDByFirebaseUID(ctx, firebaseUID)
 	if err != nil {
 		return nil, err
 	}
-	return s.dashboardRepository.GetRecentlyAssignedTasks(userID, limit)
+	return s.dashboardRepository.GetRecentlyAssignedTasks(ctx, userID, limit)
 }
 
-func (s *dashboardService) GetInProgressTasks(firebaseUID string, limit int) ([]models.TaskResponseDTO, error) {
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+func (s *dashboardService) GetInProgressTasks(ctx context.Context, firebaseUID string, limit int) ([]models.TaskResponseDTO, error) {
+	userID, err := s.userService.GetUserIDByFirebaseUID(ctx, firebaseUID)
 	if err != nil {
 		return nil, err
 	}
-	return s.dashboardRepository.GetInProgressTasks(userID, limit)
+	return s.dashboardRepository.GetInProgressTasks(ctx, userID, limit)
 }
 
-func (s *dashboardService) GetApproachingDeadlineTasks(firebaseUID string, limit int) ([]models.TaskResponseDTO, error) {
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+func (s *dashboardService) GetApproachingDeadlineTasks(ctx context.Context, firebaseUID string, limit int) ([]models.TaskResponseDTO, error) {
+	userID, err := s.userService.GetUserIDByFirebaseUID(ctx, firebaseUID)
 	if err != nil {
 		return nil, err
 	}
-	return s.dashboardRepository.GetApproachingDeadlineTasks(userID, limit)
+	return s.dashboardRepository.GetApproachingDeadlineTasks(ctx, userID, limit)
 }
 
-func (s *dashboardService) Search(firebaseUID string, query string) (*models.SearchResult, error) {
+func (s *dashboardService) Search(ctx context.Context, firebaseUID string, query string, labels []string) (*models.SearchResult, error) {
 
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+	userID, err := s.userService.GetUserIDByFirebaseUID(ctx, firebaseUID)
 	if err != nil {
 		return nil, err
 	}
@@ -60,7 +63,11 @@ func (s *dashboardService) Search(firebaseUID string, query string) (*models.Sea
 	projectsErrChan := make(chan error, 1)
 
 	go func() {
-		projects, err := s.dashboardRepository.SearchProjects(userID, query)
+		if query == "" {
+			projectsChan <- nil
+			return
+		}
+		projects, err := s.dashboardRepository.SearchProjects(ctx, userID, query)
 		if err != nil {
 			projectsErrChan <- err
 			return
@@ -72,7 +79,7 @@ func (s *dashboardService) Search(firebaseUID string, query string) (*models.Sea
 	tasksErrChan := make(chan error, 1)
 
 	go func() {
-		tasks, err := s.dashboardRepository.SearchTasks(userID, query)
+		tasks, err := s.dashboardRepository.SearchTasks(ctx, userID, query, labels)
 		if err != nil {
 			tasksErrChan <- err
 			return
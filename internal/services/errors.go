@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/utils"
+)
+
+// requireMember reports a missing membership as forbidden rather than not found so that
+// callers can't probe which projects exist
+func requireMember(err error) error {
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return apperrors.Forbidden("you are not a member of this project").Wrap(err)
+	}
+	return err
+}
+
+func noEditPrivilege() error {
+	return apperrors.Forbidden("you don't have edit privileges in this project")
+}
+
+// requireEditor returns the caller's membership of the project, provided it has edit privileges
+func requireEditor(ctx context.Context, projectMemberService ProjectMemberService, firebaseUID string, projectID uuid.UUID) (*models.ProjectMemberResponseDTO, error) {
+	projectMember, err := projectMemberService.GetProjectMemberByFirebaseUID(ctx, firebaseUID, projectID)
+	if err != nil {
+		return nil, requireMember(err)
+	}
+
+	if !utils.HasEditPrivileges(projectMember) {
+		return nil, noEditPrivilege()
+	}
+	return projectMember, nil
+}
+
+// withCurrent adds the resource as it is now and its entity tag to a failed If-Match precondition,
+// so the client can retry against them. Other errors pass through.
+func withCurrent(err error, current any, etag string) error {
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) && appErr.Kind == apperrors.KindPreconditionFailed {
+		appErr.Current = current
+		appErr.Details = map[string]string{"etag": etag}
+	}
+	return err
+}
@@ -1,21 +1,23 @@
 package services
 
 import (
-	"errors"
+	"context"
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/logging"
 	"github.com/sarvochcha01/enlace-backend/internal/models"
 	"github.com/sarvochcha01/enlace-backend/internal/repositories"
 	"github.com/sarvochcha01/enlace-backend/internal/utils"
 )
 
 type InvitationService interface {
-	CreateInvitation(firebaseUID string, createInvitationDTO *models.CreateInvitationDTO) error
-	GetInvitations(firebaseUID string) ([]models.InvitationResponseDTO, error)
-	HasInvitation(userID uuid.UUID, projectID uuid.UUID) bool
-	HasInvitationFirebaseUID(firebaseUID string, projectID uuid.UUID) bool
-	EditInvitation(firebaseUID string, editInvitationDTO models.EditInvitationDTO) error
+	CreateInvitation(ctx context.Context, firebaseUID string, createInvitationDTO *models.CreateInvitationDTO) error
+	GetInvitations(ctx context.Context, firebaseUID string) ([]models.InvitationResponseDTO, error)
+	HasInvitation(ctx context.Context, userID uuid.UUID, projectID uuid.UUID) bool
+	HasInvitationFirebaseUID(ctx context.Context, firebaseUID string, projectID uuid.UUID) bool
+	EditInvitation(ctx context.Context, firebaseUID string, editInvitationDTO models.EditInvitationDTO) error
 }
 
 type invitationService struct {
@@ -30,27 +32,27 @@ func NewInvitationService(ir repositories.InvitationRepository, us UserService,
 	return &invitationService{invitationRepository: ir, userService: us, projectService: ps, projectMemberService: pms, notificationService: ns}
 }
 
-func (s *invitationService) CreateInvitation(firebaseUID string, createInvitationDTO *models.CreateInvitationDTO) error {
+func (s *invitationService) CreateInvitation(ctx context.Context, firebaseUID string, createInvitationDTO *models.CreateInvitationDTO) error {
 
-	user, err := s.userService.GetUserByFirebaseUID(firebaseUID)
+	user, err := s.userService.GetUserByFirebaseUID(ctx, firebaseUID)
 	if err != nil {
 		return err
 	}
 
 	createInvitationDTO.InvitedBy = user.ID
 
-	projectMember, err := s.projectMemberService.GetProjectMemberByFirebaseUID(firebaseUID, createInvitationDTO.ProjectID)
+	projectMember, err := s.projectMemberService.GetProjectMemberByFirebaseUID(ctx, firebaseUID, createInvitationDTO.ProjectID)
 	if err != nil {
-		return err
+		return requireMember(err)
 	}
 
 	if !utils.HasEditPrivileges(projectMember) {
-		return errors.New("you dont have the privileges to invite an user")
+		return apperrors.Forbidden("you don't have the privileges to invite a user")
 	}
 
-	err = s.invitationRepository.CreateInvitation(createInvitationDTO)
+	err = s.invitationRepository.CreateInvitation(ctx, createInvitationDTO)
 	if err == nil {
-		projectName, err := s.projectService.GetProjectName(createInvitationDTO.ProjectID)
+		projectName, err := s.projectService.GetProjectName(ctx, createInvitationDTO.ProjectID)
 		if err == nil {
 			notification := &models.CreateNotificationDTO{
 				UserID:    createInvitationDTO.InvitedUserID,
@@ -60,9 +62,9 @@ func (s *invitationService) CreateInvitation(firebaseUID string, createInvitatio
 				TaskID:    nil,
 			}
 
-			err = s.notificationService.CreateNotification(*notification)
+			err = s.notificationService.CreateNotification(ctx, *notification)
 			if err != nil {
-				fmt.Println("Failed to create notification:", err)
+				logging.FromContext(ctx).Error("failed to notify invited user", "project_id", createInvitationDTO.ProjectID, "user_id", createInvitationDTO.InvitedUserID, "error", err)
 			}
 		}
 	}
@@ -70,48 +72,48 @@ func (s *invitationService) CreateInvitation(firebaseUID string, createInvitatio
 	return err
 }
 
-func (s *invitationService) GetInvitations(firebaseUID string) ([]models.InvitationResponseDTO, error) {
+func (s *invitationService) GetInvitations(ctx context.Context, firebaseUID string) ([]models.InvitationResponseDTO, error) {
 
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+	userID, err := s.userService.GetUserIDByFirebaseUID(ctx, firebaseUID)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.invitationRepository.GetInvitations(userID)
+	return s.invitationRepository.GetInvitations(ctx, userID)
 }
 
-func (s *invitationService) HasInvitation(userID uuid.UUID, projectID uuid.UUID) bool {
+func (s *invitationService) HasInvitation(ctx context.Context, userID uuid.UUID, projectID uuid.UUID) bool {
 
-	return s.invitationRepository.HasInvitation(userID, projectID)
+	return s.invitationRepository.HasInvitation(ctx, userID, projectID)
 }
 
-func (s *invitationService) HasInvitationFirebaseUID(firebaseUID string, projectID uuid.UUID) bool {
+func (s *invitationService) HasInvitationFirebaseUID(ctx context.Context, firebaseUID string, projectID uuid.UUID) bool {
 
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+	userID, err := s.userService.GetUserIDByFirebaseUID(ctx, firebaseUID)
 	if err != nil {
 		return false
 	}
 
-	return s.invitationRepository.HasInvitation(userID, projectID)
+	return s.invitationRepository.HasInvitation(ctx, userID, projectID)
 }
 
-func (s *invitationService) EditInvitation(firebaseUID string, editInvitationDTO models.EditInvitationDTO) error {
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+func (s *invitationService) EditInvitation(ctx context.Context, firebaseUID string, editInvitationDTO models.EditInvitationDTO) error {
+	userID, err := s.userService.GetUserIDByFirebaseUID(ctx, firebaseUID)
 	if err != nil {
-		return nil
+		return err
 	}
 
-	hasInvitation := s.HasInvitation(userID, editInvitationDTO.ProjectID)
+	hasInvitation := s.HasInvitation(ctx, userID, editInvitationDTO.ProjectID)
 
 	if hasInvitation {
 		if editInvitationDTO.Status == string(models.InivtationStatusAccepted) {
-			if err = s.projectService.JoinProject(editInvitationDTO.ProjectID, firebaseUID); err != nil {
+			if err = s.projectService.JoinProject(ctx, editInvitationDTO.ProjectID, firebaseUID); err != nil {
 				return err
 			}
 		}
-		return s.invitationRepository.EditInvitation(editInvitationDTO)
+		return s.invitationRepository.EditInvitation(ctx, editInvitationDTO)
 	} else {
-		return errors.New("invitation for the user and project doesnt exist")
+		return apperrors.NotFound("invitation for the user and project doesn't exist")
 	}
 
 }
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/repositories"
+)
+
+type LabelService interface {
+	CreateLabel(ctx context.Context, firebaseUID string, labelDTO *models.CreateLabelDTO) (*models.LabelResponseDTO, error)
+	GetLabels(ctx context.Context, firebaseUID string, projectID uuid.UUID) ([]models.LabelResponseDTO, error)
+	UpdateLabel(ctx context.Context, firebaseUID string, projectID uuid.UUID, labelID uuid.UUID, labelDTO *models.UpdateLabelDTO) (*models.LabelResponseDTO, error)
+	DeleteLabel(ctx context.Context, firebaseUID string, projectID uuid.UUID, labelID uuid.UUID) error
+
+	// AddTaskLabel and RemoveTaskLabel return the task with its labels after the change
+	AddTaskLabel(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID, labelID uuid.UUID) (*models.TaskResponseDTO, error)
+	RemoveTaskLabel(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID, labelID uuid.UUID) (*models.TaskResponseDTO, error)
+}
+
+type labelService struct {
+	labelRepository      repositories.LabelRepository
+	taskService          TaskService
+	projectMemberService ProjectMemberService
+}
+
+func NewLabelService(lr repositories.LabelRepository, ts TaskService, pms ProjectMemberService) LabelService {
+	return &labelService{labelRepository: lr, taskService: ts, projectMemberService: pms}
+}
+
+// getProjectLabel answers not found for labels of other projects as well as missing ones
+func (s *labelService) getProjectLabel(ctx context.Context, projectID uuid.UUID, labelID uuid.UUID) (*models.LabelResponseDTO, error) {
+	label, err := s.labelRepository.GetLabel(ctx, labelID)
+	if err != nil {
+		return nil, err
+	}
+	if label.ProjectID != projectID {
+		return nil, apperrors.NotFound("label not found")
+	}
+	return label, nil
+}
+
+func (s *labelService) CreateLabel(ctx context.Context, firebaseUID string, labelDTO *models.CreateLabelDTO) (*models.LabelResponseDTO, error) {
+	if _, err := requireEditor(ctx, s.projectMemberService, firebaseUID, labelDTO.ProjectID); err != nil {
+		return nil, err
+	}
+
+	labelDTO.Name, labelDTO.Color = models.NormalizeLabel(labelDTO.Name, labelDTO.Color)
+	if problems := models.ValidateLabel(labelDTO.Name, labelDTO.Color); problems != nil {
+		return nil, apperrors.Validation("invalid label", problems)
+	}
+
+	labelID, err := s.labelRepository.CreateLabel(ctx, labelDTO)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.labelRepository.GetLabel(ctx, labelID)
+}
+
+func (s *labelService) GetLabels(ctx context.Context, firebaseUID string, projectID uuid.UUID) ([]models.LabelResponseDTO, error) {
+	if _, err := s.projectMemberService.GetProjectMemberByFirebaseUID(ctx, firebaseUID, projectID); err != nil {
+		return nil, requireMember(err)
+	}
+
+	return s.labelRepository.GetLabels(ctx, projectID)
+}
+
+func (s *labelService) UpdateLabel(ctx context.Context, firebaseUID string, projectID uuid.UUID, labelID uuid.UUID, labelDTO *models.UpdateLabelDTO) (*models.LabelResponseDTO, error) {
+	if _, err := requireEditor(ctx, s.projectMemberService, firebaseUID, projectID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getProjectLabel(ctx, projectID, labelID); err != nil {
+		return nil, err
+	}
+
+	labelDTO.Name, labelDTO.Color = models.NormalizeLabel(labelDTO.Name, labelDTO.Color)
+	if problems := models.ValidateLabel(labelDTO.Name, labelDTO.Color); problems != nil {
+		return nil, apperrors.Validation("invalid label", problems)
+	}
+
+	if err := s.labelRepository.UpdateLabel(ctx, labelID, labelDTO); err != nil {
+		return nil, err
+	}
+
+	return s.labelRepository.GetLabel(ctx, labelID)
+}
+
+// DeleteLabel deletes the label and detaches it from every task it was on
+func (s *labelService) DeleteLabel(ctx context.Context, firebaseUID string, projectID uuid.UUID, labelID uuid.UUID) error {
+	if _, err := requireEditor(ctx, s.projectMemberService, firebaseUID, projectID); err != nil {
+		return err
+	}
+
+	if _, err := s.getProjectLabel(ctx, projectID, labelID); err != nil {
+		return err
+	}
+
+	return s.labelRepository.DeleteLabel(ctx, labelID)
+}
+
+func (s *labelService) AddTaskLabel(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID, labelID uuid.UUID) (*models.TaskResponseDTO, error) {
+	return s.changeTaskLabel(ctx, firebaseUID, projectID, taskID, labelID, s.labelRepository.AddTaskLabel)
+}
+
+func (s *labelService) RemoveTaskLabel(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID, labelID uuid.UUID) (*models.TaskResponseDTO, error) {
+	return s.changeTaskLabel(ctx, firebaseUID, projectID, taskID, labelID, s.labelRepository.RemoveTaskLabel)
+}
+
+func (s *labelService) changeTaskLabel(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID, labelID uuid.UUID, change func(ctx context.Context, taskID uuid.UUID, labelID uuid.UUID) error) (*models.TaskResponseDTO, error) {
+	if _, err := requireEditor(ctx, s.projectMemberService, firebaseUID, projectID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.taskService.GetTaskByID(ctx, firebaseUID, projectID, taskID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getProjectLabel(ctx, projectID, labelID); err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return nil, apperrors.Validation("invalid label", map[string]string{"labelId": "must be a label of this project"})
+		}
+		return nil, err
+	}
+
+	if err := change(ctx, taskID, labelID); err != nil {
+		return nil, err
+	}
+
+	return s.taskService.GetTaskByID(ctx, firebaseUID, projectID, taskID)
+}
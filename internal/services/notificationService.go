@@ -1,70 +1,80 @@
 package services
 
 import (
-	"errors"
+	"context"
 
 	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/metrics"
 	"github.com/sarvochcha01/enlace-backend/internal/models"
 	"github.com/sarvochcha01/enlace-backend/internal/repositories"
 	"github.com/sarvochcha01/enlace-backend/internal/websockets"
 )
 
 type NotificationService interface {
-	CreateNotification(createNotificationDTO models.CreateNotificationDTO) error
-	GetAllNotificationsForUser(firebaseUID string) ([]models.NotificationResponseDTO, error)
-	GetNotification(notificationID uuid.UUID) (*models.NotificationResponseDTO, error)
-	MarkNotificationAsRead(firebaseUID string, notificationID uuid.UUID) error
+	CreateNotification(ctx context.Context, createNotificationDTO models.CreateNotificationDTO) error
+	GetAllNotificationsForUser(ctx context.Context, firebaseUID string) ([]models.NotificationResponseDTO, error)
+	GetNotification(ctx context.Context, notificationID uuid.UUID) (*models.NotificationResponseDTO, error)
+	MarkNotificationAsRead(ctx context.Context, firebaseUID string, notificationID uuid.UUID) error
+	// PublishProjectEvent pushes event to the given users' open connections without storing it
+	PublishProjectEvent(userIDs []uuid.UUID, event models.ProjectEventDTO)
 }
 
 type notificationService struct {
 	notificationRepository repositories.NotificationRepository
 	wsHub                  *websockets.WebSocketHub
 	userService            UserService
+	metrics                *metrics.Metrics
 }
 
-func NewNotificationService(nr repositories.NotificationRepository, wsHub *websockets.WebSocketHub, us UserService) NotificationService {
-	return &notificationService{notificationRepository: nr, wsHub: wsHub, userService: us}
+func NewNotificationService(nr repositories.NotificationRepository, wsHub *websockets.WebSocketHub, us UserService, m *metrics.Metrics) NotificationService {
+	return &notificationService{notificationRepository: nr, wsHub: wsHub, userService: us, metrics: m}
 }
 
-func (s *notificationService) CreateNotification(createNotificationDTO models.CreateNotificationDTO) error {
-	notification, err := s.notificationRepository.CreateNotification(createNotificationDTO)
+func (s *notificationService) CreateNotification(ctx context.Context, createNotificationDTO models.CreateNotificationDTO) error {
+	notification, err := s.notificationRepository.CreateNotification(ctx, createNotificationDTO)
 	if err != nil {
 		return err
 	}
+	s.metrics.NotificationCreated()
 
 	s.wsHub.SendNotificationToUser(*notification)
 
 	return nil
 }
 
-func (s *notificationService) GetAllNotificationsForUser(firebaseUID string) ([]models.NotificationResponseDTO, error) {
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+func (s *notificationService) PublishProjectEvent(userIDs []uuid.UUID, event models.ProjectEventDTO) {
+	s.wsHub.SendEventToUsers(userIDs, event)
+}
+
+func (s *notificationService) GetAllNotificationsForUser(ctx context.Context, firebaseUID string) ([]models.NotificationResponseDTO, error) {
+	userID, err := s.userService.GetUserIDByFirebaseUID(ctx, firebaseUID)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.notificationRepository.GetAllNotificationsForUser(userID)
+	return s.notificationRepository.GetAllNotificationsForUser(ctx, userID)
 }
 
-func (s *notificationService) GetNotification(notificationID uuid.UUID) (*models.NotificationResponseDTO, error) {
-	return s.notificationRepository.GetNotification(notificationID)
+func (s *notificationService) GetNotification(ctx context.Context, notificationID uuid.UUID) (*models.NotificationResponseDTO, error) {
+	return s.notificationRepository.GetNotification(ctx, notificationID)
 }
 
-func (s *notificationService) MarkNotificationAsRead(fireabseUID string, notificationID uuid.UUID) error {
-	userID, err := s.userService.GetUserIDByFirebaseUID(fireabseUID)
+func (s *notificationService) MarkNotificationAsRead(ctx context.Context, fireabseUID string, notificationID uuid.UUID) error {
+	userID, err := s.userService.GetUserIDByFirebaseUID(ctx, fireabseUID)
 	if err != nil {
 		return err
 	}
 
-	notification, err := s.GetNotification(notificationID)
+	notification, err := s.GetNotification(ctx, notificationID)
 	if err != nil {
 		return err
 	}
 
 	if userID != notification.UserID {
-		return errors.New("only the receiver can update the notification status")
+		return apperrors.Forbidden("only the receiver can update the notification status")
 	}
 
-	return s.notificationRepository.MarkNotificationAsRead(notificationID)
+	return s.notificationRepository.MarkNotificationAsRead(ctx, notificationID)
 
 }
@@ -1,28 +1,28 @@
 package services
 
 import (
-	"database/sql"
-	"errors"
-	"log"
+	"context"
 
 	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
 	"github.com/sarvochcha01/enlace-backend/internal/models"
 	"github.com/sarvochcha01/enlace-backend/internal/repositories"
 	"github.com/sarvochcha01/enlace-backend/internal/utils"
 )
 
 type ProjectMemberService interface {
-	CreateProjectMember(createProjectMemberDTO *models.CreateProjectMemberDTO, firebaseUID string) error
-	CreateProjectMemberTx(*sql.Tx, *models.CreateProjectMemberDTO) (uuid.UUID, error)
-	GetUserID(projectMemberID uuid.UUID) (uuid.UUID, error)
-	GetProjectMemberID(userID uuid.UUID, projectID uuid.UUID) (uuid.UUID, error)
-	GetProjectMemberIDByFirebaseUID(firebaseUID string, projectID uuid.UUID) (uuid.UUID, error)
-	GetProjectMemberByFirebaseUID(firebaseUID string, projectID uuid.UUID) (*models.ProjectMemberResponseDTO, error)
-	GetProjectMemberByUserID(userID uuid.UUID, projectID uuid.UUID) (*models.ProjectMemberResponseDTO, error)
-	GetProjectMember(uuid.UUID) (*models.ProjectMemberResponseDTO, error)
-
-	UpdateProjectMemberStatus(projectMemberID uuid.UUID, newStatus models.ProjectMemberStatus) error
-	UpdateProjectMemberRole(firebaseUID string, updateProjectMemberDTO *models.UpdateProjectMemberDTO) error
+	CreateProjectMember(ctx context.Context, createProjectMemberDTO *models.CreateProjectMemberDTO, firebaseUID string) error
+	CreateProjectMemberTx(context.Context, repositories.Tx, *models.CreateProjectMemberDTO) (uuid.UUID, error)
+	GetUserID(ctx context.Context, projectMemberID uuid.UUID) (uuid.UUID, error)
+	GetProjectMemberID(ctx context.Context, userID uuid.UUID, projectID uuid.UUID) (uuid.UUID, error)
+	GetProjectMemberIDByFirebaseUID(ctx context.Context, firebaseUID string, projectID uuid.UUID) (uuid.UUID, error)
+	GetProjectMemberByFirebaseUID(ctx context.Context, firebaseUID string, projectID uuid.UUID) (*models.ProjectMemberResponseDTO, error)
+	GetProjectMemberByUserID(ctx context.Context, userID uuid.UUID, projectID uuid.UUID) (*models.ProjectMemberResponseDTO, error)
+	GetProjectMember(context.Context, uuid.UUID) (*models.ProjectMemberResponseDTO, error)
+	GetActiveUserIDs(ctx context.Context, projectID uuid.UUID) ([]uuid.UUID, error)
+
+	UpdateProjectMemberStatus(ctx context.Context, projectMemberID uuid.UUID, newStatus models.ProjectMemberStatus) error
+	UpdateProjectMemberRole(ctx context.Context, firebaseUID string, updateProjectMemberDTO *models.UpdateProjectMemberDTO) error
 }
 
 type projectMemberService struct {
@@ -34,91 +34,96 @@ func NewProjectMemberService(pr repositories.ProjectMemberRepository, us UserSer
 	return &projectMemberService{projectMemberRepository: pr, userService: us}
 }
 
-func (s *projectMemberService) CreateProjectMember(createProjectMemberDTO *models.CreateProjectMemberDTO, firebaseUID string) error {
+func (s *projectMemberService) CreateProjectMember(ctx context.Context, createProjectMemberDTO *models.CreateProjectMemberDTO, firebaseUID string) error {
 
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+	userID, err := s.userService.GetUserIDByFirebaseUID(ctx, firebaseUID)
 	if err != nil {
-		log.Println("UserID not found: ", err)
-		return errors.New("UserID not found: " + err.Error())
+		return err
 	}
 
 	createProjectMemberDTO.UserID = userID
 
-	return s.projectMemberRepository.CreateProjectMember(createProjectMemberDTO)
+	return s.projectMemberRepository.CreateProjectMember(ctx, createProjectMemberDTO)
+}
+
+func (s *projectMemberService) CreateProjectMemberTx(ctx context.Context, tx repositories.Tx, createProjectMemberDTO *models.CreateProjectMemberDTO) (uuid.UUID, error) {
+	return s.projectMemberRepository.CreateProjectMemberTx(ctx, tx, createProjectMemberDTO)
 }
 
-func (s *projectMemberService) CreateProjectMemberTx(tx *sql.Tx, createProjectMemberDTO *models.CreateProjectMemberDTO) (uuid.UUID, error) {
-	return s.projectMemberRepository.CreateProjectMemberTx(tx, createProjectMemberDTO)
+func (s *projectMemberService) GetProjectMemberByUserID(ctx context.Context, userID uuid.UUID, projectID uuid.UUID) (*models.ProjectMemberResponseDTO, error) {
+	return s.projectMemberRepository.GetProjectMemberByUserID(ctx, userID, projectID)
 }
 
-func (s *projectMemberService) GetProjectMemberByUserID(userID uuid.UUID, projectID uuid.UUID) (*models.ProjectMemberResponseDTO, error) {
-	return s.projectMemberRepository.GetProjectMemberByUserID(userID, projectID)
+func (s *projectMemberService) GetUserID(ctx context.Context, projectMemberID uuid.UUID) (uuid.UUID, error) {
+	return s.projectMemberRepository.GetUserID(ctx, projectMemberID)
 }
 
-func (s *projectMemberService) GetUserID(projectMemberID uuid.UUID) (uuid.UUID, error) {
-	return s.projectMemberRepository.GetUserID(projectMemberID)
+func (s *projectMemberService) GetProjectMemberID(ctx context.Context, userID uuid.UUID, projectID uuid.UUID) (uuid.UUID, error) {
+	return s.projectMemberRepository.GetProjectMemberID(ctx, userID, projectID)
 }
 
-func (s *projectMemberService) GetProjectMemberID(userID uuid.UUID, projectID uuid.UUID) (uuid.UUID, error) {
-	return s.projectMemberRepository.GetProjectMemberID(userID, projectID)
+func (s *projectMemberService) GetProjectMember(ctx context.Context, projectMemberID uuid.UUID) (*models.ProjectMemberResponseDTO, error) {
+	return s.projectMemberRepository.GetProjectMember(ctx, projectMemberID)
 }
 
-func (s *projectMemberService) GetProjectMember(projectMemberID uuid.UUID) (*models.ProjectMemberResponseDTO, error) {
-	return s.projectMemberRepository.GetProjectMember(projectMemberID)
+func (s *projectMemberService) GetActiveUserIDs(ctx context.Context, projectID uuid.UUID) ([]uuid.UUID, error) {
+	return s.projectMemberRepository.GetActiveUserIDs(ctx, projectID)
 }
 
-func (s *projectMemberService) GetProjectMemberIDByFirebaseUID(firebaseUID string, projectID uuid.UUID) (uuid.UUID, error) {
+func (s *projectMemberService) GetProjectMemberIDByFirebaseUID(ctx context.Context, firebaseUID string, projectID uuid.UUID) (uuid.UUID, error) {
 
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+	userID, err := s.userService.GetUserIDByFirebaseUID(ctx, firebaseUID)
 	if err != nil {
-		log.Println("Failed to get userID")
-		return uuid.Nil, errors.New("failed to get userID")
+		return uuid.Nil, err
 	}
 
-	return s.projectMemberRepository.GetProjectMemberID(userID, projectID)
+	return s.projectMemberRepository.GetProjectMemberID(ctx, userID, projectID)
 }
 
-func (s *projectMemberService) GetProjectMemberByFirebaseUID(firebaseUID string, projectID uuid.UUID) (*models.ProjectMemberResponseDTO, error) {
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+func (s *projectMemberService) GetProjectMemberByFirebaseUID(ctx context.Context, firebaseUID string, projectID uuid.UUID) (*models.ProjectMemberResponseDTO, error) {
+	userID, err := s.userService.GetUserIDByFirebaseUID(ctx, firebaseUID)
 	if err != nil {
-		log.Println("Failed to get userID")
-		return nil, errors.New("failed to get userID")
+		return nil, err
 	}
 
-	return s.projectMemberRepository.GetProjectMemberByUserID(userID, projectID)
+	return s.projectMemberRepository.GetProjectMemberByUserID(ctx, userID, projectID)
 }
 
-func (s *projectMemberService) UpdateProjectMemberStatus(projectMemberID uuid.UUID, newStatus models.ProjectMemberStatus) error {
-	return s.projectMemberRepository.UpdateProjectMemberStatus(projectMemberID, newStatus)
+func (s *projectMemberService) UpdateProjectMemberStatus(ctx context.Context, projectMemberID uuid.UUID, newStatus models.ProjectMemberStatus) error {
+	return s.projectMemberRepository.UpdateProjectMemberStatus(ctx, projectMemberID, newStatus)
 }
 
-func (s *projectMemberService) UpdateProjectMemberRole(firebaseUID string, updateProjectMemberDTO *models.UpdateProjectMemberDTO) error {
+func (s *projectMemberService) UpdateProjectMemberRole(ctx context.Context, firebaseUID string, updateProjectMemberDTO *models.UpdateProjectMemberDTO) error {
 
-	projectMemberWhoRequested, err := s.GetProjectMemberByFirebaseUID(firebaseUID, updateProjectMemberDTO.ProjectID)
+	projectMemberWhoRequested, err := s.GetProjectMemberByFirebaseUID(ctx, firebaseUID, updateProjectMemberDTO.ProjectID)
 	if err != nil {
-		return err
+		return requireMember(err)
 	}
 
 	if projectMemberWhoRequested.ID == updateProjectMemberDTO.ID {
-		return errors.New("no you can't change your own role")
+		return apperrors.Forbidden("you can't change your own role")
 	}
 
 	if !utils.HasEditPrivileges(projectMemberWhoRequested) {
-		return errors.New("no edit privileges")
+		return noEditPrivilege()
 	}
 
-	projectMemberToUpdate, err := s.GetProjectMember(updateProjectMemberDTO.ID)
+	projectMemberToUpdate, err := s.GetProjectMember(ctx, updateProjectMemberDTO.ID)
 	if err != nil {
 		return err
 	}
 
+	if projectMemberToUpdate.ProjectID != updateProjectMemberDTO.ProjectID {
+		return apperrors.NotFound("project member not found")
+	}
+
 	if projectMemberToUpdate.Role == models.RoleOwner {
-		return errors.New("owners can't be edited")
+		return apperrors.Forbidden("owners can't be edited")
 	}
 
 	if projectMemberWhoRequested.Role == models.RoleEditor && updateProjectMemberDTO.Role == models.RoleOwner {
-		return errors.New("lmao, editors can't make themselves owner")
+		return apperrors.Forbidden("editors can't promote members to owner")
 	}
 
-	return s.projectMemberRepository.UpdateProjectMemberRole(projectMemberToUpdate.ID, models.ProjectMemberRole(updateProjectMemberDTO.Role))
+	return s.projectMemberRepository.UpdateProjectMemberRole(ctx, projectMemberToUpdate.ID, models.ProjectMemberRole(updateProjectMemberDTO.Role))
 }
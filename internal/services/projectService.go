@@ -1,28 +1,29 @@
 package services
 
 import (
-	"database/sql"
+	"context"
 	"errors"
-	"log"
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/logging"
 	"github.com/sarvochcha01/enlace-backend/internal/models"
 	"github.com/sarvochcha01/enlace-backend/internal/repositories"
 )
 
 type ProjectService interface {
-	CreateProject(projectDTO *models.CreateProjectDTO, firebaseUID string) error
-	GetProjectByID(uuid.UUID, string) (*models.ProjectResponseDTO, error)
-	GetAllProjectsForUser(firebaseUID string) ([]models.ProjectResponseDTO, error)
-	EditProject(firebaseUID string, projectID uuid.UUID, projectDTO *models.EditProjectDTO) error
-	DeleteProject(firebaseUID string, projectID uuid.UUID) error
+	CreateProject(ctx context.Context, projectDTO *models.CreateProjectDTO, firebaseUID string) error
+	GetProjectByID(context.Context, uuid.UUID, string) (*models.ProjectResponseDTO, error)
+	GetAllProjectsForUser(ctx context.Context, firebaseUID string) ([]models.ProjectResponseDTO, error)
+	EditProject(ctx context.Context, firebaseUID string, projectID uuid.UUID, projectDTO *models.EditProjectDTO) (*models.ProjectResponseDTO, error)
+	DeleteProject(ctx context.Context, firebaseUID string, projectID uuid.UUID) error
 
-	GetProjectName(projectID uuid.UUID) (string, error)
+	GetProjectName(ctx context.Context, projectID uuid.UUID) (string, error)
 
-	LeaveProject(projectID uuid.UUID, firebaseUID string) error
-	JoinProject(projectID uuid.UUID, firebaseUID string) error
-	GetProjectCreatorID(projectID uuid.UUID) (uuid.UUID, error)
+	LeaveProject(ctx context.Context, projectID uuid.UUID, firebaseUID string) error
+	JoinProject(ctx context.Context, projectID uuid.UUID, firebaseUID string) error
+	GetProjectCreatorID(ctx context.Context, projectID uuid.UUID) (uuid.UUID, error)
 }
 
 type projectService struct {
@@ -35,12 +36,12 @@ func NewProjectService(pr repositories.ProjectRepository, us UserService, ps Pro
 	return &projectService{projectRepository: pr, userService: us, projectMemberService: ps}
 }
 
-func (s *projectService) CreateProject(projectDTO *models.CreateProjectDTO, firebaseUID string) error {
+func (s *projectService) CreateProject(ctx context.Context, projectDTO *models.CreateProjectDTO, firebaseUID string) error {
 
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+	userID, err := s.userService.GetUserIDByFirebaseUID(ctx, firebaseUID)
 
 	if err != nil {
-		return errors.New("UserID not found: " + err.Error())
+		return err
 	}
 
 	projectDTO.Key = strings.ToUpper(projectDTO.Key)
@@ -48,16 +49,15 @@ func (s *projectService) CreateProject(projectDTO *models.CreateProjectDTO, fire
 
 	var projectID uuid.UUID
 
-	tx, err := s.projectRepository.BeginTransaction()
+	tx, err := s.projectRepository.BeginTransaction(ctx)
 	if err != nil {
 		return err
 	}
 
-	projectID, err = s.projectRepository.CreateProject(tx, projectDTO)
+	projectID, err = s.projectRepository.CreateProject(ctx, tx, projectDTO)
 
 	if err != nil {
 		tx.Rollback()
-		log.Println("Failed to create project: ", err)
 		return err
 	}
 
@@ -67,118 +67,126 @@ func (s *projectService) CreateProject(projectDTO *models.CreateProjectDTO, fire
 		Role:      models.RoleOwner,
 	}
 
-	_, err = s.projectMemberService.CreateProjectMemberTx(tx, projectMemberDTO)
+	_, err = s.projectMemberService.CreateProjectMemberTx(ctx, tx, projectMemberDTO)
 	if err != nil {
 		tx.Rollback()
-		log.Println("Failed to add creator as project member: ", err)
 		return err
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		log.Println("Failed to commit transaction:", err)
 		return err
 	}
 
+	logging.FromContext(ctx).Info("project created", "project_id", projectID, "key", projectDTO.Key)
+
 	return nil
 }
 
-func (s *projectService) GetProjectByID(projectID uuid.UUID, firebaseUID string) (*models.ProjectResponseDTO, error) {
+func (s *projectService) GetProjectByID(ctx context.Context, projectID uuid.UUID, firebaseUID string) (*models.ProjectResponseDTO, error) {
 
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+	userID, err := s.userService.GetUserIDByFirebaseUID(ctx, firebaseUID)
 	if err != nil {
-		log.Println("UserID not found: ", err)
-		return nil, errors.New("UserID not found: " + err.Error())
+		return nil, err
 	}
 
-	projectMember, err := s.projectMemberService.GetProjectMemberByUserID(userID, projectID)
+	projectMember, err := s.projectMemberService.GetProjectMemberByUserID(ctx, userID, projectID)
 	if err != nil {
-		log.Println("Not a project member: ", err)
-		return nil, errors.New("not a project member: " + err.Error())
+		return nil, requireMember(err)
 	}
 
 	if projectMember.Status == models.StatusInactive {
-		log.Println("Project member is inactive")
-		return nil, errors.New("project member is inactive")
+		return nil, apperrors.Forbidden("you are no longer an active member of this project")
 	}
 
-	return s.projectRepository.GetProjectByID(projectID)
+	return s.projectRepository.GetProjectByID(ctx, projectID)
 }
 
-func (s *projectService) GetAllProjectsForUser(firebaseUID string) ([]models.ProjectResponseDTO, error) {
+func (s *projectService) GetAllProjectsForUser(ctx context.Context, firebaseUID string) ([]models.ProjectResponseDTO, error) {
 
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+	userID, err := s.userService.GetUserIDByFirebaseUID(ctx, firebaseUID)
 
 	if err != nil {
-		log.Println("UserID not found: ", err)
-		return nil, errors.New("UserID not found: " + err.Error())
+		return nil, err
 	}
 
-	return s.projectRepository.GetAllProjectsForUser(userID)
+	return s.projectRepository.GetAllProjectsForUser(ctx, userID)
 }
 
-func (s *projectService) GetProjectName(projectID uuid.UUID) (string, error) {
-	return s.projectRepository.GetProjectName(projectID)
+func (s *projectService) GetProjectName(ctx context.Context, projectID uuid.UUID) (string, error) {
+	return s.projectRepository.GetProjectName(ctx, projectID)
 }
 
-func (s *projectService) GetProjectCreatorID(projectID uuid.UUID) (uuid.UUID, error) {
-	return s.projectRepository.GetProjectCreatorID(projectID)
+func (s *projectService) GetProjectCreatorID(ctx context.Context, projectID uuid.UUID) (uuid.UUID, error) {
+	return s.projectRepository.GetProjectCreatorID(ctx, projectID)
 }
 
-func (s *projectService) DeleteProject(firebaseUID string, projectID uuid.UUID) error {
+func (s *projectService) DeleteProject(ctx context.Context, firebaseUID string, projectID uuid.UUID) error {
 
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+	userID, err := s.userService.GetUserIDByFirebaseUID(ctx, firebaseUID)
 	if err != nil {
 		return err
 	}
 
-	creatorID, err := s.GetProjectCreatorID(projectID)
+	creatorID, err := s.GetProjectCreatorID(ctx, projectID)
 	if err != nil {
-		return nil
+		return err
 	}
 
 	if userID != creatorID {
-		return errors.New("only the project creator can delete the project")
+		return apperrors.Forbidden("only the project creator can delete the project")
 	}
 
-	return s.projectRepository.DeleteProject(projectID)
+	return s.projectRepository.DeleteProject(ctx, projectID)
 }
 
-func (s *projectService) EditProject(firebaseUID string, projectID uuid.UUID, projectDTO *models.EditProjectDTO) error {
+// EditProject renames or redescribes the project and returns it. An edit with an If-Match that no
+// longer matches the project fails with the project as it is now.
+func (s *projectService) EditProject(ctx context.Context, firebaseUID string, projectID uuid.UUID, projectDTO *models.EditProjectDTO) (*models.ProjectResponseDTO, error) {
 
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+	userID, err := s.userService.GetUserIDByFirebaseUID(ctx, firebaseUID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	projectCreatorID, err := s.GetProjectCreatorID(projectID)
+	projectCreatorID, err := s.GetProjectCreatorID(ctx, projectID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if userID != projectCreatorID {
-		return errors.New("only project creator can edit project")
+		return nil, apperrors.Forbidden("only the project creator can edit the project")
+	}
+
+	err = s.projectRepository.EditProject(ctx, projectID, projectDTO)
+	if errors.Is(err, apperrors.ErrPreconditionFailed) {
+		if current, getErr := s.GetProjectByID(ctx, projectID, firebaseUID); getErr == nil {
+			return nil, withCurrent(err, current, current.ETag())
+		}
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return s.projectRepository.EditProject(projectID, projectDTO)
+	return s.GetProjectByID(ctx, projectID, firebaseUID)
 }
 
-func (s *projectService) JoinProject(projectID uuid.UUID, firebaseUID string) error {
+func (s *projectService) JoinProject(ctx context.Context, projectID uuid.UUID, firebaseUID string) error {
 
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+	userID, err := s.userService.GetUserIDByFirebaseUID(ctx, firebaseUID)
 	if err != nil {
 		return err
 	}
 
-	projectMember, err := s.projectMemberService.GetProjectMemberByUserID(userID, projectID)
+	projectMember, err := s.projectMemberService.GetProjectMemberByUserID(ctx, userID, projectID)
 	if err != nil {
 
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, apperrors.ErrNotFound) {
 			var projectMemberDTO models.CreateProjectMemberDTO
 			projectMemberDTO.ProjectID = projectID
 			projectMemberDTO.Role = models.RoleViewer
 
-			return s.projectMemberService.CreateProjectMember(&projectMemberDTO, firebaseUID)
+			return s.projectMemberService.CreateProjectMember(ctx, &projectMemberDTO, firebaseUID)
 		}
 
 		return err
@@ -186,32 +194,32 @@ func (s *projectService) JoinProject(projectID uuid.UUID, firebaseUID string) er
 	}
 
 	if projectMember.Status == models.StatusActive {
-		return errors.New("already an active project member")
+		return apperrors.Conflict("already an active project member")
 	}
 
-	return s.projectMemberService.UpdateProjectMemberStatus(projectMember.ID, models.StatusActive)
+	return s.projectMemberService.UpdateProjectMemberStatus(ctx, projectMember.ID, models.StatusActive)
 }
 
-func (s *projectService) LeaveProject(projectID uuid.UUID, firebaseUID string) error {
+func (s *projectService) LeaveProject(ctx context.Context, projectID uuid.UUID, firebaseUID string) error {
 
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+	userID, err := s.userService.GetUserIDByFirebaseUID(ctx, firebaseUID)
 	if err != nil {
 		return err
 	}
 
-	creatorID, err := s.GetProjectCreatorID(projectID)
+	creatorID, err := s.GetProjectCreatorID(ctx, projectID)
 	if err != nil {
-		return nil
+		return err
 	}
 
 	if userID == creatorID {
-		return errors.New("project creator can't leave the project")
+		return apperrors.Validation("the project creator can't leave the project", nil)
 	}
 
-	projectMemberID, err := s.projectMemberService.GetProjectMemberID(userID, projectID)
+	projectMemberID, err := s.projectMemberService.GetProjectMemberID(ctx, userID, projectID)
 	if err != nil {
-		return err
+		return requireMember(err)
 	}
 
-	return s.projectMemberService.UpdateProjectMemberStatus(projectMemberID, models.StatusInactive)
+	return s.projectMemberService.UpdateProjectMemberStatus(ctx, projectMemberID, models.StatusInactive)
 }
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/repositories"
+)
+
+type SprintService interface {
+	CreateSprint(ctx context.Context, firebaseUID string, sprintDTO *models.CreateSprintDTO) (*models.SprintResponseDTO, error)
+	GetSprints(ctx context.Context, firebaseUID string, projectID uuid.UUID) ([]models.SprintResponseDTO, error)
+	GetSprint(ctx context.Context, firebaseUID string, projectID uuid.UUID, sprintID uuid.UUID) (*models.SprintResponseDTO, error)
+	UpdateSprint(ctx context.Context, firebaseUID string, projectID uuid.UUID, sprintID uuid.UUID, sprintDTO *models.UpdateSprintDTO) (*models.SprintResponseDTO, error)
+	DeleteSprint(ctx context.Context, firebaseUID string, projectID uuid.UUID, sprintID uuid.UUID) error
+	StartSprint(ctx context.Context, firebaseUID string, projectID uuid.UUID, sprintID uuid.UUID) (*models.SprintResponseDTO, error)
+	CloseSprint(ctx context.Context, firebaseUID string, projectID uuid.UUID, sprintID uuid.UUID, closeDTO *models.CloseSprintDTO) (*models.CloseSprintResponseDTO, error)
+	GetBurndown(ctx context.Context, firebaseUID string, projectID uuid.UUID, sprintID uuid.UUID) (*models.SprintBurndownDTO, error)
+	GetVelocity(ctx context.Context, firebaseUID string, projectID uuid.UUID) (*models.VelocityDTO, error)
+}
+
+type sprintService struct {
+	sprintRepository     repositories.SprintRepository
+	projectMemberService ProjectMemberService
+}
+
+func NewSprintService(sr repositories.SprintRepository, pms ProjectMemberService) SprintService {
+	return &sprintService{sprintRepository: sr, projectMemberService: pms}
+}
+
+// getProjectSprint answers not found for sprints of other projects as well as missing ones
+func (s *sprintService) getProjectSprint(ctx context.Context, projectID uuid.UUID, sprintID uuid.UUID) (*models.SprintResponseDTO, error) {
+	sprint, err := s.sprintRepository.GetSprint(ctx, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	if sprint.ProjectID != projectID {
+		return nil, apperrors.NotFound("sprint not found")
+	}
+	return sprint, nil
+}
+
+// CreateSprint plans a sprint. Its tasks are added by setting their sprintId.
+func (s *sprintService) CreateSprint(ctx context.Context, firebaseUID string, sprintDTO *models.CreateSprintDTO) (*models.SprintResponseDTO, error) {
+	projectMember, err := requireEditor(ctx, s.projectMemberService, firebaseUID, sprintDTO.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	sprintDTO.Name, sprintDTO.Goal = models.NormalizeSprint(sprintDTO.Name, sprintDTO.Goal)
+	if problems := models.ValidateSprint(sprintDTO.Name, sprintDTO.Goal, sprintDTO.StartDate, sprintDTO.EndDate); problems != nil {
+		return nil, apperrors.Validation("invalid sprint", problems)
+	}
+
+	sprintDTO.CreatedBy = projectMember.ID
+	sprintID, err := s.sprintRepository.CreateSprint(ctx, sprintDTO)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sprintRepository.GetSprint(ctx, sprintID)
+}
+
+func (s *sprintService) GetSprints(ctx context.Context, firebaseUID string, projectID uuid.UUID) ([]models.SprintResponseDTO, error) {
+	if _, err := s.projectMemberService.GetProjectMemberByFirebaseUID(ctx, firebaseUID, projectID); err != nil {
+		return nil, requireMember(err)
+	}
+
+	return s.sprintRepository.GetSprints(ctx, projectID)
+}
+
+func (s *sprintService) GetSprint(ctx context.Context, firebaseUID string, projectID uuid.UUID, sprintID uuid.UUID) (*models.SprintResponseDTO, error) {
+	if _, err := s.projectMemberService.GetProjectMemberByFirebaseUID(ctx, firebaseUID, projectID); err != nil {
+		return nil, requireMember(err)
+	}
+
+	return s.getProjectSprint(ctx, projectID, sprintID)
+}
+
+// UpdateSprint replaces the name, goal and dates of a sprint that hasn't closed yet
+func (s *sprintService) UpdateSprint(ctx context.Context, firebaseUID string, projectID uuid.UUID, sprintID uuid.UUID, sprintDTO *models.UpdateSprintDTO) (*models.SprintResponseDTO, error) {
+	if _, err := requireEditor(ctx, s.projectMemberService, firebaseUID, projectID); err != nil {
+		return nil, err
+	}
+
+	sprint, err := s.getProjectSprint(ctx, projectID, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	if sprint.State == models.SprintClosed {
+		return nil, apperrors.Conflict("a closed sprint can't be changed")
+	}
+
+	sprintDTO.Name, sprintDTO.Goal = models.NormalizeSprint(sprintDTO.Name, sprintDTO.Goal)
+	if problems := models.ValidateSprint(sprintDTO.Name, sprintDTO.Goal, sprintDTO.StartDate, sprintDTO.EndDate); problems != nil {
+		return nil, apperrors.Validation("invalid sprint", problems)
+	}
+
+	if err := s.sprintRepository.UpdateSprint(ctx, sprintID, sprintDTO); err != nil {
+		return nil, err
+	}
+
+	return s.sprintRepository.GetSprint(ctx, sprintID)
+}
+
+// DeleteSprint deletes a sprint that hasn't started, putting its tasks back in the backlog. Sprints
+// that have started are kept for the burndown and velocity.
+func (s *sprintService) DeleteSprint(ctx context.Context, firebaseUID string, projectID uuid.UUID, sprintID uuid.UUID) error {
+	if _, err := requireEditor(ctx, s.projectMemberService, firebaseUID, projectID); err != nil {
+		return err
+	}
+
+	sprint, err := s.getProjectSprint(ctx, projectID, sprintID)
+	if err != nil {
+		return err
+	}
+	if sprint.State != models.SprintPlanned {
+		return apperrors.Conflict("only a planned sprint can be deleted")
+	}
+
+	return s.sprintRepository.DeleteSprint(ctx, sprintID)
+}
+
+// StartSprint makes a planned sprint the project's active one, committing to the story points it
+// has at that moment
+func (s *sprintService) StartSprint(ctx context.Context, firebaseUID string, projectID uuid.UUID, sprintID uuid.UUID) (*models.SprintResponseDTO, error) {
+	if _, err := requireEditor(ctx, s.projectMemberService, firebaseUID, projectID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getProjectSprint(ctx, projectID, sprintID); err != nil {
+		return nil, err
+	}
+
+	if err := s.sprintRepository.StartSprint(ctx, sprintID); err != nil {
+		return nil, err
+	}
+
+	return s.sprintRepository.GetSprint(ctx, sprintID)
+}
+
+// CloseSprint closes the active sprint. Its done tasks stay in it, the unfinished ones are carried
+// over to the planned sprint closeDTO names or go back to the backlog.
+func (s *sprintService) CloseSprint(ctx context.Context, firebaseUID string, projectID uuid.UUID, sprintID uuid.UUID, closeDTO *models.CloseSprintDTO) (*models.CloseSprintResponseDTO, error) {
+	projectMember, err := requireEditor(ctx, s.projectMemberService, firebaseUID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getProjectSprint(ctx, projectID, sprintID); err != nil {
+		return nil, err
+	}
+
+	// The repository checks closeDTO.CarryOverTo while it holds the sprints, so it can't start in between
+	carriedOver, err := s.sprintRepository.CloseSprint(ctx, sprintID, closeDTO.CarryOverTo, projectMember.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sprint, err := s.sprintRepository.GetSprint(ctx, sprintID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CloseSprintResponseDTO{Sprint: sprint, CarriedOver: carriedOver}, nil
+}
+
+// GetBurndown charts the story points left in a sprint that has started, day by day
+func (s *sprintService) GetBurndown(ctx context.Context, firebaseUID string, projectID uuid.UUID, sprintID uuid.UUID) (*models.SprintBurndownDTO, error) {
+	sprint, err := s.GetSprint(ctx, firebaseUID, projectID, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	if sprint.State == models.SprintPlanned {
+		return nil, apperrors.Conflict("the sprint hasn't started yet")
+	}
+
+	tasks, err := s.sprintRepository.GetSprintTaskPoints(ctx, sprintID)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.Burndown(sprint, tasks, time.Now())
+}
+
+// GetVelocity compares the story points committed to and completed in the last closed sprints
+func (s *sprintService) GetVelocity(ctx context.Context, firebaseUID string, projectID uuid.UUID) (*models.VelocityDTO, error) {
+	if _, err := s.projectMemberService.GetProjectMemberByFirebaseUID(ctx, firebaseUID, projectID); err != nil {
+		return nil, requireMember(err)
+	}
+
+	closed, err := s.sprintRepository.GetClosedSprints(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.Velocity(closed), nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/repositories"
+	"github.com/sarvochcha01/enlace-backend/internal/utils"
+)
+
+type TaskLinkService interface {
+	CreateTaskLink(ctx context.Context, firebaseUID string, projectID uuid.UUID, linkDTO *models.CreateTaskLinkDTO) (*models.TaskLinkResponseDTO, error)
+	GetTaskLinks(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID) ([]models.TaskLinkResponseDTO, error)
+	DeleteTaskLink(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID, linkID uuid.UUID) error
+}
+
+type taskLinkService struct {
+	taskLinkRepository   repositories.TaskLinkRepository
+	taskService          TaskService
+	projectMemberService ProjectMemberService
+}
+
+func NewTaskLinkService(tlr repositories.TaskLinkRepository, ts TaskService, pms ProjectMemberService) TaskLinkService {
+	return &taskLinkService{taskLinkRepository: tlr, taskService: ts, projectMemberService: pms}
+}
+
+// CreateTaskLink links linkDTO.SourceTaskID to linkDTO.TargetTaskID, both tasks of the project, and
+// returns the link as seen from the source. Blocks and duplicates links may not form a cycle.
+func (s *taskLinkService) CreateTaskLink(ctx context.Context, firebaseUID string, projectID uuid.UUID, linkDTO *models.CreateTaskLinkDTO) (*models.TaskLinkResponseDTO, error) {
+	projectMember, err := s.projectMemberService.GetProjectMemberByFirebaseUID(ctx, firebaseUID, projectID)
+	if err != nil {
+		return nil, requireMember(err)
+	}
+
+	if !utils.HasEditPrivileges(projectMember) {
+		return nil, noEditPrivilege()
+	}
+
+	invalid := func(field string, problem string) error {
+		return apperrors.Validation("invalid task link", map[string]string{field: problem})
+	}
+
+	if !linkDTO.Type.IsValid() {
+		return nil, invalid("type", "must be one of blocks, blocked-by, relates-to, duplicates or duplicated-by")
+	}
+
+	if _, err := s.taskService.GetTaskByID(ctx, firebaseUID, projectID, linkDTO.SourceTaskID); err != nil {
+		return nil, err
+	}
+
+	if linkDTO.TargetTaskID == linkDTO.SourceTaskID {
+		return nil, invalid("taskId", "a task can't be linked to itself")
+	}
+	if _, err := s.taskService.GetTaskByID(ctx, firebaseUID, projectID, linkDTO.TargetTaskID); err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return nil, invalid("taskId", "must be a task in this project")
+		}
+		return nil, err
+	}
+
+	// Store the link the way round the table expects, keeping the source to read it back from
+	stored := *linkDTO
+	stored.CreatedBy = projectMember.ID
+	if !stored.Type.IsStored() {
+		stored.SourceTaskID, stored.TargetTaskID, stored.Type = stored.TargetTaskID, stored.SourceTaskID, stored.Type.Inverse()
+	}
+	if stored.Type == models.TaskLinkRelatesTo && stored.SourceTaskID.String() > stored.TargetTaskID.String() {
+		stored.SourceTaskID, stored.TargetTaskID = stored.TargetTaskID, stored.SourceTaskID
+	}
+
+	// The repository refuses links that would close a cycle, checking under a lock so that concurrent
+	// links can't close one between them
+	linkID, err := s.taskLinkRepository.CreateTaskLink(ctx, projectID, &stored)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.taskLinkRepository.GetTaskLink(ctx, linkDTO.SourceTaskID, linkID)
+}
+
+func (s *taskLinkService) GetTaskLinks(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID) ([]models.TaskLinkResponseDTO, error) {
+	if _, err := s.taskService.GetTaskByID(ctx, firebaseUID, projectID, taskID); err != nil {
+		return nil, err
+	}
+
+	return s.taskLinkRepository.GetTaskLinks(ctx, taskID)
+}
+
+func (s *taskLinkService) DeleteTaskLink(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID, linkID uuid.UUID) error {
+	projectMember, err := s.projectMemberService.GetProjectMemberByFirebaseUID(ctx, firebaseUID, projectID)
+	if err != nil {
+		return requireMember(err)
+	}
+
+	if !utils.HasEditPrivileges(projectMember) {
+		return noEditPrivilege()
+	}
+
+	if _, err := s.taskService.GetTaskByID(ctx, firebaseUID, projectID, taskID); err != nil {
+		return err
+	}
+
+	if _, err := s.taskLinkRepository.GetTaskLink(ctx, taskID, linkID); err != nil {
+		return err
+	}
+
+	return s.taskLinkRepository.DeleteTaskLink(ctx, linkID)
+}
@@ -1,139 +1,1189 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"log"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/logging"
 	"github.com/sarvochcha01/enlace-backend/internal/models"
 	"github.com/sarvochcha01/enlace-backend/internal/repositories"
 	"github.com/sarvochcha01/enlace-backend/internal/utils"
 )
 
 type TaskService interface {
-	CreateTask(taskDTo *models.CreateTaskDTO, firebaseUID string) (uuid.UUID, error)
-	GetTaskByID(fireabseUID string, projectID uuid.UUID, taskID uuid.UUID) (*models.TaskResponseDTO, error)
-	EditTask(uuid.UUID, uuid.UUID, string, *models.UpdateTaskDTO) error
-	DeleteTask(*models.DeleteTaskDTO) error
+	CreateTask(ctx context.Context, taskDTo *models.CreateTaskDTO, firebaseUID string) (uuid.UUID, error)
+	GetTaskByID(ctx context.Context, fireabseUID string, projectID uuid.UUID, taskID uuid.UUID) (*models.TaskResponseDTO, error)
+	GetTaskByKey(ctx context.Context, firebaseUID string, key string) (*models.TaskResponseDTO, error)
+	ListTasks(ctx context.Context, firebaseUID string, projectID uuid.UUID, query *models.TaskListQuery) (*models.TaskListResponseDTO, error)
+	CreateSubtask(ctx context.Context, firebaseUID string, projectID uuid.UUID, parentID uuid.UUID, taskDTO *models.CreateTaskDTO) (*models.TaskResponseDTO, error)
+	ListSubtasks(ctx context.Context, firebaseUID string, projectID uuid.UUID, parentID uuid.UUID, query *models.TaskListQuery) (*models.TaskListResponseDTO, error)
+	EditTask(context.Context, uuid.UUID, uuid.UUID, string, *models.UpdateTaskDTO) (*models.TaskResponseDTO, error)
+	PatchTask(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID, patch *models.PatchTaskDTO) (*models.TaskResponseDTO, error)
+	GetTaskActivity(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID) ([]models.TaskTimelineEntryDTO, error)
+	MoveTask(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID, move *models.MoveTaskDTO) (*models.TaskResponseDTO, error)
+	SetTaskRecurrence(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID, recurrence *models.SetTaskRecurrenceDTO) (*models.TaskResponseDTO, error)
+	StopTaskRecurrence(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID) (*models.TaskResponseDTO, error)
+	DeleteTask(context.Context, *models.DeleteTaskDTO) error
+	BulkUpdateTasks(ctx context.Context, firebaseUID string, projectID uuid.UUID, bulk *models.BulkTaskDTO) (*models.BulkTaskResponseDTO, error)
+	GetTaskWatchers(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID) ([]models.ProjectMemberResponseDTO, error)
+	// WatchTask and UnwatchTask start and stop the caller watching the task, returning its watchers
+	WatchTask(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID) ([]models.ProjectMemberResponseDTO, error)
+	UnwatchTask(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID) ([]models.ProjectMemberResponseDTO, error)
 }
 
 type taskService struct {
 	taskRepository       repositories.TaskRepository
+	sprintRepository     repositories.SprintRepository
 	userService          UserService
 	projectMemberService ProjectMemberService
 	notificationService  NotificationService
+	commentService       CommentService
+	workflowService      WorkflowService
 }
 
-func NewTaskService(tr repositories.TaskRepository, us UserService, pms ProjectMemberService, ns NotificationService) TaskService {
-	return &taskService{taskRepository: tr, userService: us, projectMemberService: pms, notificationService: ns}
+func NewTaskService(tr repositories.TaskRepository, sr repositories.SprintRepository, us UserService, pms ProjectMemberService, ns NotificationService, cs CommentService, ws WorkflowService) TaskService {
+	return &taskService{taskRepository: tr, sprintRepository: sr, userService: us, projectMemberService: pms, notificationService: ns, commentService: cs, workflowService: ws}
 }
 
-func (s *taskService) CreateTask(taskDTO *models.CreateTaskDTO, firebaseUID string) (uuid.UUID, error) {
+func (s *taskService) CreateTask(ctx context.Context, taskDTO *models.CreateTaskDTO, firebaseUID string) (uuid.UUID, error) {
 
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+	userID, err := s.userService.GetUserIDByFirebaseUID(ctx, firebaseUID)
 
 	if err != nil {
-		log.Println("UserID not found: ", err)
-		return uuid.Nil, errors.New("UserID not found: " + err.Error())
+		return uuid.Nil, err
 	}
 
-	projectMember, err := s.projectMemberService.GetProjectMemberByUserID(userID, taskDTO.ProjectID)
+	projectMember, err := s.projectMemberService.GetProjectMemberByUserID(ctx, userID, taskDTO.ProjectID)
 	if err != nil {
-		return uuid.Nil, errors.New("Project Member not found: " + err.Error())
+		return uuid.Nil, requireMember(err)
 	}
 
 	if !utils.HasEditPrivileges(projectMember) {
-		return uuid.Nil, errors.New("no edit privilege")
+		return uuid.Nil, noEditPrivilege()
 	}
 
 	taskDTO.CreatedBy = projectMember.ID
 	taskDTO.UpdatedBy = projectMember.ID
 
-	return s.taskRepository.CreateTask(taskDTO)
+	// Tasks start in the workflow's first status unless they say otherwise
+	workflow, err := s.workflowService.GetWorkflow(ctx, firebaseUID, taskDTO.ProjectID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if taskDTO.Status == "" {
+		taskDTO.Status = workflow.Initial()
+	} else if workflow.Status(taskDTO.Status) == nil {
+		return uuid.Nil, apperrors.Validation("invalid task", map[string]string{"status": "must be one of " + workflow.Keys()})
+	}
+
+	if taskDTO.ParentID != nil {
+		if err := s.checkParent(ctx, taskDTO.ProjectID, uuid.Nil, *taskDTO.ParentID); err != nil {
+			return uuid.Nil, err
+		}
+	}
+
+	problems := map[string]string{}
+	if problem := models.ValidateEstimate(taskDTO.OriginalEstimate); problem != "" {
+		problems["originalEstimate"] = problem
+	}
+	if problem := models.ValidateEstimate(taskDTO.RemainingEstimate); problem != "" {
+		problems["remainingEstimate"] = problem
+	}
+	if problem := models.ValidateStoryPoints(taskDTO.StoryPoints); problem != "" {
+		problems["storyPoints"] = problem
+	}
+	if len(problems) > 0 {
+		return uuid.Nil, apperrors.Validation("invalid task", problems)
+	}
+	if taskDTO.RemainingEstimate == nil {
+		taskDTO.RemainingEstimate = taskDTO.OriginalEstimate
+	}
+
+	if taskDTO.SprintID != nil {
+		if err := s.checkSprint(ctx, taskDTO.ProjectID, *taskDTO.SprintID); err != nil {
+			return uuid.Nil, err
+		}
+	}
+
+	if taskDTO.Recurrence != nil {
+		rule, err := checkRecurrence("recurrence", *taskDTO.Recurrence, taskDTO.DueDate)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		taskDTO.Recurrence = &rule
+	}
+	taskDTO.RecurrenceID = nil
+
+	return s.taskRepository.CreateTask(ctx, taskDTO)
 }
 
-func (s *taskService) GetTaskByID(firebaseUID string, projectID uuid.UUID, taskID uuid.UUID) (*models.TaskResponseDTO, error) {
-	_, err := s.projectMemberService.GetProjectMemberByFirebaseUID(firebaseUID, projectID)
+// CreateSubtask creates a task under parentID and returns it
+func (s *taskService) CreateSubtask(ctx context.Context, firebaseUID string, projectID uuid.UUID, parentID uuid.UUID, taskDTO *models.CreateTaskDTO) (*models.TaskResponseDTO, error) {
+	if _, err := s.GetTaskByID(ctx, firebaseUID, projectID, parentID); err != nil {
+		return nil, err
+	}
+
+	taskDTO.ProjectID = projectID
+	taskDTO.ParentID = &parentID
+
+	taskID, err := s.CreateTask(ctx, taskDTO, firebaseUID)
 	if err != nil {
-		return nil, nil
+		return nil, err
+	}
+
+	return s.taskRepository.GetFullTaskByID(ctx, taskID)
+}
+
+// checkParent reports a validation error unless parentID is a task in the project that can become
+// the parent of taskID, i.e. it is neither the task itself nor one of its subtasks at any depth.
+// taskID is uuid.Nil for a task that doesn't exist yet.
+func (s *taskService) checkParent(ctx context.Context, projectID uuid.UUID, taskID uuid.UUID, parentID uuid.UUID) error {
+	invalid := func(problem string) error {
+		return apperrors.Validation("invalid parent task", map[string]string{"parentId": problem})
+	}
+
+	parent, err := s.taskRepository.GetFullTaskByID(ctx, parentID)
+	if errors.Is(err, apperrors.ErrNotFound) || (err == nil && parent.ProjectID != projectID) {
+		return invalid("must be a task in this project")
+	}
+	if err != nil {
+		return err
+	}
+
+	if parentID == taskID {
+		return invalid("a task can't be its own parent")
+	}
+
+	if taskID != uuid.Nil {
+		ancestors, err := s.taskRepository.GetTaskAncestors(ctx, parentID)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(ancestors, taskID) {
+			return invalid("a task can't be a subtask of its own subtask")
+		}
 	}
-	return s.taskRepository.GetFullTaskByID(taskID)
+
+	return nil
 }
 
-func (s *taskService) GetTaskByIDNoAuth(taskID uuid.UUID) (*models.TaskResponseDTO, error) {
-	return s.taskRepository.GetFullTaskByID(taskID)
+func (s *taskService) GetTaskByID(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID) (*models.TaskResponseDTO, error) {
+	_, err := s.projectMemberService.GetProjectMemberByFirebaseUID(ctx, firebaseUID, projectID)
+	if err != nil {
+		return nil, requireMember(err)
+	}
+
+	task, err := s.taskRepository.GetFullTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.ProjectID != projectID {
+		return nil, apperrors.NotFound("task not found")
+	}
+
+	return task, nil
+}
+
+// GetTaskByKey resolves a key such as APO-42. A task of a project the caller isn't a member of is
+// reported as not found, so keys can't be used to probe other projects.
+func (s *taskService) GetTaskByKey(ctx context.Context, firebaseUID string, key string) (*models.TaskResponseDTO, error) {
+	projectKey, taskNumber, ok := models.ParseTaskKey(key)
+	if !ok {
+		return nil, apperrors.Validation("invalid task key", map[string]string{"key": "must be a project key and a task number, e.g. APO-42"})
+	}
+
+	task, err := s.taskRepository.GetTaskByKey(ctx, projectKey, taskNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.projectMemberService.GetProjectMemberByFirebaseUID(ctx, firebaseUID, task.ProjectID); err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return nil, apperrors.NotFound("task not found")
+		}
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// ListTasks is open to every project member. The "me" assignee filter is resolved to the caller's membership.
+func (s *taskService) ListTasks(ctx context.Context, firebaseUID string, projectID uuid.UUID, query *models.TaskListQuery) (*models.TaskListResponseDTO, error) {
+	projectMember, err := s.projectMemberService.GetProjectMemberByFirebaseUID(ctx, firebaseUID, projectID)
+	if err != nil {
+		return nil, requireMember(err)
+	}
+
+	if query.Filter.AssignedToMe {
+		query.Filter.AssignedTo = append(query.Filter.AssignedTo, projectMember.ID)
+		query.Filter.AssignedToMe = false
+	}
+
+	return s.taskRepository.ListTasks(ctx, projectID, query)
+}
+
+// ListSubtasks lists the direct subtasks of a task, with the same query options as ListTasks
+func (s *taskService) ListSubtasks(ctx context.Context, firebaseUID string, projectID uuid.UUID, parentID uuid.UUID, query *models.TaskListQuery) (*models.TaskListResponseDTO, error) {
+	if _, err := s.GetTaskByID(ctx, firebaseUID, projectID, parentID); err != nil {
+		return nil, err
+	}
+
+	query.Filter.ParentID = &parentID
+	return s.ListTasks(ctx, firebaseUID, projectID, query)
+}
+
+func (s *taskService) GetTaskByIDNoAuth(ctx context.Context, taskID uuid.UUID) (*models.TaskResponseDTO, error) {
+	return s.taskRepository.GetFullTaskByID(ctx, taskID)
+}
+
+// EditTask replaces every editable field of the task. It goes through PatchTask with all fields
+// present so that a full update is validated, notified and recorded in the history the same way.
+func (s *taskService) EditTask(ctx context.Context, taskID uuid.UUID, projectID uuid.UUID, firebaseUID string, updateTaskDTO *models.UpdateTaskDTO) (*models.TaskResponseDTO, error) {
+	patch := models.PatchTaskDTO{
+		Title:       models.PatchField[string]{Present: true, Value: &updateTaskDTO.Title},
+		Description: models.PatchField[string]{Present: true, Value: updateTaskDTO.Description},
+		Status:      models.PatchField[models.TaskStatus]{Present: true, Value: &updateTaskDTO.Status},
+		Priority:    models.PatchField[models.TaskPriority]{Present: true, Value: &updateTaskDTO.Priority},
+		DueDate:     models.PatchField[time.Time]{Present: true, Value: updateTaskDTO.DueDate},
+		AssignedTo:  models.PatchField[uuid.UUID]{Present: true, Value: updateTaskDTO.AssignedTo},
+
+		OverrideBlockers: updateTaskDTO.OverrideBlockers,
+		IfMatch:          updateTaskDTO.IfMatch,
+	}
+
+	return s.PatchTask(ctx, firebaseUID, projectID, taskID, &patch)
+}
+
+// PatchTask applies a merge patch. Only fields that actually change are written and recorded in
+// the task's history, and a patch that changes nothing doesn't write at all. A patch with an If-Match
+// that no longer matches the task fails with the task as it is now.
+func (s *taskService) PatchTask(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID, patch *models.PatchTaskDTO) (*models.TaskResponseDTO, error) {
+	projectMember, err := s.projectMemberService.GetProjectMemberByFirebaseUID(ctx, firebaseUID, projectID)
+	if err != nil {
+		return nil, requireMember(err)
+	}
+
+	if !utils.HasEditPrivileges(projectMember) {
+		return nil, noEditPrivilege()
+	}
+
+	if problems := patch.Validate(); problems != nil {
+		return nil, apperrors.Validation("invalid task patch", problems)
+	}
+
+	current, err := s.taskRepository.GetFullTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if current.ProjectID != projectID {
+		return nil, apperrors.NotFound("task not found")
+	}
+	if etag := models.ETag(current.UpdatedAt); !patch.IfMatch.Matches(etag) {
+		return nil, withCurrent(apperrors.PreconditionFailed("the task has changed since it was read"), current, etag)
+	}
+
+	if patch.AssignedTo.Present && patch.AssignedTo.Value != nil {
+		if err := s.checkAssignee(ctx, projectID, *patch.AssignedTo.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	if patch.ParentID.Present && patch.ParentID.Value != nil {
+		if err := s.checkParent(ctx, projectID, taskID, *patch.ParentID.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	if patch.SprintID.Present && patch.SprintID.Value != nil && (current.SprintID == nil || *current.SprintID != *patch.SprintID.Value) {
+		if err := s.checkSprint(ctx, projectID, *patch.SprintID.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(patch.Changes(current)) == 0 {
+		return current, nil
+	}
+
+	// Even a status that matches the task above may be a change by the time the task is locked
+	var changes []models.TaskFieldChange
+	if patch.Status.Present {
+		current, changes, err = s.patchTaskStatus(ctx, firebaseUID, projectMember, taskID, patch)
+	} else {
+		changes, err = s.taskRepository.PatchTask(ctx, taskID, projectMember.ID, patch)
+	}
+	if errors.Is(err, apperrors.ErrPreconditionFailed) {
+		// Another edit got in after the task was read above
+		if latest, getErr := s.taskRepository.GetFullTaskByID(ctx, taskID); getErr == nil {
+			return nil, withCurrent(err, latest, models.ETag(latest.UpdatedAt))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reassigned := slices.ContainsFunc(changes, func(change models.TaskFieldChange) bool {
+		return change.Field == models.TaskFieldAssignedTo
+	})
+	if reassigned && patch.AssignedTo.Value != nil {
+		title := current.Title
+		if patch.Title.Present {
+			title = *patch.Title.Value
+		}
+		s.notifyAssignee(ctx, projectID, *patch.AssignedTo.Value, projectMember.UserID, []models.TaskReferenceDTO{{ID: taskID, Title: title}})
+	}
+
+	task, err := s.taskRepository.GetFullTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyWatchers(ctx, projectMember, []*models.TaskResponseDTO{task}, map[uuid.UUID][]models.TaskFieldChange{taskID: changes})
+
+	if s.continueSeries(ctx, firebaseUID, projectMember, current, task) {
+		return s.taskRepository.GetFullTaskByID(ctx, taskID)
+	}
+
+	return task, nil
 }
 
-func (s *taskService) EditTask(taskID uuid.UUID, projectID uuid.UUID, firebaseUID string, updateTaskDTO *models.UpdateTaskDTO) error {
+// patchTaskStatus writes a patch that sets the task's status. A change is checked against the task
+// as it is locked for the write, so neither another edit nor a new blocker can get in between.
+// It returns the task as it was before the patch, and the changes.
+func (s *taskService) patchTaskStatus(ctx context.Context, firebaseUID string, projectMember *models.ProjectMemberResponseDTO, taskID uuid.UUID, patch *models.PatchTaskDTO) (*models.TaskResponseDTO, []models.TaskFieldChange, error) {
+	tx, err := s.taskRepository.BeginTransaction(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
 
-	userID, err := s.userService.GetUserIDByFirebaseUID(firebaseUID)
+	locked, err := s.taskRepository.LockTasks(ctx, tx, projectMember.ProjectID, []uuid.UUID{taskID})
 	if err != nil {
-		return errors.New("user not found")
+		return nil, nil, err
+	}
+	current, ok := locked[taskID]
+	if !ok {
+		return nil, nil, apperrors.NotFound("task not found")
+	}
+	if !patch.IfMatch.Matches(models.ETag(current.UpdatedAt)) {
+		return nil, nil, apperrors.PreconditionFailed("the task has changed since it was read")
+	}
+
+	if *patch.Status.Value != current.Status {
+		workflow, err := s.workflowService.GetWorkflow(ctx, firebaseUID, current.ProjectID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := checkStatusChange(ctx, workflow, projectMember, current, *patch.Status.Value, patch.OverrideBlockers); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	changes, err := s.taskRepository.PatchTasks(ctx, tx, []uuid.UUID{taskID}, projectMember.ID, patch)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
 	}
 
-	projectMember, err := s.projectMemberService.GetProjectMemberByUserID(userID, projectID)
+	return current, changes[taskID], nil
+}
+
+// MoveTask puts the task between its new neighbours on the board, changing its status as well if
+// the move is to another column, and tells the project's members so open boards can follow
+func (s *taskService) MoveTask(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID, move *models.MoveTaskDTO) (*models.TaskResponseDTO, error) {
+	projectMember, err := s.projectMemberService.GetProjectMemberByFirebaseUID(ctx, firebaseUID, projectID)
 	if err != nil {
-		return errors.New("Project Member not found: " + err.Error())
+		return nil, requireMember(err)
 	}
 
 	if !utils.HasEditPrivileges(projectMember) {
-		return errors.New("no edit privilege")
+		return nil, noEditPrivilege()
+	}
+
+	problems := map[string]string{}
+	if move.AfterTaskID != nil && *move.AfterTaskID == taskID {
+		problems["afterTaskId"] = "must be another task"
+	}
+	if move.BeforeTaskID != nil && *move.BeforeTaskID == taskID {
+		problems["beforeTaskId"] = "must be another task"
+	}
+	if len(problems) > 0 {
+		return nil, apperrors.Validation("invalid move", problems)
 	}
-	updateTaskDTO.UpdatedBy = projectMember.ID
 
-	if updateTaskDTO.AssignedTo != nil {
+	tx, err := s.taskRepository.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// The status change is checked against the task as it is locked for the move
+	locked, err := s.taskRepository.LockTasks(ctx, tx, projectID, []uuid.UUID{taskID})
+	if err != nil {
+		return nil, err
+	}
+	current, ok := locked[taskID]
+	if !ok {
+		return nil, apperrors.NotFound("task not found")
+	}
 
-		currentTask, err := s.taskRepository.GetFullTaskByID(taskID)
+	if move.Status == "" {
+		move.Status = current.Status
+	}
+	if move.Status != current.Status {
+		workflow, err := s.workflowService.GetWorkflow(ctx, firebaseUID, projectID)
 		if err != nil {
-			return errors.New("Failed to get current task: " + err.Error())
-		}
-
-		if currentTask.AssignedTo == nil || currentTask.AssignedTo.ID != *updateTaskDTO.AssignedTo {
-			var assignedToUserID uuid.UUID
-
-			assignedToUserID, err = s.projectMemberService.GetUserID(*updateTaskDTO.AssignedTo)
-
-			if err == nil {
-				if assignedToUserID != userID {
-					notification := &models.CreateNotificationDTO{
-						UserID:    assignedToUserID,
-						Type:      models.NotificationTypeTaskAssigned,
-						Content:   fmt.Sprintf("You have been assigned to task: %s", updateTaskDTO.Title),
-						ProjectID: projectID,
-						TaskID:    &taskID,
-					}
-					err = s.notificationService.CreateNotification(*notification)
-					if err != nil {
-						fmt.Println("Failed to create notification:", err)
-					}
-				}
-			}
+			return nil, err
 		}
+		if err := checkStatusChange(ctx, workflow, projectMember, current, move.Status, move.OverrideBlockers); err != nil {
+			return nil, err
+		}
+	}
+
+	// The status changes together with the rank, so a move that can't be placed changes nothing
+	rebalanced, err := s.taskRepository.MoveTask(ctx, tx, taskID, projectMember.ID, current.Status, move)
+	if err != nil {
+		return nil, err
+	}
 
+	if err := tx.Commit(); err != nil {
+		return nil, err
 	}
 
-	return s.taskRepository.EditTask(taskID, updateTaskDTO)
+	task, err := s.taskRepository.GetFullTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != current.Status {
+		change := models.TaskFieldChange{Field: models.TaskFieldStatus}
+		s.notifyWatchers(ctx, projectMember, []*models.TaskResponseDTO{task}, map[uuid.UUID][]models.TaskFieldChange{taskID: {change}})
+	}
+
+	if s.continueSeries(ctx, firebaseUID, projectMember, current, task) {
+		if task, err = s.taskRepository.GetFullTaskByID(ctx, taskID); err != nil {
+			return nil, err
+		}
+	}
+
+	s.publishProjectEvent(ctx, models.ProjectEventDTO{
+		Type:       models.ProjectEventTaskMoved,
+		ProjectID:  projectID,
+		ActorID:    projectMember.ID,
+		Task:       task,
+		Rebalanced: rebalanced,
+	})
+
+	return task, nil
 }
 
-func (s *taskService) DeleteTask(deleteTaskDTO *models.DeleteTaskDTO) error {
+// SetTaskRecurrence makes the task repeat by an RRULE such as FREQ=WEEKLY;BYDAY=MO,TH. The task
+// becomes the first occurrence of a new series, or if it is already part of one the rule of the
+// whole series changes. The rule applies from the next completion on.
+func (s *taskService) SetTaskRecurrence(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID, recurrence *models.SetTaskRecurrenceDTO) (*models.TaskResponseDTO, error) {
+	projectMember, task, err := s.getEditableTask(ctx, firebaseUID, projectID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := checkRecurrence("rule", recurrence.Rule, task.DueDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.taskRepository.SetTaskRecurrence(ctx, taskID, rule, projectMember.ID); err != nil {
+		return nil, err
+	}
+
+	return s.taskRepository.GetFullTaskByID(ctx, taskID)
+}
+
+// StopTaskRecurrence ends the series the task is part of. Its tasks are kept, but completing them
+// no longer creates new ones.
+func (s *taskService) StopTaskRecurrence(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID) (*models.TaskResponseDTO, error) {
+	if _, _, err := s.getEditableTask(ctx, firebaseUID, projectID, taskID); err != nil {
+		return nil, err
+	}
+
+	if err := s.taskRepository.StopTaskRecurrence(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	return s.taskRepository.GetFullTaskByID(ctx, taskID)
+}
 
-	projectMember, err := s.projectMemberService.GetProjectMemberByFirebaseUID(deleteTaskDTO.FirebaseUID, deleteTaskDTO.ProjectID)
+// getEditableTask returns the caller's membership and the task, provided the task is in the
+// project and the caller may edit it
+func (s *taskService) getEditableTask(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID) (*models.ProjectMemberResponseDTO, *models.TaskResponseDTO, error) {
+	projectMember, err := s.projectMemberService.GetProjectMemberByFirebaseUID(ctx, firebaseUID, projectID)
 	if err != nil {
-		return errors.New("failed to get project member")
+		return nil, nil, requireMember(err)
 	}
 
 	if !utils.HasEditPrivileges(projectMember) {
-		return errors.New("no edit privilege")
+		return nil, nil, noEditPrivilege()
+	}
+
+	task, err := s.taskRepository.GetFullTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if task.ProjectID != projectID {
+		return nil, nil, apperrors.NotFound("task not found")
+	}
+
+	return projectMember, task, nil
+}
+
+// checkRecurrence returns rule in the canonical form it is stored in, provided it is valid and
+// the task has a due date for the series to roll forward from. field names the rule in errors.
+func checkRecurrence(field string, rule string, dueDate *time.Time) (string, error) {
+	parsed, err := models.ParseRecurrenceRule(rule)
+	if err != nil {
+		return "", apperrors.Validation("invalid recurrence rule", map[string]string{field: err.Error()})
+	}
+	if dueDate == nil {
+		return "", apperrors.Validation("invalid recurrence rule", map[string]string{"dueDate": "a recurring task needs a due date"})
+	}
+	return parsed.String(), nil
+}
+
+// continueSeries creates the next occurrence of a recurring task that before and after show was
+// just completed, or ends the series when its rule has run out. It reports whether the series moved
+// on; like notifications, a failure is logged rather than failing the completion that caused it.
+func (s *taskService) continueSeries(ctx context.Context, firebaseUID string, projectMember *models.ProjectMemberResponseDTO, before *models.TaskResponseDTO, after *models.TaskResponseDTO) bool {
+	if !seriesCompleted(before, after) {
+		return false
+	}
+
+	logger := logging.FromContext(ctx)
+	series := after.Recurrence
+	workflow, err := s.workflowService.GetWorkflow(ctx, firebaseUID, after.ProjectID)
+	if err != nil {
+		logger.Error("failed to create next occurrence", "recurrence_id", series.ID, "task_id", after.ID, "error", err)
+		return false
+	}
+
+	next, err := nextOccurrence(workflow, projectMember, after)
+	if err != nil {
+		logger.Error("failed to create next occurrence", "recurrence_id", series.ID, "task_id", after.ID, "error", err)
+		return false
+	}
+
+	nextID, err := s.taskRepository.AdvanceTaskRecurrence(ctx, series.ID, after.ID, next)
+	if err != nil {
+		logger.Error("failed to create next occurrence", "recurrence_id", series.ID, "task_id", after.ID, "error", err)
+		return false
+	}
+
+	if nextID != uuid.Nil && next.AssignedTo != nil {
+		s.notifyAssignee(ctx, after.ProjectID, *next.AssignedTo, projectMember.UserID, []models.TaskReferenceDTO{{ID: nextID, Title: next.Title}})
+	}
+
+	return true
+}
+
+// seriesCompleted reports whether before and after show the current occurrence of a recurring
+// series was just completed
+func seriesCompleted(before *models.TaskResponseDTO, after *models.TaskResponseDTO) bool {
+	series := after.Recurrence
+	return series != nil && series.CurrentTaskID != nil && *series.CurrentTaskID == after.ID &&
+		before.StatusCategory != models.StatusCategoryDone && after.StatusCategory == models.StatusCategoryDone
+}
+
+// nextOccurrence returns the task that follows after in its series, or nil when the rule has run
+// out. The occurrence copies the task into the workflow's first status and the backlog with its
+// full estimate left, due on the next date of the rule.
+func nextOccurrence(workflow *models.WorkflowDTO, projectMember *models.ProjectMemberResponseDTO, after *models.TaskResponseDTO) (*models.CreateTaskDTO, error) {
+	series := after.Recurrence
+	rule, err := models.ParseRecurrenceRule(series.Rule)
+	if err != nil {
+		return nil, fmt.Errorf("recurrence rule %q can't be read: %w", series.Rule, err)
+	}
+
+	// A due date cleared after the rule was set rolls forward from today
+	previous := time.Now().UTC().Truncate(24 * time.Hour)
+	if after.DueDate != nil {
+		previous = *after.DueDate
+	}
+
+	dueDate, ok := rule.Next(previous, series.Occurrences)
+	if !ok {
+		return nil, nil
+	}
+
+	next := &models.CreateTaskDTO{
+		ProjectID:   after.ProjectID,
+		CreatedBy:   projectMember.ID,
+		UpdatedBy:   projectMember.ID,
+		Title:       after.Title,
+		Description: after.Description,
+		Status:      workflow.Initial(),
+		Priority:    after.Priority,
+		DueDate:     &dueDate,
+		ParentID:    after.ParentID,
+
+		OriginalEstimate:  after.OriginalEstimate,
+		RemainingEstimate: after.OriginalEstimate,
+		StoryPoints:       after.StoryPoints,
+	}
+	if after.AssignedTo != nil {
+		next.AssignedTo = &after.AssignedTo.ID
+	}
+	return next, nil
+}
+
+// publishProjectEvent sends event to the project's active members. Like notifications, a failure
+// is logged rather than failing the change that caused it.
+func (s *taskService) publishProjectEvent(ctx context.Context, event models.ProjectEventDTO) {
+	userIDs, err := s.projectMemberService.GetActiveUserIDs(ctx, event.ProjectID)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to publish project event", "project_id", event.ProjectID, "event_type", event.Type, "error", err)
+		return
+	}
+
+	s.notificationService.PublishProjectEvent(userIDs, event)
+}
+
+// GetTaskActivity merges the task's field changes and comments into one timeline, oldest first
+func (s *taskService) GetTaskActivity(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID) ([]models.TaskTimelineEntryDTO, error) {
+	if _, err := s.GetTaskByID(ctx, firebaseUID, projectID, taskID); err != nil {
+		return nil, err
+	}
+
+	activity, err := s.taskRepository.GetTaskActivity(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.commentService.GetAllCommentsForTask(ctx, taskID, projectID, firebaseUID)
+	if err != nil {
+		return nil, err
+	}
+
+	timeline := make([]models.TaskTimelineEntryDTO, 0, len(activity)+len(comments))
+	for i := range activity {
+		timeline = append(timeline, models.TaskTimelineEntryDTO{
+			Type:      models.TaskTimelineActivity,
+			CreatedAt: activity[i].CreatedAt,
+			Activity:  &activity[i],
+		})
+	}
+	for i := range comments {
+		createdAt, err := time.Parse(time.RFC3339Nano, comments[i].CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("comment %s has an unreadable creation time: %w", comments[i].ID, err)
+		}
+		timeline = append(timeline, models.TaskTimelineEntryDTO{
+			Type:      models.TaskTimelineComment,
+			CreatedAt: createdAt,
+			Comment:   &comments[i],
+		})
+	}
+
+	// Stable, so changes made together keep their field order
+	slices.SortStableFunc(timeline, func(a, b models.TaskTimelineEntryDTO) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return timeline, nil
+}
+
+// checkAssignee makes sure tasks are only assigned to members of their project
+func (s *taskService) checkAssignee(ctx context.Context, projectID uuid.UUID, assigneeID uuid.UUID) error {
+	assignee, err := s.projectMemberService.GetProjectMember(ctx, assigneeID)
+	if err != nil && !errors.Is(err, apperrors.ErrNotFound) {
+		return err
+	}
+	if err != nil || assignee.ProjectID != projectID {
+		return apperrors.Validation("invalid task patch", map[string]string{"assignedTo": "must be a member of this project"})
+	}
+	return nil
+}
+
+// checkSprint makes sure tasks only go into planned or active sprints of their project
+func (s *taskService) checkSprint(ctx context.Context, projectID uuid.UUID, sprintID uuid.UUID) error {
+	sprint, err := s.sprintRepository.GetSprint(ctx, sprintID)
+	if err != nil && !errors.Is(err, apperrors.ErrNotFound) {
+		return err
+	}
+	if err != nil || sprint.ProjectID != projectID || sprint.State == models.SprintClosed {
+		return apperrors.Validation("invalid task patch", map[string]string{"sprintId": "must be a planned or active sprint of this project"})
+	}
+	return nil
+}
+
+// checkStatusChange makes sure the task may move to status: the workflow has to allow it, and
+// completing a task with open blockers takes an owner's override. current should be locked, so
+// that neither can change before the task is written.
+func checkStatusChange(ctx context.Context, workflow *models.WorkflowDTO, projectMember *models.ProjectMemberResponseDTO, current *models.TaskResponseDTO, status models.TaskStatus, overrideBlockers bool) error {
+	target, err := checkTransition(workflow, current.Status, status)
+	if err != nil {
+		return err
+	}
+
+	completing := target.Category == models.StatusCategoryDone && current.StatusCategory != models.StatusCategoryDone
+	if !completing || len(current.OpenBlockers) == 0 {
+		return nil
+	}
+	if !overrideBlockers {
+		return blockedError(current.OpenBlockers)
+	}
+	if projectMember.Role != models.RoleOwner {
+		return apperrors.Forbidden("only project owners can complete a task that has open blockers")
+	}
+	logging.FromContext(ctx).Info("task completed despite open blockers", "task_id", current.ID, "project_member_id", projectMember.ID, "blockers", len(current.OpenBlockers))
+	return nil
+}
+
+// checkTransition returns the status a task moves to, provided the workflow has it and allows
+// moving there from the task's current status
+func checkTransition(workflow *models.WorkflowDTO, from models.TaskStatus, to models.TaskStatus) (*models.TaskStatusDTO, error) {
+	target := workflow.Status(to)
+	if target == nil {
+		return nil, apperrors.Validation("invalid task patch", map[string]string{"status": "must be one of " + workflow.Keys()})
+	}
+
+	if !workflow.CanTransition(from, to) {
+		allowed := "none, it is a final status"
+		if current := workflow.Status(from); current != nil && len(current.Transitions) > 0 {
+			allowed = strings.Join(statusStrings(current.Transitions), ", ")
+		}
+		return nil, &apperrors.Error{
+			Kind:    apperrors.KindConflict,
+			Message: fmt.Sprintf("the workflow doesn't allow moving a task from %s to %s", from, to),
+			Details: map[string]string{"status": "allowed from " + string(from) + ": " + allowed},
+		}
+	}
+
+	return target, nil
+}
+
+func statusStrings(statuses []models.TaskStatus) []string {
+	result := make([]string, len(statuses))
+	for i, status := range statuses {
+		result[i] = string(status)
+	}
+	return result
+}
+
+// blockedError refuses to complete a task, naming the blockers in the details
+func blockedError(blockers []models.TaskReferenceDTO) error {
+	details := make(map[string]string, len(blockers))
+	for _, blocker := range blockers {
+		details[blocker.ID.String()] = fmt.Sprintf("#%d %s is %s", blocker.TaskNumber, blocker.Title, blocker.Status)
+	}
+	return &apperrors.Error{
+		Kind:    apperrors.KindConflict,
+		Message: "the task has open blockers, complete them first or have an owner override",
+		Details: details,
+	}
+}
+
+// notifyAssignee tells a newly assigned member about the tasks unless they assigned them to
+// themselves. Several tasks make one notification, which only points at a task when there is one.
+// A failed notification is logged rather than failing the update that caused it.
+func (s *taskService) notifyAssignee(ctx context.Context, projectID uuid.UUID, assigneeID uuid.UUID, actorUserID uuid.UUID, tasks []models.TaskReferenceDTO) {
+	assignedToUserID, err := s.projectMemberService.GetUserID(ctx, assigneeID)
+	if err != nil || assignedToUserID == actorUserID || len(tasks) == 0 {
+		return
+	}
+
+	notification := models.CreateNotificationDTO{
+		UserID:    assignedToUserID,
+		Type:      models.NotificationTypeTaskAssigned,
+		ProjectID: projectID,
+	}
+	if len(tasks) == 1 {
+		notification.Content = fmt.Sprintf("You have been assigned to task: %s", tasks[0].Title)
+		notification.TaskID = &tasks[0].ID
+	} else {
+		titles := make([]string, len(tasks))
+		for i, task := range tasks {
+			titles[i] = task.Title
+		}
+		notification.Content = fmt.Sprintf("You have been assigned to %d tasks: %s", len(tasks), listTitles(titles))
+	}
+
+	if err := s.notificationService.CreateNotification(ctx, notification); err != nil {
+		logging.FromContext(ctx).Error("failed to notify assignee", "tasks", len(tasks), "user_id", assignedToUserID, "error", err)
+	}
+}
+
+// maxNotifiedTitles is how many task titles a notification about several tasks names
+const maxNotifiedTitles = 5
+
+// listTitles names the first maxNotifiedTitles of titles and counts the rest
+func listTitles(titles []string) string {
+	listed := slices.Clone(titles[:min(len(titles), maxNotifiedTitles)])
+	if len(titles) > maxNotifiedTitles {
+		listed = append(listed, fmt.Sprintf("and %d more", len(titles)-maxNotifiedTitles))
+	}
+	return strings.Join(listed, ", ")
+}
+
+// notifyWatchers tells the watchers of tasks, which have just been updated by actor, about the
+// status, assignee and due date changes among changes. The actor isn't told, and neither is a new
+// assignee, who hears about the assignment instead. A watcher gets one notification however many
+// of the tasks they watch changed. Failures are logged rather than failing the update.
+func (s *taskService) notifyWatchers(ctx context.Context, actor *models.ProjectMemberResponseDTO, tasks []*models.TaskResponseDTO, changes map[uuid.UUID][]models.TaskFieldChange) {
+	logger := logging.FromContext(ctx)
+
+	var userIDs []uuid.UUID
+	watched := map[uuid.UUID][]*models.TaskResponseDTO{}
+	for _, task := range tasks {
+		taskChanges := changes[task.ID]
+		if len(describeWatchedChanges(task, taskChanges)) == 0 {
+			continue
+		}
+
+		exclude := []uuid.UUID{actor.ID}
+		if task.AssignedTo != nil && slices.ContainsFunc(taskChanges, func(change models.TaskFieldChange) bool {
+			return change.Field == models.TaskFieldAssignedTo
+		}) {
+			exclude = append(exclude, task.AssignedTo.ID)
+		}
+
+		watchers, err := s.taskRepository.GetTaskWatchers(ctx, task.ID)
+		if err != nil {
+			logger.Error("failed to notify watchers", "task_id", task.ID, "error", err)
+			continue
+		}
+		for _, watcher := range watchersToNotify(watchers, exclude...) {
+			if _, ok := watched[watcher.UserID]; !ok {
+				userIDs = append(userIDs, watcher.UserID)
+			}
+			watched[watcher.UserID] = append(watched[watcher.UserID], task)
+		}
+	}
+
+	for _, userID := range userIDs {
+		tasks := watched[userID]
+		// The tasks were just updated, so they name the actor
+		actorName := tasks[0].UpdatedBy.Name
+		notification := models.CreateNotificationDTO{
+			UserID:    userID,
+			Type:      models.NotificationTypeTaskUpdated,
+			ProjectID: tasks[0].ProjectID,
+		}
+		if len(tasks) == 1 {
+			notification.Content = fmt.Sprintf("%s updated %s: %s", actorName, tasks[0].Title, strings.Join(describeWatchedChanges(tasks[0], changes[tasks[0].ID]), ", "))
+			notification.TaskID = &tasks[0].ID
+		} else {
+			titles := make([]string, len(tasks))
+			for i, task := range tasks {
+				titles[i] = task.Title
+			}
+			notification.Content = fmt.Sprintf("%s updated %d tasks you watch: %s", actorName, len(tasks), listTitles(titles))
+		}
+
+		if err := s.notificationService.CreateNotification(ctx, notification); err != nil {
+			logger.Error("failed to notify watcher", "tasks", len(tasks), "user_id", userID, "error", err)
+		}
+	}
+}
+
+// describeWatchedChanges words the changes watchers hear about, going by the task as it is after them
+func describeWatchedChanges(task *models.TaskResponseDTO, changes []models.TaskFieldChange) []string {
+	var described []string
+	for _, change := range changes {
+		switch change.Field {
+		case models.TaskFieldStatus:
+			described = append(described, fmt.Sprintf("status changed to %s", task.Status))
+		case models.TaskFieldAssignedTo:
+			if task.AssignedTo != nil {
+				described = append(described, fmt.Sprintf("assigned to %s", task.AssignedTo.Name))
+			} else {
+				described = append(described, "unassigned")
+			}
+		case models.TaskFieldDueDate:
+			if task.DueDate != nil {
+				described = append(described, fmt.Sprintf("due date changed to %s", task.DueDate.Format(time.DateOnly)))
+			} else {
+				described = append(described, "due date removed")
+			}
+		}
+	}
+	return described
+}
+
+// watchersToNotify leaves out the watchers who are no longer active members and those in exclude
+func watchersToNotify(watchers []models.ProjectMemberResponseDTO, exclude ...uuid.UUID) []models.ProjectMemberResponseDTO {
+	var notified []models.ProjectMemberResponseDTO
+	for _, watcher := range watchers {
+		if watcher.Status == models.StatusActive && !slices.Contains(exclude, watcher.ID) {
+			notified = append(notified, watcher)
+		}
+	}
+	return notified
+}
+
+func (s *taskService) GetTaskWatchers(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID) ([]models.ProjectMemberResponseDTO, error) {
+	if _, err := s.GetTaskByID(ctx, firebaseUID, projectID, taskID); err != nil {
+		return nil, err
 	}
 
-	task, err := s.GetTaskByIDNoAuth(deleteTaskDTO.TaskID)
+	return s.taskRepository.GetTaskWatchers(ctx, taskID)
+}
+
+func (s *taskService) WatchTask(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID) ([]models.ProjectMemberResponseDTO, error) {
+	return s.setWatching(ctx, firebaseUID, projectID, taskID, true)
+}
+
+func (s *taskService) UnwatchTask(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID) ([]models.ProjectMemberResponseDTO, error) {
+	return s.setWatching(ctx, firebaseUID, projectID, taskID, false)
+}
+
+// setWatching starts or stops the caller watching the task. Watching doesn't change the task, so
+// viewers may watch as well.
+func (s *taskService) setWatching(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID, watch bool) ([]models.ProjectMemberResponseDTO, error) {
+	projectMember, err := s.projectMemberService.GetProjectMemberByFirebaseUID(ctx, firebaseUID, projectID)
+	if err != nil {
+		return nil, requireMember(err)
+	}
+
+	task, err := s.taskRepository.GetFullTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.ProjectID != projectID {
+		return nil, apperrors.NotFound("task not found")
+	}
+
+	if watch {
+		err = s.taskRepository.WatchTask(ctx, taskID, projectMember.ID)
+	} else {
+		err = s.taskRepository.UnwatchTask(ctx, taskID, projectMember.ID)
+	}
 	if err != nil {
-		return errors.New("failed to get task")
+		return nil, err
+	}
+
+	return s.taskRepository.GetTaskWatchers(ctx, taskID)
+}
+
+func (s *taskService) DeleteTask(ctx context.Context, deleteTaskDTO *models.DeleteTaskDTO) error {
+	projectMember, err := requireEditor(ctx, s.projectMemberService, deleteTaskDTO.FirebaseUID, deleteTaskDTO.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	task, err := s.GetTaskByIDNoAuth(ctx, deleteTaskDTO.TaskID)
+	if err != nil {
+		return err
+	}
+	if task.ProjectID != deleteTaskDTO.ProjectID {
+		return apperrors.NotFound("task not found")
 	}
 
 	if projectMember.Role != models.RoleOwner && task.CreatedBy.ID != projectMember.ID {
-		return errors.New("only the owner or task creator can delete this task")
+		return apperrors.Forbidden("only the owner or task creator can delete this task")
+	}
+	return s.taskRepository.DeleteTask(ctx, deleteTaskDTO.TaskID)
+}
+
+// BulkUpdateTasks applies the same change to many tasks, checking each of them like PatchTask or
+// DeleteTask would. The tasks are locked, checked and written in one transaction, along with the
+// next occurrences of recurring tasks it completes. If any task fails its check nothing is applied,
+// and the response says which tasks failed and why. Assignees get one notification for all the
+// tasks assigned to them, and watchers one for all the tasks they watch.
+func (s *taskService) BulkUpdateTasks(ctx context.Context, firebaseUID string, projectID uuid.UUID, bulk *models.BulkTaskDTO) (*models.BulkTaskResponseDTO, error) {
+	projectMember, err := s.projectMemberService.GetProjectMemberByFirebaseUID(ctx, firebaseUID, projectID)
+	if err != nil {
+		return nil, requireMember(err)
+	}
+
+	if !utils.HasEditPrivileges(projectMember) {
+		return nil, noEditPrivilege()
+	}
+
+	if problems := bulk.Validate(); problems != nil {
+		return nil, apperrors.Validation("invalid bulk operation", problems)
+	}
+
+	patch := bulk.Patch()
+	if patch.AssignedTo.Present && patch.AssignedTo.Value != nil {
+		if err := s.checkAssignee(ctx, projectID, *patch.AssignedTo.Value); err != nil {
+			return nil, err
+		}
+	}
+	if patch.SprintID.Present && patch.SprintID.Value != nil {
+		if err := s.checkSprint(ctx, projectID, *patch.SprintID.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := s.taskRepository.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	current, err := s.taskRepository.LockTasks(ctx, tx, projectID, bulk.TaskIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read after the lock, which a workflow change waits for, so the checks see the workflow the
+	// tasks are written under
+	var workflow *models.WorkflowDTO
+	if patch.Status.Present {
+		if workflow, err = s.workflowService.GetWorkflow(ctx, firebaseUID, projectID); err != nil {
+			return nil, err
+		}
 	}
-	return s.taskRepository.DeleteTask(deleteTaskDTO.TaskID)
+
+	response := &models.BulkTaskResponseDTO{Results: make([]models.BulkTaskResultDTO, len(bulk.TaskIDs))}
+	failed := false
+	for i, taskID := range bulk.TaskIDs {
+		response.Results[i].TaskID = taskID
+
+		if err := checkBulkTask(ctx, workflow, projectMember, current[taskID], bulk, patch); err != nil {
+			failed = true
+			errResponse := apperrors.ResponseOf(err, "")
+			response.Results[i].Outcome = models.BulkTaskFailed
+			response.Results[i].Error = &errResponse
+		}
+	}
+
+	if failed {
+		for i := range response.Results {
+			if response.Results[i].Outcome != models.BulkTaskFailed {
+				response.Results[i].Outcome = models.BulkTaskSkipped
+			}
+		}
+		return response, nil
+	}
+
+	if bulk.Delete {
+		if err := s.taskRepository.DeleteTasks(ctx, tx, bulk.TaskIDs); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		for i := range response.Results {
+			response.Results[i].Outcome = models.BulkTaskDeleted
+		}
+		response.Applied = true
+		return response, nil
+	}
+
+	var changing []uuid.UUID
+	for i := range response.Results {
+		task := current[response.Results[i].TaskID]
+		if len(patch.Changes(task)) == 0 {
+			response.Results[i].Outcome = models.BulkTaskUnchanged
+			response.Results[i].Task = task
+		} else {
+			changing = append(changing, task.ID)
+		}
+	}
+
+	changes, err := s.taskRepository.PatchTasks(ctx, tx, changing, projectMember.ID, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.taskRepository.LockTasks(ctx, tx, projectID, changing)
+	if err != nil {
+		return nil, err
+	}
+
+	// Completed occurrences of recurring series are continued in the same transaction
+	var continued []uuid.UUID
+	nextByAssignee := make(map[uuid.UUID][]models.TaskReferenceDTO)
+	for _, taskID := range changing {
+		after := updated[taskID]
+		if !seriesCompleted(current[taskID], after) {
+			continue
+		}
+
+		next, err := nextOccurrence(workflow, projectMember, after)
+		if err != nil {
+			return nil, err
+		}
+		nextID, err := s.taskRepository.AdvanceTaskRecurrenceTx(ctx, tx, after.Recurrence.ID, taskID, next)
+		if err != nil {
+			return nil, err
+		}
+
+		continued = append(continued, taskID)
+		if nextID != uuid.Nil && next.AssignedTo != nil {
+			nextByAssignee[*next.AssignedTo] = append(nextByAssignee[*next.AssignedTo], models.TaskReferenceDTO{ID: nextID, Title: next.Title})
+		}
+	}
+
+	// The series have moved on, so the tasks are read again to show it
+	var advanced map[uuid.UUID]*models.TaskResponseDTO
+	if len(continued) > 0 {
+		if advanced, err = s.taskRepository.LockTasks(ctx, tx, projectID, continued); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	response.Applied = true
+
+	var assigned []models.TaskReferenceDTO
+	watched := make([]*models.TaskResponseDTO, 0, len(changing))
+	for i := range response.Results {
+		result := &response.Results[i]
+		if result.Outcome == models.BulkTaskUnchanged {
+			continue
+		}
+
+		result.Outcome = models.BulkTaskUpdated
+		result.Task = updated[result.TaskID]
+		watched = append(watched, result.Task)
+		if task, ok := advanced[result.TaskID]; ok {
+			result.Task = task
+		}
+
+		if slices.ContainsFunc(changes[result.TaskID], func(change models.TaskFieldChange) bool {
+			return change.Field == models.TaskFieldAssignedTo
+		}) {
+			assigned = append(assigned, models.TaskReferenceDTO{ID: result.TaskID, TaskNumber: result.Task.TaskNumber, Title: result.Task.Title, Status: result.Task.Status})
+		}
+	}
+
+	if patch.AssignedTo.Value != nil {
+		s.notifyAssignee(ctx, projectID, *patch.AssignedTo.Value, projectMember.UserID, assigned)
+	}
+	for assigneeID, tasks := range nextByAssignee {
+		s.notifyAssignee(ctx, projectID, assigneeID, projectMember.UserID, tasks)
+	}
+	s.notifyWatchers(ctx, projectMember, watched, changes)
+
+	return response, nil
+}
+
+// checkBulkTask says why the bulk operation can't be applied to current, the task as it is locked
+// before the operation, or nil when it can. current is nil for a task that isn't in the project.
+func checkBulkTask(ctx context.Context, workflow *models.WorkflowDTO, projectMember *models.ProjectMemberResponseDTO, current *models.TaskResponseDTO, bulk *models.BulkTaskDTO, patch *models.PatchTaskDTO) error {
+	if current == nil {
+		return apperrors.NotFound("task not found")
+	}
+
+	if bulk.Delete {
+		if projectMember.Role != models.RoleOwner && current.CreatedBy.ID != projectMember.ID {
+			return apperrors.Forbidden("only the owner or task creator can delete this task")
+		}
+		return nil
+	}
+
+	if patch.Status.Present && *patch.Status.Value != current.Status {
+		return checkStatusChange(ctx, workflow, projectMember, current, *patch.Status.Value, patch.OverrideBlockers)
+	}
+
+	return nil
 }
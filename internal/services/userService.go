@@ -1,17 +1,18 @@
 package services
 
 import (
+	"context"
 	"github.com/google/uuid"
 	"github.com/sarvochcha01/enlace-backend/internal/models"
 	"github.com/sarvochcha01/enlace-backend/internal/repositories"
 )
 
 type UserService interface {
-	CreateUser(*models.CreateUserDTO) error
-	GetUserIDByFirebaseUID(string) (uuid.UUID, error)
-	GetUserByFirebaseUID(string) (*models.UserResponseDTO, error)
+	CreateUser(context.Context, *models.CreateUserDTO) error
+	GetUserIDByFirebaseUID(context.Context, string) (uuid.UUID, error)
+	GetUserByFirebaseUID(context.Context, string) (*models.UserResponseDTO, error)
 
-	SearchUsers(searchQuery string) ([]models.UserResponseDTO, error)
+	SearchUsers(ctx context.Context, searchQuery string) ([]models.UserResponseDTO, error)
 }
 
 type userService struct {
@@ -22,19 +23,19 @@ func NewUserService(r repositories.UserRepository) UserService {
 	return &userService{userRepository: r}
 }
 
-func (s *userService) CreateUser(userDTO *models.CreateUserDTO) error {
-	return s.userRepository.CreateUser(userDTO)
+func (s *userService) CreateUser(ctx context.Context, userDTO *models.CreateUserDTO) error {
+	return s.userRepository.CreateUser(ctx, userDTO)
 }
 
-func (s *userService) GetUserIDByFirebaseUID(firebaseUID string) (uuid.UUID, error) {
-	return s.userRepository.GetUserIDByFirebaseUID(firebaseUID)
+func (s *userService) GetUserIDByFirebaseUID(ctx context.Context, firebaseUID string) (uuid.UUID, error) {
+	return s.userRepository.GetUserIDByFirebaseUID(ctx, firebaseUID)
 }
 
-func (s *userService) GetUserByFirebaseUID(firebaseUID string) (*models.UserResponseDTO, error) {
+func (s *userService) GetUserByFirebaseUID(ctx context.Context, firebaseUID string) (*models.UserResponseDTO, error) {
 
-	return s.userRepository.GetUserByFirebaseUID(firebaseUID)
+	return s.userRepository.GetUserByFirebaseUID(ctx, firebaseUID)
 }
 
-func (s *userService) SearchUsers(searchQuery string) ([]models.UserResponseDTO, error) {
-	return s.userRepository.SearchUsers(searchQuery)
+func (s *userService) SearchUsers(ctx context.Context, searchQuery string) ([]models.UserResponseDTO, error) {
+	return s.userRepository.SearchUsers(ctx, searchQuery)
 }
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/repositories"
+)
+
+type WorkflowService interface {
+	GetWorkflow(ctx context.Context, firebaseUID string, projectID uuid.UUID) (*models.WorkflowDTO, error)
+	UpdateWorkflow(ctx context.Context, firebaseUID string, projectID uuid.UUID, workflow *models.WorkflowDTO) (*models.WorkflowDTO, error)
+}
+
+type workflowService struct {
+	workflowRepository   repositories.WorkflowRepository
+	projectMemberService ProjectMemberService
+}
+
+func NewWorkflowService(wr repositories.WorkflowRepository, pms ProjectMemberService) WorkflowService {
+	return &workflowService{workflowRepository: wr, projectMemberService: pms}
+}
+
+func (s *workflowService) GetWorkflow(ctx context.Context, firebaseUID string, projectID uuid.UUID) (*models.WorkflowDTO, error) {
+	if _, err := s.projectMemberService.GetProjectMemberByFirebaseUID(ctx, firebaseUID, projectID); err != nil {
+		return nil, requireMember(err)
+	}
+
+	return s.workflowRepository.GetWorkflow(ctx, projectID)
+}
+
+// UpdateWorkflow replaces the project's statuses and transitions. Only owners can change the
+// workflow, and statuses can only be removed once no task is in them.
+func (s *workflowService) UpdateWorkflow(ctx context.Context, firebaseUID string, projectID uuid.UUID, workflow *models.WorkflowDTO) (*models.WorkflowDTO, error) {
+	projectMember, err := s.projectMemberService.GetProjectMemberByFirebaseUID(ctx, firebaseUID, projectID)
+	if err != nil {
+		return nil, requireMember(err)
+	}
+
+	if projectMember.Role != models.RoleOwner {
+		return nil, apperrors.Forbidden("only project owners can change the workflow")
+	}
+
+	workflow.Normalize()
+	if problems := workflow.Validate(); problems != nil {
+		return nil, apperrors.Validation("invalid workflow", problems)
+	}
+
+	if err := s.workflowRepository.ReplaceWorkflow(ctx, projectID, workflow); err != nil {
+		return nil, err
+	}
+
+	return s.workflowRepository.GetWorkflow(ctx, projectID)
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/models"
+	"github.com/sarvochcha01/enlace-backend/internal/repositories"
+)
+
+type WorklogService interface {
+	CreateWorklog(ctx context.Context, firebaseUID string, projectID uuid.UUID, worklogDTO *models.CreateWorklogDTO) (*models.WorklogResponseDTO, error)
+	GetTaskWorklogs(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID) ([]models.WorklogResponseDTO, error)
+	UpdateWorklog(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID, worklogID uuid.UUID, worklogDTO *models.UpdateWorklogDTO) (*models.WorklogResponseDTO, error)
+	DeleteWorklog(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID, worklogID uuid.UUID) error
+	GetTimeReport(ctx context.Context, firebaseUID string, projectID uuid.UUID, query *models.TimeReportQuery) (*models.TimeReportDTO, error)
+}
+
+type worklogService struct {
+	worklogRepository    repositories.WorklogRepository
+	taskService          TaskService
+	projectMemberService ProjectMemberService
+}
+
+func NewWorklogService(wr repositories.WorklogRepository, ts TaskService, pms ProjectMemberService) WorklogService {
+	return &worklogService{worklogRepository: wr, taskService: ts, projectMemberService: pms}
+}
+
+// CreateWorklog logs time on a task for the caller, who owns the work log from then on. Logging
+// takes edit privileges, like changing the task does.
+func (s *worklogService) CreateWorklog(ctx context.Context, firebaseUID string, projectID uuid.UUID, worklogDTO *models.CreateWorklogDTO) (*models.WorklogResponseDTO, error) {
+	projectMember, err := requireEditor(ctx, s.projectMemberService, firebaseUID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.taskService.GetTaskByID(ctx, firebaseUID, projectID, worklogDTO.TaskID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	worklogDTO.Date, worklogDTO.Note = models.NormalizeWorklog(worklogDTO.Date, worklogDTO.Note, now)
+	if problems := models.ValidateWorklog(worklogDTO.Duration, worklogDTO.Date, worklogDTO.Note, now); problems != nil {
+		return nil, apperrors.Validation("invalid work log", problems)
+	}
+
+	worklogDTO.ProjectMemberID = projectMember.ID
+	worklogID, err := s.worklogRepository.CreateWorklog(ctx, worklogDTO)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.worklogRepository.GetWorklog(ctx, worklogID)
+}
+
+func (s *worklogService) GetTaskWorklogs(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID) ([]models.WorklogResponseDTO, error) {
+	if _, err := s.taskService.GetTaskByID(ctx, firebaseUID, projectID, taskID); err != nil {
+		return nil, err
+	}
+
+	return s.worklogRepository.GetTaskWorklogs(ctx, taskID)
+}
+
+// UpdateWorklog replaces the duration, date and note of one of the caller's own work logs
+func (s *worklogService) UpdateWorklog(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID, worklogID uuid.UUID, worklogDTO *models.UpdateWorklogDTO) (*models.WorklogResponseDTO, error) {
+	projectMember, worklog, err := s.getTaskWorklog(ctx, firebaseUID, projectID, taskID, worklogID)
+	if err != nil {
+		return nil, err
+	}
+
+	if worklog.Author.ID != projectMember.ID {
+		return nil, apperrors.Forbidden("you can only edit your own work logs")
+	}
+
+	now := time.Now()
+	worklogDTO.Date, worklogDTO.Note = models.NormalizeWorklog(worklogDTO.Date, worklogDTO.Note, now)
+	if problems := models.ValidateWorklog(worklogDTO.Duration, worklogDTO.Date, worklogDTO.Note, now); problems != nil {
+		return nil, apperrors.Validation("invalid work log", problems)
+	}
+
+	if err := s.worklogRepository.UpdateWorklog(ctx, worklogID, worklogDTO); err != nil {
+		return nil, err
+	}
+
+	return s.worklogRepository.GetWorklog(ctx, worklogID)
+}
+
+// DeleteWorklog deletes a work log of the caller's own or, for owners, anyone's
+func (s *worklogService) DeleteWorklog(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID, worklogID uuid.UUID) error {
+	projectMember, worklog, err := s.getTaskWorklog(ctx, firebaseUID, projectID, taskID, worklogID)
+	if err != nil {
+		return err
+	}
+
+	if worklog.Author.ID != projectMember.ID && projectMember.Role != models.RoleOwner {
+		return apperrors.Forbidden("only the owner or the author can delete this work log")
+	}
+
+	return s.worklogRepository.DeleteWorklog(ctx, worklogID)
+}
+
+// getTaskWorklog returns the caller's membership and the work log, answering not found for work
+// logs of other tasks as well as missing ones
+func (s *worklogService) getTaskWorklog(ctx context.Context, firebaseUID string, projectID uuid.UUID, taskID uuid.UUID, worklogID uuid.UUID) (*models.ProjectMemberResponseDTO, *models.WorklogResponseDTO, error) {
+	projectMember, err := s.projectMemberService.GetProjectMemberByFirebaseUID(ctx, firebaseUID, projectID)
+	if err != nil {
+		return nil, nil, requireMember(err)
+	}
+
+	if _, err := s.taskService.GetTaskByID(ctx, firebaseUID, projectID, taskID); err != nil {
+		return nil, nil, err
+	}
+
+	worklog, err := s.worklogRepository.GetWorklog(ctx, worklogID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if worklog.TaskID != taskID {
+		return nil, nil, apperrors.NotFound("work log not found")
+	}
+
+	return projectMember, worklog, nil
+}
+
+// GetTimeReport totals the project's logged time per task, per member and overall. Every member
+// may see it.
+func (s *worklogService) GetTimeReport(ctx context.Context, firebaseUID string, projectID uuid.UUID, query *models.TimeReportQuery) (*models.TimeReportDTO, error) {
+	if _, err := s.projectMemberService.GetProjectMemberByFirebaseUID(ctx, firebaseUID, projectID); err != nil {
+		return nil, requireMember(err)
+	}
+
+	return s.worklogRepository.GetTimeReport(ctx, projectID, query)
+}
@@ -3,147 +3,319 @@ package websockets
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
+	"strings"
 	"sync"
+	"time"
 
-	"firebase.google.com/go/auth"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/sarvochcha01/enlace-backend/internal/apperrors"
+	"github.com/sarvochcha01/enlace-backend/internal/auth"
+	"github.com/sarvochcha01/enlace-backend/internal/logging"
+	"github.com/sarvochcha01/enlace-backend/internal/metrics"
 	"github.com/sarvochcha01/enlace-backend/internal/models"
 )
 
-// WebSocket upgrader
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		return true // Allow all connections (configure for production)
-	},
-}
+const (
+	// Messages queued per client before it is treated as too slow and skipped
+	sendBufferSize = 32
+	// Time allowed to write a message or close frame to a client
+	writeWait = 10 * time.Second
+)
 
-// Client struct to manage WebSocket connections
+// Client struct to manage WebSocket connections. Send carries notifications and project events,
+// which clients tell apart by the type field events have.
 type Client struct {
 	Conn   *websocket.Conn
-	Send   chan models.NotificationResponseDTO
+	Send   chan any
 	UserID uuid.UUID
 }
 
 type UserIDFinder interface {
-	GetUserIDByFirebaseUID(firebaseUID string) (uuid.UUID, error)
+	GetUserIDByFirebaseUID(ctx context.Context, firebaseUID string) (uuid.UUID, error)
 }
 
-// WebSocketHub manages active clients
+// WebSocketHub manages active clients. A user can hold several connections, e.g. one per browser tab.
 type WebSocketHub struct {
-	Clients    map[uuid.UUID]*Client
+	Clients    map[uuid.UUID]map[*Client]struct{}
 	Broadcast  chan models.NotificationResponseDTO
 	Register   chan *Client
 	Unregister chan *Client
 	mu         sync.Mutex
-	authClient *auth.Client
+	verifier   auth.TokenVerifier
 	userFinder UserIDFinder
+	upgrader   websocket.Upgrader
+	metrics    *metrics.Metrics
+
+	ping     chan struct{}
+	quit     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+	writers  sync.WaitGroup
 }
 
 func (hub *WebSocketHub) SetUserFinder(finder UserIDFinder) {
 	hub.userFinder = finder
 }
 
-// NewWebSocketHub initializes a WebSocketHub
-func NewWebSocketHub(ac *auth.Client) *WebSocketHub {
+// NewWebSocketHub initializes a WebSocketHub that accepts upgrades from the given origins ("*" allows any).
+// m may be nil.
+func NewWebSocketHub(verifier auth.TokenVerifier, allowedOrigins []string, m *metrics.Metrics) *WebSocketHub {
 	hub := &WebSocketHub{
-		Clients:    make(map[uuid.UUID]*Client),
+		Clients:    make(map[uuid.UUID]map[*Client]struct{}),
 		Broadcast:  make(chan models.NotificationResponseDTO),
 		Register:   make(chan *Client),
 		Unregister: make(chan *Client),
-		authClient: ac,
+		verifier:   verifier,
+		metrics:    m,
+		ping:       make(chan struct{}),
+		quit:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+
+	hub.upgrader = websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			// Non-browser clients don't send an Origin header
+			if origin == "" {
+				return true
+			}
+			for _, allowed := range allowedOrigins {
+				if allowed == "*" || strings.EqualFold(allowed, origin) {
+					return true
+				}
+			}
+			return false
+		},
 	}
 
 	return hub
 }
 
-// Run starts the WebSocketHub
+// Run starts the WebSocketHub and returns once Shutdown is called
 func (hub *WebSocketHub) Run() {
+	defer close(hub.stopped)
+
 	for {
 		select {
 		case client := <-hub.Register:
 			hub.mu.Lock()
-			hub.Clients[client.UserID] = client
+			if hub.Clients[client.UserID] == nil {
+				hub.Clients[client.UserID] = make(map[*Client]struct{})
+			}
+			hub.Clients[client.UserID][client] = struct{}{}
 			hub.mu.Unlock()
 		case client := <-hub.Unregister:
 			hub.mu.Lock()
-			if _, ok := hub.Clients[client.UserID]; ok {
-				close(client.Send)
-				delete(hub.Clients, client.UserID)
-			}
+			hub.removeClient(client)
 			hub.mu.Unlock()
 		case notification := <-hub.Broadcast:
 			hub.mu.Lock()
-			for _, client := range hub.Clients {
-				client.Send <- notification
+			for _, clients := range hub.Clients {
+				for client := range clients {
+					client.enqueue(notification)
+				}
 			}
 			hub.mu.Unlock()
+		case <-hub.ping:
+		case <-hub.quit:
+			// Closing Send lets every WritePump flush what is queued and send a close frame
+			hub.mu.Lock()
+			for _, clients := range hub.Clients {
+				for client := range clients {
+					hub.removeClient(client)
+				}
+			}
+			hub.mu.Unlock()
+			return
 		}
 	}
 }
 
+var ErrHubStopped = errors.New("websocket hub stopped")
+
+// Ping succeeds once the Run loop has picked it up, proving the loop is running and not stuck
+func (hub *WebSocketHub) Ping(ctx context.Context) error {
+	select {
+	case hub.ping <- struct{}{}:
+		return nil
+	case <-hub.stopped:
+		return ErrHubStopped
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown stops the hub, sends a close frame to every connected client and waits for
+// their pending writes to finish or for ctx to expire.
+func (hub *WebSocketHub) Shutdown(ctx context.Context) error {
+	hub.stopOnce.Do(func() { close(hub.quit) })
+
+	select {
+	case <-hub.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		hub.writers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ConnectedClients is the number of open connections across all users
+func (hub *WebSocketHub) ConnectedClients() int {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	count := 0
+	for _, clients := range hub.Clients {
+		count += len(clients)
+	}
+	return count
+}
+
+// removeClient must be called with hub.mu held
+func (hub *WebSocketHub) removeClient(client *Client) {
+	clients, ok := hub.Clients[client.UserID]
+	if !ok {
+		return
+	}
+
+	if _, ok := clients[client]; ok {
+		close(client.Send)
+		delete(clients, client)
+	}
+
+	if len(clients) == 0 {
+		delete(hub.Clients, client.UserID)
+	}
+}
+
 // HandleWebSocket handles WebSocket connections
 func (h *WebSocketHub) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromContext(r.Context())
+
+	select {
+	case <-h.quit:
+		h.metrics.RecordError(string(apperrors.KindUnavailable))
+		apperrors.Write(w, apperrors.Unavailable("Server shutting down"), "")
+		return
+	default:
+	}
 
 	token := r.URL.Query().Get("token")
 	if token == "" {
-		log.Printf("Missing token")
-		http.Error(w, "Missing token", http.StatusUnauthorized)
+		h.metrics.RecordError(string(apperrors.KindUnauthorized))
+		apperrors.Write(w, apperrors.Unauthorized("Missing token"), "")
 		return
 	}
 
-	firebaseToken, err := h.authClient.VerifyIDToken(context.Background(), token)
+	authToken, err := h.verifier.VerifyToken(r.Context(), token)
 	if err != nil {
-		log.Printf("Invalid token: %v", err)
-		http.Error(w, "Invalid token", http.StatusUnauthorized)
+		logger.Info("websocket token rejected", "error", err)
+		h.metrics.RecordError(string(apperrors.KindUnauthorized))
+		apperrors.Write(w, apperrors.Unauthorized("Invalid token"), "")
 		return
 	}
 
 	if h.userFinder == nil {
-		log.Println("UserFinder not set")
-		http.Error(w, "Server configuration error", http.StatusInternalServerError)
+		logger.Error("websocket user finder not set")
+		h.metrics.RecordError(string(apperrors.KindInternal))
+		apperrors.Write(w, nil, "Server configuration error")
 		return
 	}
 
-	userID, err := h.userFinder.GetUserIDByFirebaseUID(firebaseToken.UID)
+	userID, err := h.userFinder.GetUserIDByFirebaseUID(r.Context(), authToken.UID)
 	if err != nil {
-		log.Println("No user found")
-		http.Error(w, "No user found", http.StatusBadRequest)
+		logger.Info("websocket user lookup failed", "error", err)
+		h.metrics.RecordError(string(apperrors.KindOf(err)))
+		apperrors.Write(w, err, "Failed to look up user")
 		return
 	}
 
-	conn, err := upgrader.Upgrade(w, r, nil)
+	conn, err := h.upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Println("WebSocket upgrade failed:", err)
+		logger.Info("websocket upgrade failed", "error", err)
 		return
 	}
 
 	client := &Client{
 		Conn:   conn,
 		UserID: userID,
-		Send:   make(chan models.NotificationResponseDTO),
+		Send:   make(chan any, sendBufferSize),
 	}
 
-	h.Register <- client
+	// Counted before the hub can see the client, so Shutdown waits for its close frame
+	h.writers.Add(1)
+	select {
+	case h.Register <- client:
+		logger.Debug("websocket client connected", "user_id", userID)
+	case <-h.quit:
+		h.writers.Done()
+		client.close(websocket.CloseGoingAway, "server shutting down")
+		return
+	}
 
 	go client.ReadPump(h)
-	go client.WritePump()
+	go func() {
+		defer h.writers.Done()
+		client.WritePump(h.metrics)
+	}()
 }
 
 func (h *WebSocketHub) SendNotificationToUser(notification models.NotificationResponseDTO) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	if client, exists := h.Clients[notification.UserID]; exists {
-		client.Send <- notification
+	for client := range h.Clients[notification.UserID] {
+		client.enqueue(notification)
+	}
+}
+
+// SendEventToUsers delivers a project event to every connection of the given users
+func (h *WebSocketHub) SendEventToUsers(userIDs []uuid.UUID, event models.ProjectEventDTO) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, userID := range userIDs {
+		for client := range h.Clients[userID] {
+			client.enqueue(event)
+		}
+	}
+}
+
+// enqueue never blocks so one slow client can't stall the hub; must be called with hub.mu held
+func (c *Client) enqueue(message any) {
+	select {
+	case c.Send <- message:
+	default:
+		switch message := message.(type) {
+		case models.NotificationResponseDTO:
+			slog.Warn("dropping notification for slow websocket client", "user_id", c.UserID, "notification_id", message.ID)
+		case models.ProjectEventDTO:
+			slog.Warn("dropping project event for slow websocket client", "user_id", c.UserID, "event_type", message.Type, "project_id", message.ProjectID)
+		}
 	}
 }
 
 func (c *Client) ReadPump(hub *WebSocketHub) {
 	defer func() {
-		hub.Unregister <- c
+		select {
+		case hub.Unregister <- c:
+		case <-hub.quit:
+		}
 		c.Conn.Close()
 	}()
 
@@ -155,16 +327,28 @@ func (c *Client) ReadPump(hub *WebSocketHub) {
 	}
 }
 
-func (c *Client) WritePump() {
-	for notification := range c.Send {
-		data, err := json.Marshal(notification)
+// WritePump delivers queued messages until Send is closed, then says goodbye with a close frame
+func (c *Client) WritePump(m *metrics.Metrics) {
+	for message := range c.Send {
+		data, err := json.Marshal(message)
 		if err != nil {
-			log.Println("Failed to encode notification:", err)
+			slog.Error("failed to encode websocket message", "user_id", c.UserID, "error", err)
 			continue
 		}
 
+		c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
 		if err := c.Conn.WriteMessage(websocket.TextMessage, data); err != nil {
 			break
 		}
+		if _, ok := message.(models.NotificationResponseDTO); ok {
+			m.NotificationDelivered()
+		}
 	}
+
+	c.close(websocket.CloseGoingAway, "connection closed by server")
+}
+
+func (c *Client) close(code int, reason string) {
+	c.Conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(writeWait))
+	c.Conn.Close()
 }
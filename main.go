@@ -1,9 +1,19 @@
 package main
 
-import "github.com/sarvochcha01/enlace-backend/cmd/app"
+import (
+	"os"
+
+	"github.com/sarvochcha01/enlace-backend/cmd/app"
+)
 
 func main() {
 	app := app.App{}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		app.Migrate(os.Args[2:])
+		return
+	}
+
 	app.Initialise()
 	app.Run()
 }